@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,7 +23,11 @@ import (
 	"github.com/Emyrk/strava/api/webhooks"
 	"github.com/Emyrk/strava/database"
 	"github.com/Emyrk/strava/database/gencache"
+	"github.com/Emyrk/strava/internal/certificate"
 	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/internal/nearby"
+	"github.com/Emyrk/strava/internal/predict"
+	"github.com/Emyrk/strava/lib/diskcache"
 	server "github.com/Emyrk/strava/site"
 )
 
@@ -33,6 +39,9 @@ type Options struct {
 	VerifyToken   string
 	SigningKeyPEM []byte
 	Registry      *prometheus.Registry
+	// RenderCacheDir keeps rendered map images, a temp directory if empty.
+	RenderCacheDir   string
+	RenderCacheBytes int64
 }
 
 type OAuthOptions struct {
@@ -51,17 +60,33 @@ type API struct {
 
 	SuperHugelBoardCache *gencache.LazyCache[[]database.SuperHugelLeaderboardRow]
 
-	HugelBoard2023Cache *gencache.LazyCache[[]database.HugelLeaderboardRow]
+	HugelBoard2023Cache *gencache.LazyCache[hugelBoard]
 
-	HugelBoard2024Cache     *gencache.LazyCache[[]database.HugelLeaderboardRow]
-	HugelBoard2024LiteCache *gencache.LazyCache[[]database.HugelLeaderboardRow]
+	HugelBoard2024Cache     *gencache.LazyCache[hugelBoard]
+	HugelBoard2024LiteCache *gencache.LazyCache[hugelBoard]
 
-	HugelBoard2025Cache     *gencache.LazyCache[[]database.HugelLeaderboardRow]
-	HugelBoard2025LiteCache *gencache.LazyCache[[]database.HugelLeaderboardRow]
+	HugelBoard2025Cache     *gencache.LazyCache[hugelBoard]
+	HugelBoard2025LiteCache *gencache.LazyCache[hugelBoard]
 
 	HugelRouteCache     *gencache.LazyCache[database.GetCompetitiveRouteRow]
 	HugelLiteRouteCache *gencache.LazyCache[database.GetCompetitiveRouteRow]
 
+	HugelFatigueCache     *gencache.LazyCache[predict.Fatigue]
+	HugelLiteFatigueCache *gencache.LazyCache[predict.Fatigue]
+
+	SegmentIndexCache *gencache.LazyCache[*nearby.Index]
+
+	EddingtonBoardCache *gencache.LazyCache[[]database.EddingtonLeaderboardRow]
+
+	// GroupRidesCaches are the detected groups by edition route name.
+	GroupRidesCaches map[string]*gencache.LazyCache[groupRides]
+
+	CertificateCache *renderCache
+	RenderCache      *diskcache.Cache
+	// ProfileFailures are the activities whose altitude stream failed to
+	// fetch recently, Strava is not asked again until they expire.
+	ProfileFailures *renderCache
+
 	// Metrics
 	Registry *prometheus.Registry
 }
@@ -106,8 +131,8 @@ func New(ctx context.Context, opts Options) (*API, error) {
 		return api.Opts.DB.SuperHugelLeaderboard(ctx, 0)
 	})
 
-	api.HugelBoard2023Cache = gencache.New(ctx, time.Hour*48, func(ctx context.Context) ([]database.HugelLeaderboardRow, error) {
-		return api.Opts.DB.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+	api.HugelBoard2023Cache = gencache.New(ctx, time.Hour*48, func(ctx context.Context) (hugelBoard, error) {
+		return api.loadHugelBoard(ctx, database.YearlyHugelLeaderboardParams{
 			RouteYear: 2023,
 			HugelLeaderboardParams: database.HugelLeaderboardParams{
 				AthleteID: -1,
@@ -116,8 +141,8 @@ func New(ctx context.Context, opts Options) (*API, error) {
 			},
 		})
 	})
-	api.HugelBoard2024Cache = gencache.New(ctx, time.Hour*48, func(ctx context.Context) ([]database.HugelLeaderboardRow, error) {
-		return api.Opts.DB.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+	api.HugelBoard2024Cache = gencache.New(ctx, time.Hour*48, func(ctx context.Context) (hugelBoard, error) {
+		return api.loadHugelBoard(ctx, database.YearlyHugelLeaderboardParams{
 			RouteYear: 2024,
 			HugelLeaderboardParams: database.HugelLeaderboardParams{
 				AthleteID: -1,
@@ -126,8 +151,8 @@ func New(ctx context.Context, opts Options) (*API, error) {
 			},
 		})
 	})
-	api.HugelBoard2024LiteCache = gencache.New(ctx, time.Hour*48, func(ctx context.Context) ([]database.HugelLeaderboardRow, error) {
-		return api.Opts.DB.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+	api.HugelBoard2024LiteCache = gencache.New(ctx, time.Hour*48, func(ctx context.Context) (hugelBoard, error) {
+		return api.loadHugelBoard(ctx, database.YearlyHugelLeaderboardParams{
 			RouteYear: 2024,
 			Lite:      true,
 			HugelLeaderboardParams: database.HugelLeaderboardParams{
@@ -137,8 +162,8 @@ func New(ctx context.Context, opts Options) (*API, error) {
 			},
 		})
 	})
-	api.HugelBoard2025Cache = gencache.New(ctx, time.Minute*15, func(ctx context.Context) ([]database.HugelLeaderboardRow, error) {
-		return api.Opts.DB.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+	api.HugelBoard2025Cache = gencache.New(ctx, time.Minute*15, func(ctx context.Context) (hugelBoard, error) {
+		return api.loadHugelBoard(ctx, database.YearlyHugelLeaderboardParams{
 			RouteYear: 2025,
 			HugelLeaderboardParams: database.HugelLeaderboardParams{
 				AthleteID: -1,
@@ -147,8 +172,8 @@ func New(ctx context.Context, opts Options) (*API, error) {
 			},
 		})
 	})
-	api.HugelBoard2025LiteCache = gencache.New(ctx, time.Minute*15, func(ctx context.Context) ([]database.HugelLeaderboardRow, error) {
-		return api.Opts.DB.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+	api.HugelBoard2025LiteCache = gencache.New(ctx, time.Minute*15, func(ctx context.Context) (hugelBoard, error) {
+		return api.loadHugelBoard(ctx, database.YearlyHugelLeaderboardParams{
 			RouteYear: 2025,
 			Lite:      true,
 			HugelLeaderboardParams: database.HugelLeaderboardParams{
@@ -164,6 +189,47 @@ func New(ctx context.Context, opts Options) (*API, error) {
 	api.HugelLiteRouteCache = gencache.New(ctx, time.Hour*4, func(ctx context.Context) (database.GetCompetitiveRouteRow, error) {
 		return api.Opts.DB.GetCompetitiveRoute(ctx, "lite-das-hugel")
 	})
+	api.CertificateCache = newRenderCache(time.Hour, 512)
+	api.ProfileFailures = newRenderCache(profileBackoff, 1024)
+	renderDir := opts.RenderCacheDir
+	if renderDir == "" {
+		renderDir = filepath.Join(os.TempDir(), "strava-render")
+	}
+	renderBytes := opts.RenderCacheBytes
+	if renderBytes <= 0 {
+		renderBytes = 256 << 20
+	}
+	api.RenderCache, err = diskcache.Open(renderDir, renderBytes)
+	if err != nil {
+		return nil, fmt.Errorf("open render cache: %w", err)
+	}
+	api.HugelFatigueCache = gencache.New(ctx, time.Hour*24, func(ctx context.Context) (predict.Fatigue, error) {
+		return routeFatigue(ctx, api.Opts.DB, "das-hugel")
+	})
+	api.HugelLiteFatigueCache = gencache.New(ctx, time.Hour*24, func(ctx context.Context) (predict.Fatigue, error) {
+		return routeFatigue(ctx, api.Opts.DB, "lite-das-hugel")
+	})
+
+	api.SegmentIndexCache = gencache.New(ctx, time.Hour, func(ctx context.Context) (*nearby.Index, error) {
+		return nearby.Load(ctx, api.Opts.DB)
+	})
+
+	api.EddingtonBoardCache = gencache.New(ctx, time.Minute*15, func(ctx context.Context) ([]database.EddingtonLeaderboardRow, error) {
+		return api.Opts.DB.EddingtonLeaderboard(ctx)
+	})
+
+	// Groups are kept as long as the board they are shown on.
+	api.GroupRidesCaches = make(map[string]*gencache.LazyCache[groupRides], len(hugeldate.Editions))
+	for _, e := range hugeldate.Editions {
+		board := api.editionBoardCache(e)
+		if board == nil {
+			continue
+		}
+		routeName := e.RouteName
+		api.GroupRidesCaches[routeName] = gencache.New(ctx, board.Stale, func(ctx context.Context) (groupRides, error) {
+			return api.fetchGroupRides(ctx, routeName)
+		})
+	}
 
 	return api, nil
 }
@@ -175,6 +241,13 @@ func (api *API) HugelRoute(ctx context.Context, lite bool) (database.GetCompetit
 	return api.HugelRouteCache.Load(ctx)
 }
 
+func (api *API) HugelFatigue(ctx context.Context, lite bool) (predict.Fatigue, error) {
+	if lite {
+		return api.HugelLiteFatigueCache.Load(ctx)
+	}
+	return api.HugelFatigueCache.Load(ctx)
+}
+
 // StartWebhook needs to be called after the API is served.
 func (api *API) StartWebhook(ctx context.Context, setup bool) (<-chan *webhooks.Handled[webhooks.WebhookEvent], error) {
 	if setup {
@@ -218,10 +291,20 @@ func (api *API) Routes() chi.Router {
 					r.Get("/hugels", api.athleteHugels)
 					r.Get("/sync-summary", api.syncSummary)
 					r.Get("/eddington", api.eddingtonNumber)
+					r.Get("/eddington/history", api.eddingtonHistory)
+					r.Get("/calendar", api.activityCalendar)
+					r.Get("/segments/{segment_id}/efforts", api.segmentEffortHistory)
+					r.Get("/certificate/{activity_id}.{format}", api.finisherCertificate(certificate.Full))
+					r.Get("/certificate/{activity_id}/og.{format}", api.finisherCertificate(certificate.OpenGraph))
+					r.Get("/year/{year}", api.yearReview)
+					r.Put("/year/{year}/share", api.setYearReviewShared)
+					r.Get("/year/{year}/og.{format}", api.yearReviewImage)
 				})
 			})
 			r.Route("/athletes", func(r chi.Router) {
 				r.Get("/eddington", api.allEddingtons)
+				r.Get("/eddington/leaderboard", api.eddingtonLeaderboard)
+				r.Get("/eddington/{metric}", api.eddingtonMetricLeaderboard)
 			})
 		})
 		r.Group(func(r chi.Router) {
@@ -245,6 +328,29 @@ func (api *API) Routes() chi.Router {
 			r.Route("/missing", func(r chi.Router) {
 				r.Get("/{activity_id}", api.missingSegments)
 			})
+			r.Route("/activities", func(r chi.Router) {
+				r.Get("/{activity_id}.geojson", api.activityGeoJSON)
+			})
+			// Only route renders are public, an activity's track is as private
+			// as its geojson.
+			r.Get("/render/activity/{activity_id}.png", api.renderActivity)
+			r.Route("/heatmap-opt-out", func(r chi.Router) {
+				r.Get("/", api.heatmapOptOut)
+				r.Put("/", api.setHeatmapOptOut)
+			})
+			r.Route("/leaderboard-opt-out", func(r chi.Router) {
+				r.Get("/", api.leaderboardOptOut)
+				r.Put("/", api.setLeaderboardOptOut)
+			})
+			r.Route("/route/{route-name}/reference", func(r chi.Router) {
+				r.Use(httpmw.AuthenticatedAsAdmins())
+				r.Put("/", api.setRouteReference)
+				r.Delete("/", api.clearRouteReference)
+			})
+			r.Route("/hugelboard/export/gpx", func(r chi.Router) {
+				r.Use(httpmw.AuthenticatedAsAdmins())
+				r.Get("/", api.hugelboardExportGPX)
+			})
 		})
 		r.Group(func(r chi.Router) {
 			// Unauthenticated routes
@@ -253,18 +359,33 @@ func (api *API) Routes() chi.Router {
 			)
 			r.Get("/superhugelboard", api.superHugelboard)
 			r.Get("/hugelboard", api.hugelboard)
+			r.Get("/hugelboard/export", api.hugelboardExport)
 			r.Route("/route", func(r chi.Router) {
 				r.Get("/{route-name}", api.competitiveRoute)
+				r.Get("/{route-name}.geojson", api.routeGeoJSON)
 				r.Get("/{route-name}/verify/{route-id}", api.verifyRoute)
+				r.Get("/{route-name}/compare", api.compareRouteResults)
+				r.Get("/{route-name}/pacing/{activity_id}", api.routePacing)
+				r.Get("/{route-name}/predict", api.predictRoute)
+				r.Get("/{route-name}/course.{format}", api.routeCourse)
+				r.Get("/{route-name}/unofficial", api.unofficialRouteResults)
+			})
+			r.Route("/events/{edition}", func(r chi.Router) {
+				r.Get("/heatmap", api.eventHeatmap)
+				r.Get("/heatmap.{format}", api.eventHeatmap)
+				r.Get("/groups", api.eventGroupRides)
 			})
+			r.Get("/render/route/{route-name}.png", api.renderRoute)
 			r.Route("/segments", func(r chi.Router) {
 				r.Post("/", api.getSegments)
+				r.Get("/search", api.searchSegments)
+				r.Get("/{segment_id}.geojson", api.segmentGeoJSON)
 			})
 		})
 		r.NotFound(api.apiNotFound)
 	})
 	r.Get("/logout", api.logout)
-	r.NotFound(server.Handler(server.FS()).ServeHTTP)
+	r.NotFound(server.Handler(server.FS(), api).ServeHTTP)
 
 	return r
 }
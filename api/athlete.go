@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/Emyrk/strava/api/httpapi"
 	"github.com/Emyrk/strava/api/httpmw"
 	"github.com/Emyrk/strava/api/modelsdk"
 	river2 "github.com/Emyrk/strava/api/river"
 	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/eddington"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -230,7 +232,16 @@ func (api *API) missingSegments(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, missing)
+	resp, err := api.explainMissingSegments(ctx, actID, missing)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to explain missing segments",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
 }
 
 func (api *API) forwardLoadAthlete(rw http.ResponseWriter, r *http.Request) {
@@ -362,13 +373,97 @@ func (api *API) eddingtonNumber(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, modelsdk.Eddington{
-		AthleteID:        eddington.AthleteID,
-		MilesHistogram:   eddington.MilesHistogram,
-		CurrentEddington: eddington.CurrentEddington,
-		LastCalculated:   eddington.LastCalculated.Time,
-		TotalActivities:  eddington.TotalActivities,
-	})
+	breakdowns, err := api.Opts.DB.GetAthleteEddingtonBreakdowns(ctx, ath.Athlete.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to fetch eddington breakdowns",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	metrics, err := api.Opts.DB.GetAthleteEddingtonMetrics(ctx, ath.Athlete.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to fetch eddington metrics",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertEddington(eddington, breakdowns, metrics))
+}
+
+// eddingtonPlanSteps is how many numbers past the current one are planned.
+const eddingtonPlanSteps = 5
+
+// eddingtonHistory replays the athlete's rides, so it is always up to date
+// and works in either unit.
+func (api *API) eddingtonHistory(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		_, ok = httpmw.AuthenticatedAthleteIDOptional(r)
+		ath   = httpmw.Athlete(r)
+		unit  = r.URL.Query().Get("unit")
+	)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, modelsdk.Response{
+			Message: "Synced data requires authentication. No authentication provided",
+		})
+		return
+	}
+
+	if !httpmw.RequestAuthenticatedAsAdminsOrMe(rw, r, ath.Athlete.ID) {
+		return
+	}
+
+	step := eddington.Miles
+	switch unit {
+	case "", "mi":
+		unit = "mi"
+	case "km":
+		step = eddington.Kilometers
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Unsupported unit %q, use mi or km", unit),
+		})
+		return
+	}
+
+	acts, err := api.Opts.DB.EddingtonActivities(ctx, ath.Athlete.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to fetch eddington activities",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	days := eddington.DailyTotals(eddington.FromRows(acts), eddington.SportAll)
+	sums := eddington.FromDays(days, step)
+	resp := modelsdk.EddingtonHistory{
+		AthleteID:  ath.Athlete.ID,
+		Unit:       unit,
+		Current:    sums.Current(),
+		Milestones: []modelsdk.EddingtonMilestone{},
+		Plan:       []modelsdk.EddingtonGoal{},
+	}
+	for _, m := range eddington.History(days, step) {
+		resp.Milestones = append(resp.Milestones, modelsdk.EddingtonMilestone{
+			Eddington:  m.Eddington,
+			Date:       m.Date,
+			ActivityID: modelsdk.StringInt(m.ActivityID),
+		})
+	}
+	for _, g := range eddington.Plan(sums, eddingtonPlanSteps) {
+		resp.Plan = append(resp.Plan, modelsdk.EddingtonGoal{
+			Eddington: g.Eddington,
+			Have:      g.Have,
+			Need:      g.Need,
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
 }
 
 func (api *API) allEddingtons(rw http.ResponseWriter, r *http.Request) {
@@ -396,6 +491,48 @@ func (api *API) allEddingtons(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(ctx, rw, http.StatusOK, sdkAll)
 }
 
+// eddingtonMetricLeaderboard ranks athletes by one of the numbers that are not
+// counted from distance.
+func (api *API) eddingtonMetricLeaderboard(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		metric = chi.URLParam(r, "metric")
+	)
+
+	if _, ok := eddington.MetricByName(metric); !ok {
+		names := make([]string, 0, len(eddington.Metrics))
+		for _, m := range eddington.Metrics {
+			names = append(names, m.Name)
+		}
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: fmt.Sprintf("Unknown eddington metric %q", metric),
+			Detail:  fmt.Sprintf("Metrics are %s", strings.Join(names, ", ")),
+		})
+		return
+	}
+
+	board, err := api.Opts.DB.EddingtonMetricLeaderboard(ctx, metric)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to fetch eddington leaderboard",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	out := make([]modelsdk.EddingtonMetricRank, 0, len(board))
+	for _, row := range board {
+		out = append(out, modelsdk.EddingtonMetricRank{
+			Rank:      row.Rank,
+			AthleteID: modelsdk.StringInt(row.AthleteID),
+			Eddington: row.Eddington,
+			Days:      row.Days,
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, out)
+}
+
 func convertActivitySummary(activity database.ActivitySummary) modelsdk.ActivitySummary {
 	return modelsdk.ActivitySummary{
 		ActivityID:     modelsdk.StringInt(activity.ID),
@@ -447,3 +584,38 @@ func convertHugelSegmentEfforts(dbEfforts []database.HugelSegmentEffort) []model
 	}
 	return efforts
 }
+
+func convertEddington(e database.AthleteEddington, breakdowns []database.AthleteEddingtonBreakdown, metrics []database.AthleteEddingtonMetric) modelsdk.Eddington {
+	out := modelsdk.Eddington{
+		AthleteID:           e.AthleteID,
+		MilesHistogram:      e.MilesHistogram,
+		CurrentEddington:    e.CurrentEddington,
+		KilometersHistogram: e.KilometersHistogram,
+		CurrentEddingtonKm:  e.CurrentEddingtonKm,
+		LastCalculated:      e.LastCalculated.Time,
+		TotalActivities:     e.TotalActivities,
+		TotalDays:           e.TotalDays,
+		Breakdowns:          make([]modelsdk.EddingtonBreakdown, 0, len(breakdowns)),
+		Metrics:             make([]modelsdk.EddingtonMetric, 0, len(metrics)),
+	}
+	for _, b := range breakdowns {
+		out.Breakdowns = append(out.Breakdowns, modelsdk.EddingtonBreakdown{
+			Sport:               b.Sport,
+			Year:                b.Year,
+			Days:                b.Days,
+			MilesHistogram:      b.MilesHistogram,
+			Miles:               b.EddingtonMiles,
+			KilometersHistogram: b.KilometersHistogram,
+			Kilometers:          b.EddingtonKilometers,
+		})
+	}
+	for _, m := range metrics {
+		out.Metrics = append(out.Metrics, modelsdk.EddingtonMetric{
+			Metric:    m.Metric,
+			Days:      m.Days,
+			Histogram: m.Histogram,
+			Eddington: m.Eddington,
+		})
+	}
+	return out
+}
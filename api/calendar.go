@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/trainingload"
+)
+
+const (
+	calendarDateFormat  = "2006-01-02"
+	calendarDefaultDays = 90
+	calendarMaxDays     = 366
+)
+
+// activityCalendar returns the athlete's days between from and to, by default
+// the last 90. The load is computed on every request from the history before
+// from, so it always reflects the athlete's current FTP.
+func (api *API) activityCalendar(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		_, ok = httpmw.AuthenticatedAthleteIDOptional(r)
+		ath   = httpmw.Athlete(r)
+		query = r.URL.Query()
+	)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, modelsdk.Response{
+			Message: "Synced data requires authentication. No authentication provided",
+		})
+		return
+	}
+
+	if !httpmw.RequestAuthenticatedAsAdminsOrMe(rw, r, ath.Athlete.ID) {
+		return
+	}
+
+	date := func(name string, def time.Time) (time.Time, bool) {
+		v := query.Get(name)
+		if v == "" {
+			return def, true
+		}
+		t, err := time.Parse(calendarDateFormat, v)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: fmt.Sprintf("Invalid %s", name),
+				Detail:  fmt.Sprintf("%q is not a date like %s", v, calendarDateFormat),
+			})
+			return time.Time{}, false
+		}
+		return t, true
+	}
+
+	now := time.Now().UTC()
+	to, ok := date("to", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if !ok {
+		return
+	}
+	from, ok := date("from", to.AddDate(0, 0, -calendarDefaultDays+1))
+	if !ok {
+		return
+	}
+	if to.Before(from) || to.Sub(from) >= calendarMaxDays*24*time.Hour {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid date range",
+			Detail:  fmt.Sprintf("from must be before to, and at most %d days apart", calendarMaxDays),
+		})
+		return
+	}
+
+	rows, err := api.Opts.DB.ActivityCalendar(ctx, database.ActivityCalendarParams{
+		AthleteID: ath.Athlete.ID,
+		After:     database.Timestamptz(from.Add(-trainingload.Warmup)),
+		Before:    database.Timestamptz(to.AddDate(0, 0, 1)),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load activities",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	days := trainingload.Calendar(trainingload.FromRows(rows), ath.Athlete.Ftp, from, to)
+	out := modelsdk.Calendar{
+		AthleteID: modelsdk.StringInt(ath.Athlete.ID),
+		FTP:       ath.Athlete.Ftp,
+		From:      from,
+		To:        to,
+		Days:      make([]modelsdk.CalendarDay, 0, len(days)),
+	}
+	for _, day := range days {
+		out.Days = append(out.Days, modelsdk.CalendarDay{
+			Date:             day.Date,
+			Activities:       day.Activities,
+			Distance:         day.Distance,
+			Elevation:        day.Elevation,
+			MovingTime:       day.MovingTime,
+			Kilojoules:       day.Kilojoules,
+			SufferScore:      day.SufferScore,
+			AverageHeartrate: day.AverageHeartrate,
+			Stress:           day.Stress,
+			ATL:              day.ATL,
+			CTL:              day.CTL,
+			TSB:              day.TSB,
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, out)
+}
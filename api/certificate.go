@@ -0,0 +1,241 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/database/gencache"
+	"github.com/Emyrk/strava/internal/certificate"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// finisherCertificate renders the certificate of a hugel result as svg or png.
+func (api *API) finisherCertificate(layout certificate.Layout) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		var (
+			ctx     = r.Context()
+			athlete = httpmw.Athlete(r)
+			format  = chi.URLParam(r, "format")
+		)
+
+		activityID, err := strconv.ParseInt(chi.URLParam(r, "activity_id"), 10, 64)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: "Invalid activity id",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		var contentType string
+		switch format {
+		case "svg":
+			contentType = "image/svg+xml"
+		case "png":
+			contentType = "image/png"
+		default:
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: fmt.Sprintf("Unsupported format %q, use svg or png", format),
+			})
+			return
+		}
+
+		// The owner is only checked on a miss, so the athlete is part of the key.
+		key := fmt.Sprintf("%d-%d-%d-%d.%s", athlete.Athlete.ID, activityID, layout.Width, layout.Height, format)
+		data, ok := api.CertificateCache.Get(key)
+		if !ok {
+			cert, status, err := api.loadCertificate(r, athlete.Athlete.ID, activityID)
+			if err != nil {
+				httpapi.Write(ctx, rw, status, modelsdk.Response{
+					Message: "Failed to load certificate",
+					Detail:  err.Error(),
+				})
+				return
+			}
+
+			var buf bytes.Buffer
+			if format == "svg" {
+				err = certificate.WriteSVG(&buf, cert, layout)
+			} else {
+				err = certificate.WritePNG(&buf, cert, layout)
+			}
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+					Message: "Failed to render certificate",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			data = buf.Bytes()
+			api.CertificateCache.Set(key, data)
+		}
+
+		rw.Header().Set("Content-Type", contentType)
+		rw.Header().Set("Cache-Control", "public, max-age=3600")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write(data)
+	}
+}
+
+// loadCertificate collects everything printed on a certificate. The returned
+// status code is only meaningful with an error.
+func (api *API) loadCertificate(r *http.Request, athleteID, activityID int64) (certificate.Certificate, int, error) {
+	ctx := r.Context()
+
+	rows, err := api.Opts.DB.RouteActivityResults(ctx, []int64{activityID})
+	if err != nil {
+		return certificate.Certificate{}, http.StatusInternalServerError, fmt.Errorf("route results: %w", err)
+	}
+	result, ok := routeEditionResult(rows, activityID, hugeldate.Editions)
+	if !ok || result.AthleteID != athleteID {
+		return certificate.Certificate{}, http.StatusNotFound, fmt.Errorf("activity %d is not a hugel result of athlete %d", activityID, athleteID)
+	}
+
+	edition, _ := hugeldate.EditionByRoute(result.RouteName)
+	start := result.StartDate.Time
+	if start.Before(edition.Dates.Start) || start.After(edition.Dates.End) {
+		return certificate.Certificate{}, http.StatusNotFound, fmt.Errorf("activity %d was not ridden during %s", activityID, edition.Title())
+	}
+
+	cert := certificate.Certificate{
+		AthleteName:  fmt.Sprintf("%s %s", result.Firstname, result.Lastname),
+		Edition:      edition.Title(),
+		Date:         start.In(hugeldate.CentralTimeZone),
+		ClimbingTime: time.Duration(result.TotalTimeSeconds) * time.Second,
+	}
+
+	if board := api.editionBoardCache(edition); board != nil {
+		loaded, err := board.Load(ctx)
+		if err != nil {
+			return certificate.Certificate{}, http.StatusInternalServerError, fmt.Errorf("leaderboard: %w", err)
+		}
+		cert.Finishers = len(loaded.rows)
+		for _, act := range loaded.rows {
+			if act.ActivityID == activityID {
+				cert.Rank = act.Rank
+			}
+		}
+	}
+
+	var efforts database.HugelSegmentEfforts
+	if err := json.Unmarshal(result.Efforts, &efforts); err != nil {
+		return certificate.Certificate{}, http.StatusInternalServerError, fmt.Errorf("parse efforts: %w", err)
+	}
+	sort.Slice(efforts, func(i, j int) bool {
+		return efforts[i].StartDate.Before(efforts[j].StartDate)
+	})
+
+	segmentIDs := make([]int64, 0, len(efforts))
+	for _, e := range efforts {
+		segmentIDs = append(segmentIDs, int64(e.SegmentID))
+	}
+	segments, err := api.Opts.DB.GetSegments(ctx, segmentIDs)
+	if err != nil {
+		return certificate.Certificate{}, http.StatusInternalServerError, fmt.Errorf("segments: %w", err)
+	}
+	names := make(map[int64]string, len(segments))
+	for _, seg := range segments {
+		names[seg.Segment.ID] = seg.Segment.Name
+		if seg.Segment.FriendlyName != "" {
+			names[seg.Segment.ID] = seg.Segment.FriendlyName
+		}
+	}
+	for _, e := range efforts {
+		cert.Splits = append(cert.Splits, certificate.Split{
+			Name:    names[int64(e.SegmentID)],
+			Elapsed: time.Duration(e.ElapsedTime) * time.Second,
+		})
+	}
+
+	activityMap, err := api.Opts.DB.GetActivityMap(ctx, activityID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return certificate.Certificate{}, http.StatusInternalServerError, fmt.Errorf("activity map: %w", err)
+	}
+	cert.Route = activityMap.Polyline
+	if cert.Route == "" {
+		cert.Route = activityMap.SummaryPolyline
+	}
+
+	return cert, http.StatusOK, nil
+}
+
+// editionBoardCache returns the leaderboard cache of an edition, nil if the
+// edition has no leaderboard.
+func (api *API) editionBoardCache(edition hugeldate.Edition) *gencache.LazyCache[hugelBoard] {
+	switch {
+	case edition.Year == 2023 && !edition.Lite:
+		return api.HugelBoard2023Cache
+	case edition.Year == 2024 && !edition.Lite:
+		return api.HugelBoard2024Cache
+	case edition.Year == 2024 && edition.Lite:
+		return api.HugelBoard2024LiteCache
+	case edition.Year == 2025 && !edition.Lite:
+		return api.HugelBoard2025Cache
+	case edition.Year == 2025 && edition.Lite:
+		return api.HugelBoard2025LiteCache
+	}
+	return nil
+}
+
+// renderCache keeps recently rendered images in memory. Rendering a png is
+// far more expensive than serving one, and link previews are fetched a lot.
+type renderCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]renderEntry
+}
+
+type renderEntry struct {
+	data    []byte
+	created time.Time
+}
+
+func newRenderCache(ttl time.Duration, max int) *renderCache {
+	return &renderCache{
+		ttl:     ttl,
+		max:     max,
+		entries: make(map[string]renderEntry),
+	}
+}
+
+func (c *renderCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Since(e.created) > c.ttl {
+		return nil, false
+	}
+	return e.data, true
+}
+
+func (c *renderCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.max {
+		// Evict the oldest entry.
+		var oldest string
+		var oldestAt time.Time
+		for k, e := range c.entries {
+			if oldest == "" || e.created.Before(oldestAt) {
+				oldest, oldestAt = k, e.created
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = renderEntry{data: data, created: time.Now()}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/certificate"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// certificateStore has a single hugel result, owned by one athlete.
+type certificateStore struct {
+	database.Store
+	result database.RouteActivityResultsRow
+}
+
+func (s certificateStore) GetAthleteFull(_ context.Context, athleteID int64) (database.GetAthleteFullRow, error) {
+	return database.GetAthleteFullRow{Athlete: database.Athlete{ID: athleteID}}, nil
+}
+
+func (s certificateStore) RouteActivityResults(_ context.Context, activityIDs []int64) ([]database.RouteActivityResultsRow, error) {
+	for _, id := range activityIDs {
+		if id == s.result.ActivityID {
+			return []database.RouteActivityResultsRow{s.result}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s certificateStore) GetSegments(context.Context, []int64) ([]database.GetSegmentsRow, error) {
+	return nil, nil
+}
+
+func (s certificateStore) GetActivityMap(context.Context, int64) (database.Map, error) {
+	return database.Map{}, pgx.ErrNoRows
+}
+
+func TestFinisherCertificateOwner(t *testing.T) {
+	t.Parallel()
+
+	const (
+		owner      = 11
+		other      = 22
+		activityID = 33
+	)
+	edition := hugeldate.Editions[0]
+	db := certificateStore{result: database.RouteActivityResultsRow{
+		RouteName:        edition.RouteName,
+		ActivityID:       activityID,
+		AthleteID:        owner,
+		TotalTimeSeconds: 3600,
+		Efforts:          []byte("[]"),
+		StartDate:        database.Timestamptz(edition.Dates.Start.Add(time.Hour)),
+	}}
+
+	api := &API{
+		Opts:             &Options{DB: db},
+		CertificateCache: newRenderCache(time.Hour, 8),
+	}
+	r := chi.NewRouter()
+	r.Route("/athlete/{athlete_id}", func(r chi.Router) {
+		r.Use(httpmw.ExtractAthlete(db))
+		r.Get("/certificate/{activity_id}.{format}", api.finisherCertificate(certificate.Full))
+	})
+
+	get := func(athleteID int64) int {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/athlete/%d/certificate/%d.svg", athleteID, activityID), nil)
+		r.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	require.Equal(t, http.StatusOK, get(owner))
+	// The owner's render is cached, and must not be served to anyone else.
+	require.Equal(t, http.StatusNotFound, get(other))
+	require.Equal(t, http.StatusOK, get(owner))
+}
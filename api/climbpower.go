@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/api/superlative"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/climbpower"
+)
+
+const (
+	rankByTime = "time"
+	rankByWkg  = "wkg"
+	rankByVAM  = "vam"
+)
+
+// climbPowerInputs are what W/kg and VAM need beyond the results: the
+// athletes' weights and the segments' elevation gains.
+type climbPowerInputs struct {
+	weights    map[int64]float64
+	elevations map[int64]float64
+}
+
+func (api *API) loadClimbPower(ctx context.Context, athleteIDs, segmentIDs []int64) (climbPowerInputs, error) {
+	in := climbPowerInputs{
+		weights:    make(map[int64]float64, len(athleteIDs)),
+		elevations: make(map[int64]float64, len(segmentIDs)),
+	}
+
+	weights, err := api.Opts.DB.AthleteWeights(ctx, athleteIDs)
+	if err != nil {
+		return in, fmt.Errorf("athlete weights: %w", err)
+	}
+	for _, w := range weights {
+		in.weights[w.ID] = w.Weight
+	}
+
+	elevations, err := api.Opts.DB.SegmentElevationGains(ctx, segmentIDs)
+	if err != nil {
+		return in, fmt.Errorf("segment elevations: %w", err)
+	}
+	for _, e := range elevations {
+		in.elevations[e.ID] = e.ElevationGain
+	}
+	return in, nil
+}
+
+// hugelBoard is a leaderboard with what its W/kg and VAM need and its
+// superlatives, loaded and cached together so serving a cached board neither
+// queries nor analyzes the pacing of every result.
+type hugelBoard struct {
+	rows         []database.HugelLeaderboardRow
+	power        climbPowerInputs
+	superlatives superlative.List
+}
+
+func (api *API) loadHugelBoard(ctx context.Context, arg database.YearlyHugelLeaderboardParams) (hugelBoard, error) {
+	rows, err := api.Opts.DB.YearlyHugelLeaderboard(ctx, arg)
+	if err != nil {
+		return hugelBoard{}, err
+	}
+	return api.newHugelBoard(ctx, rows)
+}
+
+// newHugelBoard loads the climb power inputs of every result on the board and
+// finds its superlatives.
+func (api *API) newHugelBoard(ctx context.Context, rows []database.HugelLeaderboardRow) (hugelBoard, error) {
+	athleteIDs := make([]int64, 0, len(rows))
+	segmentIDs := make([]int64, 0)
+	seen := make(map[int]bool)
+	for _, act := range rows {
+		athleteIDs = append(athleteIDs, act.AthleteID)
+		for _, e := range act.Efforts {
+			if !seen[e.SegmentID] {
+				seen[e.SegmentID] = true
+				segmentIDs = append(segmentIDs, int64(e.SegmentID))
+			}
+		}
+	}
+	power, err := api.loadClimbPower(ctx, athleteIDs, segmentIDs)
+	if err != nil {
+		return hugelBoard{}, fmt.Errorf("climb power: %w", err)
+	}
+	return hugelBoard{rows: rows, power: power, superlatives: superlative.Parse(rows)}, nil
+}
+
+func (in climbPowerInputs) effort(athleteID int64, e database.HugelSegmentEffort) modelsdk.ClimbPower {
+	return convertClimbPower(climbpower.ForEffort(climbPowerEffort(e), in.elevations[int64(e.SegmentID)], in.weights[athleteID]))
+}
+
+func (in climbPowerInputs) route(athleteID int64, efforts []database.HugelSegmentEffort) modelsdk.ClimbPower {
+	climbs := make([]climbpower.Effort, 0, len(efforts))
+	for _, e := range efforts {
+		climbs = append(climbs, climbPowerEffort(e))
+	}
+	return convertClimbPower(climbpower.ForRoute(climbs, in.elevations, in.weights[athleteID]))
+}
+
+// addClimbPower fills in the W/kg and VAM of the converted leaderboard
+// activities, rows and activities are in the same order.
+func addClimbPower(activities []modelsdk.HugelLeaderBoardActivity, rows []database.HugelLeaderboardRow, in climbPowerInputs) {
+	for i, row := range rows {
+		activities[i].ClimbPower = in.route(row.AthleteID, row.Efforts)
+		for j, e := range row.Efforts {
+			if j < len(activities[i].Efforts) {
+				activities[i].Efforts[j].ClimbPower = in.effort(row.AthleteID, e)
+			}
+		}
+	}
+}
+
+// rankByClimbPower re-ranks the leaderboard, best first. Results without a
+// score, or without device power on the wkg board, are not ranked and keep
+// their time order at the end.
+func rankByClimbPower(activities []modelsdk.HugelLeaderBoardActivity, by string) []modelsdk.HugelLeaderBoardActivity {
+	score := func(a modelsdk.HugelLeaderBoardActivity) (float64, bool) {
+		if by == rankByVAM {
+			return a.VAM, a.VAM > 0
+		}
+		if a.WattsPerKg == nil || a.PowerSource != climbpower.SourceDevice {
+			return 0, false
+		}
+		return *a.WattsPerKg, true
+	}
+
+	ranked := make([]modelsdk.HugelLeaderBoardActivity, 0, len(activities))
+	var unranked []modelsdk.HugelLeaderBoardActivity
+	for _, a := range activities {
+		if _, ok := score(a); ok {
+			ranked = append(ranked, a)
+			continue
+		}
+		a.Rank = 0
+		unranked = append(unranked, a)
+	}
+	// Stable, so equal scores stay in time order.
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, _ := score(ranked[i])
+		sj, _ := score(ranked[j])
+		return si > sj
+	})
+	for i := range ranked {
+		ranked[i].Rank = int64(i + 1)
+	}
+	return append(ranked, unranked...)
+}
+
+// hideWattsPerKg drops the W/kg of every athlete but the viewer. Next to the
+// watts it gives away the athlete's weight, so the wkg board only shows its
+// order to everyone else.
+func hideWattsPerKg(activities []modelsdk.HugelLeaderBoardActivity, viewer int64) {
+	for i := range activities {
+		athleteID := int64(activities[i].AthleteID)
+		activities[i].ClimbPower = ownClimbPower(activities[i].ClimbPower, athleteID, viewer)
+		for j := range activities[i].Efforts {
+			activities[i].Efforts[j].ClimbPower = ownClimbPower(activities[i].Efforts[j].ClimbPower, athleteID, viewer)
+		}
+	}
+}
+
+// ownClimbPower keeps the W/kg only when the athlete is the viewer.
+func ownClimbPower(power modelsdk.ClimbPower, athleteID, viewer int64) modelsdk.ClimbPower {
+	if athleteID != viewer {
+		power.WattsPerKg = nil
+	}
+	return power
+}
+
+func climbPowerEffort(e database.HugelSegmentEffort) climbpower.Effort {
+	return climbpower.Effort{
+		SegmentID:    int64(e.SegmentID),
+		Elapsed:      float64(e.ElapsedTime),
+		AverageWatts: e.AverageWatts,
+		DeviceWatts:  e.DeviceWatts,
+	}
+}
+
+func convertClimbPower(m climbpower.Metrics) modelsdk.ClimbPower {
+	return modelsdk.ClimbPower{
+		VAM:         m.VAM,
+		WattsPerKg:  m.WattsPerKg,
+		PowerSource: m.PowerSource,
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/database/gencache"
+	"github.com/Emyrk/strava/internal/climbpower"
+)
+
+func TestHideWattsPerKg(t *testing.T) {
+	t.Parallel()
+
+	wkg := func(v float64) modelsdk.ClimbPower {
+		return modelsdk.ClimbPower{VAM: 1000, WattsPerKg: &v, PowerSource: climbpower.SourceDevice}
+	}
+	board := []modelsdk.HugelLeaderBoardActivity{
+		{AthleteID: 1, ClimbPower: wkg(3), Efforts: []modelsdk.SegmentEffort{{ClimbPower: wkg(4)}}},
+		{AthleteID: 2, ClimbPower: wkg(5), Efforts: []modelsdk.SegmentEffort{{ClimbPower: wkg(6)}}},
+	}
+
+	// Ranked on the values before they are hidden.
+	board = rankByClimbPower(board, rankByWkg)
+	hideWattsPerKg(board, 1)
+
+	require.Equal(t, modelsdk.StringInt(2), board[0].AthleteID)
+	require.Equal(t, int64(1), board[0].Rank)
+	require.Nil(t, board[0].WattsPerKg)
+	require.Nil(t, board[0].Efforts[0].WattsPerKg)
+	require.Equal(t, float64(1000), board[0].VAM)
+
+	require.Equal(t, int64(2), board[1].Rank)
+	require.NotNil(t, board[1].WattsPerKg)
+	require.NotNil(t, board[1].Efforts[0].WattsPerKg)
+
+	// Anonymous viewers see no one's.
+	hideWattsPerKg(board, -1)
+	require.Nil(t, board[1].WattsPerKg)
+}
+
+// boardStore counts the queries of loading a board.
+type boardStore struct {
+	database.Store
+	queries *atomic.Int32
+}
+
+func (s boardStore) YearlyHugelLeaderboard(context.Context, database.YearlyHugelLeaderboardParams) ([]database.HugelLeaderboardRow, error) {
+	s.queries.Add(1)
+	return []database.HugelLeaderboardRow{
+		{AthleteID: 1, Efforts: []database.HugelSegmentEffort{{SegmentID: 10, ElapsedTime: 600}}},
+	}, nil
+}
+
+func (s boardStore) AthleteWeights(context.Context, []int64) ([]database.AthleteWeightsRow, error) {
+	s.queries.Add(1)
+	return []database.AthleteWeightsRow{{ID: 1, Weight: 70}}, nil
+}
+
+func (s boardStore) SegmentElevationGains(context.Context, []int64) ([]database.SegmentElevationGainsRow, error) {
+	s.queries.Add(1)
+	return []database.SegmentElevationGainsRow{{ID: 10, ElevationGain: 100}}, nil
+}
+
+func TestHugelBoardCachesClimbPower(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &atomic.Int32{}
+	api := &API{Opts: &Options{DB: boardStore{queries: queries}}}
+	cache := gencache.New(ctx, time.Minute, func(ctx context.Context) (hugelBoard, error) {
+		return api.loadHugelBoard(ctx, database.YearlyHugelLeaderboardParams{RouteYear: 2025})
+	})
+
+	for i := 0; i < 3; i++ {
+		loaded, err := cache.Load(ctx)
+		require.NoError(t, err)
+		require.Len(t, loaded.rows, 1)
+		require.Equal(t, float64(70), loaded.power.weights[1])
+		require.Equal(t, float64(100), loaded.power.elevations[10])
+	}
+	// The board, the weights and the elevations, once.
+	require.Equal(t, int32(3), queries.Load())
+}
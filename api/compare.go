@@ -0,0 +1,249 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/internal/routecompare"
+)
+
+// compareRouteResults compares two results of a route head to head. The
+// results can be from different editions, as long as they share segments.
+func (api *API) compareRouteResults(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	routeName := chi.URLParam(r, "route-name")
+	viewer, _ := httpmw.AuthenticatedAthleteIDOptional(r)
+
+	editions := hugeldate.RouteEditions(routeName)
+	if len(editions) == 0 {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: "Route not found",
+		})
+		return
+	}
+
+	activityIDs := make([]int64, 0, 2)
+	for _, param := range []string{"a", "b"} {
+		id, err := strconv.ParseInt(r.URL.Query().Get(param), 10, 64)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: fmt.Sprintf("Invalid activity id for %q", param),
+				Detail:  err.Error(),
+			})
+			return
+		}
+		activityIDs = append(activityIDs, id)
+	}
+
+	rows, err := api.Opts.DB.RouteActivityResults(ctx, activityIDs)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load route results",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	results := make([]database.RouteActivityResultsRow, 0, 2)
+	for _, id := range activityIDs {
+		row, ok := routeEditionResult(rows, id, editions)
+		if !ok {
+			httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+				Message: fmt.Sprintf("Activity %d has no result on route %q", id, routeName),
+			})
+			return
+		}
+		results = append(results, row)
+	}
+
+	a, err := routeCompareResult(results[0])
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to parse efforts",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	b, err := routeCompareResult(results[1])
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to parse efforts",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	cmp, ok := routecompare.Compare(a, b)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Results do not share any segments",
+		})
+		return
+	}
+
+	segmentIDs := make([]int64, 0, len(a.Efforts)+len(b.Efforts))
+	for _, efforts := range []database.HugelSegmentEfforts{a.Efforts, b.Efforts} {
+		for _, e := range efforts {
+			segmentIDs = append(segmentIDs, int64(e.SegmentID))
+		}
+	}
+	segments, err := api.Opts.DB.GetSegments(ctx, segmentIDs)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segments",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	power, err := api.loadClimbPower(ctx, []int64{results[0].AthleteID, results[1].AthleteID}, segmentIDs)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load climb power",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sdk := convertRouteComparison(results[0], results[1], cmp, segments)
+	aID, bID := results[0].AthleteID, results[1].AthleteID
+	sdk.A.ClimbPower = ownClimbPower(power.route(aID, a.Efforts), aID, viewer)
+	sdk.B.ClimbPower = ownClimbPower(power.route(bID, b.Efforts), bID, viewer)
+	for i, climb := range cmp.Climbs {
+		sdk.Climbs[i].A.ClimbPower = ownClimbPower(power.effort(aID, climb.A), aID, viewer)
+		sdk.Climbs[i].B.ClimbPower = ownClimbPower(power.effort(bID, climb.B), bID, viewer)
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, sdk)
+}
+
+// routeEditionResult picks the result of an activity to compare. The views
+// are not date filtered, so an activity can be a result on several editions.
+// The edition of the year the activity was ridden wins, otherwise the newest.
+func routeEditionResult(rows []database.RouteActivityResultsRow, activityID int64, editions []hugeldate.Edition) (database.RouteActivityResultsRow, bool) {
+	var found database.RouteActivityResultsRow
+	foundIdx := -1
+	for _, row := range rows {
+		if row.ActivityID != activityID {
+			continue
+		}
+		for i, edition := range editions {
+			if edition.RouteName != row.RouteName {
+				continue
+			}
+			if edition.Year == row.StartDate.Time.In(hugeldate.CentralTimeZone).Year() {
+				return row, true
+			}
+			if i > foundIdx {
+				found, foundIdx = row, i
+			}
+		}
+	}
+	return found, foundIdx >= 0
+}
+
+func routeCompareResult(row database.RouteActivityResultsRow) (routecompare.Result, error) {
+	var efforts database.HugelSegmentEfforts
+	if err := json.Unmarshal(row.Efforts, &efforts); err != nil {
+		return routecompare.Result{}, err
+	}
+	return routecompare.Result{
+		ActivityID:       row.ActivityID,
+		TotalTimeSeconds: row.TotalTimeSeconds,
+		Efforts:          efforts,
+		AverageHeartrate: row.AverageHeartrate,
+		AverageWatts:     row.AverageWatts,
+		DeviceWatts:      row.DeviceWatts,
+	}, nil
+}
+
+func convertRouteComparison(a, b database.RouteActivityResultsRow, cmp routecompare.Comparison, segments []database.GetSegmentsRow) modelsdk.RouteComparison {
+	names := make(map[int64]string, len(segments))
+	for _, seg := range segments {
+		names[seg.Segment.ID] = seg.Segment.Name
+		if seg.Segment.FriendlyName != "" {
+			names[seg.Segment.ID] = seg.Segment.FriendlyName
+		}
+	}
+	summary := func(id int64) modelsdk.SegmentSummary {
+		return modelsdk.SegmentSummary{ID: modelsdk.StringInt(id), Name: names[id]}
+	}
+
+	sdk := modelsdk.RouteComparison{
+		A:              convertRouteComparisonResult(a),
+		B:              convertRouteComparisonResult(b),
+		Climbs:         make([]modelsdk.ClimbComparison, 0, len(cmp.Climbs)),
+		Transitions:    make([]modelsdk.TransitionComparison, 0, len(cmp.Transitions)),
+		OnlyA:          make([]modelsdk.SegmentSummary, 0, len(cmp.OnlyA)),
+		OnlyB:          make([]modelsdk.SegmentSummary, 0, len(cmp.OnlyB)),
+		TotalDelta:     cmp.TotalDelta,
+		HeartrateDelta: cmp.HeartrateDelta,
+		WattsDelta:     cmp.WattsDelta,
+	}
+
+	for _, climb := range cmp.Climbs {
+		efforts := convertHugelSegmentEfforts([]database.HugelSegmentEffort{climb.A, climb.B})
+		sdk.Climbs = append(sdk.Climbs, modelsdk.ClimbComparison{
+			Segment:         summary(climb.SegmentID),
+			A:               efforts[0],
+			B:               efforts[1],
+			Delta:           int64(climb.Delta),
+			CumulativeDelta: int64(climb.CumulativeDelta),
+			WattsDelta:      climb.WattsDelta,
+		})
+	}
+	for _, t := range cmp.Transitions {
+		sdk.Transitions = append(sdk.Transitions, modelsdk.TransitionComparison{
+			From:  summary(t.FromSegmentID),
+			To:    summary(t.ToSegmentID),
+			A:     int64Ptr(t.A),
+			B:     int64Ptr(t.B),
+			Delta: int64Ptr(t.Delta),
+		})
+	}
+	for _, id := range cmp.OnlyA {
+		sdk.OnlyA = append(sdk.OnlyA, summary(id))
+	}
+	for _, id := range cmp.OnlyB {
+		sdk.OnlyB = append(sdk.OnlyB, summary(id))
+	}
+	return sdk
+}
+
+func convertRouteComparisonResult(row database.RouteActivityResultsRow) modelsdk.RouteComparisonResult {
+	return modelsdk.RouteComparisonResult{
+		RouteName:        row.RouteName,
+		ActivityID:       modelsdk.StringInt(row.ActivityID),
+		ActivityName:     row.Name,
+		StartDate:        row.StartDate.Time,
+		Elapsed:          row.TotalTimeSeconds,
+		AverageHeartrate: row.AverageHeartrate,
+		DeviceWatts:      row.DeviceWatts,
+		AverageWatts:     row.AverageWatts,
+		Athlete: modelsdk.MinAthlete{
+			AthleteID:      modelsdk.StringInt(row.AthleteID),
+			Username:       row.Username,
+			Firstname:      row.Firstname,
+			Lastname:       row.Lastname,
+			Sex:            row.Sex,
+			ProfilePicLink: row.ProfilePicLink,
+		},
+	}
+}
+
+func int64Ptr(v *int) *int64 {
+	if v == nil {
+		return nil
+	}
+	i := int64(*v)
+	return &i
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/export"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// routeCourse returns a competitive route as a gpx or tcx course, to be
+// loaded onto a head unit.
+func (api *API) routeCourse(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		routeName = chi.URLParam(r, "route-name")
+		format    = chi.URLParam(r, "format")
+	)
+
+	var (
+		contentType string
+		write       func(io.Writer, export.Course) error
+	)
+	switch format {
+	case "gpx":
+		contentType = "application/gpx+xml"
+		write = export.WriteCourseGPX
+	case "tcx":
+		contentType = "application/vnd.garmin.tcx+xml"
+		write = export.WriteCourseTCX
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Unsupported format %q, use gpx or tcx", format),
+		})
+		return
+	}
+
+	route, err := api.Opts.DB.GetCompetitiveRouteByName(ctx, routeName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pgx.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load route",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	segments, err := api.Opts.DB.GetSegments(ctx, route.Segments)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segments",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	course := export.Course{
+		Name:        route.DisplayName,
+		Description: route.Description,
+		Climbs:      routeClimbs(route, segments),
+	}
+	if edition, ok := hugeldate.EditionByRoute(route.Name); ok {
+		course.Start = edition.Dates.Start
+	}
+
+	if route.ReferenceActivityID.Valid {
+		activityMap, err := api.Opts.DB.GetActivityMap(ctx, route.ReferenceActivityID.Int64)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to load reference activity",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		encoded := activityMap.Polyline
+		if encoded == "" {
+			encoded = activityMap.SummaryPolyline
+		}
+		// A broken polyline falls back to straight lines between climbs.
+		course.Reference, _ = geo.DecodePolyline(encoded)
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, course); err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to write course",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", route.Name+"."+format))
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(buf.Bytes())
+}
+
+// routeClimbs returns the segments of a route in the route's order.
+func routeClimbs(route database.CompetitiveRoute, segments []database.GetSegmentsRow) []export.Climb {
+	byID := make(map[int64]database.GetSegmentsRow, len(segments))
+	for _, seg := range segments {
+		byID[seg.Segment.ID] = seg
+	}
+
+	climbs := make([]export.Climb, 0, len(route.Segments))
+	for _, id := range route.Segments {
+		seg, ok := byID[id]
+		if !ok {
+			continue
+		}
+		encoded := seg.Map.Polyline
+		if encoded == "" {
+			encoded = seg.Map.SummaryPolyline
+		}
+		line, _ := geo.DecodePolyline(encoded)
+
+		name := seg.Segment.Name
+		if seg.Segment.FriendlyName != "" {
+			name = seg.Segment.FriendlyName
+		}
+		climbs = append(climbs, export.Climb{
+			ID:            id,
+			Name:          name,
+			Line:          line,
+			Distance:      seg.Segment.Distance,
+			AverageGrade:  seg.Segment.AverageGrade,
+			ElevationLow:  seg.Segment.ElevationLow,
+			ElevationHigh: seg.Segment.ElevationHigh,
+			ClimbCategory: seg.Segment.ClimbCategory,
+		})
+	}
+	return climbs
+}
+
+// setRouteReference picks the finisher activity whose track connects the
+// climbs of the route's course exports.
+func (api *API) setRouteReference(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		routeName = chi.URLParam(r, "route-name")
+	)
+
+	var req modelsdk.SetRouteReferenceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	rows, err := api.Opts.DB.RouteActivityResults(ctx, []int64{int64(req.ActivityID)})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load route results",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	finished := false
+	for _, row := range rows {
+		if row.RouteName == routeName {
+			finished = true
+		}
+	}
+	if !finished {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Activity %d is not a finisher of %s", req.ActivityID, routeName),
+		})
+		return
+	}
+
+	api.updateRouteReference(rw, r, routeName, pgtype.Int8{Int64: int64(req.ActivityID), Valid: true})
+}
+
+// clearRouteReference goes back to straight lines between climbs.
+func (api *API) clearRouteReference(rw http.ResponseWriter, r *http.Request) {
+	api.updateRouteReference(rw, r, chi.URLParam(r, "route-name"), pgtype.Int8{})
+}
+
+func (api *API) updateRouteReference(rw http.ResponseWriter, r *http.Request, routeName string, activityID pgtype.Int8) {
+	ctx := r.Context()
+
+	updated, err := api.Opts.DB.SetCompetitiveRouteReference(ctx, database.SetCompetitiveRouteReferenceParams{
+		ReferenceActivityID: activityID,
+		RouteName:           routeName,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to update route",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if updated == 0 {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: fmt.Sprintf("Route %q not found", routeName),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, modelsdk.Response{
+		Message: "Route reference updated",
+	})
+}
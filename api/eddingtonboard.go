@@ -0,0 +1,207 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/eddington"
+)
+
+const (
+	eddingtonBoardDefaultLimit = 50
+	eddingtonBoardMaxLimit     = 200
+)
+
+// eddingtonLeaderboard ranks athletes by the number of all their rides. The
+// whole board is cached, filters and pages are applied to the cached rows and
+// the ranks are counted after filtering.
+func (api *API) eddingtonLeaderboard(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	param := func(name string, def int) (int, bool) {
+		v := query.Get(name)
+		if v == "" {
+			return def, true
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: fmt.Sprintf("Invalid %s", name),
+				Detail:  fmt.Sprintf("%q is not a positive number", v),
+			})
+			return 0, false
+		}
+		return n, true
+	}
+
+	year, ok := param("year", 0)
+	if !ok {
+		return
+	}
+	minActivities, ok := param("min_activities", 0)
+	if !ok {
+		return
+	}
+	page, ok := param("page", 1)
+	if !ok {
+		return
+	}
+	limit, ok := param("limit", eddingtonBoardDefaultLimit)
+	if !ok {
+		return
+	}
+	page = max(page, 1)
+	limit = min(max(limit, 1), eddingtonBoardMaxLimit)
+
+	units := query.Get("units")
+	switch units {
+	case "":
+		units = "imperial"
+	case "imperial", "metric":
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid units",
+			Detail:  "Units are imperial or metric",
+		})
+		return
+	}
+	sex := strings.TrimSpace(query.Get("sex"))
+	city := strings.TrimSpace(query.Get("city"))
+
+	rows, err := api.EddingtonBoardCache.Load(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load eddington leaderboard",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	number := func(row database.EddingtonLeaderboardRow) int32 {
+		if units == "metric" {
+			return row.EddingtonKilometers
+		}
+		return row.EddingtonMiles
+	}
+
+	board := make([]database.EddingtonLeaderboardRow, 0)
+	for _, row := range rows {
+		if int(row.Year) != year || number(row) == 0 {
+			continue
+		}
+		if sex != "" && !strings.EqualFold(row.Sex, sex) {
+			continue
+		}
+		if city != "" && !strings.EqualFold(strings.TrimSpace(row.City), city) {
+			continue
+		}
+		if int(row.TotalActivities) < minActivities {
+			continue
+		}
+		board = append(board, row)
+	}
+	sort.Slice(board, func(i, j int) bool {
+		if number(board[i]) != number(board[j]) {
+			return number(board[i]) > number(board[j])
+		}
+		return board[i].AthleteID < board[j].AthleteID
+	})
+
+	numbers := make([]int32, 0, len(board))
+	for _, row := range board {
+		numbers = append(numbers, number(row))
+	}
+	ranks := eddington.Ranks(numbers)
+
+	out := modelsdk.EddingtonLeaderboard{
+		Units:   units,
+		Year:    year,
+		Total:   len(board),
+		Page:    page,
+		Limit:   limit,
+		Entries: make([]modelsdk.EddingtonLeaderboardEntry, 0, limit),
+	}
+	start := min((page-1)*limit, len(board))
+	end := min(start+limit, len(board))
+	for i := start; i < end; i++ {
+		out.Entries = append(out.Entries, convertEddingtonLeaderboardEntry(board[i], ranks[i], number(board[i])))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, out)
+}
+
+func (api *API) leaderboardOptOut(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		id  = httpmw.AuthenticatedAthleteID(r)
+	)
+
+	optOut, err := api.Opts.DB.LeaderboardOptedOut(ctx, id)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load leaderboard setting",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, modelsdk.LeaderboardOptOut{OptOut: optOut})
+}
+
+// setLeaderboardOptOut hides the athlete from the eddington leaderboards. The
+// cached board is reloaded so the athlete does not linger on it.
+func (api *API) setLeaderboardOptOut(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		id  = httpmw.AuthenticatedAthleteID(r)
+	)
+
+	var req modelsdk.LeaderboardOptOut
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var err error
+	if req.OptOut {
+		err = api.Opts.DB.InsertLeaderboardOptOut(ctx, id)
+	} else {
+		err = api.Opts.DB.DeleteLeaderboardOptOut(ctx, id)
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to save leaderboard setting",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.EddingtonBoardCache.Touch(ctx, api.EddingtonBoardCache.Stale)
+
+	httpapi.Write(ctx, rw, http.StatusOK, req)
+}
+
+func convertEddingtonLeaderboardEntry(row database.EddingtonLeaderboardRow, rank int, number int32) modelsdk.EddingtonLeaderboardEntry {
+	return modelsdk.EddingtonLeaderboardEntry{
+		Rank: rank,
+		Athlete: modelsdk.MinAthlete{
+			AthleteID:      modelsdk.StringInt(row.AthleteID),
+			Username:       row.Username,
+			Firstname:      row.Firstname,
+			Lastname:       row.Lastname,
+			Sex:            row.Sex,
+			ProfilePicLink: row.ProfilePicLink,
+		},
+		City:            row.City,
+		Eddington:       number,
+		Days:            row.Days,
+		TotalActivities: row.TotalActivities,
+	}
+}
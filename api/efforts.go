@@ -2,13 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/Emyrk/strava/api/superlative"
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/Emyrk/strava/api/httpapi"
 	"github.com/Emyrk/strava/api/httpmw"
@@ -192,9 +193,22 @@ func (api *API) hugelboard(rw http.ResponseWriter, r *http.Request) {
 	after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
 	year, _ := strconv.ParseInt(r.URL.Query().Get("year"), 10, 64)
 	lite, _ := strconv.ParseBool(r.URL.Query().Get("lite"))
+	withPacing, _ := strconv.ParseBool(r.URL.Query().Get("pacing"))
+	rankBy := r.URL.Query().Get("rank_by")
+	switch rankBy {
+	case "":
+		rankBy = rankByTime
+	case rankByTime, rankByWkg, rankByVAM:
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Invalid rank_by %q", rankBy),
+			Detail:  "Rank by time, wkg or vam",
+		})
+		return
+	}
 	var beforeTime time.Time
 	var afterTime time.Time
-	var activities []database.HugelLeaderboardRow
+	var loaded hugelBoard
 	var err error
 
 	if before > 0 && after > 0 {
@@ -206,30 +220,34 @@ func (api *API) hugelboard(rw http.ResponseWriter, r *http.Request) {
 		}
 		beforeTime = time.Unix(before, 0)
 		afterTime = time.Unix(after, 0)
-		activities, err = api.Opts.DB.HugelLeaderboard(ctx, database.HugelLeaderboardParams{
+		var rows []database.HugelLeaderboardRow
+		rows, err = api.Opts.DB.HugelLeaderboard(ctx, database.HugelLeaderboardParams{
 			AthleteID: -1,
 			Before:    database.Timestamp(beforeTime),
 			After:     database.Timestamp(afterTime),
 		})
+		if err == nil {
+			loaded, err = api.newHugelBoard(ctx, rows)
+		}
 	} else {
 		switch year {
 		case 2023:
-			activities, err = api.HugelBoard2023Cache.Load(ctx)
+			loaded, err = api.HugelBoard2023Cache.Load(ctx)
 			beforeTime = hugeldate.Year2023.Start
 			afterTime = hugeldate.Year2023.End
 		case 2024:
 			if lite {
-				activities, err = api.HugelBoard2024LiteCache.Load(ctx)
+				loaded, err = api.HugelBoard2024LiteCache.Load(ctx)
 			} else {
-				activities, err = api.HugelBoard2024Cache.Load(ctx)
+				loaded, err = api.HugelBoard2024Cache.Load(ctx)
 			}
 			beforeTime = hugeldate.Year2024.Start
 			afterTime = hugeldate.Year2024.End
 		case 2025:
 			if lite {
-				activities, err = api.HugelBoard2025LiteCache.Load(ctx)
+				loaded, err = api.HugelBoard2025LiteCache.Load(ctx)
 			} else {
-				activities, err = api.HugelBoard2025Cache.Load(ctx)
+				loaded, err = api.HugelBoard2025Cache.Load(ctx)
 			}
 			beforeTime = hugeldate.Year2025.Start
 			afterTime = hugeldate.Year2025.End
@@ -249,12 +267,39 @@ func (api *API) hugelboard(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	activities := loaded.rows
 	board := modelsdk.HugelLeaderBoard{
 		PersonalBest: nil,
 		Activities:   convertHugelActivities(activities),
+		RankedBy:     rankBy,
+	}
+
+	board.Superlatives = loaded.superlatives
+
+	addClimbPower(board.Activities, activities, loaded.power)
+	if withPacing {
+		addPacing(board.Activities, activities)
 	}
 
-	board.Superlatives = superlative.Parse(activities)
+	if before == 0 || after == 0 {
+		if edition, ok := hugeldate.EditionByYear(int(year), lite); ok {
+			// The board is still useful without the groups, whether they are
+			// not detected yet or failed to load.
+			state, _, err := api.loadGroupRides(ctx, edition.RouteName)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+			case err != nil:
+				api.Opts.Logger.Error().Err(err).Str("route", edition.RouteName).Msg("load group rides for leaderboard")
+			default:
+				addRodeWith(board.Activities, state)
+			}
+		}
+	}
+
+	if rankBy != rankByTime {
+		board.Activities = rankByClimbPower(board.Activities, rankBy)
+	}
+	hideWattsPerKg(board.Activities, id)
 
 	if athleteLoggedIn {
 		for _, act := range board.Activities {
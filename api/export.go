@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/internal/export"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// hugelboardExport streams the leaderboard of an edition as csv or json lines.
+func (api *API) hugelboardExport(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format := r.URL.Query().Get("format")
+	var contentType string
+	switch format {
+	case "", "csv":
+		format = "csv"
+		contentType = "text/csv; charset=utf-8"
+	case "jsonl":
+		contentType = "application/jsonl; charset=utf-8"
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Unsupported format %q, use csv or jsonl", format),
+		})
+		return
+	}
+
+	results, status, err := api.exportResults(r)
+	if err != nil {
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load results",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", results.Filename(format)))
+	rw.WriteHeader(http.StatusOK)
+	if format == "csv" {
+		err = export.WriteCSV(rw, results)
+	} else {
+		err = export.WriteJSONL(rw, results)
+	}
+	if err != nil {
+		// The status is already sent, all that is left is to log it.
+		api.Opts.Logger.Warn().Err(err).Str("format", format).Msg("write results export")
+	}
+}
+
+// hugelboardExportGPX bundles the summary polyline of every ranked result as
+// gpx tracks in a zip.
+func (api *API) hugelboardExportGPX(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	results, status, err := api.exportResults(r)
+	if err != nil {
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load results",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	activityIDs := make([]int64, 0, len(results.Rows))
+	for _, row := range results.Rows {
+		activityIDs = append(activityIDs, row.ActivityID)
+	}
+	maps, err := api.Opts.DB.GetActivitySummaryPolylines(ctx, activityIDs)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load activity maps",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	polylines := make(map[int64]string, len(maps))
+	for _, m := range maps {
+		polylines[m.ActivityID] = m.SummaryPolyline
+	}
+
+	rw.Header().Set("Content-Type", "application/zip")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", results.Filename("zip")))
+	rw.WriteHeader(http.StatusOK)
+	if err := export.WriteGPXZip(rw, results, polylines); err != nil {
+		api.Opts.Logger.Warn().Err(err).Msg("write gpx export")
+	}
+}
+
+// exportResults loads the results of the edition in the year and lite query
+// params. The returned status code is only meaningful with an error.
+func (api *API) exportResults(r *http.Request) (export.Results, int, error) {
+	ctx := r.Context()
+
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil {
+		return export.Results{}, http.StatusBadRequest, fmt.Errorf("invalid year: %w", err)
+	}
+	lite, _ := strconv.ParseBool(r.URL.Query().Get("lite"))
+
+	edition, ok := hugeldate.EditionByYear(year, lite)
+	if !ok {
+		return export.Results{}, http.StatusBadRequest, fmt.Errorf("no edition in %d", year)
+	}
+	board := api.editionBoardCache(edition)
+	if board == nil {
+		return export.Results{}, http.StatusNotFound, fmt.Errorf("%s has no leaderboard", edition.Title())
+	}
+
+	loaded, err := board.Load(ctx)
+	if err != nil {
+		return export.Results{}, http.StatusInternalServerError, fmt.Errorf("leaderboard: %w", err)
+	}
+	results, err := export.Load(ctx, api.Opts.DB, edition, loaded.rows)
+	if err != nil {
+		return export.Results{}, http.StatusInternalServerError, err
+	}
+	return results, http.StatusOK, nil
+}
@@ -0,0 +1,323 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// routeGeoJSON returns every segment of a competitive route as GeoJSON lines.
+func (api *API) routeGeoJSON(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		routeName = chi.URLParam(r, "route-name")
+	)
+
+	opts, ok := api.geoJSONOptions(rw, r)
+	if !ok {
+		return
+	}
+
+	route, err := api.Opts.DB.GetCompetitiveRoute(ctx, routeName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pgx.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load route",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sdkRoute := convertRoute(route)
+	segmentIDs := make([]int64, 0, len(sdkRoute.Segments))
+	for _, seg := range sdkRoute.Segments {
+		segmentIDs = append(segmentIDs, int64(seg.ID))
+	}
+
+	segments, err := api.Opts.DB.GetSegments(ctx, segmentIDs)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segments",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	features, err := api.segmentFeatures(r, segments, opts)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load personal bests",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	writeGeoJSON(rw, geo.NewFeatureCollection(features...))
+}
+
+// segmentGeoJSON returns a single segment as a GeoJSON line.
+func (api *API) segmentGeoJSON(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	segmentID, err := strconv.ParseInt(chi.URLParam(r, "segment_id"), 10, 64)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid segment id",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	opts, ok := api.geoJSONOptions(rw, r)
+	if !ok {
+		return
+	}
+
+	segments, err := api.Opts.DB.GetSegments(ctx, []int64{segmentID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segment",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if len(segments) == 0 {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: fmt.Sprintf("Segment %d not found", segmentID),
+		})
+		return
+	}
+
+	features, err := api.segmentFeatures(r, segments, opts)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load personal bests",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	writeGeoJSON(rw, geo.NewFeatureCollection(features...))
+}
+
+// activityGeoJSON returns an activity as a GeoJSON line. Activities can be
+// private, so only the owner and admins can see them.
+func (api *API) activityGeoJSON(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	activityID, err := strconv.ParseInt(chi.URLParam(r, "activity_id"), 10, 64)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid activity id",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	opts, ok := api.geoJSONOptions(rw, r)
+	if !ok {
+		return
+	}
+
+	activity, err := api.Opts.DB.GetActivitySummary(ctx, activityID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pgx.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load activity",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if !httpmw.RequestAuthenticatedAsAdminsOrMe(rw, r, activity.AthleteID) {
+		return
+	}
+
+	activityMap, err := api.Opts.DB.GetActivityMap(ctx, activityID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load activity map",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	efforts, err := api.Opts.DB.GetActivitySegmentEfforts(ctx, activityID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load activity efforts",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var features []geo.Feature
+	if f, ok := opts.feature(strconv.FormatInt(activityID, 10), activityMap, convertActivityFeatureProperties(activity, efforts)); ok {
+		features = append(features, f)
+	}
+	writeGeoJSON(rw, geo.NewFeatureCollection(features...))
+}
+
+// segmentFeatures returns the segments as GeoJSON lines, with the personal
+// bests of the logged in athlete.
+func (api *API) segmentFeatures(r *http.Request, segments []database.GetSegmentsRow, opts geoJSONOptions) ([]geo.Feature, error) {
+	ctx := r.Context()
+
+	bests := make(map[int64]float64)
+	if athleteID, ok := httpmw.AuthenticatedAthleteIDOptional(r); ok {
+		segmentIDs := make([]int64, 0, len(segments))
+		for _, seg := range segments {
+			segmentIDs = append(segmentIDs, seg.Segment.ID)
+		}
+		efforts, err := api.Opts.DB.GetBestPersonalSegmentEffort(ctx, database.GetBestPersonalSegmentEffortParams{
+			AthleteID:  athleteID,
+			SegmentIds: segmentIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, effort := range efforts {
+			bests[effort.SegmentID] = effort.ElapsedTime
+		}
+	}
+
+	features := make([]geo.Feature, 0, len(segments))
+	for _, seg := range segments {
+		props := convertSegmentFeatureProperties(seg.Segment)
+		if best, ok := bests[seg.Segment.ID]; ok {
+			props.PersonalBestElapsedTime = &best
+		}
+		if f, ok := opts.feature(strconv.FormatInt(seg.Segment.ID, 10), seg.Map, props); ok {
+			features = append(features, f)
+		}
+	}
+	return features, nil
+}
+
+type geoJSONOptions struct {
+	// tolerance in meters to simplify lines by, 0 to keep every point.
+	tolerance float64
+	// bbox drops features entirely outside of it, if set.
+	bbox *geo.BBox
+}
+
+func (*API) geoJSONOptions(rw http.ResponseWriter, r *http.Request) (geoJSONOptions, bool) {
+	var (
+		ctx   = r.Context()
+		query = r.URL.Query()
+		opts  geoJSONOptions
+	)
+
+	if tolerance := query.Get("tolerance"); tolerance != "" {
+		v, err := strconv.ParseFloat(tolerance, 64)
+		if err != nil || v < 0 || v > 1000 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: "Invalid tolerance, must be between 0 and 1000 meters",
+				Detail:  fmt.Sprintf("tolerance %q", tolerance),
+			})
+			return geoJSONOptions{}, false
+		}
+		opts.tolerance = v
+	}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		b, err := geo.ParseBBox(bbox)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: "Invalid bbox",
+				Detail:  err.Error(),
+			})
+			return geoJSONOptions{}, false
+		}
+		opts.bbox = &b
+	}
+	return opts, true
+}
+
+// feature returns the line of a map as a GeoJSON feature, false if it has no
+// line or is outside of the bbox.
+func (o geoJSONOptions) feature(id string, m database.Map, properties any) (geo.Feature, bool) {
+	encoded := m.Polyline
+	if encoded == "" {
+		encoded = m.SummaryPolyline
+	}
+	// A broken polyline still returns the part that decoded.
+	line, _ := geo.DecodePolyline(encoded)
+	if len(line) == 0 {
+		return geo.Feature{}, false
+	}
+	if o.bbox != nil && !o.bbox.Intersects(geo.Bounds(line)) {
+		return geo.Feature{}, false
+	}
+	if o.tolerance > 0 {
+		line = geo.Simplify(line, o.tolerance)
+	}
+	return geo.LineStringFeature(id, line, properties), true
+}
+
+func writeGeoJSON(rw http.ResponseWriter, fc geo.FeatureCollection) {
+	data, err := json.Marshal(fc)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/geo+json")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(data)
+}
+
+func convertSegmentFeatureProperties(seg database.Segment) modelsdk.SegmentFeatureProperties {
+	return modelsdk.SegmentFeatureProperties{
+		ID:                 modelsdk.StringInt(seg.ID),
+		Name:               seg.Name,
+		FriendlyName:       seg.FriendlyName,
+		Distance:           seg.Distance,
+		AverageGrade:       seg.AverageGrade,
+		MaximumGrade:       seg.MaximumGrade,
+		ElevationHigh:      seg.ElevationHigh,
+		ElevationLow:       seg.ElevationLow,
+		TotalElevationGain: seg.TotalElevationGain,
+		ClimbCategory:      seg.ClimbCategory,
+		TotalEffortCount:   seg.TotalEffortCount,
+	}
+}
+
+func convertActivityFeatureProperties(activity database.ActivitySummary, efforts []database.SegmentEffort) modelsdk.ActivityFeatureProperties {
+	props := modelsdk.ActivityFeatureProperties{
+		ID:                 modelsdk.StringInt(activity.ID),
+		AthleteID:          modelsdk.StringInt(activity.AthleteID),
+		Name:               activity.Name,
+		SportType:          activity.SportType,
+		StartDate:          activity.StartDate.Time,
+		Distance:           activity.Distance,
+		MovingTime:         activity.MovingTime,
+		ElapsedTime:        activity.ElapsedTime,
+		TotalElevationGain: activity.TotalElevationGain,
+		Efforts:            make([]modelsdk.ActivityFeatureEffort, 0, len(efforts)),
+	}
+	for _, effort := range efforts {
+		props.Efforts = append(props.Efforts, modelsdk.ActivityFeatureEffort{
+			SegmentID:   modelsdk.StringInt(effort.SegmentID),
+			Name:        effort.Name,
+			StartDate:   effort.StartDate.Time,
+			ElapsedTime: effort.ElapsedTime,
+			MovingTime:  effort.MovingTime,
+		})
+	}
+	return props
+}
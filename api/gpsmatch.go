@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/gpsmatch"
+)
+
+// unofficialRouteResults lists the results of a route that only finish with
+// GPS matched efforts. They are kept apart from the official results.
+func (api *API) unofficialRouteResults(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	routeName := chi.URLParam(r, "route-name")
+
+	rows, err := api.Opts.DB.UnofficialRouteResults(ctx, routeName)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load unofficial results",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	results := make([]modelsdk.UnofficialRouteResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, convertUnofficialRouteResult(routeName, row))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, results)
+}
+
+// explainMissingSegments says for every missing segment whether the activity's
+// track rode it. Stored GPS matches come from the full time stream, the rest
+// are checked against the activity polyline, which has no times.
+func (api *API) explainMissingSegments(ctx context.Context, activityID int64, missing []database.Segment) (modelsdk.MissingSegmentsResponse, error) {
+	resp := modelsdk.MissingSegmentsResponse{
+		ActivityID: modelsdk.StringInt(activityID),
+		Segments:   make([]modelsdk.MissingSegment, 0, len(missing)),
+	}
+	if len(missing) == 0 {
+		return resp, nil
+	}
+
+	efforts, err := api.Opts.DB.GetGPSSegmentEfforts(ctx, activityID)
+	if err != nil {
+		return resp, fmt.Errorf("gps efforts: %w", err)
+	}
+	matched := make(map[int64]database.GpsSegmentEffort, len(efforts))
+	for _, e := range efforts {
+		matched[e.SegmentID] = e
+	}
+
+	var track gpsmatch.Track
+	m, err := api.Opts.DB.GetActivityMap(ctx, activityID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return resp, fmt.Errorf("activity map: %w", err)
+	}
+	if err == nil {
+		// Without a track that decodes, every segment is explained as no track.
+		if track.Points, err = decodeMap(m); err != nil {
+			track.Points = nil
+		}
+	}
+
+	ids := make([]int64, 0, len(missing))
+	for _, seg := range missing {
+		ids = append(ids, seg.ID)
+	}
+	segs, err := gpsmatch.LoadSegments(ctx, api.Opts.DB, ids)
+	if err != nil {
+		return resp, err
+	}
+
+	matcher := gpsmatch.New()
+	for i, seg := range missing {
+		ms := modelsdk.MissingSegment{
+			Segment: modelsdk.SegmentSummary{ID: modelsdk.StringInt(seg.ID), Name: seg.Name},
+		}
+		if e, ok := matched[seg.ID]; ok {
+			ms.GPSMatch = convertGPSSegmentEffort(e)
+			ms.Explanation = gpsmatch.Match{Reason: gpsmatch.ReasonMatched, Coverage: e.Coverage}.Explain(matcher.Tolerance)
+		} else {
+			ms.Explanation = matcher.Match(track, segs[i]).Explain(matcher.Tolerance)
+		}
+		resp.Segments = append(resp.Segments, ms)
+	}
+	return resp, nil
+}
+
+func convertGPSSegmentEffort(e database.GpsSegmentEffort) *modelsdk.GPSSegmentEffort {
+	return &modelsdk.GPSSegmentEffort{
+		SegmentID:   modelsdk.StringInt(e.SegmentID),
+		Source:      e.Source,
+		StartDate:   e.StartDate.Time,
+		ElapsedTime: e.ElapsedTime,
+		Distance:    e.Distance,
+		Coverage:    e.Coverage,
+	}
+}
+
+func convertUnofficialRouteResult(routeName string, row database.UnofficialRouteResultsRow) modelsdk.UnofficialRouteResult {
+	gps := make([]modelsdk.StringInt, 0, len(row.GpsSegmentIds))
+	for _, id := range row.GpsSegmentIds {
+		gps = append(gps, modelsdk.StringInt(id))
+	}
+	return modelsdk.UnofficialRouteResult{
+		RouteName:    routeName,
+		ActivityID:   modelsdk.StringInt(row.ActivityID),
+		ActivityName: row.Name,
+		StartDate:    row.StartDate.Time,
+		Elapsed:      int64(row.TotalTimeSeconds),
+		GPSSegments:  gps,
+		Athlete: modelsdk.MinAthlete{
+			AthleteID:      modelsdk.StringInt(row.AthleteID),
+			Username:       row.Username,
+			Firstname:      row.Firstname,
+			Lastname:       row.Lastname,
+			Sex:            row.Sex,
+			ProfilePicLink: row.ProfilePicLink,
+		},
+		Unofficial: true,
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/gpsmatch"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// gpsMatchStore has an activity with the given map, and one segment far from
+// any track.
+type gpsMatchStore struct {
+	database.Store
+	activityMap database.Map
+}
+
+func (s gpsMatchStore) GetGPSSegmentEfforts(context.Context, int64) ([]database.GpsSegmentEffort, error) {
+	return nil, nil
+}
+
+func (s gpsMatchStore) GetActivityMap(context.Context, int64) (database.Map, error) {
+	return s.activityMap, nil
+}
+
+func (s gpsMatchStore) GetSegments(_ context.Context, ids []int64) ([]database.GetSegmentsRow, error) {
+	line := geo.EncodePolyline([]geo.Point{{Lat: 10, Lng: 10}, {Lat: 10.01, Lng: 10.01}})
+	return []database.GetSegmentsRow{{
+		Segment: database.Segment{ID: ids[0]},
+		Map:     database.Map{Polyline: line},
+	}}, nil
+}
+
+func TestExplainMissingSegmentsBrokenTrack(t *testing.T) {
+	t.Parallel()
+
+	const broken = "_p~iF~ps|U_ulL"
+	track := geo.EncodePolyline([]geo.Point{{Lat: 30.25, Lng: -97.75}, {Lat: 30.26, Lng: -97.74}})
+	matcher := gpsmatch.New()
+	missing := []database.Segment{{ID: 1, Name: "Far away"}}
+
+	explain := func(m database.Map) string {
+		api := &API{Opts: &Options{DB: gpsMatchStore{activityMap: m}}}
+		resp, err := api.explainMissingSegments(context.Background(), 7, missing)
+		require.NoError(t, err)
+		require.Len(t, resp.Segments, 1)
+		return resp.Segments[0].Explanation
+	}
+
+	// The summary polyline stands in for a broken detailed one.
+	require.Equal(t,
+		gpsmatch.Match{Reason: gpsmatch.ReasonNeverNearStart}.Explain(matcher.Tolerance),
+		explain(database.Map{Polyline: broken, SummaryPolyline: track}))
+	// Neither decodes, so there is no track to explain with.
+	require.Equal(t,
+		gpsmatch.Match{Reason: gpsmatch.ReasonNoTrack}.Explain(matcher.Tolerance),
+		explain(database.Map{Polyline: broken, SummaryPolyline: broken}))
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/modelsdk"
+	river2 "github.com/Emyrk/strava/api/river"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/grouprides"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// eventGroupRides lists the groups that rode an edition together. The groups
+// are detected by a job as results arrive, this only serves them.
+func (api *API) eventGroupRides(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		routeName = chi.URLParam(r, "edition")
+	)
+
+	edition, ok := hugeldate.EditionByRoute(routeName)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: fmt.Sprintf("No edition %q", routeName),
+		})
+		return
+	}
+
+	state, computedAt, err := api.loadGroupRides(ctx, routeName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, _ = api.RiverManager.EnqueueGroupRides(ctx, river2.GroupRidesArgs{RouteName: routeName})
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: "The groups are being found, try again in a few minutes",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load group rides",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var board []database.HugelLeaderboardRow
+	if cache := api.editionBoardCache(edition); cache != nil {
+		loaded, err := cache.Load(ctx)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to load leaderboard",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		board = loaded.rows
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, modelsdk.EventGroupRides{
+		RouteName:  edition.RouteName,
+		Edition:    edition.Title(),
+		ComputedAt: computedAt,
+		Groups:     convertGroupRides(state, board),
+	})
+}
+
+// groupRides is the stored detection of an edition.
+type groupRides struct {
+	state      grouprides.State
+	computedAt time.Time
+}
+
+// loadGroupRides returns the groups of an edition, cached alongside its
+// board. pgx.ErrNoRows means they have not been detected yet.
+func (api *API) loadGroupRides(ctx context.Context, routeName string) (grouprides.State, time.Time, error) {
+	var (
+		groups groupRides
+		err    error
+	)
+	if cache, ok := api.GroupRidesCaches[routeName]; ok {
+		groups, err = cache.Load(ctx)
+	} else {
+		groups, err = api.fetchGroupRides(ctx, routeName)
+	}
+	return groups.state, groups.computedAt, err
+}
+
+func (api *API) fetchGroupRides(ctx context.Context, routeName string) (groupRides, error) {
+	row, err := api.Opts.DB.EventGroupRides(ctx, routeName)
+	if err != nil {
+		return groupRides{}, err
+	}
+	groups := groupRides{
+		state:      grouprides.State{Seen: row.Seen},
+		computedAt: row.ComputedAt.Time,
+	}
+	if err := json.Unmarshal(row.Groups, &groups.state.Groups); err != nil {
+		return groupRides{}, fmt.Errorf("parse groups: %w", err)
+	}
+	return groups, nil
+}
+
+// addRodeWith fills in who each activity on the board rode with. Members of
+// a group that are no longer on the board are left out.
+func addRodeWith(activities []modelsdk.HugelLeaderBoardActivity, state grouprides.State) {
+	onBoard := make(map[int64]bool, len(activities))
+	for _, act := range activities {
+		onBoard[int64(act.ActivityID)] = true
+	}
+
+	with := state.RodeWith()
+	for i, act := range activities {
+		for _, id := range with[int64(act.ActivityID)] {
+			if onBoard[id] {
+				activities[i].RodeWith = append(activities[i].RodeWith, modelsdk.StringInt(id))
+			}
+		}
+	}
+}
+
+// convertGroupRides describes the groups by the board, in the order they
+// started. Members that left the board are dropped, and so are groups left
+// with a single rider.
+func convertGroupRides(state grouprides.State, board []database.HugelLeaderboardRow) []modelsdk.GroupRide {
+	rows := make(map[int64]database.HugelLeaderboardRow, len(board))
+	for _, row := range board {
+		rows[row.ActivityID] = row
+	}
+
+	groups := make([]modelsdk.GroupRide, 0, len(state.Groups))
+	for _, ids := range state.Groups {
+		group := modelsdk.GroupRide{Members: make([]modelsdk.GroupRideMember, 0, len(ids))}
+		var total int64
+		for _, id := range ids {
+			row, ok := rows[id]
+			if !ok {
+				continue
+			}
+			act := convertHugelActivity(row)
+			group.Members = append(group.Members, modelsdk.GroupRideMember{
+				ActivityID: act.ActivityID,
+				Rank:       act.Rank,
+				Elapsed:    act.Elapsed,
+				Athlete:    act.Athlete,
+			})
+
+			total += act.Elapsed
+			if group.Start.IsZero() || act.ActivityStartDate.Before(group.Start) {
+				group.Start = act.ActivityStartDate
+			}
+			if group.BestRank == 0 || act.Rank < group.BestRank {
+				group.BestRank = act.Rank
+			}
+			if group.FastestTime == 0 || act.Elapsed < group.FastestTime {
+				group.FastestTime = act.Elapsed
+			}
+			group.SlowestTime = max(group.SlowestTime, act.Elapsed)
+		}
+		if len(group.Members) < 2 {
+			continue
+		}
+		sort.Slice(group.Members, func(i, j int) bool {
+			return group.Members[i].Rank < group.Members[j].Rank
+		})
+		group.Riders = len(group.Members)
+		group.AverageTime = total / int64(group.Riders)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Start.Before(groups[j].Start)
+	})
+	return groups
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	river2 "github.com/Emyrk/strava/api/river"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/heatmap"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// eventHeatmap returns where the riders of an edition rode, as a density grid
+// or a png overlay. The heatmap is built by a job, this only serves it.
+func (api *API) eventHeatmap(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		routeName = chi.URLParam(r, "edition")
+		format    = chi.URLParam(r, "format")
+	)
+
+	if format != "" && format != "json" && format != "png" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Unsupported format %q, use json or png", format),
+		})
+		return
+	}
+
+	if _, ok := hugeldate.EditionByRoute(routeName); !ok {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: fmt.Sprintf("No edition %q", routeName),
+		})
+		return
+	}
+
+	row, err := api.Opts.DB.EventHeatmap(ctx, routeName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, _ = api.RiverManager.EnqueueHeatmap(ctx, river2.HeatmapArgs{RouteName: routeName})
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: "The heatmap is being built, try again in a few minutes",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load heatmap",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	grid := heatmap.FromRow(row)
+	if format != "png" {
+		httpapi.Write(ctx, rw, http.StatusOK, convertHeatmap(row, grid))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := grid.PNG(&buf); err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to render heatmap",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.Header().Set("Content-Type", "image/png")
+	rw.Header().Set("Cache-Control", "public, max-age=3600")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(buf.Bytes())
+}
+
+func (api *API) heatmapOptOut(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		id  = httpmw.AuthenticatedAthleteID(r)
+	)
+
+	optOut, err := api.Opts.DB.HeatmapOptedOut(ctx, id)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load heatmap setting",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, modelsdk.HeatmapOptOut{OptOut: optOut})
+}
+
+// setHeatmapOptOut keeps the athlete's rides off the event heatmaps. Every
+// heatmap is rebuilt, so the change shows up without waiting for the next run.
+func (api *API) setHeatmapOptOut(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		id  = httpmw.AuthenticatedAthleteID(r)
+	)
+
+	var req modelsdk.HeatmapOptOut
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var err error
+	if req.OptOut {
+		err = api.Opts.DB.InsertHeatmapOptOut(ctx, id)
+	} else {
+		err = api.Opts.DB.DeleteHeatmapOptOut(ctx, id)
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to save heatmap setting",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	_, err = api.RiverManager.EnqueueHeatmap(ctx, river2.HeatmapArgs{
+		AthleteID: id,
+		OptOut:    req.OptOut,
+		ChangedAt: time.Now(),
+	})
+	if err != nil {
+		// The scheduled rebuild still picks the change up.
+		api.Opts.Logger.Error().Err(err).Int64("athlete_id", id).Msg("enqueue heatmap rebuild")
+		httpapi.Write(ctx, rw, http.StatusOK, modelsdk.Response{
+			Message: "Saved heatmap setting",
+			Detail:  "The heatmaps will reflect it at their next scheduled rebuild, within 6 hours.",
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, modelsdk.Response{
+		Message: "Saved heatmap setting",
+		Detail:  "The heatmaps are being rebuilt and will reflect it in a few minutes.",
+	})
+}
+
+func convertHeatmap(row database.EventHeatmap, grid *heatmap.Grid) modelsdk.Heatmap {
+	return modelsdk.Heatmap{
+		RouteName:  row.RouteName,
+		BBox:       grid.BBox.GeoJSON(),
+		Width:      grid.Width,
+		Height:     grid.Height,
+		Cells:      grid.Cells,
+		Max:        grid.Max(),
+		Activities: grid.Tracks,
+		ComputedAt: row.ComputedAt.Time,
+	}
+}
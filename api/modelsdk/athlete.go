@@ -85,6 +85,10 @@ type HugelLeaderBoard struct {
 	PersonalBest *HugelLeaderBoardActivity  `json:"personal_best,omitempty"`
 	Superlatives superlative.List           `json:"superlatives"`
 	Activities   []HugelLeaderBoardActivity `json:"activities"`
+	// RankedBy is time, wkg or vam. The wkg board only ranks results with
+	// power from a device, the rest follow with a rank of 0. Its values are
+	// only shown to their own athlete.
+	RankedBy string `json:"ranked_by"`
 }
 
 type HugelLeaderBoardActivity struct {
@@ -105,6 +109,12 @@ type HugelLeaderBoardActivity struct {
 	ActivityTotalElevationGain float64   `json:"activity_total_elevation_gain"`
 	ActivitySufferScore        int       `json:"activity_suffer_score"`
 	ActivityAchievementCount   int       `json:"activity_achievement_count"`
+
+	ClimbPower
+	// Pacing is only included when asked for.
+	Pacing *Pacing `json:"pacing,omitempty"`
+	// RodeWith are the activities on the board ridden in the same group.
+	RodeWith []StringInt `json:"rode_with,omitempty"`
 }
 
 type SuperHugelLeaderBoard struct {
@@ -130,6 +140,19 @@ type SegmentEffort struct {
 	MovingTime   int64     `json:"moving_time"`
 	DeviceWatts  bool      `json:"device_watts"`
 	AverageWatts float64   `json:"average_watts"`
+
+	ClimbPower
+}
+
+// ClimbPower scores a climb, or all the climbs of a route, by physics. VAM is
+// in vertical meters per hour. WattsPerKg is missing without the athlete's
+// weight or watts, and is only shown to the athlete themselves. PowerSource is
+// device, estimated (by Strava) or missing. Both are left empty where they are
+// not computed.
+type ClimbPower struct {
+	VAM         float64  `json:"vam,omitempty"`
+	WattsPerKg  *float64 `json:"watts_per_kg,omitempty"`
+	PowerSource string   `json:"power_source,omitempty"`
 }
 
 type MinAthlete struct {
@@ -143,11 +166,101 @@ type MinAthlete struct {
 }
 
 type Eddington struct {
-	AthleteID        int64     `json:"athlete_id"`
-	MilesHistogram   []int32   `json:"miles_histogram"`
-	CurrentEddington int32     `json:"current_eddington"`
-	LastCalculated   time.Time `json:"last_calculated"`
-	TotalActivities  int32     `json:"total_activities"`
+	AthleteID           int64     `json:"athlete_id"`
+	MilesHistogram      []int32   `json:"miles_histogram"`
+	CurrentEddington    int32     `json:"current_eddington"`
+	KilometersHistogram []int32   `json:"kilometers_histogram"`
+	CurrentEddingtonKm  int32     `json:"current_eddington_km"`
+	LastCalculated      time.Time `json:"last_calculated"`
+	TotalActivities     int32     `json:"total_activities"`
+	// TotalDays is the days with a ride, the number counts days not rides.
+	TotalDays  int32                `json:"total_days"`
+	Breakdowns []EddingtonBreakdown `json:"breakdowns"`
+	Metrics    []EddingtonMetric    `json:"metrics"`
+}
+
+// EddingtonMetric is a lifetime number counted from something other than
+// distance, e.g. 100s of feet climbed or hours ridden in a day.
+type EddingtonMetric struct {
+	// Metric is elevation_ft, elevation_m, hours or hugel_segments.
+	Metric    string  `json:"metric"`
+	Days      int32   `json:"days"`
+	Histogram []int32 `json:"histogram"`
+	Eddington int32   `json:"eddington"`
+}
+
+// EddingtonLeaderboard is one page of athletes ranked by the number of all
+// their rides.
+type EddingtonLeaderboard struct {
+	// Units is imperial for miles or metric for kilometers.
+	Units string `json:"units"`
+	// Year is 0 for the lifetime number.
+	Year int `json:"year"`
+	// Total is the athletes on the board after filtering, across every page.
+	Total   int                         `json:"total"`
+	Page    int                         `json:"page"`
+	Limit   int                         `json:"limit"`
+	Entries []EddingtonLeaderboardEntry `json:"entries"`
+}
+
+type EddingtonLeaderboardEntry struct {
+	// Rank is shared by equal numbers, e.g. 1, 2, 2, 4.
+	Rank            int        `json:"rank"`
+	Athlete         MinAthlete `json:"athlete"`
+	City            string     `json:"city"`
+	Eddington       int32      `json:"eddington"`
+	Days            int32      `json:"days"`
+	TotalActivities int32      `json:"total_activities"`
+}
+
+type LeaderboardOptOut struct {
+	OptOut bool `json:"opt_out"`
+}
+
+type EddingtonMetricRank struct {
+	Rank      int64     `json:"rank"`
+	AthleteID StringInt `json:"athlete_id"`
+	Eddington int32     `json:"eddington"`
+	Days      int32     `json:"days"`
+}
+
+// EddingtonBreakdown is the number for one sport, over the lifetime or a
+// calendar year.
+type EddingtonBreakdown struct {
+	// Sport is all, ride or virtualride.
+	Sport string `json:"sport"`
+	// Year is 0 for the lifetime number.
+	Year                int32   `json:"year"`
+	Days                int32   `json:"days"`
+	MilesHistogram      []int32 `json:"miles_histogram"`
+	Miles               int32   `json:"miles"`
+	KilometersHistogram []int32 `json:"kilometers_histogram"`
+	Kilometers          int32   `json:"kilometers"`
+}
+
+// EddingtonHistory is when each number was first reached, and what it takes
+// to reach the next few.
+type EddingtonHistory struct {
+	AthleteID int64 `json:"athlete_id"`
+	// Unit is mi or km.
+	Unit       string               `json:"unit"`
+	Current    int32                `json:"current"`
+	Milestones []EddingtonMilestone `json:"milestones"`
+	Plan       []EddingtonGoal      `json:"plan"`
+}
+
+type EddingtonMilestone struct {
+	Eddington  int32     `json:"eddington"`
+	Date       time.Time `json:"date"`
+	ActivityID StringInt `json:"activity_id"`
+}
+
+type EddingtonGoal struct {
+	Eddington int32 `json:"eddington"`
+	// Have is the days that already count towards it.
+	Have int32 `json:"have"`
+	// Need is the more days needed of at least Eddington in the unit.
+	Need int32 `json:"need"`
 }
 
 type EddingtonShort struct {
@@ -0,0 +1,33 @@
+package modelsdk
+
+import "time"
+
+// Calendar is an athlete's activities by day, with their training load.
+// Distances and elevation are in meters, times in seconds.
+type Calendar struct {
+	AthleteID StringInt `json:"athlete_id"`
+	// FTP is the athlete's functional threshold power. When zero, and for
+	// rides without a power meter, training stress comes from suffer scores
+	// instead of power.
+	FTP  float64       `json:"ftp"`
+	From time.Time     `json:"from"`
+	To   time.Time     `json:"to"`
+	Days []CalendarDay `json:"days"`
+}
+
+type CalendarDay struct {
+	Date             time.Time `json:"date"`
+	Activities       int       `json:"activities"`
+	Distance         float64   `json:"distance"`
+	Elevation        float64   `json:"elevation"`
+	MovingTime       float64   `json:"moving_time"`
+	Kilojoules       float64   `json:"kilojoules"`
+	SufferScore      int32     `json:"suffer_score"`
+	AverageHeartrate float64   `json:"average_heartrate"`
+	Stress           float64   `json:"stress"`
+	// ATL is the acute load (fatigue), CTL the chronic load (fitness) and
+	// TSB the form going into the day.
+	ATL float64 `json:"atl"`
+	CTL float64 `json:"ctl"`
+	TSB float64 `json:"tsb"`
+}
@@ -0,0 +1,30 @@
+package modelsdk
+
+import "time"
+
+// EventGroupRides are the results of an edition that were ridden together.
+// Times are climbing times in seconds, as on the leaderboard.
+type EventGroupRides struct {
+	RouteName  string      `json:"route_name"`
+	Edition    string      `json:"edition"`
+	ComputedAt time.Time   `json:"computed_at"`
+	Groups     []GroupRide `json:"groups"`
+}
+
+type GroupRide struct {
+	Riders int `json:"riders"`
+	// Start is when the first of the group started.
+	Start       time.Time         `json:"start"`
+	BestRank    int64             `json:"best_rank"`
+	FastestTime int64             `json:"fastest_time"`
+	SlowestTime int64             `json:"slowest_time"`
+	AverageTime int64             `json:"average_time"`
+	Members     []GroupRideMember `json:"members"`
+}
+
+type GroupRideMember struct {
+	ActivityID StringInt  `json:"activity_id"`
+	Rank       int64      `json:"rank"`
+	Elapsed    int64      `json:"elapsed"`
+	Athlete    MinAthlete `json:"athlete"`
+}
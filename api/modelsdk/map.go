@@ -8,3 +8,61 @@ type Map struct {
 	SummaryPolyline string    `json:"summary_polyline"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
+
+// SegmentFeatureProperties are the GeoJSON properties of a segment line.
+type SegmentFeatureProperties struct {
+	ID                 StringInt `json:"id"`
+	Name               string    `json:"name"`
+	FriendlyName       string    `json:"friendly_name"`
+	Distance           float64   `json:"distance"`
+	AverageGrade       float64   `json:"average_grade"`
+	MaximumGrade       float64   `json:"maximum_grade"`
+	ElevationHigh      float64   `json:"elevation_high"`
+	ElevationLow       float64   `json:"elevation_low"`
+	TotalElevationGain float64   `json:"total_elevation_gain"`
+	ClimbCategory      int32     `json:"climb_category"`
+	TotalEffortCount   int32     `json:"total_effort_count"`
+	// PersonalBestElapsedTime is in seconds, only set for a logged in
+	// athlete who has ridden the segment.
+	PersonalBestElapsedTime *float64 `json:"personal_best_elapsed_time,omitempty"`
+}
+
+// ActivityFeatureProperties are the GeoJSON properties of an activity line.
+type ActivityFeatureProperties struct {
+	ID                 StringInt               `json:"id"`
+	AthleteID          StringInt               `json:"athlete_id"`
+	Name               string                  `json:"name"`
+	SportType          string                  `json:"sport_type"`
+	StartDate          time.Time               `json:"start_date"`
+	Distance           float64                 `json:"distance"`
+	MovingTime         float64                 `json:"moving_time"`
+	ElapsedTime        float64                 `json:"elapsed_time"`
+	TotalElevationGain float64                 `json:"total_elevation_gain"`
+	Efforts            []ActivityFeatureEffort `json:"efforts"`
+}
+
+type ActivityFeatureEffort struct {
+	SegmentID   StringInt `json:"segment_id"`
+	Name        string    `json:"name"`
+	StartDate   time.Time `json:"start_date"`
+	ElapsedTime float64   `json:"elapsed_time"`
+	MovingTime  float64   `json:"moving_time"`
+}
+
+// Heatmap is how many riders of an edition passed through each cell of a grid.
+type Heatmap struct {
+	RouteName string `json:"route_name"`
+	// BBox is [west, south, east, north], the same as GeoJSON.
+	BBox   []float64 `json:"bbox"`
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+	// Cells are row by row, starting in the north west corner.
+	Cells      []int32   `json:"cells"`
+	Max        int32     `json:"max"`
+	Activities int       `json:"activities"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+type HeatmapOptOut struct {
+	OptOut bool `json:"opt_out"`
+}
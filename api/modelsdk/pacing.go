@@ -0,0 +1,41 @@
+package modelsdk
+
+// RoutePacing is the pacing of one result on a route, against the field of
+// the edition it was ridden in.
+type RoutePacing struct {
+	RouteName  string    `json:"route_name"`
+	Edition    string    `json:"edition"`
+	ActivityID StringInt `json:"activity_id"`
+	AthleteID  StringInt `json:"athlete_id"`
+	// Finishers is the size of the field the climbs are measured against.
+	Finishers int `json:"finishers"`
+	Pacing
+}
+
+// Pacing splits a result into climbing and the transitions between the
+// climbs, all times in seconds. Relative times are over the field median, 1
+// is the median and lower is faster, 0 when there is no field.
+type Pacing struct {
+	ClimbingTime   int64 `json:"climbing_time"`
+	TransitionTime int64 `json:"transition_time"`
+	// FatigueIndex is the relative time of the late climbs over the early
+	// ones. Above 1 the rider faded, below 1 they finished stronger.
+	FatigueIndex float64            `json:"fatigue_index"`
+	Fastest      *PacingClimb       `json:"fastest,omitempty"`
+	Slowest      *PacingClimb       `json:"slowest,omitempty"`
+	Climbs       []PacingClimb      `json:"climbs"`
+	Transitions  []PacingTransition `json:"transitions"`
+}
+
+type PacingClimb struct {
+	SegmentID   StringInt `json:"segment_id"`
+	SegmentName string    `json:"segment_name,omitempty"`
+	Elapsed     int64     `json:"elapsed"`
+	Relative    float64   `json:"relative"`
+}
+
+type PacingTransition struct {
+	FromSegmentID StringInt `json:"from_segment_id"`
+	ToSegmentID   StringInt `json:"to_segment_id"`
+	Seconds       int64     `json:"seconds"`
+}
@@ -17,6 +17,10 @@ type CompetitiveRoute struct {
 	Segments    []SegmentSummary `json:"segments"`
 }
 
+type SetRouteReferenceRequest struct {
+	ActivityID StringInt `json:"activity_id"`
+}
+
 type SegmentSummary struct {
 	ID   StringInt `json:"id"`
 	Name string    `json:"name"`
@@ -27,6 +31,7 @@ type PersonalSegment struct {
 
 	Starred      bool                       `json:"starred,omitempty"`
 	PersonalBest *PersonalBestSegmentEffort `json:"personal_best,omitempty"`
+	Prediction   *SegmentPrediction         `json:"prediction,omitempty"`
 }
 
 type PersonalBestSegmentEffort struct {
@@ -70,3 +75,194 @@ type DetailedSegment struct {
 	// The time at which this segment was fetched from the Strava API.
 	FetchedAt time.Time `json:"fetched_at"`
 }
+
+// NearbySegment is a segment found by a location search.
+type NearbySegment struct {
+	ID            StringInt `json:"id"`
+	Name          string    `json:"name"`
+	ActivityType  string    `json:"activity_type"`
+	Distance      float64   `json:"distance"`
+	AverageGrade  float64   `json:"average_grade"`
+	MaximumGrade  float64   `json:"maximum_grade"`
+	ElevationHigh float64   `json:"elevation_high"`
+	ElevationLow  float64   `json:"elevation_low"`
+	StartLatlng   []float64 `json:"start_latlng"`
+	EndLatlng     []float64 `json:"end_latlng"`
+	ClimbCategory int32     `json:"climb_category"`
+	City          string    `json:"city"`
+	State         string    `json:"state"`
+	Country       string    `json:"country"`
+	Hazardous     bool      `json:"hazardous"`
+	// DistanceAway is the meters from the searched point to the start of the
+	// segment.
+	DistanceAway float64 `json:"distance_away"`
+}
+
+// RouteComparison lines up two results of a competitive route climb by climb.
+// All deltas are B minus A, so a negative time delta means B was faster.
+type RouteComparison struct {
+	A           RouteComparisonResult  `json:"a"`
+	B           RouteComparisonResult  `json:"b"`
+	Climbs      []ClimbComparison      `json:"climbs"`
+	Transitions []TransitionComparison `json:"transitions"`
+	// Segments only one of the results rode, when comparing across editions.
+	OnlyA []SegmentSummary `json:"only_a"`
+	OnlyB []SegmentSummary `json:"only_b"`
+
+	TotalDelta     int64    `json:"total_delta"`
+	HeartrateDelta *float64 `json:"heartrate_delta,omitempty"`
+	WattsDelta     *float64 `json:"watts_delta,omitempty"`
+}
+
+type RouteComparisonResult struct {
+	// RouteName is the edition this result was scored against.
+	RouteName        string     `json:"route_name"`
+	ActivityID       StringInt  `json:"activity_id"`
+	ActivityName     string     `json:"activity_name"`
+	StartDate        time.Time  `json:"start_date"`
+	Elapsed          int64      `json:"elapsed"`
+	AverageHeartrate float64    `json:"average_heartrate"`
+	DeviceWatts      bool       `json:"device_watts"`
+	AverageWatts     float64    `json:"average_watts"`
+	Athlete          MinAthlete `json:"athlete"`
+
+	ClimbPower
+}
+
+type ClimbComparison struct {
+	Segment         SegmentSummary `json:"segment"`
+	A               SegmentEffort  `json:"a"`
+	B               SegmentEffort  `json:"b"`
+	Delta           int64          `json:"delta"`
+	CumulativeDelta int64          `json:"cumulative_delta"`
+	WattsDelta      *float64       `json:"watts_delta,omitempty"`
+}
+
+// TransitionComparison is the time spent between two consecutive climbs.
+// A side is omitted if that result rode the climbs in the other order.
+type TransitionComparison struct {
+	From  SegmentSummary `json:"from"`
+	To    SegmentSummary `json:"to"`
+	A     *int64         `json:"a,omitempty"`
+	B     *int64         `json:"b,omitempty"`
+	Delta *int64         `json:"delta,omitempty"`
+}
+
+// RoutePrediction estimates an athlete's climbing time on a route. All times
+// are in seconds.
+type RoutePrediction struct {
+	RouteName string              `json:"route_name"`
+	Segments  []SegmentPrediction `json:"segments"`
+	// Segments the athlete has never ridden are not part of the totals.
+	Missing   []SegmentSummary `json:"missing"`
+	Complete  bool             `json:"complete"`
+	Predicted float64          `json:"predicted"`
+	Low       float64          `json:"low"`
+	High      float64          `json:"high"`
+}
+
+type SegmentPrediction struct {
+	SegmentID StringInt `json:"segment_id"`
+	PR        float64   `json:"pr"`
+	// Recent is the recency weighted average of the athlete's hard efforts.
+	Recent    float64 `json:"recent"`
+	Efforts   int     `json:"efforts"`
+	Predicted float64 `json:"predicted"`
+	Low       float64 `json:"low"`
+	High      float64 `json:"high"`
+}
+
+// MissingSegmentsResponse lists the route segments an activity has no Strava
+// effort on, and why.
+type MissingSegmentsResponse struct {
+	ActivityID StringInt        `json:"activity_id"`
+	Segments   []MissingSegment `json:"segments"`
+}
+
+type MissingSegment struct {
+	Segment SegmentSummary `json:"segment"`
+	// GPSMatch is the effort found in the activity's own track, if any.
+	GPSMatch    *GPSSegmentEffort `json:"gps_match,omitempty"`
+	Explanation string            `json:"explanation"`
+}
+
+// GPSSegmentEffort is an effort estimated from an activity's GPS track,
+// because Strava did not match the segment.
+type GPSSegmentEffort struct {
+	SegmentID   StringInt `json:"segment_id"`
+	Source      string    `json:"source"`
+	StartDate   time.Time `json:"start_date"`
+	ElapsedTime float64   `json:"elapsed_time"`
+	Distance    float64   `json:"distance"`
+	// Coverage is the fraction of the segment the track followed.
+	Coverage float64 `json:"coverage"`
+}
+
+// UnofficialRouteResult finishes a route only with GPS matched efforts. It is
+// never part of the official results.
+type UnofficialRouteResult struct {
+	RouteName    string      `json:"route_name"`
+	ActivityID   StringInt   `json:"activity_id"`
+	ActivityName string      `json:"activity_name"`
+	StartDate    time.Time   `json:"start_date"`
+	Elapsed      int64       `json:"elapsed"`
+	GPSSegments  []StringInt `json:"gps_segments"`
+	Athlete      MinAthlete  `json:"athlete"`
+	Unofficial   bool        `json:"unofficial"`
+}
+
+// SegmentEffortHistory is every stored effort of an athlete on a route
+// segment, newest first, and how they trend. Times are in seconds.
+type SegmentEffortHistory struct {
+	AthleteID StringInt `json:"athlete_id"`
+	SegmentID StringInt `json:"segment_id"`
+	// Routes are the names of the competitive routes with the segment.
+	Routes  []string                  `json:"routes"`
+	Efforts []HistoricalSegmentEffort `json:"efforts"`
+	// ElapsedTrend and WattsTrend are nil without enough efforts to fit.
+	ElapsedTrend *SegmentTrendLine   `json:"elapsed_trend,omitempty"`
+	WattsTrend   *SegmentTrendLine   `json:"watts_trend,omitempty"`
+	SeasonBests  []SegmentSeasonBest `json:"season_bests"`
+	Form         SegmentForm         `json:"form"`
+}
+
+type HistoricalSegmentEffort struct {
+	EffortID       StringInt `json:"effort_id"`
+	ActivityID     StringInt `json:"activity_id"`
+	StartDate      time.Time `json:"start_date"`
+	StartDateLocal time.Time `json:"start_date_local"`
+	ElapsedTime    float64   `json:"elapsed_time"`
+	MovingTime     float64   `json:"moving_time"`
+	DeviceWatts    bool      `json:"device_watts"`
+	AverageWatts   float64   `json:"average_watts"`
+	// PRRank and KOMRank are set by Strava for top 3 and top 10 efforts.
+	PRRank  *int32 `json:"pr_rank,omitempty"`
+	KOMRank *int32 `json:"kom_rank,omitempty"`
+}
+
+// SegmentTrendLine is a straight line fit through the efforts.
+type SegmentTrendLine struct {
+	Start      time.Time `json:"start"`
+	StartValue float64   `json:"start_value"`
+	End        time.Time `json:"end"`
+	EndValue   float64   `json:"end_value"`
+	PerDay     float64   `json:"per_day"`
+}
+
+type SegmentSeasonBest struct {
+	Year        int       `json:"year"`
+	EffortID    StringInt `json:"effort_id"`
+	ElapsedTime float64   `json:"elapsed_time"`
+	Date        time.Time `json:"date"`
+}
+
+// SegmentForm compares the best of the last six weeks to the PR.
+type SegmentForm struct {
+	// Level is one of peak, close, building or unknown.
+	Level      string  `json:"level"`
+	PR         float64 `json:"pr"`
+	RecentBest float64 `json:"recent_best"`
+	// Pace is the PR divided by the recent best, 1 when matching the PR.
+	Pace   float64 `json:"pace"`
+	Recent int     `json:"recent"`
+}
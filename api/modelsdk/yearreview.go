@@ -0,0 +1,82 @@
+package modelsdk
+
+import "time"
+
+// YearReview is an athlete's calendar year of activities. Distances and
+// elevation are in meters, times in seconds.
+type YearReview struct {
+	AthleteID  StringInt `json:"athlete_id"`
+	Year       int       `json:"year"`
+	ComputedAt time.Time `json:"computed_at"`
+	// Totals is every activity, Sport is empty.
+	Totals YearTotals `json:"totals"`
+	// Sports are the totals by sport type, most activities first.
+	Sports []YearTotals `json:"sports"`
+	// LongestRide and BiggestClimbDay are nil without activities.
+	LongestRide     *YearActivity `json:"longest_ride"`
+	BiggestClimbDay *YearDay      `json:"biggest_climb_day"`
+	// Segments are the most ridden segments of the year.
+	Segments  []YearSegment `json:"segments"`
+	Eddington YearEddington `json:"eddington"`
+	Hugels    []YearHugel   `json:"hugels"`
+	// Shared is if the athlete made the card public.
+	Shared bool `json:"shared"`
+}
+
+type YearReviewShare struct {
+	Shared bool `json:"shared"`
+}
+
+type YearTotals struct {
+	Sport      string  `json:"sport"`
+	Activities int     `json:"activities"`
+	Distance   float64 `json:"distance"`
+	Elevation  float64 `json:"elevation"`
+	MovingTime float64 `json:"moving_time"`
+}
+
+type YearActivity struct {
+	ActivityID     StringInt `json:"activity_id"`
+	Name           string    `json:"name"`
+	StartDateLocal time.Time `json:"start_date_local"`
+	SportType      string    `json:"sport_type"`
+	Distance       float64   `json:"distance"`
+	Elevation      float64   `json:"elevation"`
+}
+
+type YearDay struct {
+	Date       time.Time `json:"date"`
+	Elevation  float64   `json:"elevation"`
+	Activities int       `json:"activities"`
+}
+
+type YearSegment struct {
+	SegmentID StringInt `json:"segment_id"`
+	Name      string    `json:"name"`
+	Efforts   int64     `json:"efforts"`
+	// BestTime is the fastest effort of the year.
+	BestTime float64 `json:"best_time"`
+}
+
+// YearEddington is the lifetime number in miles over the year.
+type YearEddington struct {
+	Start int32 `json:"start"`
+	End   int32 `json:"end"`
+	// Year is the number of the year's rides alone.
+	Year       int32                `json:"year"`
+	Milestones []EddingtonMilestone `json:"milestones"`
+}
+
+// YearHugel is the athlete's result in an edition held that year.
+type YearHugel struct {
+	RouteName        string    `json:"route_name"`
+	Title            string    `json:"title"`
+	Lite             bool      `json:"lite"`
+	ActivityID       StringInt `json:"activity_id"`
+	Rank             int64     `json:"rank"`
+	Finishers        int       `json:"finishers"`
+	TotalTimeSeconds int64     `json:"total_time_seconds"`
+	// Superlatives are the json names of the edition's superlatives the
+	// result holds, e.g. "longest_ride".
+	Superlatives []string `json:"superlatives"`
+}
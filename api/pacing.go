@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/internal/pacing"
+)
+
+// routePacing breaks a result into its climbs and transitions, measured
+// against the field of the edition it was ridden in.
+func (api *API) routePacing(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	routeName := chi.URLParam(r, "route-name")
+
+	editions := hugeldate.RouteEditions(routeName)
+	if len(editions) == 0 {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: "Route not found",
+		})
+		return
+	}
+
+	activityID, err := strconv.ParseInt(chi.URLParam(r, "activity_id"), 10, 64)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid activity id",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rows, err := api.Opts.DB.RouteActivityResults(ctx, []int64{activityID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load route results",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	result, ok := routeEditionResult(rows, activityID, editions)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: fmt.Sprintf("Activity %d has no result on route %q", activityID, routeName),
+		})
+		return
+	}
+
+	var efforts database.HugelSegmentEfforts
+	if err := json.Unmarshal(result.Efforts, &efforts); err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to parse efforts",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	edition, _ := hugeldate.EditionByRoute(result.RouteName)
+	var field []database.HugelLeaderboardRow
+	if board := api.editionBoardCache(edition); board != nil {
+		loaded, err := board.Load(ctx)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to load leaderboard",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		field = loaded.rows
+	}
+
+	segmentIDs := make([]int64, 0, len(efforts))
+	for _, e := range efforts {
+		segmentIDs = append(segmentIDs, int64(e.SegmentID))
+	}
+	segments, err := api.Opts.DB.GetSegments(ctx, segmentIDs)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segments",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	names := make(map[int64]string, len(segments))
+	for _, seg := range segments {
+		names[seg.Segment.ID] = seg.Segment.Name
+		if seg.Segment.FriendlyName != "" {
+			names[seg.Segment.ID] = seg.Segment.FriendlyName
+		}
+	}
+
+	sdk := modelsdk.RoutePacing{
+		RouteName:  result.RouteName,
+		Edition:    edition.Title(),
+		ActivityID: modelsdk.StringInt(result.ActivityID),
+		AthleteID:  modelsdk.StringInt(result.AthleteID),
+		Finishers:  len(field),
+		Pacing:     convertPacing(pacing.Analyze(efforts, fieldMedians(field)), names),
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sdk)
+}
+
+// addPacing fills in the pacing of the converted leaderboard activities,
+// rows and activities are in the same order.
+func addPacing(activities []modelsdk.HugelLeaderBoardActivity, rows []database.HugelLeaderboardRow) {
+	medians := fieldMedians(rows)
+	for i, row := range rows {
+		p := convertPacing(pacing.Analyze(row.Efforts, medians), nil)
+		activities[i].Pacing = &p
+	}
+}
+
+func fieldMedians(rows []database.HugelLeaderboardRow) pacing.Medians {
+	results := make([]database.HugelSegmentEfforts, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, row.Efforts)
+	}
+	return pacing.FieldMedians(results)
+}
+
+// convertPacing names the climbs when names are given.
+func convertPacing(b pacing.Breakdown, names map[int64]string) modelsdk.Pacing {
+	climb := func(c pacing.Climb) modelsdk.PacingClimb {
+		return modelsdk.PacingClimb{
+			SegmentID:   modelsdk.StringInt(c.SegmentID),
+			SegmentName: names[c.SegmentID],
+			Elapsed:     int64(c.Elapsed),
+			Relative:    c.Relative,
+		}
+	}
+
+	sdk := modelsdk.Pacing{
+		ClimbingTime:   int64(b.ClimbingTime),
+		TransitionTime: int64(b.TransitionTime),
+		FatigueIndex:   b.FatigueIndex,
+		Climbs:         make([]modelsdk.PacingClimb, 0, len(b.Climbs)),
+		Transitions:    make([]modelsdk.PacingTransition, 0, len(b.Transitions)),
+	}
+	for _, c := range b.Climbs {
+		sdk.Climbs = append(sdk.Climbs, climb(c))
+	}
+	for _, t := range b.Transitions {
+		sdk.Transitions = append(sdk.Transitions, modelsdk.PacingTransition{
+			FromSegmentID: modelsdk.StringInt(t.FromSegmentID),
+			ToSegmentID:   modelsdk.StringInt(t.ToSegmentID),
+			Seconds:       int64(t.Seconds),
+		})
+	}
+	if b.Fastest != nil {
+		fastest := climb(*b.Fastest)
+		sdk.Fastest = &fastest
+	}
+	if b.Slowest != nil {
+		slowest := climb(*b.Slowest)
+		sdk.Slowest = &slowest
+	}
+	return sdk
+}
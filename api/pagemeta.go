@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/certificate"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	server "github.com/Emyrk/strava/site"
+)
+
+var _ server.MetaLookup = (*API)(nil)
+
+// PageMeta returns the link preview of a site page, so shared links to
+// athletes and results are not all the same generic preview.
+func (api *API) PageMeta(ctx context.Context, path string) (server.Meta, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	var (
+		meta server.Meta
+		err  error
+	)
+	switch {
+	case len(parts) == 4 && parts[0] == "athlete" && parts[2] == "year":
+		meta, err = api.yearReviewPageMeta(ctx, parts[1], parts[3])
+	case len(parts) != 2:
+		return server.Meta{}, false
+	case parts[0] == "athlete":
+		meta, err = api.athletePageMeta(ctx, parts[1])
+	case parts[0] == "hugelboard":
+		meta, err = api.hugelboardPageMeta(ctx, parts[1])
+	case parts[0] == "route":
+		meta, err = api.routePageMeta(ctx, parts[1])
+	default:
+		return server.Meta{}, false
+	}
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			api.Opts.Logger.Warn().Err(err).Str("path", path).Msg("page meta lookup")
+		}
+		return server.Meta{}, false
+	}
+
+	meta.URL = api.absoluteURL(path)
+	return meta, true
+}
+
+func (api *API) athletePageMeta(ctx context.Context, idStr string) (server.Meta, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return server.Meta{}, err
+	}
+
+	athlete, err := api.Opts.DB.GetAthleteFull(ctx, id)
+	if err != nil {
+		return server.Meta{}, err
+	}
+
+	name := strings.TrimSpace(fmt.Sprintf("%s %s", athlete.Athlete.Firstname, athlete.Athlete.Lastname))
+	description := fmt.Sprintf("%s has not completed a Tour Das Hugel yet.", name)
+	if athlete.HugelCount == 1 {
+		description = fmt.Sprintf("%s has completed 1 Tour Das Hugel.", name)
+	} else if athlete.HugelCount > 1 {
+		description = fmt.Sprintf("%s has completed %d Tour Das Hugels.", name, athlete.HugelCount)
+	}
+	return server.Meta{
+		Title:       fmt.Sprintf("%s | Das Hugel", name),
+		Description: description,
+		Image:       athlete.Athlete.ProfilePicLink,
+	}, nil
+}
+
+// yearReviewPageMeta previews an athlete's year with its card, once the athlete
+// has shared it.
+func (api *API) yearReviewPageMeta(ctx context.Context, idStr, yearStr string) (server.Meta, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return server.Meta{}, err
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		return server.Meta{}, err
+	}
+
+	athlete, err := api.Opts.DB.GetAthleteFull(ctx, id)
+	if err != nil {
+		return server.Meta{}, err
+	}
+	review, err := api.Opts.DB.GetAthleteYearReview(ctx, database.GetAthleteYearReviewParams{
+		AthleteID: id,
+		Year:      int32(year),
+	})
+	if err != nil {
+		return server.Meta{}, err
+	}
+	if !review.Shared {
+		return server.Meta{}, fmt.Errorf("year review not shared: %w", pgx.ErrNoRows)
+	}
+
+	name := strings.TrimSpace(fmt.Sprintf("%s %s", athlete.Athlete.Firstname, athlete.Athlete.Lastname))
+	return server.Meta{
+		Title:       fmt.Sprintf("%s's %d in review | Das Hugel", name, year),
+		Description: fmt.Sprintf("The rides, climbs and hugels of %s in %d.", name, year),
+		Image:       api.absoluteURL(fmt.Sprintf("/api/v1/athlete/%d/year/%d/og.png", id, year)),
+	}, nil
+}
+
+func (api *API) hugelboardPageMeta(ctx context.Context, yearStr string) (server.Meta, error) {
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		return server.Meta{}, err
+	}
+
+	edition, ok := hugeldate.EditionByYear(year, false)
+	if !ok {
+		return server.Meta{}, fmt.Errorf("no edition in %d: %w", year, pgx.ErrNoRows)
+	}
+
+	meta := server.Meta{
+		Title:       fmt.Sprintf("%s Results", edition.Title()),
+		Description: fmt.Sprintf("Results of %s.", edition.Title()),
+	}
+
+	board := api.editionBoardCache(edition)
+	if board == nil {
+		return meta, nil
+	}
+	loaded, err := board.Load(ctx)
+	if err != nil {
+		return server.Meta{}, err
+	}
+	activities := loaded.rows
+	if len(activities) > 0 {
+		winner := activities[0]
+		meta.Description = fmt.Sprintf("%d finishers. Fastest climbing time %s by %s %s.",
+			len(activities),
+			certificate.FormatDuration(time.Duration(winner.TotalTimeSeconds)*time.Second),
+			winner.Firstname, winner.Lastname,
+		)
+		meta.Image = api.absoluteURL(fmt.Sprintf("/api/v1/athlete/%d/certificate/%d/og.png", winner.AthleteID, winner.ActivityID))
+	}
+	return meta, nil
+}
+
+func (api *API) routePageMeta(ctx context.Context, routeName string) (server.Meta, error) {
+	route, err := api.Opts.DB.GetCompetitiveRoute(ctx, routeName)
+	if err != nil {
+		return server.Meta{}, err
+	}
+	return server.Meta{
+		Title:       fmt.Sprintf("%s | Das Hugel", route.DisplayName),
+		Description: route.Description,
+	}, nil
+}
+
+func (api *API) absoluteURL(path string) string {
+	return strings.TrimSuffix(api.Opts.AccessURL.String(), "/") + path
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/internal/predict"
+)
+
+// predictRoute predicts the authenticated athlete's climbing time on a route.
+func (api *API) predictRoute(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx                 = r.Context()
+		id, athleteLoggedIn = httpmw.AuthenticatedAthleteIDOptional(r)
+	)
+	routeName := chi.URLParam(r, "route-name")
+
+	if !athleteLoggedIn {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, modelsdk.Response{
+			Message: "Log in to get a prediction",
+		})
+		return
+	}
+
+	lite := false
+	switch routeName {
+	case "das-hugel":
+	case "lite-das-hugel":
+		lite = true
+	default:
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: "Route not found",
+		})
+		return
+	}
+
+	route, err := api.HugelRoute(ctx, lite)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load route",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	fatigue, err := api.HugelFatigue(ctx, lite)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load field fatigue",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	segments := convertRoute(route).Segments
+	segmentIDs := make([]int64, 0, len(segments))
+	for _, seg := range segments {
+		segmentIDs = append(segmentIDs, int64(seg.ID))
+	}
+
+	efforts, err := api.Opts.DB.GetPersonalSegmentEfforts(ctx, database.GetPersonalSegmentEffortsParams{
+		AthleteID:  id,
+		SegmentIds: segmentIDs,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segment efforts",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	p := predict.Predictor{Fatigue: fatigue}
+	pred := p.Route(segmentIDs, convertPredictEfforts(efforts), time.Now())
+
+	resp := modelsdk.RoutePrediction{
+		RouteName: route.Name,
+		Segments:  make([]modelsdk.SegmentPrediction, 0, len(pred.Segments)),
+		Missing:   make([]modelsdk.SegmentSummary, 0, len(pred.Missing)),
+		Complete:  pred.Complete(),
+		Predicted: pred.Predicted,
+		Low:       pred.Low,
+		High:      pred.High,
+	}
+	for _, seg := range pred.Segments {
+		resp.Segments = append(resp.Segments, convertSegmentPrediction(seg))
+	}
+	for _, missing := range pred.Missing {
+		for _, seg := range segments {
+			if int64(seg.ID) == missing {
+				resp.Missing = append(resp.Missing, seg)
+			}
+		}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// routeFatigue learns how much the field fades on the day from every past
+// edition of a route.
+func routeFatigue(ctx context.Context, db database.Store, routeName string) (predict.Fatigue, error) {
+	editions := hugeldate.RouteEditions(routeName)
+	routeNames := make([]string, 0, len(editions))
+	for _, e := range editions {
+		routeNames = append(routeNames, e.RouteName)
+	}
+
+	rows, err := db.RouteFatigueSamples(ctx, routeNames)
+	if err != nil {
+		return predict.Fatigue{}, fmt.Errorf("fatigue samples: %w", err)
+	}
+	return predict.FieldFatigue(predict.Samples(predict.Predictor{}, predict.Climbs(rows))), nil
+}
+
+func convertPredictEfforts(efforts []database.SegmentEffort) []predict.Effort {
+	converted := make([]predict.Effort, 0, len(efforts))
+	for _, e := range efforts {
+		converted = append(converted, predict.Effort{
+			SegmentID: e.SegmentID,
+			Elapsed:   e.ElapsedTime,
+			StartDate: e.StartDate.Time,
+		})
+	}
+	return converted
+}
+
+func convertSegmentPrediction(seg predict.SegmentPrediction) modelsdk.SegmentPrediction {
+	return modelsdk.SegmentPrediction{
+		SegmentID: modelsdk.StringInt(seg.SegmentID),
+		PR:        seg.PR,
+		Recent:    seg.Recent,
+		Efforts:   seg.Efforts,
+		Predicted: seg.Predicted,
+		Low:       seg.Low,
+		High:      seg.High,
+	}
+}
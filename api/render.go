@@ -0,0 +1,365 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/internal/staticmap"
+	"github.com/Emyrk/strava/lib/geo"
+	"github.com/Emyrk/strava/strava"
+	"github.com/Emyrk/strava/strava/stravalimit"
+)
+
+const (
+	renderDefaultWidth  = 800
+	renderDefaultHeight = 500
+	renderMinSize       = 100
+	renderMaxSize       = 2000
+
+	// profileBackoff is how long an activity's altitude stream is not asked
+	// for again after Strava failed to give it.
+	profileBackoff = 15 * time.Minute
+)
+
+// renderActivity draws an activity's track as a png, with the climbs of the
+// competitive routes highlighted. Like the activity's geojson, it is only drawn
+// for its athlete and admins.
+func (api *API) renderActivity(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	activityID, err := strconv.ParseInt(chi.URLParam(r, "activity_id"), 10, 64)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid activity id",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	m, profile, ok := renderOptions(rw, r)
+	if !ok {
+		return
+	}
+
+	activity, err := api.Opts.DB.GetActivitySummary(ctx, activityID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pgx.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load activity",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if !httpmw.RequestAuthenticatedAsAdminsOrMe(rw, r, activity.AthleteID) {
+		return
+	}
+
+	activityMap, err := api.Opts.DB.GetActivityMap(ctx, activityID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load activity map",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	key := fmt.Sprintf("activity-%d-%d-%dx%d-%t", activityID, activityMap.UpdatedAt.Time.Unix(), m.Width, m.Height, profile)
+	if data, ok := api.RenderCache.Get(key); ok {
+		writeRender(rw, data, true)
+		return
+	}
+
+	m.Climbs, err = api.activityClimbs(ctx, activityID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load climbs",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// A broken polyline falls back to straight lines between climbs.
+	m.Track, _ = decodeMap(activityMap)
+	if len(m.Track) == 0 {
+		for _, climb := range m.Climbs {
+			m.Track = append(m.Track, climb...)
+		}
+	}
+
+	// A profile that can't be fetched right now is left off, and the image
+	// is not cached so a request after the backoff tries again.
+	cache := true
+	if profile {
+		m.Elevation, err = api.elevationProfile(ctx, activity)
+		if err != nil {
+			api.Opts.Logger.Warn().Err(err).Int64("activity_id", activityID).Msg("render without elevation profile")
+			cache = false
+		}
+	}
+
+	api.render(ctx, rw, m, key, cache, true)
+}
+
+// renderRoute draws a competitive route as a png. The track is the route's
+// reference activity, or straight lines between the climbs without one.
+func (api *API) renderRoute(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		routeName = chi.URLParam(r, "route-name")
+	)
+
+	m, profile, ok := renderOptions(rw, r)
+	if !ok {
+		return
+	}
+
+	route, err := api.Opts.DB.GetCompetitiveRouteByName(ctx, routeName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pgx.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load route",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// A new reference activity is a new image.
+	key := fmt.Sprintf("route-%s-%d-%dx%d-%t", route.Name, route.ReferenceActivityID.Int64, m.Width, m.Height, profile)
+	if data, ok := api.RenderCache.Get(key); ok {
+		writeRender(rw, data, false)
+		return
+	}
+
+	segments, err := api.Opts.DB.GetSegments(ctx, route.Segments)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segments",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	for _, climb := range routeClimbs(route, segments) {
+		m.Climbs = append(m.Climbs, climb.Line)
+	}
+
+	cache := true
+	if route.ReferenceActivityID.Valid {
+		activityMap, err := api.Opts.DB.GetActivityMap(ctx, route.ReferenceActivityID.Int64)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to load reference activity",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		// A broken polyline falls back to straight lines between climbs.
+		m.Track, _ = decodeMap(activityMap)
+
+		if profile {
+			activity, err := api.Opts.DB.GetActivitySummary(ctx, route.ReferenceActivityID.Int64)
+			if err == nil {
+				m.Elevation, err = api.elevationProfile(ctx, activity)
+			}
+			if err != nil {
+				api.Opts.Logger.Warn().Err(err).Str("route", route.Name).Msg("render without elevation profile")
+				cache = false
+			}
+		}
+	}
+	if len(m.Track) == 0 {
+		for _, climb := range m.Climbs {
+			m.Track = append(m.Track, climb...)
+		}
+	}
+
+	api.render(ctx, rw, m, key, cache, false)
+}
+
+// activityClimbs returns the lines of the activity's efforts on the segments
+// of any edition's route.
+func (api *API) activityClimbs(ctx context.Context, activityID int64) ([][]geo.Point, error) {
+	onRoute := make(map[int64]bool)
+	for _, e := range hugeldate.Editions {
+		route, err := api.Opts.DB.GetCompetitiveRouteByName(ctx, e.RouteName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", e.RouteName, err)
+		}
+		for _, id := range route.Segments {
+			onRoute[id] = true
+		}
+	}
+
+	efforts, err := api.Opts.DB.GetActivitySegmentEfforts(ctx, activityID)
+	if err != nil {
+		return nil, fmt.Errorf("efforts: %w", err)
+	}
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, e := range efforts {
+		if onRoute[e.SegmentID] && !seen[e.SegmentID] {
+			seen[e.SegmentID] = true
+			ids = append(ids, e.SegmentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	segments, err := api.Opts.DB.GetSegments(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("segments: %w", err)
+	}
+	climbs := make([][]geo.Point, 0, len(segments))
+	for _, seg := range segments {
+		// A broken segment polyline is just not highlighted.
+		if line, err := decodeMap(seg.Map); err == nil && len(line) > 1 {
+			climbs = append(climbs, line)
+		}
+	}
+	return climbs, nil
+}
+
+// elevationProfile returns the altitude stream of the activity. The stream
+// is cached on its own, so every size of the image shares one call to Strava,
+// and a failed call is not retried until the backoff passes.
+func (api *API) elevationProfile(ctx context.Context, activity database.ActivitySummary) ([]float64, error) {
+	key := fmt.Sprintf("altitude-%d", activity.ID)
+	if data, ok := api.RenderCache.Get(key); ok {
+		var altitude []float64
+		if err := json.Unmarshal(data, &altitude); err == nil {
+			return altitude, nil
+		}
+	}
+	if _, failed := api.ProfileFailures.Get(key); failed {
+		return nil, fmt.Errorf("altitude stream failed recently, retrying after %s", profileBackoff)
+	}
+
+	altitude, err := api.fetchElevationProfile(ctx, activity)
+	if err != nil {
+		api.ProfileFailures.Set(key, nil)
+		return nil, err
+	}
+
+	data, err := json.Marshal(altitude)
+	if err == nil {
+		err = api.RenderCache.Set(key, data)
+	}
+	if err != nil {
+		api.Opts.Logger.Error().Err(err).Str("key", key).Msg("cache altitude stream")
+	}
+	return altitude, nil
+}
+
+// fetchElevationProfile fetches the altitude stream of the activity from
+// Strava.
+func (api *API) fetchElevationProfile(ctx context.Context, activity database.ActivitySummary) ([]float64, error) {
+	ok, _ := stravalimit.CanLogger(1, 50, 500, api.Opts.Logger)
+	if !ok {
+		return nil, fmt.Errorf("strava rate limit reached")
+	}
+
+	login, err := api.Opts.DB.GetAthleteLogin(ctx, activity.AthleteID)
+	if err != nil {
+		return nil, fmt.Errorf("athlete login: %w", err)
+	}
+
+	cli := strava.NewOAuthClient(api.OAuthConfig.Client(ctx, login.OAuthToken()))
+	streams, err := cli.GetActivityStreams(ctx, activity.ID, "altitude")
+	if err != nil {
+		return nil, fmt.Errorf("altitude stream: %w", err)
+	}
+	if streams.Altitude == nil {
+		return nil, nil
+	}
+	return streams.Altitude.Data, nil
+}
+
+func (api *API) render(ctx context.Context, rw http.ResponseWriter, m staticmap.Map, key string, cache bool, private bool) {
+	var buf bytes.Buffer
+	if err := m.WritePNG(&buf); err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to render map",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if cache {
+		if err := api.RenderCache.Set(key, buf.Bytes()); err != nil {
+			api.Opts.Logger.Error().Err(err).Str("key", key).Msg("cache rendered map")
+		}
+	}
+	writeRender(rw, buf.Bytes(), private)
+}
+
+func writeRender(rw http.ResponseWriter, data []byte, private bool) {
+	rw.Header().Set("Content-Type", "image/png")
+	if private {
+		rw.Header().Set("Cache-Control", "private, max-age=3600")
+	} else {
+		rw.Header().Set("Cache-Control", "public, max-age=3600")
+	}
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(data)
+}
+
+// renderOptions reads the image size, and if an elevation profile is wanted.
+func renderOptions(rw http.ResponseWriter, r *http.Request) (staticmap.Map, bool, bool) {
+	m := staticmap.Map{Width: renderDefaultWidth, Height: renderDefaultHeight}
+	for _, opt := range []struct {
+		name string
+		dst  *int
+	}{{"width", &m.Width}, {"height", &m.Height}} {
+		v := r.URL.Query().Get(opt.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < renderMinSize || n > renderMaxSize {
+			httpapi.Write(r.Context(), rw, http.StatusBadRequest, modelsdk.Response{
+				Message: fmt.Sprintf("Invalid %s, must be between %d and %d", opt.name, renderMinSize, renderMaxSize),
+			})
+			return m, false, false
+		}
+		*opt.dst = n
+	}
+
+	profile := r.URL.Query().Get("profile")
+	return m, profile == "true" || profile == "1", true
+}
+
+// decodeMap decodes the most detailed polyline of the map, falling back to
+// the summary polyline when the detailed one is missing or broken.
+func decodeMap(m database.Map) ([]geo.Point, error) {
+	if m.Polyline != "" {
+		points, err := geo.DecodePolyline(m.Polyline)
+		if err == nil || m.SummaryPolyline == "" {
+			return points, err
+		}
+	}
+	return geo.DecodePolyline(m.SummaryPolyline)
+}
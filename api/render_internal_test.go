@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/lib/diskcache"
+)
+
+func TestElevationProfileCache(t *testing.T) {
+	t.Parallel()
+
+	cache, err := diskcache.Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	api := &API{
+		RenderCache:     cache,
+		ProfileFailures: newRenderCache(time.Hour, 8),
+	}
+	ctx := context.Background()
+
+	// A stream that is cached is not fetched again.
+	require.NoError(t, api.RenderCache.Set("altitude-1", []byte("[10,20.5,30]")))
+	altitude, err := api.elevationProfile(ctx, database.ActivitySummary{ID: 1})
+	require.NoError(t, err)
+	require.Equal(t, []float64{10, 20.5, 30}, altitude)
+
+	// Nor is one that failed, until the backoff passes.
+	api.ProfileFailures.Set("altitude-2", nil)
+	_, err = api.elevationProfile(ctx, database.ActivitySummary{ID: 2})
+	require.ErrorContains(t, err, "failed recently")
+}
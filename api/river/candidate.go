@@ -0,0 +1,51 @@
+package river
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Emyrk/strava/internal/candidate"
+	"github.com/Emyrk/strava/strava"
+)
+
+// scorerCache keeps the candidate scorer of the latest editions around, the
+// routes only change when an admin edits them.
+type scorerCache struct {
+	mu       sync.Mutex
+	scorer   *candidate.Scorer
+	loadedAt time.Time
+}
+
+func (m *Manager) candidateScorer(ctx context.Context) (*candidate.Scorer, error) {
+	m.scorers.mu.Lock()
+	defer m.scorers.mu.Unlock()
+
+	if m.scorers.scorer != nil && time.Since(m.scorers.loadedAt) < time.Hour {
+		return m.scorers.scorer, nil
+	}
+	scorer, err := candidate.LoadYear(ctx, m.db, candidate.LatestYear())
+	if err != nil {
+		return nil, err
+	}
+	m.scorers.scorer, m.scorers.loadedAt = scorer, time.Now()
+	return scorer, nil
+}
+
+// hugelPotential guesses if an activity could be a hugel result from its
+// summary track. scored is false if there is no track to go on, then the
+// distance and climbing of the ride are used instead.
+func (m *Manager) hugelPotential(ctx context.Context, summary strava.ActivitySummary) (potential bool, scored bool) {
+	fallback := canBeHugel(summary) || canBeHugelLite(summary)
+
+	scorer, err := m.candidateScorer(ctx)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("load candidate scorer")
+		return fallback, false
+	}
+	score, ok := scorer.ScorePolyline(summary.Map.SummaryPolyline)
+	if !ok {
+		return fallback, false
+	}
+	return scorer.Candidate(score), true
+}
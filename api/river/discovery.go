@@ -0,0 +1,245 @@
+package river
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/candidate"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/strava/stravalimit"
+)
+
+const (
+	// discoveryLimit is the most detail fetches a discovery run queues.
+	discoveryLimit = 100
+	// discoveryDailyBuffer keeps old discoveries from eating into the calls
+	// new activities need.
+	discoveryDailyBuffer = 1000
+)
+
+func (m *Manager) EnqueueHugelDiscovery(ctx context.Context, args HugelDiscoveryArgs, opts ...func(j *river.InsertOpts)) (bool, error) {
+	iopts := &river.InsertOpts{}
+	for _, opt := range opts {
+		opt(iopts)
+	}
+
+	fi, err := m.cli.Insert(ctx, args, iopts)
+
+	skipped := false
+	if fi != nil {
+		skipped = fi.UniqueSkippedAsDuplicate
+	}
+
+	return !skipped, err
+}
+
+// HugelDiscoveryArgs scans the stored summaries of every past event for rides
+// that were never fetched in detail, but pass the route's climbs.
+type HugelDiscoveryArgs struct {
+	// Limit is the most detail fetches to queue, 0 for the default. The rest
+	// wait for the next run.
+	Limit int `json:"limit"`
+}
+
+func (HugelDiscoveryArgs) Kind() string { return "hugel_discovery" }
+func (HugelDiscoveryArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       riverDatabaseQueue,
+		MaxAttempts: 3,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Hour * 23,
+		},
+	}
+}
+
+type HugelDiscoveryWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[HugelDiscoveryArgs]
+}
+
+func (*HugelDiscoveryWorker) Middleware(job *rivertype.JobRow) []rivertype.WorkerMiddleware {
+	return []rivertype.WorkerMiddleware{}
+}
+
+func (w *HugelDiscoveryWorker) Work(ctx context.Context, job *river.Job[HugelDiscoveryArgs]) error {
+	res, err := w.mgr.DiscoverHugels(ctx, job.Args.Limit)
+	if err != nil {
+		return err
+	}
+
+	finishers, err := DiscoveredFinishers(ctx, w.mgr.db)
+	if err != nil {
+		return err
+	}
+
+	_ = river.RecordOutput(ctx, map[string]any{
+		"scanned":    res.Scanned,
+		"candidates": res.Candidates,
+		"enqueued":   res.Enqueued,
+		"waiting":    res.Waiting,
+		"finishers":  fmt.Sprintf("%d discovered finishers so far", len(finishers)),
+	})
+	return nil
+}
+
+type DiscoveryResult struct {
+	// Scanned is how many unfetched rides were scored.
+	Scanned int
+	// Candidates is how many of them pass enough of the route.
+	Candidates int
+	// Enqueued is how many detail fetches were queued this run.
+	Enqueued int
+	// Waiting is how many candidates are left for a later run.
+	Waiting int
+}
+
+// DiscoverHugels scores every stored ride summary from past event dates that
+// has no detail against that year's routes. The best candidates get a detail
+// fetch, as many as the daily rate limit budget allows.
+func (m *Manager) DiscoverHugels(ctx context.Context, limit int) (DiscoveryResult, error) {
+	if limit <= 0 {
+		limit = discoveryLimit
+	}
+
+	type discovered struct {
+		row   database.HugelDiscoveryCandidatesRow
+		score candidate.Score
+	}
+
+	var (
+		res   DiscoveryResult
+		found []discovered
+		years = make(map[int]bool)
+	)
+	for _, e := range hugeldate.Editions {
+		// The full and lite routes of a year share their dates.
+		if years[e.Year] {
+			continue
+		}
+		years[e.Year] = true
+
+		scorer, err := candidate.LoadYear(ctx, m.db, e.Year)
+		if err != nil {
+			return res, fmt.Errorf("load %d routes: %w", e.Year, err)
+		}
+
+		rows, err := m.db.HugelDiscoveryCandidates(ctx, database.HugelDiscoveryCandidatesParams{
+			StartDate: database.Timestamptz(e.Dates.Start),
+			EndDate:   database.Timestamptz(e.Dates.End),
+		})
+		if err != nil {
+			return res, fmt.Errorf("%d candidates: %w", e.Year, err)
+		}
+
+		for _, row := range rows {
+			res.Scanned++
+			score, ok := scorer.ScorePolyline(row.SummaryPolyline)
+			if !ok || !scorer.Candidate(score) {
+				continue
+			}
+
+			err := m.db.UpsertHugelDiscovery(ctx, database.UpsertHugelDiscoveryParams{
+				ActivityID:  row.ActivityID,
+				AthleteID:   row.AthleteID,
+				RouteName:   score.RouteName,
+				Hits:        int32(score.Hits),
+				Checkpoints: int32(score.Checkpoints),
+				Score:       score.Fraction(),
+			})
+			if err != nil {
+				return res, fmt.Errorf("upsert discovery %d: %w", row.ActivityID, err)
+			}
+			found = append(found, discovered{row: row, score: score})
+		}
+	}
+	res.Candidates = len(found)
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score.Fraction() > found[j].score.Fraction()
+	})
+
+	_, daily := stravalimit.Remaining()
+	budget := min(limit, int(daily-discoveryDailyBuffer))
+
+	enqueued := make([]int64, 0, max(budget, 0))
+	for _, f := range found {
+		if len(enqueued) >= budget {
+			break
+		}
+		_, err := m.EnqueueFetchActivity(ctx, FetchActivityArgs{
+			Source:         database.ActivityDetailSourceBackload,
+			ActivityID:     f.row.ActivityID,
+			AthleteID:      f.row.AthleteID,
+			HugelPotential: true,
+			HugelScored:    true,
+		}, PriorityLow)
+		if err != nil {
+			return res, fmt.Errorf("enqueue fetch activity: %w", err)
+		}
+		// A skipped duplicate is already queued, which is just as good.
+		enqueued = append(enqueued, f.row.ActivityID)
+	}
+
+	if len(enqueued) > 0 {
+		if err := m.db.MarkHugelDiscoveriesEnqueued(ctx, enqueued); err != nil {
+			return res, fmt.Errorf("mark enqueued: %w", err)
+		}
+	}
+	res.Enqueued = len(enqueued)
+	res.Waiting = res.Candidates - res.Enqueued
+	return res, nil
+}
+
+// DiscoveredFinisher is a result that was only found by a discovery scan.
+type DiscoveredFinisher struct {
+	Discovery database.HugelDiscovery
+	Result    database.RouteActivityResultsRow
+}
+
+// DiscoveredFinishers returns the discoveries that turned out to be results
+// once their details were fetched. A discovery can finish more than one
+// edition, e.g. the full route and the lite route of the same year.
+func DiscoveredFinishers(ctx context.Context, db database.Store) ([]DiscoveredFinisher, error) {
+	discoveries, err := db.HugelDiscoveries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("discoveries: %w", err)
+	}
+	if len(discoveries) == 0 {
+		return nil, nil
+	}
+
+	byID := make(map[int64]database.HugelDiscovery, len(discoveries))
+	ids := make([]int64, 0, len(discoveries))
+	for _, d := range discoveries {
+		byID[d.ActivityID] = d
+		ids = append(ids, d.ActivityID)
+	}
+
+	results, err := db.RouteActivityResults(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("route results: %w", err)
+	}
+
+	finishers := make([]DiscoveredFinisher, 0, len(results))
+	for _, r := range results {
+		finishers = append(finishers, DiscoveredFinisher{
+			Discovery: byID[r.ActivityID],
+			Result:    r,
+		})
+	}
+	sort.Slice(finishers, func(i, j int) bool {
+		a, b := finishers[i].Result, finishers[j].Result
+		if a.RouteName != b.RouteName {
+			return a.RouteName < b.RouteName
+		}
+		return a.TotalTimeSeconds < b.TotalTimeSeconds
+	})
+	return finishers, nil
+}
@@ -2,11 +2,15 @@ package river
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/Emyrk/strava/database"
 	"github.com/Emyrk/strava/internal/eddington"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/riverqueue/river"
 )
 
@@ -76,32 +80,423 @@ type EddingtonWorker struct {
 	river.WorkerDefaults[EddingtonArgs]
 }
 
+// Work is the full calculation from every ride. It also rebuilds the daily
+// totals the incremental updates work from, and reports any drift of the
+// numbers they kept.
 func (w *EddingtonWorker) Work(ctx context.Context, job *river.Job[EddingtonArgs]) error {
+	logger := jobLogFields(w.mgr.logger, job)
+
 	acts, err := w.mgr.db.EddingtonActivities(ctx, job.Args.AthleteID)
 	if err != nil {
 		return fmt.Errorf("fetching eddington activities: %w", err)
 	}
 
-	edds := eddington.FromActivities(acts)
+	// Without a previous calculation every milestone is a backfill.
+	var previous *database.AthleteEddington
+	prev, err := w.mgr.db.GetAthleteEddington(ctx, job.Args.AthleteID)
+	switch {
+	case err == nil:
+		previous = &prev
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("fetching previous eddington: %w", err)
+	}
+
+	activities := eddington.FromRows(acts)
+	breakdowns := eddington.Breakdowns(activities)
+	lifetime := lifetimeBreakdown(breakdowns)
+
+	if previous != nil && !slices.Equal(previous.MilesHistogram, lifetime.Miles) {
+		logger.Warn().
+			Int64("athlete_id", job.Args.AthleteID).
+			Int32("kept", previous.CurrentEddington).
+			Int32("calculated", lifetime.Miles.Current()).
+			Msg("eddington drifted from its daily updates")
+	}
+
+	err = w.mgr.db.InTx(func(store database.Store) error {
+		err := store.DeleteAthleteDailyTotals(ctx, database.DeleteAthleteDailyTotalsParams{
+			AthleteID: job.Args.AthleteID,
+			AllDays:   true,
+		})
+		if err != nil {
+			return fmt.Errorf("deleting daily totals: %w", err)
+		}
+		_, err = store.InsertAthleteDailyTotals(ctx, database.InsertAthleteDailyTotalsParams{
+			AthleteID: job.Args.AthleteID,
+			AllDays:   true,
+		})
+		if err != nil {
+			return fmt.Errorf("inserting daily totals: %w", err)
+		}
+
+		err = saveEddington(ctx, store, job.Args.AthleteID, breakdowns, int32(len(acts)), time.Now())
+		if err != nil {
+			return err
+		}
+
+		for _, m := range eddington.Metrics {
+			sums, days := eddington.MetricSums(activities, m)
+			err = saveEddingtonMetric(ctx, store, job.Args.AthleteID, m, sums, days)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	events := 0
+	if previous != nil {
+		events, err = w.mgr.recordEddingtonEvents(ctx, job.Args.AthleteID, previous.CurrentEddington, lifetime.Miles.Current(), activities)
+		if err != nil {
+			return err
+		}
+	}
+
+	_ = river.RecordOutput(ctx, map[string]interface{}{
+		"number":     lifetime.Miles.Current(),
+		"km":         lifetime.Kilometers.Current(),
+		"total":      len(acts),
+		"breakdowns": len(breakdowns),
+		"events":     events,
+	})
+	return nil
+}
+
+// lifetimeBreakdown is the lifetime number of all rides, the headline. It is
+// the first breakdown when there are any rides.
+func lifetimeBreakdown(breakdowns []eddington.Breakdown) eddington.Breakdown {
+	if len(breakdowns) > 0 && breakdowns[0].Sport == eddington.SportAll && breakdowns[0].Year == 0 {
+		return breakdowns[0]
+	}
+	return eddington.Breakdown{Sport: eddington.SportAll, Miles: eddington.Sums{}, Kilometers: eddington.Sums{}}
+}
 
-	_, err = w.mgr.db.UpsertAthleteEddington(ctx, database.UpsertAthleteEddingtonParams{
-		AthleteID:        job.Args.AthleteID,
-		MilesHistogram:   edds,
-		CurrentEddington: edds.Current(),
-		LastCalculated:   database.Timestamptz(time.Now()),
-		TotalActivities:  int32(len(acts)),
+func saveEddington(ctx context.Context, store database.Store, athleteID int64, breakdowns []eddington.Breakdown, activities int32, calculated time.Time) error {
+	lifetime := lifetimeBreakdown(breakdowns)
+	_, err := store.UpsertAthleteEddington(ctx, database.UpsertAthleteEddingtonParams{
+		AthleteID:           athleteID,
+		MilesHistogram:      lifetime.Miles,
+		CurrentEddington:    lifetime.Miles.Current(),
+		LastCalculated:      database.Timestamptz(calculated),
+		TotalActivities:     activities,
+		KilometersHistogram: lifetime.Kilometers,
+		CurrentEddingtonKm:  lifetime.Kilometers.Current(),
+		TotalDays:           int32(lifetime.Days),
 	})
 	if err != nil {
 		return fmt.Errorf("upserting athlete eddington: %w", err)
 	}
 
-	_ = river.RecordOutput(ctx, map[string]interface{}{
-		"number": edds.Current(),
-		"total":  len(acts),
+	err = store.DeleteAthleteEddingtonBreakdowns(ctx, athleteID)
+	if err != nil {
+		return fmt.Errorf("deleting breakdowns: %w", err)
+	}
+	for _, b := range breakdowns {
+		err = store.InsertAthleteEddingtonBreakdown(ctx, database.InsertAthleteEddingtonBreakdownParams{
+			AthleteID:           athleteID,
+			Sport:               b.Sport,
+			Year:                int32(b.Year),
+			Days:                int32(b.Days),
+			MilesHistogram:      b.Miles,
+			EddingtonMiles:      b.Miles.Current(),
+			KilometersHistogram: b.Kilometers,
+			EddingtonKilometers: b.Kilometers.Current(),
+		})
+		if err != nil {
+			return fmt.Errorf("inserting %s %d breakdown: %w", b.Sport, b.Year, err)
+		}
+	}
+	return nil
+}
+
+func saveEddingtonMetric(ctx context.Context, store database.Store, athleteID int64, m eddington.Metric, sums eddington.Sums, days int) error {
+	err := store.UpsertAthleteEddingtonMetric(ctx, database.UpsertAthleteEddingtonMetricParams{
+		AthleteID: athleteID,
+		Metric:    m.Name,
+		Days:      int32(days),
+		Histogram: sums,
+		Eddington: sums.Current(),
 	})
+	if err != nil {
+		return fmt.Errorf("upserting %s eddington: %w", m.Name, err)
+	}
 	return nil
 }
 
+// recordEddingtonEvents records each number above previous the athlete reached.
+// The history is replayed from the activities to find the day each was
+// reached. They are loaded when nil.
+func (m *Manager) recordEddingtonEvents(ctx context.Context, athleteID int64, previous, current int32, activities []eddington.Activity) (int, error) {
+	if current <= previous {
+		return 0, nil
+	}
+
+	if activities == nil {
+		acts, err := m.db.EddingtonActivities(ctx, athleteID)
+		if err != nil {
+			return 0, fmt.Errorf("fetching eddington activities: %w", err)
+		}
+		activities = eddington.FromRows(acts)
+	}
+
+	events := 0
+	for _, ms := range eddington.History(eddington.DailyTotals(activities, eddington.SportAll), eddington.Miles) {
+		if ms.Eddington <= previous {
+			continue
+		}
+		n, err := m.db.InsertEddingtonEvent(ctx, database.InsertEddingtonEventParams{
+			AthleteID:  athleteID,
+			Eddington:  ms.Eddington,
+			Previous:   previous,
+			ReachedOn:  database.Timestamptz(ms.Date),
+			ActivityID: ms.ActivityID,
+		})
+		if err != nil {
+			return events, fmt.Errorf("inserting eddington event: %w", err)
+		}
+		if n > 0 {
+			events++
+			m.managerMetrics.eddingtonEvents.Inc()
+			m.logger.Info().
+				Int64("athlete_id", athleteID).
+				Int32("eddington", ms.Eddington).
+				Int32("previous", previous).
+				Int64("activity_id", ms.ActivityID).
+				Msg("eddington number went up")
+		}
+	}
+	return events, nil
+}
+
+func (m *Manager) EnqueueEddingtonDays(ctx context.Context, athleteID int64, days []time.Time, opts ...func(j *river.InsertOpts)) (bool, error) {
+	if len(days) == 0 {
+		return false, nil
+	}
+	iopts := &river.InsertOpts{}
+	for _, opt := range opts {
+		opt(iopts)
+	}
+
+	// The same days in the same order, so duplicates are unique by args.
+	dates := make([]time.Time, 0, len(days))
+	for _, d := range days {
+		d = d.UTC()
+		dates = append(dates, time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC))
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+	dates = slices.CompactFunc(dates, func(a, b time.Time) bool { return a.Equal(b) })
+
+	fi, err := m.cli.Insert(ctx, EddingtonDaysArgs{
+		AthleteID: athleteID,
+		Days:      dates,
+	}, iopts)
+
+	skipped := false
+	if fi != nil {
+		skipped = fi.UniqueSkippedAsDuplicate
+	}
+
+	return !skipped, err
+}
+
+// EddingtonDaysArgs updates the numbers for activities that were added,
+// changed or deleted on the days. Days are the local day the ride started.
+type EddingtonDaysArgs struct {
+	AthleteID int64
+	Days      []time.Time
+}
+
+func (EddingtonDaysArgs) Kind() string { return "eddington_days" }
+func (EddingtonDaysArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       riverDatabaseQueue,
+		MaxAttempts: 3,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Minute * 1,
+		},
+	}
+}
+
+type EddingtonDaysWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[EddingtonDaysArgs]
+}
+
+// Work recounts only the touched days, and moves the histograms from their old
+// totals to the new ones. The database queue runs one job at a time, so the
+// full calculation can not race it.
+func (w *EddingtonDaysWorker) Work(ctx context.Context, job *river.Job[EddingtonDaysArgs]) error {
+	athleteID := job.Args.AthleteID
+
+	prev, err := w.mgr.db.GetAthleteEddington(ctx, athleteID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Nothing to update, the full calculation starts it off.
+		_, err := w.mgr.EnqueueEddington(athleteID)
+		if err != nil {
+			return fmt.Errorf("enqueue eddington: %w", err)
+		}
+		return river.RecordOutput(ctx, "no eddington yet, full calculation queued")
+	}
+	if err != nil {
+		return fmt.Errorf("fetching eddington: %w", err)
+	}
+
+	ready, err := incrementalEddingtonReady(ctx, w.mgr.db, athleteID)
+	if err != nil {
+		return err
+	}
+	if !ready {
+		_, err := w.mgr.EnqueueEddington(athleteID)
+		if err != nil {
+			return fmt.Errorf("enqueue eddington: %w", err)
+		}
+		return river.RecordOutput(ctx, "nothing to update from, full calculation queued")
+	}
+
+	days := make([]pgtype.Date, 0, len(job.Args.Days))
+	for _, d := range job.Args.Days {
+		days = append(days, pgtype.Date{Time: d, Valid: true})
+	}
+
+	var (
+		updated []eddington.Breakdown
+		// Metrics are only updated from a full calculation of them.
+		missingMetrics bool
+	)
+	err = w.mgr.db.InTx(func(store database.Store) error {
+		rows, err := store.GetAthleteEddingtonBreakdowns(ctx, athleteID)
+		if err != nil {
+			return fmt.Errorf("fetching breakdowns: %w", err)
+		}
+
+		before, err := store.AthleteDailyTotals(ctx, database.AthleteDailyTotalsParams{AthleteID: athleteID, Days: days})
+		if err != nil {
+			return fmt.Errorf("fetching daily totals: %w", err)
+		}
+		err = store.DeleteAthleteDailyTotals(ctx, database.DeleteAthleteDailyTotalsParams{AthleteID: athleteID, Days: days})
+		if err != nil {
+			return fmt.Errorf("deleting daily totals: %w", err)
+		}
+		_, err = store.InsertAthleteDailyTotals(ctx, database.InsertAthleteDailyTotalsParams{AthleteID: athleteID, Days: days})
+		if err != nil {
+			return fmt.Errorf("inserting daily totals: %w", err)
+		}
+		after, err := store.AthleteDailyTotals(ctx, database.AthleteDailyTotalsParams{AthleteID: athleteID, Days: days})
+		if err != nil {
+			return fmt.Errorf("fetching daily totals: %w", err)
+		}
+
+		activities := prev.TotalActivities
+		for _, t := range before {
+			activities -= t.Activities
+		}
+		for _, t := range after {
+			activities += t.Activities
+		}
+
+		beforeDays, afterDays := convertDailyTotals(before), convertDailyTotals(after)
+		updated = eddington.Update(convertBreakdownRows(rows), beforeDays, afterDays)
+		// Keep the last full calculation, so the reconciliation still runs.
+		err = saveEddington(ctx, store, athleteID, updated, activities, prev.LastCalculated.Time)
+		if err != nil {
+			return err
+		}
+
+		metrics, err := store.GetAthleteEddingtonMetrics(ctx, athleteID)
+		if err != nil {
+			return fmt.Errorf("fetching eddington metrics: %w", err)
+		}
+		missingMetrics = len(metrics) < len(eddington.Metrics)
+		for _, row := range metrics {
+			m, ok := eddington.MetricByName(row.Metric)
+			if !ok {
+				continue
+			}
+			sums, days := eddington.UpdateMetric(m, row.Histogram, int(row.Days), beforeDays, afterDays)
+			err = saveEddingtonMetric(ctx, store, athleteID, m, sums, days)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if missingMetrics {
+		_, err := w.mgr.EnqueueEddington(athleteID)
+		if err != nil {
+			return fmt.Errorf("enqueue eddington: %w", err)
+		}
+	}
+
+	lifetime := lifetimeBreakdown(updated)
+	events, err := w.mgr.recordEddingtonEvents(ctx, athleteID, prev.CurrentEddington, lifetime.Miles.Current(), nil)
+	if err != nil {
+		return err
+	}
+
+	return river.RecordOutput(ctx, map[string]interface{}{
+		"days":     len(days),
+		"number":   lifetime.Miles.Current(),
+		"previous": prev.CurrentEddington,
+		"events":   events,
+	})
+}
+
+// incrementalEddingtonReady reports if the stored numbers can be moved day by
+// day. Eddingtons calculated before daily totals were kept have none, and
+// updating from an empty day would count the touched days twice.
+func incrementalEddingtonReady(ctx context.Context, store database.Store, athleteID int64) (bool, error) {
+	hasTotals, err := store.AthleteHasDailyTotals(ctx, athleteID)
+	if err != nil {
+		return false, fmt.Errorf("checking daily totals: %w", err)
+	}
+	if !hasTotals {
+		return false, nil
+	}
+	breakdowns, err := store.GetAthleteEddingtonBreakdowns(ctx, athleteID)
+	if err != nil {
+		return false, fmt.Errorf("fetching breakdowns: %w", err)
+	}
+	return len(breakdowns) > 0, nil
+}
+
+func convertBreakdownRows(rows []database.AthleteEddingtonBreakdown) []eddington.Breakdown {
+	out := make([]eddington.Breakdown, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, eddington.Breakdown{
+			Sport:      row.Sport,
+			Year:       int(row.Year),
+			Days:       int(row.Days),
+			Miles:      row.MilesHistogram,
+			Kilometers: row.KilometersHistogram,
+		})
+	}
+	return out
+}
+
+func convertDailyTotals(rows []database.AthleteDailyTotal) []eddington.DayTotal {
+	out := make([]eddington.DayTotal, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, eddington.DayTotal{
+			Date:          row.Day.Time,
+			Sport:         row.Sport,
+			Distance:      row.Distance,
+			Activities:    int(row.Activities),
+			ElevationGain: row.ElevationGain,
+			MovingTime:    row.MovingTime,
+			HugelSegments: int(row.HugelSegments),
+		})
+	}
+	return out
+}
+
 type QueueEddingtonArgs struct{}
 
 func (QueueEddingtonArgs) Kind() string { return "eddington_load" }
@@ -0,0 +1,46 @@
+package river
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/database"
+)
+
+// eddingtonStore has a stored eddington, and the daily totals and breakdowns
+// it was calculated with.
+type eddingtonStore struct {
+	database.Store
+	dailyTotals bool
+	breakdowns  []database.AthleteEddingtonBreakdown
+}
+
+func (s eddingtonStore) AthleteHasDailyTotals(context.Context, int64) (bool, error) {
+	return s.dailyTotals, nil
+}
+
+func (s eddingtonStore) GetAthleteEddingtonBreakdowns(context.Context, int64) ([]database.AthleteEddingtonBreakdown, error) {
+	return s.breakdowns, nil
+}
+
+func TestIncrementalEddingtonReady(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	lifetime := []database.AthleteEddingtonBreakdown{{AthleteID: 1, MilesHistogram: []int32{3, 2, 1}}}
+
+	// Calculated before the daily totals were kept.
+	ready, err := incrementalEddingtonReady(ctx, eddingtonStore{breakdowns: lifetime}, 1)
+	require.NoError(t, err)
+	require.False(t, ready)
+
+	ready, err = incrementalEddingtonReady(ctx, eddingtonStore{dailyTotals: true}, 1)
+	require.NoError(t, err)
+	require.False(t, ready)
+
+	ready, err = incrementalEddingtonReady(ctx, eddingtonStore{dailyTotals: true, breakdowns: lifetime}, 1)
+	require.NoError(t, err)
+	require.True(t, ready)
+}
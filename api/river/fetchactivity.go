@@ -52,12 +52,25 @@ type FetchActivityArgs struct {
 	AthleteID  int64                         `json:"athlete_id"`
 	// HugelPotential is a boolean that helps filter which events to
 	// sync during the hugel event.
-	// TODO: Remove this after november.
 	HugelPotential bool `json:"can_be_hugel"`
-	OnHugelDates   bool `json:"on_hugel_dates"`
+	// HugelScored is true if HugelPotential came from the summary track of
+	// the activity. A scored activity that is not a candidate is skipped
+	// during the event, even on the event dates.
+	HugelScored  bool `json:"hugel_scored,omitempty"`
+	OnHugelDates bool `json:"on_hugel_dates"`
 }
 
 func (FetchActivityArgs) Kind() string { return "fetch_activity" }
+
+// skipDuringEvent is true if the activity is not worth the rate limit while
+// the hugel event is ongoing. Only hugel potential activities are synced,
+// and unscored ones on the event dates, manual syncs always are.
+func (args FetchActivityArgs) skipDuringEvent() bool {
+	if args.Source == database.ActivityDetailSourceManual {
+		return false
+	}
+	return !args.HugelPotential && (args.HugelScored || !args.OnHugelDates)
+}
 func (FetchActivityArgs) InsertOpts() river.InsertOpts {
 	return river.InsertOpts{
 		Queue: riverStravaQueue,
@@ -104,16 +117,10 @@ func (w *FetchActivityWorker) Work(ctx context.Context, job *river.Job[FetchActi
 	}
 
 	// Snooze the job if not relevant during the hugel event.
-	if hugelhelp.HugelOngoing(now) {
-		// Manyal events are ok
-		if args.Source != database.ActivityDetailSourceManual {
-			// Only sync hugel potential activities during the event.
-			if !args.HugelPotential && !args.OnHugelDates {
-				// Wait a day before trying again.
-				_ = river.RecordOutput(ctx, "hugel event is ongoing, and this activity is not relevant, snoozing job")
-				return river.JobSnooze(time.Hour * 24)
-			}
-		}
+	if hugelhelp.HugelOngoing(now) && args.skipDuringEvent() {
+		// Wait a day before trying again.
+		_ = river.RecordOutput(ctx, "hugel event is ongoing, and this activity is not relevant, snoozing job")
+		return river.JobSnooze(time.Hour * 24)
 	}
 
 	err := w.mgr.jobStravaCheck(logger, 1, adjustInt, adjustDaily)
@@ -211,6 +218,7 @@ func (w *FetchActivityWorker) Work(ctx context.Context, job *river.Job[FetchActi
 					ActivityID:     args.ActivityID,
 					AthleteID:      args.AthleteID,
 					HugelPotential: args.HugelPotential,
+					HugelScored:    args.HugelScored,
 					OnHugelDates:   args.OnHugelDates,
 				}, job.Priority, func(opt *river.InsertOpts) {
 					opt.ScheduledAt = time.Now().Add(time.Hour * 2)
@@ -227,6 +235,24 @@ func (w *FetchActivityWorker) Work(ctx context.Context, job *river.Job[FetchActi
 		}
 	}
 
+	// The fetch can change the distance or type of the day's ride.
+	_, err = w.mgr.EnqueueEddingtonDays(ctx, args.AthleteID, []time.Time{activity.StartDateLocal})
+	if err != nil {
+		logger.Error().Err(err).Msg("error enqueuing eddington days")
+	}
+
+	// Strava misses a segment now and then. Event rides with some efforts
+	// get their track checked for the rest.
+	if args.HugelPotential && len(activity.SegmentEfforts) > 0 && len(editionsOn(activity.StartDate)) > 0 {
+		_, err := w.mgr.EnqueueGPSMatch(ctx, GPSMatchArgs{
+			ActivityID: activity.ID,
+			AthleteID:  args.AthleteID,
+		})
+		if err != nil {
+			logger.Error().Err(err).Msg("error enqueuing gps match")
+		}
+	}
+
 	return river.RecordOutput(ctx, map[string]any{
 		"segments": len(activity.SegmentEfforts),
 		"link":     fmt.Sprintf("https://www.strava.com/activities/%d", activity.ID),
@@ -332,6 +358,12 @@ func (w *FetchActivityWorker) insert(ctx context.Context, activity strava.Detail
 				starSegments = append(starSegments, effort.Segment.ID)
 				starStarred = append(starStarred, effort.Segment.Starred)
 				starSegmentsAdded[effort.Segment.ID] = true
+
+				if loc, ok := segmentLocation(effort.Segment); ok {
+					if err := store.UpsertSegmentLocation(ctx, loc); err != nil {
+						return fmt.Errorf("upsert segment location id=%d: %w", effort.Segment.ID, err)
+					}
+				}
 			}
 			_, err := store.UpsertSegmentEffort(ctx, database.UpsertSegmentEffortParams{
 				ID:             effort.ID,
@@ -0,0 +1,53 @@
+package river
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/database"
+)
+
+func TestSkipDuringEvent(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		args FetchActivityArgs
+		skip bool
+	}{
+		{
+			name: "Webhook",
+			args: FetchActivityArgs{Source: database.ActivityDetailSourceWebhook, HugelPotential: true, OnHugelDates: true},
+		},
+		{
+			name: "ScoredCandidate",
+			args: FetchActivityArgs{Source: database.ActivityDetailSourceBackload, HugelPotential: true, HugelScored: true, OnHugelDates: true},
+		},
+		{
+			// The track missed the climbs, the dates do not matter.
+			name: "ScoredNotCandidate",
+			args: FetchActivityArgs{Source: database.ActivityDetailSourceBackload, HugelScored: true, OnHugelDates: true},
+			skip: true,
+		},
+		{
+			// No track to score, any ride on the dates might be a result.
+			name: "UnscoredOnDates",
+			args: FetchActivityArgs{Source: database.ActivityDetailSourceBackload, OnHugelDates: true},
+		},
+		{
+			name: "UnscoredOffDates",
+			args: FetchActivityArgs{Source: database.ActivityDetailSourceBackload},
+			skip: true,
+		},
+		{
+			name: "Manual",
+			args: FetchActivityArgs{Source: database.ActivityDetailSourceManual, HugelScored: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.skip, tc.args.skipDuringEvent())
+		})
+	}
+}
@@ -206,13 +206,15 @@ func (w *ForwardLoadWorker) Work(ctx context.Context, job *river.Job[ForwardLoad
 
 			// Backload bike rides for more deets
 			if isBikeRide(act.Type) || isBikeRide(act.SportType) {
+				potential, scored := w.mgr.hugelPotential(ctx, act)
 				_, err = w.mgr.EnqueueFetchActivity(ctx, FetchActivityArgs{
 					Source:         database.ActivityDetailSourceBackload,
 					ActivityID:     act.ID,
 					AthleteID:      athleteLoad.AthleteID,
-					HugelPotential: canBeHugel(act) || canBeHugelLite(act),
+					HugelPotential: potential,
+					HugelScored:    scored,
 					OnHugelDates:   onHugelDate(act),
-				}, activityJobPriority(act), func(j *river.InsertOpts) {
+				}, activityJobPriority(act, potential), func(j *river.InsertOpts) {
 					// Delay by 5minutes.
 					// We do this because sometimes strava loads 0 segments for a ride, and it takes some time
 					// for segments to be populated. The ride might have just been uploaded.
@@ -245,6 +247,16 @@ func (w *ForwardLoadWorker) Work(ctx context.Context, job *river.Job[ForwardLoad
 		return fmt.Errorf("in tx: %w", err)
 	}
 
+	// A page can re-type an activity, so every day it touches is recounted.
+	days := make([]time.Time, 0, len(activities))
+	for _, act := range activities {
+		days = append(days, act.StartDateLocal)
+	}
+	_, err = w.mgr.EnqueueEddingtonDays(ctx, athleteLoad.AthleteID, days)
+	if err != nil {
+		logger.Error().Err(err).Msg("error enqueuing eddington days")
+	}
+
 	if len(activities) > 0 {
 		// Keep going until we have no more activities to load.
 		_ = river.RecordOutput(ctx, "athlete not finished, will continue!")
@@ -365,7 +377,7 @@ func isBikeRide(act string) bool {
 	return false
 }
 
-func activityJobPriority(summary strava.ActivitySummary) int {
+func activityJobPriority(summary strava.ActivitySummary, hugelPotential bool) int {
 	priority := PriorityLow
 	if time.Since(summary.StartDate) < (time.Hour * 24 * 7) {
 		// Add priority for recent activities
@@ -379,5 +391,10 @@ func activityJobPriority(summary strava.ActivitySummary) int {
 		priority = PriorityHigh
 	}
 
+	if hugelPotential && time.Since(summary.StartDate) < (time.Hour*24*14) {
+		// Recent rides that pass the hugel climbs might be results.
+		priority = PriorityHigh
+	}
+
 	return priority
 }
@@ -0,0 +1,235 @@
+package river
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/gpsmatch"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/strava"
+)
+
+func (m *Manager) EnqueueGPSMatch(ctx context.Context, args GPSMatchArgs, opts ...func(j *river.InsertOpts)) (bool, error) {
+	iopts := &river.InsertOpts{
+		Tags: []string{fmt.Sprintf("%d", args.AthleteID), fmt.Sprintf("%d", args.ActivityID)},
+	}
+	for _, opt := range opts {
+		opt(iopts)
+	}
+
+	fi, err := m.cli.Insert(ctx, args, iopts)
+
+	skipped := false
+	if fi != nil {
+		skipped = fi.UniqueSkippedAsDuplicate
+	}
+
+	return !skipped, err
+}
+
+// GPSMatchArgs looks for the route segments Strava did not match in the GPS
+// track of an activity. If the matches finish a route, the activity gets an
+// unofficial result.
+type GPSMatchArgs struct {
+	ActivityID int64 `json:"activity_id"`
+	AthleteID  int64 `json:"athlete_id"`
+}
+
+func (GPSMatchArgs) Kind() string { return "gps_match" }
+func (GPSMatchArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       riverStravaQueue,
+		Priority:    PriorityDefault,
+		MaxAttempts: 5,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Hour * 72,
+		},
+	}
+}
+
+type GPSMatchWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[GPSMatchArgs]
+}
+
+func (*GPSMatchWorker) Middleware(job *rivertype.JobRow) []rivertype.WorkerMiddleware {
+	return []rivertype.WorkerMiddleware{}
+}
+
+func (w *GPSMatchWorker) Work(ctx context.Context, job *river.Job[GPSMatchArgs]) error {
+	logger := jobLogFields(w.mgr.logger, job)
+	args := job.Args
+
+	logger = logger.With().
+		Int64("activity_id", args.ActivityID).
+		Int64("athlete_id", args.AthleteID).
+		Logger()
+
+	summary, err := w.mgr.db.GetActivitySummary(ctx, args.ActivityID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return river.RecordOutput(ctx, "activity not found, job abandoned")
+	}
+	if err != nil {
+		return fmt.Errorf("get activity summary: %w", err)
+	}
+
+	editions := editionsOn(summary.StartDate.Time)
+	if len(editions) == 0 {
+		return river.RecordOutput(ctx, "activity is not on the dates of any edition")
+	}
+
+	efforts, err := w.mgr.db.GetActivitySegmentEfforts(ctx, args.ActivityID)
+	if err != nil {
+		return fmt.Errorf("get segment efforts: %w", err)
+	}
+	official := make(map[int64]float64, len(efforts))
+	for _, e := range efforts {
+		if best, ok := official[e.SegmentID]; !ok || e.ElapsedTime < best {
+			official[e.SegmentID] = e.ElapsedTime
+		}
+	}
+
+	// Only routes the activity partly finished are worth a streams call.
+	routes := make(map[string][]int64)
+	for _, e := range editions {
+		route, err := w.mgr.db.GetCompetitiveRouteByName(ctx, e.RouteName)
+		if err != nil {
+			return fmt.Errorf("get route %q: %w", e.RouteName, err)
+		}
+		missing := 0
+		for _, id := range route.Segments {
+			if _, ok := official[id]; !ok {
+				missing++
+			}
+		}
+		// Either an official result, or the route was not ridden at all.
+		if missing > 0 && missing < len(route.Segments) {
+			routes[e.RouteName] = route.Segments
+		}
+	}
+	if len(routes) == 0 {
+		return river.RecordOutput(ctx, "no partly finished routes to match")
+	}
+
+	err = w.mgr.jobStravaCheck(logger, 1, 5, 50)
+	if err != nil {
+		return w.mgr.StravaSnooze(ctx)
+	}
+
+	athlete, err := w.mgr.db.GetAthleteLogin(ctx, args.AthleteID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return river.RecordOutput(ctx, "athlete not found, job abandoned")
+	}
+	if err != nil {
+		return err
+	}
+
+	cli := strava.NewOAuthClient(w.mgr.oauthCfg.Client(ctx, athlete.OAuthToken()))
+	streams, err := cli.GetActivityStreams(ctx, args.ActivityID, "latlng", "time")
+	if err != nil {
+		se := strava.IsAPIError(err)
+		if se != nil && se.Response.StatusCode == http.StatusTooManyRequests {
+			return w.mgr.StravaSnooze(ctx)
+		}
+		if se != nil && se.Response.StatusCode == http.StatusNotFound {
+			return river.RecordOutput(ctx, fmt.Sprintf("activity streams not found: https://www.strava.com/activities/%d", args.ActivityID))
+		}
+		return err
+	}
+
+	track, err := gpsmatch.TrackFromStreams(streams)
+	if err != nil {
+		return river.RecordOutput(ctx, err.Error())
+	}
+
+	matcher := gpsmatch.New()
+	matched := make(map[int64]float64)
+	output := make(map[string]any)
+	for name, segments := range routes {
+		var missing []int64
+		for _, id := range segments {
+			if _, ok := official[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+
+		segs, err := gpsmatch.LoadSegments(ctx, w.mgr.db, missing)
+		if err != nil {
+			return err
+		}
+		for _, seg := range segs {
+			if _, ok := matched[seg.ID]; ok {
+				continue
+			}
+			match := matcher.Match(track, seg)
+			if !match.Matched() {
+				continue
+			}
+			err := w.mgr.db.UpsertGPSSegmentEffort(ctx, database.UpsertGPSSegmentEffortParams{
+				ActivityID:  args.ActivityID,
+				SegmentID:   seg.ID,
+				AthleteID:   args.AthleteID,
+				StartDate:   database.Timestamptz(summary.StartDate.Time.Add(time.Duration(track.Times[match.StartIndex]) * time.Second)),
+				ElapsedTime: match.ElapsedTime,
+				Distance:    match.Distance,
+				StartIndex:  int32(match.StartIndex),
+				EndIndex:    int32(match.EndIndex),
+				Coverage:    match.Coverage,
+			})
+			if err != nil {
+				return fmt.Errorf("upsert gps effort %d: %w", seg.ID, err)
+			}
+			matched[seg.ID] = match.ElapsedTime
+		}
+
+		result, ok := gpsmatch.Combine(segments, official, matched)
+		if !ok {
+			output[name] = fmt.Sprintf("%d of %d missing segments matched, not finished", countMatched(missing, matched), len(missing))
+			continue
+		}
+		err = w.mgr.db.UpsertUnofficialRouteResult(ctx, database.UpsertUnofficialRouteResultParams{
+			RouteName:        name,
+			ActivityID:       args.ActivityID,
+			AthleteID:        args.AthleteID,
+			TotalTimeSeconds: result.TotalTime,
+			GpsSegmentIds:    result.GPSSegments,
+		})
+		if err != nil {
+			return fmt.Errorf("upsert unofficial result: %w", err)
+		}
+		output[name] = fmt.Sprintf("unofficial result with %d gps matched segments", len(result.GPSSegments))
+	}
+
+	output["link"] = fmt.Sprintf("https://www.strava.com/activities/%d", args.ActivityID)
+	return river.RecordOutput(ctx, output)
+}
+
+// editionsOn returns the editions scored on the dates that include t.
+func editionsOn(t time.Time) []hugeldate.Edition {
+	var found []hugeldate.Edition
+	for _, e := range hugeldate.Editions {
+		if !t.Before(e.Dates.Start) && !t.After(e.Dates.End) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func countMatched(ids []int64, matched map[int64]float64) int {
+	n := 0
+	for _, id := range ids {
+		if _, ok := matched[id]; ok {
+			n++
+		}
+	}
+	return n
+}
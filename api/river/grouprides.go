@@ -0,0 +1,142 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/grouprides"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+func (m *Manager) EnqueueGroupRides(ctx context.Context, args GroupRidesArgs, opts ...func(j *river.InsertOpts)) (bool, error) {
+	iopts := &river.InsertOpts{}
+	for _, opt := range opts {
+		opt(iopts)
+	}
+
+	fi, err := m.cli.Insert(ctx, args, iopts)
+
+	skipped := false
+	if fi != nil {
+		skipped = fi.UniqueSkippedAsDuplicate
+	}
+
+	return !skipped, err
+}
+
+// GroupRidesArgs detects the results of an edition that were ridden
+// together, picking up from the stored groups.
+type GroupRidesArgs struct {
+	// RouteName is the edition to detect, every edition if empty.
+	RouteName string `json:"route_name,omitempty"`
+	// Full ignores the stored groups and compares every result again.
+	Full bool `json:"full,omitempty"`
+}
+
+func (GroupRidesArgs) Kind() string { return "group_rides" }
+func (GroupRidesArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:    riverDatabaseQueue,
+		Priority: PriorityLow,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Minute * 15,
+		},
+	}
+}
+
+type GroupRidesWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[GroupRidesArgs]
+}
+
+func (*GroupRidesWorker) Middleware(job *rivertype.JobRow) []rivertype.WorkerMiddleware {
+	return []rivertype.WorkerMiddleware{}
+}
+
+func (w *GroupRidesWorker) Work(ctx context.Context, job *river.Job[GroupRidesArgs]) error {
+	editions := hugeldate.Editions
+	if job.Args.RouteName != "" {
+		e, ok := hugeldate.EditionByRoute(job.Args.RouteName)
+		if !ok {
+			return river.RecordOutput(ctx, fmt.Sprintf("no edition for route %q", job.Args.RouteName))
+		}
+		editions = []hugeldate.Edition{e}
+	}
+
+	output := make(map[string]any, len(editions))
+	for _, e := range editions {
+		state, err := w.detect(ctx, e, job.Args.Full)
+		if err != nil {
+			return fmt.Errorf("group rides %q: %w", e.RouteName, err)
+		}
+		output[e.RouteName] = fmt.Sprintf("%d groups of %d results", len(state.Groups), len(state.Seen))
+	}
+	return river.RecordOutput(ctx, output)
+}
+
+func (w *GroupRidesWorker) detect(ctx context.Context, edition hugeldate.Edition, full bool) (grouprides.State, error) {
+	var previous grouprides.State
+	if !full {
+		row, err := w.mgr.db.EventGroupRides(ctx, edition.RouteName)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+		case err != nil:
+			return previous, fmt.Errorf("stored groups: %w", err)
+		default:
+			if err := json.Unmarshal(row.Groups, &previous.Groups); err != nil {
+				return previous, fmt.Errorf("parse stored groups: %w", err)
+			}
+			previous.Seen = row.Seen
+		}
+	}
+
+	board, err := w.mgr.db.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+		RouteYear: edition.Year,
+		Lite:      edition.Lite,
+		HugelLeaderboardParams: database.HugelLeaderboardParams{
+			AthleteID: -1,
+			After:     database.Timestamp(edition.Dates.Start),
+			Before:    database.Timestamp(edition.Dates.End),
+		},
+	})
+	if err != nil {
+		return previous, fmt.Errorf("leaderboard: %w", err)
+	}
+
+	activityIDs := make([]int64, 0, len(board))
+	for _, row := range board {
+		activityIDs = append(activityIDs, row.ActivityID)
+	}
+	counts, err := w.mgr.db.ActivityAthleteCounts(ctx, activityIDs)
+	if err != nil {
+		return previous, fmt.Errorf("athlete counts: %w", err)
+	}
+	athleteCounts := make(map[int64]int32, len(counts))
+	for _, c := range counts {
+		athleteCounts[c.ID] = c.AthleteCount
+	}
+
+	state := grouprides.Detect(grouprides.FromRows(board, athleteCounts), previous)
+	groups, err := json.Marshal(state.Groups)
+	if err != nil {
+		return state, fmt.Errorf("marshal groups: %w", err)
+	}
+	err = w.mgr.db.UpsertEventGroupRides(ctx, database.UpsertEventGroupRidesParams{
+		RouteName: edition.RouteName,
+		Groups:    groups,
+		Seen:      state.Seen,
+	})
+	if err != nil {
+		return state, fmt.Errorf("store groups: %w", err)
+	}
+	return state, nil
+}
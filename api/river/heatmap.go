@@ -0,0 +1,93 @@
+package river
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/Emyrk/strava/internal/heatmap"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+func (m *Manager) EnqueueHeatmap(ctx context.Context, args HeatmapArgs, opts ...func(j *river.InsertOpts)) (bool, error) {
+	iopts := &river.InsertOpts{}
+	for _, opt := range opts {
+		opt(iopts)
+	}
+
+	fi, err := m.cli.Insert(ctx, args, iopts)
+
+	skipped := false
+	if fi != nil {
+		skipped = fi.UniqueSkippedAsDuplicate
+	}
+
+	return !skipped, err
+}
+
+// HeatmapArgs rasterizes the result tracks of an edition into the stored
+// heatmap served to spectators.
+type HeatmapArgs struct {
+	// RouteName is the edition to build, every edition if empty.
+	RouteName string `json:"route_name,omitempty"`
+	// AthleteID, OptOut and ChangedAt are the opt out change that asked for
+	// the rebuild. Every change is unique, so one is never skipped as a
+	// duplicate of an earlier change that was already built.
+	AthleteID int64     `json:"athlete_id,omitempty"`
+	OptOut    bool      `json:"opt_out,omitempty"`
+	ChangedAt time.Time `json:"changed_at,omitzero"`
+}
+
+func (HeatmapArgs) Kind() string { return "heatmap" }
+func (HeatmapArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:    riverDatabaseQueue,
+		Priority: PriorityLow,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Minute * 15,
+		},
+	}
+}
+
+type HeatmapWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[HeatmapArgs]
+}
+
+func (*HeatmapWorker) Middleware(job *rivertype.JobRow) []rivertype.WorkerMiddleware {
+	return []rivertype.WorkerMiddleware{}
+}
+
+func (w *HeatmapWorker) Work(ctx context.Context, job *river.Job[HeatmapArgs]) error {
+	editions := hugeldate.Editions
+	if job.Args.RouteName != "" {
+		e, ok := hugeldate.EditionByRoute(job.Args.RouteName)
+		if !ok {
+			return river.RecordOutput(ctx, fmt.Sprintf("no edition for route %q", job.Args.RouteName))
+		}
+		editions = []hugeldate.Edition{e}
+	}
+
+	output := make(map[string]any, len(editions))
+	for _, e := range editions {
+		grid, skipped, err := heatmap.Edition(ctx, w.mgr.db, e, heatmap.DefaultResolution)
+		if err != nil {
+			return fmt.Errorf("heatmap %q: %w", e.RouteName, err)
+		}
+		if len(skipped) > 0 {
+			w.mgr.logger.Warn().
+				Str("route", e.RouteName).
+				Ints64("activity_ids", skipped).
+				Msg("heatmap skipped activities with a broken polyline")
+		}
+		if err := w.mgr.db.UpsertEventHeatmap(ctx, grid.Params(e.RouteName)); err != nil {
+			return fmt.Errorf("store heatmap %q: %w", e.RouteName, err)
+		}
+		output[e.RouteName] = fmt.Sprintf("%d activities, %d skipped, %dx%d", grid.Tracks, len(skipped), grid.Width, grid.Height)
+	}
+	return river.RecordOutput(ctx, output)
+}
@@ -8,6 +8,7 @@ import (
 type managerMetrics struct {
 	rideActivitySummaries prometheus.Gauge
 	rideActivityDetails   prometheus.Gauge
+	eddingtonEvents       prometheus.Counter
 }
 
 func (m *Manager) initMetrics(registry *prometheus.Registry) {
@@ -24,4 +25,10 @@ func (m *Manager) initMetrics(registry *prometheus.Registry) {
 		Name:      "activity_detail_total",
 		Help:      "The total number of ride activities synced",
 	})
+	m.eddingtonEvents = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: "strava",
+		Subsystem: "manager",
+		Name:      "eddington_events_total",
+		Help:      "The number of times an athlete's eddington number went up",
+	})
 }
@@ -99,6 +99,9 @@ func (w *RefreshViewsWorker) Work(ctx context.Context, job *river.Job[RefreshVie
 
 	wg.Wait()
 
+	// The refreshed boards may have new results to group.
+	_, groupErr := w.mgr.EnqueueGroupRides(ctx, GroupRidesArgs{})
+
 	logger.Info().
 		Bool("latest", latest).
 		AnErr("super_err", superErr).
@@ -109,6 +112,7 @@ func (w *RefreshViewsWorker) Work(ctx context.Context, job *river.Job[RefreshVie
 		AnErr("hugel2024_err", hugel2024Err).
 		AnErr("hugel2025_lite_err", hugelLiteErr).
 		AnErr("hugel2025_err", hugelErr).
+		AnErr("group_rides_err", groupErr).
 		Str("super_duration", fmt.Sprintf("%.3fs", superDone.Seconds())).
 		Str("hugel_duration", fmt.Sprintf("%.3fs", hugelDone.Seconds())).
 		Str("hugel2024_duration", fmt.Sprintf("%.3fs", hugel2024Done.Seconds())).
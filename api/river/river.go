@@ -62,6 +62,7 @@ type Manager struct {
 
 	rateLimitLogger *debounce.Debouncer
 	appCtx          context.Context
+	scorers         scorerCache
 
 	managerMetrics
 }
@@ -96,6 +97,11 @@ func New(ctx context.Context, opts Options) (*Manager, error) {
 		return nil, fmt.Errorf("parse cron schedule: %w", err)
 	}
 
+	nightly, err := cron.ParseStandard("CRON_TZ=UTC 15 0 * * *")
+	if err != nil {
+		return nil, fmt.Errorf("parse cron schedule: %w", err)
+	}
+
 	periodicJobs := []*river.PeriodicJob{
 		river.NewPeriodicJob(
 			// Always resume after some amount of time to prevent the queue from sleeping
@@ -152,6 +158,45 @@ func New(ctx context.Context, opts Options) (*Manager, error) {
 			},
 			&river.PeriodicJobOpts{RunOnStart: true},
 		),
+		river.NewPeriodicJob(
+			// Just after the strava daily limit resets, discoveries only get
+			// what is left over after the buffer for the day's new rides.
+			nightly,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return HugelDiscoveryArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: false, ID: "hugel_discovery"},
+		),
+		river.NewPeriodicJob(
+			sixly,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return HeatmapArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true, ID: "heatmaps"},
+		),
+		river.NewPeriodicJob(
+			hourly,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return SegmentLocationsArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: false, ID: "segment_locations"},
+		),
+		river.NewPeriodicJob(
+			nightly,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return QueueYearReviewsArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: false, ID: "year_reviews"},
+		),
+		river.NewPeriodicJob(
+			// New results are grouped as the views refresh, this catches
+			// anything the incremental runs got wrong.
+			nightly,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return GroupRidesArgs{Full: true}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: false, ID: "group_rides"},
+		),
 	}
 
 	riverClient, err := river.NewClient(riverpgxv5.New(pool), (&river.Config{
@@ -296,12 +341,36 @@ func (m *Manager) initWorkers(workers *river.Workers) {
 	river.AddWorker[EddingtonArgs](workers, &EddingtonWorker{
 		mgr: m,
 	})
+	river.AddWorker[EddingtonDaysArgs](workers, &EddingtonDaysWorker{
+		mgr: m,
+	})
+	river.AddWorker[YearReviewArgs](workers, &YearReviewWorker{
+		mgr: m,
+	})
+	river.AddWorker[QueueYearReviewsArgs](workers, &QueueYearReviewsWorker{
+		mgr: m,
+	})
 	river.AddWorker[QueueEddingtonArgs](workers, &QueueEddingtonWorker{
 		mgr: m,
 	})
 	river.AddWorker[GarbageCollectArgs](workers, &GarbageCollectWorker{
 		mgr: m,
 	})
+	river.AddWorker[HugelDiscoveryArgs](workers, &HugelDiscoveryWorker{
+		mgr: m,
+	})
+	river.AddWorker[GPSMatchArgs](workers, &GPSMatchWorker{
+		mgr: m,
+	})
+	river.AddWorker[HeatmapArgs](workers, &HeatmapWorker{
+		mgr: m,
+	})
+	river.AddWorker[GroupRidesArgs](workers, &GroupRidesWorker{
+		mgr: m,
+	})
+	river.AddWorker[SegmentLocationsArgs](workers, &SegmentLocationsWorker{
+		mgr: m,
+	})
 }
 
 func (m *Manager) StravaSnooze(ctx context.Context) error {
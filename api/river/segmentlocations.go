@@ -0,0 +1,191 @@
+package river
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/strava"
+	"github.com/Emyrk/strava/strava/stravalimit"
+)
+
+const (
+	// segmentLocationsLimit is the most segments a run fetches from Strava.
+	segmentLocationsLimit = 50
+	// segmentLocationsDailyBuffer leaves the day's calls to new activities,
+	// the index only gets what is left over.
+	segmentLocationsDailyBuffer = 1500
+)
+
+func (m *Manager) EnqueueSegmentLocations(ctx context.Context, opts ...func(j *river.InsertOpts)) (bool, error) {
+	iopts := &river.InsertOpts{}
+	for _, opt := range opts {
+		opt(iopts)
+	}
+
+	fi, err := m.cli.Insert(ctx, SegmentLocationsArgs{}, iopts)
+
+	skipped := false
+	if fi != nil {
+		skipped = fi.UniqueSkippedAsDuplicate
+	}
+
+	return !skipped, err
+}
+
+// SegmentLocationsArgs fills in the location index for segments that have
+// efforts from before the index existed. New efforts index their segment when
+// the activity is fetched.
+type SegmentLocationsArgs struct {
+}
+
+func (SegmentLocationsArgs) Kind() string { return "segment_locations" }
+func (SegmentLocationsArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       riverBackloadQueue,
+		Priority:    PriorityLow,
+		MaxAttempts: 3,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Minute * 45,
+		},
+	}
+}
+
+type SegmentLocationsWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[SegmentLocationsArgs]
+}
+
+func (*SegmentLocationsWorker) Middleware(job *rivertype.JobRow) []rivertype.WorkerMiddleware {
+	return []rivertype.WorkerMiddleware{}
+}
+
+func (w *SegmentLocationsWorker) Work(ctx context.Context, job *river.Job[SegmentLocationsArgs]) error {
+	logger := jobLogFields(w.mgr.logger, job)
+
+	copied, err := w.mgr.db.FillSegmentLocations(ctx)
+	if err != nil {
+		return fmt.Errorf("fill from segments: %w", err)
+	}
+
+	_, daily := stravalimit.Remaining()
+	budget := min(segmentLocationsLimit, int(daily-segmentLocationsDailyBuffer))
+	if budget <= 0 {
+		return river.RecordOutput(ctx, fmt.Sprintf("%d copied from segments, no strava budget left today", copied))
+	}
+
+	missing, err := w.mgr.db.MissingSegmentLocations(ctx, int32(budget))
+	if err != nil {
+		return fmt.Errorf("missing segment locations: %w", err)
+	}
+	if len(missing) == 0 {
+		return river.RecordOutput(ctx, fmt.Sprintf("%d copied from segments, none missing", copied))
+	}
+
+	if err := w.mgr.jobStravaCheck(logger, int64(len(missing)), 0, 0); err != nil {
+		return w.mgr.StravaSnooze(ctx)
+	}
+
+	// Same login the route segments are loaded with, any athlete can see a
+	// public segment.
+	ath, err := w.mgr.db.GetAthleteLogin(ctx, 2661162)
+	if err != nil {
+		return fmt.Errorf("segment loader login: %w", err)
+	}
+
+	var (
+		cli     = strava.NewOAuthClient(w.mgr.oauthCfg.Client(ctx, ath.OAuthToken()))
+		loaded  int
+		private int
+	)
+	for _, segmentID := range missing {
+		segment, err := cli.GetSegment(ctx, segmentID)
+		if err != nil {
+			se := strava.IsAPIError(err)
+			if se != nil && se.Response.StatusCode == http.StatusTooManyRequests {
+				return w.mgr.StravaSnooze(ctx)
+			}
+			if se == nil || (se.Response.StatusCode != http.StatusNotFound && se.Response.StatusCode != http.StatusForbidden) {
+				return fmt.Errorf("get segment %d: %w", segmentID, err)
+			}
+			// Private or deleted. Remember it as private so it is not
+			// asked for again, it never shows up in a search.
+			err = w.mgr.db.UpsertSegmentLocation(ctx, database.UpsertSegmentLocationParams{
+				ID:      segmentID,
+				Private: true,
+			})
+			if err != nil {
+				return fmt.Errorf("upsert unreachable segment %d: %w", segmentID, err)
+			}
+			private++
+			continue
+		}
+
+		loc, ok := segmentLocation(strava.SegmentSummary{
+			ID:            segment.ID,
+			Name:          segment.Name,
+			ActivityType:  segment.ActivityType,
+			Distance:      segment.Distance,
+			AverageGrade:  segment.AverageGrade,
+			MaximumGrade:  segment.MaximumGrade,
+			ElevationHigh: segment.ElevationHigh,
+			ElevationLow:  segment.ElevationLow,
+			StartLatlng:   segment.StartLatlng,
+			EndLatlng:     segment.EndLatlng,
+			ClimbCategory: int(segment.ClimbCategory),
+			City:          segment.City,
+			State:         segment.State,
+			Country:       segment.Country,
+			Private:       segment.Private,
+			Hazardous:     segment.Hazardous,
+		})
+		if !ok {
+			loc = database.UpsertSegmentLocationParams{ID: segmentID, Name: segment.Name, Private: true}
+			private++
+		} else {
+			loaded++
+		}
+		if err := w.mgr.db.UpsertSegmentLocation(ctx, loc); err != nil {
+			return fmt.Errorf("upsert segment location %d: %w", segmentID, err)
+		}
+	}
+
+	return river.RecordOutput(ctx, map[string]any{
+		"copied":      copied,
+		"loaded":      loaded,
+		"unreachable": private,
+	})
+}
+
+// segmentLocation is the index row of a segment, if it has a location.
+func segmentLocation(s strava.SegmentSummary) (database.UpsertSegmentLocationParams, bool) {
+	if len(s.StartLatlng) != 2 || len(s.EndLatlng) != 2 {
+		return database.UpsertSegmentLocationParams{}, false
+	}
+	return database.UpsertSegmentLocationParams{
+		ID:            s.ID,
+		Name:          s.Name,
+		ActivityType:  s.ActivityType,
+		Distance:      s.Distance,
+		AverageGrade:  s.AverageGrade,
+		MaximumGrade:  s.MaximumGrade,
+		ElevationHigh: s.ElevationHigh,
+		ElevationLow:  s.ElevationLow,
+		StartLat:      s.StartLatlng[0],
+		StartLng:      s.StartLatlng[1],
+		EndLat:        s.EndLatlng[0],
+		EndLng:        s.EndLatlng[1],
+		ClimbCategory: int32(s.ClimbCategory),
+		City:          s.City,
+		State:         s.State,
+		Country:       s.Country,
+		Private:       s.Private,
+		Hazardous:     s.Hazardous,
+	}, true
+}
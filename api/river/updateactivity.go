@@ -97,8 +97,10 @@ func (w *UpdateActivityWorker) Update(ctx context.Context, job *river.Job[Update
 	args := job.Args
 
 	// This updates an activity.
+	var summary database.ActivitySummary
 	err := w.mgr.db.InTx(func(store database.Store) error {
-		_, err := store.GetActivitySummary(ctx, args.ObjectID)
+		var err error
+		summary, err = store.GetActivitySummary(ctx, args.ObjectID)
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn().
 				Str("activity_id", fmt.Sprintf("%d", args.ObjectID)).
@@ -144,13 +146,21 @@ func (w *UpdateActivityWorker) Update(ctx context.Context, job *river.Job[Update
 		return fmt.Errorf("update activity: %w", err)
 	}
 
+	// Only rides count towards the eddington number.
+	if _, ok := args.Updates["type"]; ok && summary.ID != 0 {
+		_, err = w.mgr.EnqueueEddingtonDays(ctx, summary.AthleteID, []time.Time{summary.StartDateLocal.Time})
+		if err != nil {
+			return fmt.Errorf("enqueue eddington days: %w", err)
+		}
+	}
+
 	return nil
 }
 
 func (w *UpdateActivityWorker) Delete(ctx context.Context, job *river.Job[UpdateActivityArgs]) error {
 	args := job.Args
 
-	_, err := w.mgr.db.DeleteActivity(ctx, args.ObjectID)
+	deleted, err := w.mgr.db.DeleteActivity(ctx, args.ObjectID)
 	if errors.Is(err, sql.ErrNoRows) {
 		_ = river.RecordOutput(ctx, "activity not found, nothing to delete")
 		return nil
@@ -158,6 +168,11 @@ func (w *UpdateActivityWorker) Delete(ctx context.Context, job *river.Job[Update
 	if err != nil {
 		return err
 	}
+
+	_, err = w.mgr.EnqueueEddingtonDays(ctx, deleted.AthleteID, []time.Time{deleted.StartDateLocal.Time})
+	if err != nil {
+		return fmt.Errorf("enqueue eddington days: %w", err)
+	}
 	return nil
 }
 
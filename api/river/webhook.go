@@ -75,7 +75,7 @@ func (m *Manager) newActivity(ctx context.Context, event webhooks.WebhookEvent)
 		// Set a high priority for webhooked events.
 		priority := PriorityHigh
 		// Hugel potential is always there for new events. This is kinda unfortunate, but
-		// the webhook gives us no intel into the event.
+		// the webhook gives us no intel into the event, there is no track to score.
 		_, qErr = m.EnqueueFetchActivity(ctx, FetchActivityArgs{
 			Source:         database.ActivityDetailSourceWebhook,
 			ActivityID:     event.ObjectID,
@@ -0,0 +1,310 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/api/superlative"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/eddington"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/internal/yearreview"
+)
+
+const (
+	// yearReviewSegments is how many of the most ridden segments are kept.
+	yearReviewSegments = 10
+	// yearReviewBoardTTL is how long an edition's leaderboard is reused
+	// across reports. December queues every athlete at once.
+	yearReviewBoardTTL = time.Hour
+)
+
+func (m *Manager) EnqueueYearReview(ctx context.Context, args YearReviewArgs, priority int, opts ...func(j *river.InsertOpts)) (bool, error) {
+	iopts := &river.InsertOpts{
+		Priority: priority,
+	}
+	for _, opt := range opts {
+		opt(iopts)
+	}
+
+	fi, err := m.cli.Insert(ctx, args, iopts)
+
+	skipped := false
+	if fi != nil {
+		skipped = fi.UniqueSkippedAsDuplicate
+	}
+
+	return !skipped, err
+}
+
+// YearReviewArgs builds and stores an athlete's year in review report.
+type YearReviewArgs struct {
+	AthleteID int64
+	Year      int
+}
+
+func (YearReviewArgs) Kind() string { return "year_review" }
+func (YearReviewArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       riverDatabaseQueue,
+		MaxAttempts: 3,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Minute * 10,
+		},
+	}
+}
+
+type YearReviewWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[YearReviewArgs]
+
+	boardsMu sync.Mutex
+	boards   map[string]yearReviewBoard
+}
+
+type yearReviewBoard struct {
+	rows         []database.HugelLeaderboardRow
+	superlatives superlative.List
+	loaded       time.Time
+}
+
+func (w *YearReviewWorker) Work(ctx context.Context, job *river.Job[YearReviewArgs]) error {
+	report, err := w.build(ctx, job.Args.AthleteID, job.Args.Year)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	err = w.mgr.db.UpsertAthleteYearReview(ctx, database.UpsertAthleteYearReviewParams{
+		AthleteID: job.Args.AthleteID,
+		Year:      int32(job.Args.Year),
+		Report:    data,
+	})
+	if err != nil {
+		return fmt.Errorf("upsert year review: %w", err)
+	}
+
+	return river.RecordOutput(ctx, map[string]interface{}{
+		"activities": report.Totals.Activities,
+		"hugels":     len(report.Hugels),
+	})
+}
+
+func (w *YearReviewWorker) build(ctx context.Context, athleteID int64, year int) (modelsdk.YearReview, error) {
+	report := modelsdk.YearReview{
+		AthleteID:  modelsdk.StringInt(athleteID),
+		Year:       year,
+		ComputedAt: time.Now(),
+		Segments:   []modelsdk.YearSegment{},
+		Hugels:     []modelsdk.YearHugel{},
+	}
+
+	rows, err := w.mgr.db.YearReviewActivities(ctx, database.YearReviewActivitiesParams{
+		AthleteID: athleteID,
+		Year:      int32(year),
+	})
+	if err != nil {
+		return report, fmt.Errorf("year activities: %w", err)
+	}
+	acts := make([]yearreview.Activity, 0, len(rows))
+	for _, row := range rows {
+		acts = append(acts, yearreview.Activity{
+			ID:             row.ID,
+			Name:           row.Name,
+			StartDateLocal: row.StartDateLocal.Time,
+			SportType:      row.SportType,
+			Distance:       row.Distance,
+			Elevation:      row.TotalElevationGain,
+			MovingTime:     row.MovingTime,
+		})
+	}
+	summary := yearreview.Summarize(acts)
+	report.Totals = convertYearTotals(summary.Totals)
+	report.Sports = make([]modelsdk.YearTotals, 0, len(summary.Sports))
+	for _, t := range summary.Sports {
+		report.Sports = append(report.Sports, convertYearTotals(t))
+	}
+	if summary.LongestRide.ID != 0 {
+		report.LongestRide = &modelsdk.YearActivity{
+			ActivityID:     modelsdk.StringInt(summary.LongestRide.ID),
+			Name:           summary.LongestRide.Name,
+			StartDateLocal: summary.LongestRide.StartDateLocal,
+			SportType:      summary.LongestRide.SportType,
+			Distance:       summary.LongestRide.Distance,
+			Elevation:      summary.LongestRide.Elevation,
+		}
+	}
+	if summary.BiggestClimbDay.Activities > 0 {
+		report.BiggestClimbDay = &modelsdk.YearDay{
+			Date:       summary.BiggestClimbDay.Date,
+			Elevation:  summary.BiggestClimbDay.Elevation,
+			Activities: summary.BiggestClimbDay.Activities,
+		}
+	}
+
+	segments, err := w.mgr.db.YearReviewSegments(ctx, database.YearReviewSegmentsParams{
+		AthleteID:   athleteID,
+		Year:        int32(year),
+		MaxSegments: yearReviewSegments,
+	})
+	if err != nil {
+		return report, fmt.Errorf("year segments: %w", err)
+	}
+	for _, seg := range segments {
+		report.Segments = append(report.Segments, modelsdk.YearSegment{
+			SegmentID: modelsdk.StringInt(seg.SegmentID),
+			Name:      seg.Name,
+			Efforts:   seg.Efforts,
+			BestTime:  seg.BestTime,
+		})
+	}
+
+	rides, err := w.mgr.db.EddingtonActivities(ctx, athleteID)
+	if err != nil {
+		return report, fmt.Errorf("eddington activities: %w", err)
+	}
+	progress := yearreview.EddingtonProgress(eddington.FromRows(rides), year)
+	report.Eddington = modelsdk.YearEddington{
+		Start:      progress.Start,
+		End:        progress.End,
+		Year:       progress.Year,
+		Milestones: make([]modelsdk.EddingtonMilestone, 0, len(progress.Milestones)),
+	}
+	for _, ms := range progress.Milestones {
+		report.Eddington.Milestones = append(report.Eddington.Milestones, modelsdk.EddingtonMilestone{
+			Eddington:  ms.Eddington,
+			Date:       ms.Date,
+			ActivityID: modelsdk.StringInt(ms.ActivityID),
+		})
+	}
+
+	for _, edition := range hugeldate.Editions {
+		if edition.Year != year {
+			continue
+		}
+		board, err := w.board(ctx, edition)
+		if err != nil {
+			return report, fmt.Errorf("%s leaderboard: %w", edition.RouteName, err)
+		}
+		for _, row := range board.rows {
+			if row.AthleteID != athleteID {
+				continue
+			}
+			report.Hugels = append(report.Hugels, modelsdk.YearHugel{
+				RouteName:        edition.RouteName,
+				Title:            edition.Title(),
+				Lite:             edition.Lite,
+				ActivityID:       modelsdk.StringInt(row.ActivityID),
+				Rank:             row.Rank,
+				Finishers:        len(board.rows),
+				TotalTimeSeconds: row.TotalTimeSeconds,
+				Superlatives:     board.superlatives.Won(row.ActivityID),
+			})
+			break
+		}
+	}
+
+	return report, nil
+}
+
+// board is the edition's leaderboard and its superlatives, shared by the
+// reports built within the ttl.
+func (w *YearReviewWorker) board(ctx context.Context, edition hugeldate.Edition) (yearReviewBoard, error) {
+	w.boardsMu.Lock()
+	defer w.boardsMu.Unlock()
+
+	if b, ok := w.boards[edition.RouteName]; ok && time.Since(b.loaded) < yearReviewBoardTTL {
+		return b, nil
+	}
+
+	rows, err := w.mgr.db.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+		RouteYear: edition.Year,
+		Lite:      edition.Lite,
+		HugelLeaderboardParams: database.HugelLeaderboardParams{
+			AthleteID: -1,
+			After:     database.Timestamp(edition.Dates.Start),
+			Before:    database.Timestamp(edition.Dates.End),
+		},
+	})
+	if err != nil {
+		return yearReviewBoard{}, err
+	}
+	if w.boards == nil {
+		w.boards = make(map[string]yearReviewBoard)
+	}
+	b := yearReviewBoard{rows: rows, superlatives: superlative.Parse(rows), loaded: time.Now()}
+	w.boards[edition.RouteName] = b
+	return b, nil
+}
+
+func convertYearTotals(t yearreview.Totals) modelsdk.YearTotals {
+	return modelsdk.YearTotals{
+		Sport:      t.Sport,
+		Activities: t.Activities,
+		Distance:   t.Distance,
+		Elevation:  t.Elevation,
+		MovingTime: t.MovingTime,
+	}
+}
+
+// QueueYearReviewsArgs precomputes the reports of the year through December,
+// when they are shared. Reports are refreshed weekly until the year is over.
+type QueueYearReviewsArgs struct{}
+
+func (QueueYearReviewsArgs) Kind() string { return "year_reviews_load" }
+func (QueueYearReviewsArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       riverDatabaseQueue,
+		MaxAttempts: 3,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Hour,
+		},
+	}
+}
+
+type QueueYearReviewsWorker struct {
+	mgr *Manager
+	river.WorkerDefaults[QueueYearReviewsArgs]
+}
+
+func (w *QueueYearReviewsWorker) Work(ctx context.Context, job *river.Job[QueueYearReviewsArgs]) error {
+	now := time.Now().UTC()
+	if now.Month() != time.December {
+		return river.RecordOutput(ctx, "not december, nothing to do")
+	}
+
+	athletes, err := w.mgr.db.AthletesNeedingYearReview(ctx, int32(now.Year()))
+	if err != nil {
+		return fmt.Errorf("fetching athletes: %w", err)
+	}
+
+	if len(athletes) > 0 {
+		many := make([]river.InsertManyParams, 0, len(athletes))
+		for _, athleteID := range athletes {
+			many = append(many, river.InsertManyParams{
+				Args:       YearReviewArgs{AthleteID: athleteID, Year: now.Year()},
+				InsertOpts: &river.InsertOpts{Priority: PriorityLow},
+			})
+		}
+		_, err = w.mgr.cli.InsertMany(ctx, many)
+		if err != nil {
+			return fmt.Errorf("inserting year reviews: %w", err)
+		}
+	}
+
+	return river.RecordOutput(ctx, map[string]interface{}{
+		"year":     now.Year(),
+		"athletes": len(athletes),
+	})
+}
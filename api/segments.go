@@ -1,13 +1,21 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 
 	"github.com/Emyrk/strava/api/httpmw"
 
 	"github.com/Emyrk/strava/api/httpapi"
 	"github.com/Emyrk/strava/api/modelsdk"
 	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/predict"
+	"github.com/Emyrk/strava/internal/segmenttrend"
 )
 
 func (api *API) getSegments(rw http.ResponseWriter, r *http.Request) {
@@ -37,7 +45,7 @@ func (api *API) getSegments(rw http.ResponseWriter, r *http.Request) {
 
 	resp := convertSegmentRows(segments)
 
-	// If logged in, add their best efforts.
+	// If logged in, add their best efforts and a prediction.
 	if athleteLoggedIn {
 		bestEfforts, err := api.Opts.DB.GetBestPersonalSegmentEffort(ctx, database.GetBestPersonalSegmentEffortParams{
 			AthleteID:  id,
@@ -61,6 +69,38 @@ func (api *API) getSegments(rw http.ResponseWriter, r *http.Request) {
 				resp[i] = row
 			}
 		}
+
+		efforts, err := api.Opts.DB.GetPersonalSegmentEfforts(ctx, database.GetPersonalSegmentEffortsParams{
+			AthleteID:  id,
+			SegmentIds: requestedSegmentsInts,
+		})
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to load segment efforts",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		fatigue, err := api.HugelFatigue(ctx, false)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to load field fatigue",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		p := predict.Predictor{Fatigue: fatigue}
+		predictEfforts := convertPredictEfforts(efforts)
+		now := time.Now()
+		for i, row := range resp {
+			if pred, ok := p.Segment(int64(row.DetailedSegment.ID), predictEfforts, now); ok {
+				sdkPred := convertSegmentPrediction(pred)
+				row.Prediction = &sdkPred
+				resp[i] = row
+			}
+		}
 	}
 
 	httpapi.Write(ctx, rw, http.StatusOK, resp)
@@ -145,3 +185,144 @@ func convertMap(m database.Map) modelsdk.Map {
 		UpdatedAt:       m.UpdatedAt.Time,
 	}
 }
+
+// segmentEffortHistory returns every effort of the athlete on a segment of a
+// competitive route, with the trends and form computed from them.
+func (api *API) segmentEffortHistory(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		_, ok = httpmw.AuthenticatedAthleteIDOptional(r)
+		ath   = httpmw.Athlete(r)
+	)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, modelsdk.Response{
+			Message: "Synced data requires authentication. No authentication provided",
+		})
+		return
+	}
+
+	if !httpmw.RequestAuthenticatedAsAdminsOrMe(rw, r, ath.Athlete.ID) {
+		return
+	}
+
+	segmentID, err := strconv.ParseInt(chi.URLParam(r, "segment_id"), 10, 64)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid segment id",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	routes, err := api.Opts.DB.AllCompetitiveRoutes(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load routes",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	var routeNames []string
+	for _, route := range routes {
+		if slices.Contains(route.Segments, segmentID) {
+			routeNames = append(routeNames, route.Name)
+		}
+	}
+	if len(routeNames) == 0 {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: "Segment is not on a competitive route",
+			Detail:  fmt.Sprintf("segment %d", segmentID),
+		})
+		return
+	}
+
+	efforts, err := api.Opts.DB.GetPersonalSegmentEfforts(ctx, database.GetPersonalSegmentEffortsParams{
+		AthleteID:  ath.Athlete.ID,
+		SegmentIds: []int64{segmentID},
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segment efforts",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	resp := modelsdk.SegmentEffortHistory{
+		AthleteID:   modelsdk.StringInt(ath.Athlete.ID),
+		SegmentID:   modelsdk.StringInt(segmentID),
+		Routes:      routeNames,
+		Efforts:     make([]modelsdk.HistoricalSegmentEffort, 0, len(efforts)),
+		SeasonBests: []modelsdk.SegmentSeasonBest{},
+	}
+	trendEfforts := make([]segmenttrend.Effort, 0, len(efforts))
+	for _, effort := range efforts {
+		resp.Efforts = append(resp.Efforts, convertHistoricalSegmentEffort(effort))
+
+		te := segmenttrend.Effort{
+			ID:             effort.ID,
+			StartDate:      effort.StartDate.Time,
+			StartDateLocal: effort.StartDateLocal.Time,
+			Elapsed:        effort.ElapsedTime,
+		}
+		// Estimated watts say more about the rider's weight than their form.
+		if effort.DeviceWatts {
+			te.Watts = effort.AverageWatts
+		}
+		trendEfforts = append(trendEfforts, te)
+	}
+
+	trend := segmenttrend.Summarize(trendEfforts, time.Now())
+	resp.ElapsedTrend = convertSegmentTrendLine(trend.Elapsed)
+	resp.WattsTrend = convertSegmentTrendLine(trend.Watts)
+	for _, season := range trend.Seasons {
+		resp.SeasonBests = append(resp.SeasonBests, modelsdk.SegmentSeasonBest{
+			Year:        season.Year,
+			EffortID:    modelsdk.StringInt(season.EffortID),
+			ElapsedTime: season.Elapsed,
+			Date:        season.Date,
+		})
+	}
+	resp.Form = modelsdk.SegmentForm{
+		Level:      trend.Form.Level,
+		PR:         trend.Form.PR,
+		RecentBest: trend.Form.RecentBest,
+		Pace:       trend.Form.Pace,
+		Recent:     trend.Form.Recent,
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func convertHistoricalSegmentEffort(row database.SegmentEffort) modelsdk.HistoricalSegmentEffort {
+	effort := modelsdk.HistoricalSegmentEffort{
+		EffortID:       modelsdk.StringInt(row.ID),
+		ActivityID:     modelsdk.StringInt(row.ActivitiesID),
+		StartDate:      row.StartDate.Time,
+		StartDateLocal: row.StartDateLocal.Time,
+		ElapsedTime:    row.ElapsedTime,
+		MovingTime:     row.MovingTime,
+		DeviceWatts:    row.DeviceWatts,
+		AverageWatts:   row.AverageWatts,
+	}
+	if row.PrRank.Valid {
+		effort.PRRank = &row.PrRank.Int32
+	}
+	if row.KomRank.Valid {
+		effort.KOMRank = &row.KomRank.Int32
+	}
+	return effort
+}
+
+func convertSegmentTrendLine(line *segmenttrend.Line) *modelsdk.SegmentTrendLine {
+	if line == nil {
+		return nil
+	}
+	return &modelsdk.SegmentTrendLine{
+		Start:      line.Start,
+		StartValue: line.StartValue,
+		End:        line.End,
+		EndValue:   line.EndValue,
+		PerDay:     line.PerDay,
+	}
+}
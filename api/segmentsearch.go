@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/modelsdk"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+const (
+	searchDefaultRadius = 2000
+	searchMaxRadius     = 25_000
+	searchDefaultLimit  = 50
+	searchMaxLimit      = 200
+)
+
+// searchSegments finds segments that start near a point, closest first. The
+// index only has locations and grades, the rest is loaded for the hits.
+func (api *API) searchSegments(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	param := func(name string, def float64, required bool) (float64, bool) {
+		v := query.Get(name)
+		if v == "" {
+			if required {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+					Message: fmt.Sprintf("Missing %s", name),
+				})
+				return 0, false
+			}
+			return def, true
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+				Message: fmt.Sprintf("Invalid %s", name),
+				Detail:  fmt.Sprintf("%q is not a number", v),
+			})
+			return 0, false
+		}
+		return f, true
+	}
+
+	lat, ok := param("lat", 0, true)
+	if !ok {
+		return
+	}
+	lng, ok := param("lng", 0, true)
+	if !ok {
+		return
+	}
+	radius, ok := param("radius", searchDefaultRadius, false)
+	if !ok {
+		return
+	}
+	minGrade, ok := param("min_grade", math.Inf(-1), false)
+	if !ok {
+		return
+	}
+	limit, ok := param("limit", searchDefaultLimit, false)
+	if !ok {
+		return
+	}
+
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid location, lat must be within ±90 and lng within ±180",
+		})
+		return
+	}
+	if radius <= 0 || radius > searchMaxRadius {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Invalid radius, must be between 0 and %d meters", searchMaxRadius),
+		})
+		return
+	}
+	if limit < 1 || limit > searchMaxLimit {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Invalid limit, must be between 1 and %d", searchMaxLimit),
+		})
+		return
+	}
+
+	index, err := api.SegmentIndexCache.Load(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segment index",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var (
+		ids  []int64
+		away = make(map[int64]float64)
+	)
+	for _, hit := range index.Near(geo.Point{Lat: lat, Lng: lng}, radius) {
+		if hit.AverageGrade < minGrade {
+			continue
+		}
+		ids = append(ids, hit.ID)
+		away[hit.ID] = hit.Distance
+		if len(ids) >= int(limit) {
+			break
+		}
+	}
+
+	resp := make([]modelsdk.NearbySegment, 0, len(ids))
+	if len(ids) == 0 {
+		httpapi.Write(ctx, rw, http.StatusOK, resp)
+		return
+	}
+
+	locations, err := api.Opts.DB.GetSegmentLocations(ctx, ids)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load segments",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	byID := make(map[int64]database.SegmentLocation, len(locations))
+	for _, loc := range locations {
+		byID[loc.ID] = loc
+	}
+
+	// Keep the closest first order of the index.
+	for _, id := range ids {
+		loc, ok := byID[id]
+		// Made private since the index was loaded.
+		if !ok || loc.Private {
+			continue
+		}
+		resp = append(resp, convertNearbySegment(loc, away[id]))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func convertNearbySegment(loc database.SegmentLocation, away float64) modelsdk.NearbySegment {
+	return modelsdk.NearbySegment{
+		ID:            modelsdk.StringInt(loc.ID),
+		Name:          loc.Name,
+		ActivityType:  loc.ActivityType,
+		Distance:      loc.Distance,
+		AverageGrade:  loc.AverageGrade,
+		MaximumGrade:  loc.MaximumGrade,
+		ElevationHigh: loc.ElevationHigh,
+		ElevationLow:  loc.ElevationLow,
+		StartLatlng:   []float64{loc.StartLat, loc.StartLng},
+		EndLatlng:     []float64{loc.EndLat, loc.EndLng},
+		ClimbCategory: loc.ClimbCategory,
+		City:          loc.City,
+		State:         loc.State,
+		Country:       loc.Country,
+		Hazardous:     loc.Hazardous,
+		DistanceAway:  away,
+	}
+}
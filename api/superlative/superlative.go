@@ -5,6 +5,7 @@ import (
 
 	"github.com/Emyrk/strava/api/modelsdk/sdktype"
 	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/pacing"
 )
 
 type List struct {
@@ -25,6 +26,11 @@ type List struct {
 	MostAchievements      Entry[int]     `json:"most_achievements"`
 	LongestRide           Entry[float64] `json:"longest_ride"`
 	ShortestRide          Entry[float64] `json:"shortest_ride"`
+	// LeastTransition value is the seconds spent between the climbs
+	LeastTransition Entry[int64] `json:"least_transition"`
+	// StrongestFinish value is the lowest fatigue index, the late climbs
+	// against the field over the early ones
+	StrongestFinish Entry[float64] `json:"strongest_finish"`
 
 	// Most elevation = mountain climber
 	// Best of $Segment
@@ -60,6 +66,12 @@ func compare[T int | float64 | int64](entry Entry[T], lowest Entry[T], highest E
 func Parse(activities []database.HugelLeaderboardRow) List {
 	var list List
 
+	results := make([]database.HugelSegmentEfforts, 0, len(activities))
+	for _, activity := range activities {
+		results = append(results, activity.Efforts)
+	}
+	medians := pacing.FieldMedians(results)
+
 	for _, activity := range activities {
 		if list.EarliestStart.Value.IsZero() || list.EarliestStart.Value.After(activity.StartDate.Time) {
 			list.EarliestStart = entry(activity.ActivityID, activity.StartDate.Time)
@@ -79,9 +91,49 @@ func Parse(activities []database.HugelLeaderboardRow) List {
 		_, list.MostAchievements = compare(entry(activity.ActivityID, int(activity.AchievementCount)), entry(0, 0), list.MostAchievements)
 		list.ShortestRide, list.LongestRide = compare(entry(activity.ActivityID, activity.Distance), list.ShortestRide, list.LongestRide)
 
+		breakdown := pacing.Analyze(activity.Efforts, medians)
+		if len(breakdown.Transitions) > 0 {
+			list.LeastTransition, _ = compare(entry(activity.ActivityID, int64(breakdown.TransitionTime)), list.LeastTransition, entry(0, int64(0)))
+		}
+		list.StrongestFinish, _ = compare(entry(activity.ActivityID, breakdown.FatigueIndex), list.StrongestFinish, entry(0, float64(0)))
+
 		if activity.DeviceWatts {
 			_, list.MostAverageWatts = compare(entry(activity.ActivityID, activity.AverageWatts), entry(0, float64(0)), list.MostAverageWatts)
 		}
 	}
 	return list
 }
+
+// Won is the superlatives held by the activity, by their json names.
+func (l List) Won(activityID int64) []string {
+	held := []struct {
+		name     string
+		activity sdktype.StringInt
+	}{
+		{"earliest_start", l.EarliestStart.Activity},
+		{"latest_end", l.LatestEnd.Activity},
+		{"most_stoppage", l.MostStoppage.Activity},
+		{"least_stoppage", l.LeastStoppage.Activity},
+		{"most_avg_watts", l.MostAverageWatts.Activity},
+		{"most_avg_cadence", l.MostAverageCadence.Activity},
+		{"least_avg_cadence", l.LeastAverageCadence.Activity},
+		{"most_avg_speed", l.MostAverageSpeed.Activity},
+		{"least_avg_speed", l.LeastAverageSpeed.Activity},
+		{"most_avg_hr", l.MostAverageHeartRate.Activity},
+		{"least_avg_hr", l.LeastAverageHeartRate.Activity},
+		{"most_suffer", l.MostSuffer.Activity},
+		{"most_achievements", l.MostAchievements.Activity},
+		{"longest_ride", l.LongestRide.Activity},
+		{"shortest_ride", l.ShortestRide.Activity},
+		{"least_transition", l.LeastTransition.Activity},
+		{"strongest_finish", l.StrongestFinish.Activity},
+	}
+
+	var won []string
+	for _, h := range held {
+		if h.activity != 0 && int64(h.activity) == activityID {
+			won = append(won, h.name)
+		}
+	}
+	return won
+}
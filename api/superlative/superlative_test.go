@@ -94,8 +94,44 @@ func TestList(t *testing.T) {
 		require.Equal(t, list.MostAchievements, entry(1, int(activities[0].AchievementCount)))
 		require.Equal(t, list.ShortestRide, entry(1, activities[0].Distance))
 		require.Equal(t, list.LongestRide, entry(2, activities[1].Distance))
+
+		require.Equal(t, []string{
+			"most_stoppage", "most_avg_cadence", "least_avg_speed", "most_avg_hr",
+			"most_suffer", "most_achievements", "shortest_ride",
+		}, list.Won(1))
+		require.Equal(t, []string{
+			"earliest_start", "latest_end", "least_stoppage", "most_avg_watts", "least_avg_cadence",
+			"most_avg_speed", "least_avg_hr", "longest_ride",
+		}, list.Won(2))
+		require.Empty(t, list.Won(3))
 	})
 
+	t.Run("Pacing", func(t *testing.T) {
+		start := time.Now().Add(time.Hour * -3)
+		climbs := func(gap int, first, second int) database.HugelSegmentEfforts {
+			return database.HugelSegmentEfforts{
+				{SegmentID: 1, StartDate: start, ElapsedTime: first},
+				{SegmentID: 2, StartDate: start.Add(time.Duration(first+gap) * time.Second), ElapsedTime: second},
+			}
+		}
+
+		activities := []database.HugelLeaderboardRow{
+			activity(1, stats{Start: start, End: start.Add(time.Hour)}),
+			activity(2, stats{Start: start, End: start.Add(time.Hour)}),
+			activity(3, stats{Start: start, End: start.Add(time.Hour)}),
+		}
+		// Quick between the climbs, but faded on the second.
+		activities[0].Efforts = climbs(60, 100, 300)
+		activities[1].Efforts = climbs(600, 200, 200)
+		// Slow start, strong finish.
+		activities[2].Efforts = climbs(300, 300, 100)
+
+		list := superlative.Parse(activities)
+		require.Equal(t, entry(1, int64(60)), list.LeastTransition)
+		require.Equal(t, entry(3, 0.5/1.5), list.StrongestFinish)
+		require.Contains(t, list.Won(1), "least_transition")
+		require.Contains(t, list.Won(3), "strongest_finish")
+	})
 }
 
 type stats struct {
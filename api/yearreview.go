@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Emyrk/strava/api/httpapi"
+	"github.com/Emyrk/strava/api/httpmw"
+	"github.com/Emyrk/strava/api/modelsdk"
+	river2 "github.com/Emyrk/strava/api/river"
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/certificate"
+)
+
+// yearReview returns the stored report of the athlete's year. A missing report,
+// or one asked to be refreshed, is queued and the caller polls until it is
+// built.
+func (api *API) yearReview(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		athlete = httpmw.Athlete(r)
+	)
+
+	if !yearReviewOwner(rw, r, athlete.Athlete.ID) {
+		return
+	}
+
+	year, ok := reviewYear(rw, r)
+	if !ok {
+		return
+	}
+
+	review, err := api.Opts.DB.GetAthleteYearReview(ctx, database.GetAthleteYearReviewParams{
+		AthleteID: athlete.Athlete.ID,
+		Year:      int32(year),
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to load year in review",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) || r.URL.Query().Get("refresh") == "true" {
+		_, err := api.RiverManager.EnqueueYearReview(ctx, river2.YearReviewArgs{
+			AthleteID: athlete.Athlete.ID,
+			Year:      year,
+		}, river2.PriorityHighest)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to queue year in review",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusAccepted, modelsdk.Response{
+			Message: fmt.Sprintf("Year in review for %d is being built, try again shortly", year),
+		})
+		return
+	}
+
+	var report modelsdk.YearReview
+	if err := json.Unmarshal(review.Report, &report); err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to decode year in review",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	report.Shared = review.Shared
+
+	httpapi.Write(ctx, rw, http.StatusOK, report)
+}
+
+// setYearReviewShared shares or hides the card of a built report. Reports are
+// built for every athlete, the card is only public once they share it.
+func (api *API) setYearReviewShared(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		athlete = httpmw.Athlete(r)
+	)
+
+	if !yearReviewOwner(rw, r, athlete.Athlete.ID) {
+		return
+	}
+
+	year, ok := reviewYear(rw, r)
+	if !ok {
+		return
+	}
+
+	var req modelsdk.YearReviewShare
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	n, err := api.Opts.DB.SetAthleteYearReviewShared(ctx, database.SetAthleteYearReviewSharedParams{
+		Shared:    req.Shared,
+		AthleteID: athlete.Athlete.ID,
+		Year:      int32(year),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+			Message: "Failed to save year in review setting",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if n == 0 {
+		httpapi.Write(ctx, rw, http.StatusNotFound, modelsdk.Response{
+			Message: fmt.Sprintf("No year in review for %d yet", year),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, req)
+}
+
+// yearReviewImage renders the card of a stored report as svg or png. The card
+// is public once the athlete shares it, until then only they can see it.
+func (api *API) yearReviewImage(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		athlete = httpmw.Athlete(r)
+		format  = chi.URLParam(r, "format")
+	)
+
+	year, ok := reviewYear(rw, r)
+	if !ok {
+		return
+	}
+
+	var contentType string
+	switch format {
+	case "svg":
+		contentType = "image/svg+xml"
+	case "png":
+		contentType = "image/png"
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: fmt.Sprintf("Unsupported format %q, use svg or png", format),
+		})
+		return
+	}
+
+	// Sharing can be taken back, so it is checked before the cache.
+	review, err := api.Opts.DB.GetAthleteYearReview(ctx, database.GetAthleteYearReviewParams{
+		AthleteID: athlete.Athlete.ID,
+		Year:      int32(year),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, pgx.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		httpapi.Write(ctx, rw, status, modelsdk.Response{
+			Message: "Failed to load year in review",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !review.Shared && !yearReviewOwner(rw, r, athlete.Athlete.ID) {
+		return
+	}
+
+	cacheControl := "public, max-age=3600"
+	if !review.Shared {
+		cacheControl = "private, no-store"
+	}
+
+	key := fmt.Sprintf("year-%d-%d.%s", athlete.Athlete.ID, year, format)
+	data, ok := api.CertificateCache.Get(key)
+	if !ok {
+		var report modelsdk.YearReview
+		if err := json.Unmarshal(review.Report, &report); err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to decode year in review",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		card := certificate.YearCard{
+			AthleteName: fmt.Sprintf("%s %s", athlete.Athlete.Firstname, athlete.Athlete.Lastname),
+			Year:        year,
+			Activities:  report.Totals.Activities,
+			Distance:    report.Totals.Distance,
+			Elevation:   report.Totals.Elevation,
+			MovingTime:  time.Duration(report.Totals.MovingTime) * time.Second,
+			Eddington:   report.Eddington.End,
+			Hugels:      len(report.Hugels),
+		}
+
+		var buf bytes.Buffer
+		if format == "svg" {
+			err = certificate.WriteYearSVG(&buf, card)
+		} else {
+			err = certificate.WriteYearPNG(&buf, card)
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, modelsdk.Response{
+				Message: "Failed to render year in review",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		data = buf.Bytes()
+		api.CertificateCache.Set(key, data)
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Cache-Control", cacheControl)
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(data)
+}
+
+// yearReviewOwner allows the athlete and admins, writing the error otherwise.
+func yearReviewOwner(rw http.ResponseWriter, r *http.Request, athleteID int64) bool {
+	if _, ok := httpmw.AuthenticatedAthleteIDOptional(r); !ok {
+		httpapi.Write(r.Context(), rw, http.StatusUnauthorized, modelsdk.Response{
+			Message: "Synced data requires authentication. No authentication provided",
+		})
+		return false
+	}
+	return httpmw.RequestAuthenticatedAsAdminsOrMe(rw, r, athleteID)
+}
+
+func reviewYear(rw http.ResponseWriter, r *http.Request) (int, bool) {
+	ctx := r.Context()
+	year, err := strconv.Atoi(chi.URLParam(r, "year"))
+	if err != nil || year < 2000 || year > time.Now().Year() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, modelsdk.Response{
+			Message: "Invalid year",
+			Detail:  fmt.Sprintf("%q is not a year with activities", chi.URLParam(r, "year")),
+		})
+		return 0, false
+	}
+	return year, true
+}
@@ -28,6 +28,10 @@ func RootCmd() *cobra.Command {
 		generateKey(),
 		offlineServer(),
 		parseDump(),
+		exportCmd(),
+		candidatesCmd(),
+		discoverCmd(),
+		backtestCmd(),
 	)
 
 	return cmd
@@ -48,6 +48,8 @@ func serverCmd() *cobra.Command {
 		promtheusAddress  string
 		pprofEnabled      bool
 		pprofAddress      string
+		renderCacheDir    string
+		renderCacheBytes  int64
 	)
 
 	v := viper.New()
@@ -157,6 +159,9 @@ func serverCmd() *cobra.Command {
 				VerifyToken:   verifyToken,
 				SigningKeyPEM: secPem,
 				Registry:      registry,
+
+				RenderCacheDir:   renderCacheDir,
+				RenderCacheBytes: renderCacheBytes,
 			})
 			if err != nil {
 				return fmt.Errorf("create server: %w", err)
@@ -304,6 +309,8 @@ func serverCmd() *cobra.Command {
 	cmd.Flags().StringVar(&promtheusAddress, "prometheus-address", "0.0.0.0:9091", "Prometheus address to listen on")
 	cmd.Flags().BoolVar(&pprofEnabled, "enable-pprof", false, "Enable pprof endpoint")
 	cmd.Flags().StringVar(&pprofAddress, "pprof-address", "0.0.0.0:6060", "Pprof address to listen on")
+	cmd.Flags().StringVar(&renderCacheDir, "render-cache-dir", "", "Directory to cache rendered map images in, a temp directory if empty")
+	cmd.Flags().Int64Var(&renderCacheBytes, "render-cache-bytes", 256<<20, "Most bytes of rendered map images to keep")
 
 	return cmd
 }
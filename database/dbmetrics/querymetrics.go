@@ -59,6 +59,20 @@ func (m queryMetricsStore) Close() error {
 	return m.dbMetrics.Close()
 }
 
+func (m queryMetricsStore) ActivityAthleteCounts(ctx context.Context, activityIds []int64) ([]database.ActivityAthleteCountsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.ActivityAthleteCounts(ctx, activityIds)
+	m.queryLatencies.WithLabelValues("ActivityAthleteCounts").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) ActivityCalendar(ctx context.Context, arg database.ActivityCalendarParams) ([]database.ActivityCalendarRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.ActivityCalendar(ctx, arg)
+	m.queryLatencies.WithLabelValues("ActivityCalendar").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) AllCompetitiveRoutes(ctx context.Context) ([]database.CompetitiveRoute, error) {
 	start := time.Now()
 	r0, r1 := m.s.AllCompetitiveRoutes(ctx)
@@ -73,6 +87,20 @@ func (m queryMetricsStore) AllEddingtons(ctx context.Context) ([]database.AllEdd
 	return r0, r1
 }
 
+func (m queryMetricsStore) AthleteDailyTotals(ctx context.Context, arg database.AthleteDailyTotalsParams) ([]database.AthleteDailyTotal, error) {
+	start := time.Now()
+	r0, r1 := m.s.AthleteDailyTotals(ctx, arg)
+	m.queryLatencies.WithLabelValues("AthleteDailyTotals").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) AthleteHasDailyTotals(ctx context.Context, athleteID int64) (bool, error) {
+	start := time.Now()
+	r0, r1 := m.s.AthleteHasDailyTotals(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("AthleteHasDailyTotals").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) AthleteHugelActivites(ctx context.Context, athleteID int64) ([]database.AthleteHugelActivitesRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.AthleteHugelActivites(ctx, athleteID)
@@ -87,6 +115,13 @@ func (m queryMetricsStore) AthleteSyncedActivities(ctx context.Context, arg data
 	return r0, r1
 }
 
+func (m queryMetricsStore) AthleteWeights(ctx context.Context, athleteIds []int64) ([]database.AthleteWeightsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.AthleteWeights(ctx, athleteIds)
+	m.queryLatencies.WithLabelValues("AthleteWeights").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) AthletesNeedingEddington(ctx context.Context) ([]database.AthletesNeedingEddingtonRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.AthletesNeedingEddington(ctx)
@@ -94,6 +129,13 @@ func (m queryMetricsStore) AthletesNeedingEddington(ctx context.Context) ([]data
 	return r0, r1
 }
 
+func (m queryMetricsStore) AthletesNeedingYearReview(ctx context.Context, year int32) ([]int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.AthletesNeedingYearReview(ctx, year)
+	m.queryLatencies.WithLabelValues("AthletesNeedingYearReview").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) BestRouteEfforts(ctx context.Context, expectedSegments []int64) ([]database.BestRouteEffortsRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.BestRouteEfforts(ctx, expectedSegments)
@@ -108,6 +150,20 @@ func (m queryMetricsStore) DeleteActivity(ctx context.Context, id int64) (databa
 	return r0, r1
 }
 
+func (m queryMetricsStore) DeleteAthleteDailyTotals(ctx context.Context, arg database.DeleteAthleteDailyTotalsParams) error {
+	start := time.Now()
+	r0 := m.s.DeleteAthleteDailyTotals(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteAthleteDailyTotals").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) DeleteAthleteEddingtonBreakdowns(ctx context.Context, athleteID int64) error {
+	start := time.Now()
+	r0 := m.s.DeleteAthleteEddingtonBreakdowns(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("DeleteAthleteEddingtonBreakdowns").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) DeleteAthleteLogin(ctx context.Context, athleteID int64) error {
 	start := time.Now()
 	r0 := m.s.DeleteAthleteLogin(ctx, athleteID)
@@ -115,6 +171,20 @@ func (m queryMetricsStore) DeleteAthleteLogin(ctx context.Context, athleteID int
 	return r0
 }
 
+func (m queryMetricsStore) DeleteHeatmapOptOut(ctx context.Context, athleteID int64) error {
+	start := time.Now()
+	r0 := m.s.DeleteHeatmapOptOut(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("DeleteHeatmapOptOut").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) DeleteLeaderboardOptOut(ctx context.Context, athleteID int64) error {
+	start := time.Now()
+	r0 := m.s.DeleteLeaderboardOptOut(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("DeleteLeaderboardOptOut").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) DeleteWebhookDump(ctx context.Context, id pgxpgtype.UUID) error {
 	start := time.Now()
 	r0 := m.s.DeleteWebhookDump(ctx, id)
@@ -129,6 +199,41 @@ func (m queryMetricsStore) EddingtonActivities(ctx context.Context, athleteID in
 	return r0, r1
 }
 
+func (m queryMetricsStore) EddingtonLeaderboard(ctx context.Context) ([]database.EddingtonLeaderboardRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.EddingtonLeaderboard(ctx)
+	m.queryLatencies.WithLabelValues("EddingtonLeaderboard").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) EddingtonMetricLeaderboard(ctx context.Context, metric string) ([]database.EddingtonMetricLeaderboardRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.EddingtonMetricLeaderboard(ctx, metric)
+	m.queryLatencies.WithLabelValues("EddingtonMetricLeaderboard").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) EventGroupRides(ctx context.Context, routeName string) (database.EventGroupRide, error) {
+	start := time.Now()
+	r0, r1 := m.s.EventGroupRides(ctx, routeName)
+	m.queryLatencies.WithLabelValues("EventGroupRides").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) EventHeatmap(ctx context.Context, routeName string) (database.EventHeatmap, error) {
+	start := time.Now()
+	r0, r1 := m.s.EventHeatmap(ctx, routeName)
+	m.queryLatencies.WithLabelValues("EventHeatmap").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) FillSegmentLocations(ctx context.Context) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.FillSegmentLocations(ctx)
+	m.queryLatencies.WithLabelValues("FillSegmentLocations").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetActivityDetail(ctx context.Context, id int64) (database.ActivityDetail, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetActivityDetail(ctx, id)
@@ -136,6 +241,20 @@ func (m queryMetricsStore) GetActivityDetail(ctx context.Context, id int64) (dat
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetActivityMap(ctx context.Context, activityID int64) (database.Map, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetActivityMap(ctx, activityID)
+	m.queryLatencies.WithLabelValues("GetActivityMap").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetActivitySegmentEfforts(ctx context.Context, activityID int64) ([]database.SegmentEffort, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetActivitySegmentEfforts(ctx, activityID)
+	m.queryLatencies.WithLabelValues("GetActivitySegmentEfforts").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetActivitySummariesByDate(ctx context.Context, startDate pgxpgtype.Timestamptz) ([]database.ActivitySummary, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetActivitySummariesByDate(ctx, startDate)
@@ -150,6 +269,13 @@ func (m queryMetricsStore) GetActivitySummary(ctx context.Context, id int64) (da
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetActivitySummaryPolylines(ctx context.Context, activityIds []int64) ([]database.GetActivitySummaryPolylinesRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetActivitySummaryPolylines(ctx, activityIds)
+	m.queryLatencies.WithLabelValues("GetActivitySummaryPolylines").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetAthlete(ctx context.Context, athleteID int64) (database.Athlete, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetAthlete(ctx, athleteID)
@@ -164,6 +290,20 @@ func (m queryMetricsStore) GetAthleteEddington(ctx context.Context, athleteID in
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetAthleteEddingtonBreakdowns(ctx context.Context, athleteID int64) ([]database.AthleteEddingtonBreakdown, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetAthleteEddingtonBreakdowns(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("GetAthleteEddingtonBreakdowns").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetAthleteEddingtonMetrics(ctx context.Context, athleteID int64) ([]database.AthleteEddingtonMetric, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetAthleteEddingtonMetrics(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("GetAthleteEddingtonMetrics").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetAthleteFull(ctx context.Context, athleteID int64) (database.GetAthleteFullRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetAthleteFull(ctx, athleteID)
@@ -206,6 +346,13 @@ func (m queryMetricsStore) GetAthleteNeedsForwardLoad(ctx context.Context) ([]da
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetAthleteYearReview(ctx context.Context, arg database.GetAthleteYearReviewParams) (database.AthleteYearReview, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetAthleteYearReview(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetAthleteYearReview").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetBestPersonalSegmentEffort(ctx context.Context, arg database.GetBestPersonalSegmentEffortParams) ([]database.SegmentEffort, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetBestPersonalSegmentEffort(ctx, arg)
@@ -220,6 +367,13 @@ func (m queryMetricsStore) GetCompetitiveRoute(ctx context.Context, routeName st
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetCompetitiveRouteByName(ctx context.Context, routeName string) (database.CompetitiveRoute, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetCompetitiveRouteByName(ctx, routeName)
+	m.queryLatencies.WithLabelValues("GetCompetitiveRouteByName").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetDeleteActivityWebhooks(ctx context.Context) ([]database.WebhookDump, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetDeleteActivityWebhooks(ctx)
@@ -227,6 +381,27 @@ func (m queryMetricsStore) GetDeleteActivityWebhooks(ctx context.Context) ([]dat
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetGPSSegmentEfforts(ctx context.Context, activityID int64) ([]database.GpsSegmentEffort, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetGPSSegmentEfforts(ctx, activityID)
+	m.queryLatencies.WithLabelValues("GetGPSSegmentEfforts").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetPersonalSegmentEfforts(ctx context.Context, arg database.GetPersonalSegmentEffortsParams) ([]database.SegmentEffort, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetPersonalSegmentEfforts(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetPersonalSegmentEfforts").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetSegmentLocations(ctx context.Context, segmentIds []int64) ([]database.SegmentLocation, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetSegmentLocations(ctx, segmentIds)
+	m.queryLatencies.WithLabelValues("GetSegmentLocations").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetSegments(ctx context.Context, segmentIds []int64) ([]database.GetSegmentsRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetSegments(ctx, segmentIds)
@@ -234,6 +409,41 @@ func (m queryMetricsStore) GetSegments(ctx context.Context, segmentIds []int64)
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetSummaryPolylinesBetween(ctx context.Context, arg database.GetSummaryPolylinesBetweenParams) ([]database.GetSummaryPolylinesBetweenRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetSummaryPolylinesBetween(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetSummaryPolylinesBetween").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) HeatmapOptedOut(ctx context.Context, athleteID int64) (bool, error) {
+	start := time.Now()
+	r0, r1 := m.s.HeatmapOptedOut(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("HeatmapOptedOut").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) HeatmapPolylines(ctx context.Context, activityIds []int64) ([]database.HeatmapPolylinesRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.HeatmapPolylines(ctx, activityIds)
+	m.queryLatencies.WithLabelValues("HeatmapPolylines").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) HugelDiscoveries(ctx context.Context) ([]database.HugelDiscovery, error) {
+	start := time.Now()
+	r0, r1 := m.s.HugelDiscoveries(ctx)
+	m.queryLatencies.WithLabelValues("HugelDiscoveries").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) HugelDiscoveryCandidates(ctx context.Context, arg database.HugelDiscoveryCandidatesParams) ([]database.HugelDiscoveryCandidatesRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.HugelDiscoveryCandidates(ctx, arg)
+	m.queryLatencies.WithLabelValues("HugelDiscoveryCandidates").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) HugelLeaderboard(ctx context.Context, arg database.HugelLeaderboardParams) ([]database.HugelLeaderboardRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.HugelLeaderboard(ctx, arg)
@@ -248,6 +458,27 @@ func (m queryMetricsStore) IncrementActivitySummaryDownload(ctx context.Context,
 	return r0
 }
 
+func (m queryMetricsStore) InsertAthleteDailyTotals(ctx context.Context, arg database.InsertAthleteDailyTotalsParams) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertAthleteDailyTotals(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertAthleteDailyTotals").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) InsertAthleteEddingtonBreakdown(ctx context.Context, arg database.InsertAthleteEddingtonBreakdownParams) error {
+	start := time.Now()
+	r0 := m.s.InsertAthleteEddingtonBreakdown(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertAthleteEddingtonBreakdown").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) InsertEddingtonEvent(ctx context.Context, arg database.InsertEddingtonEventParams) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertEddingtonEvent(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertEddingtonEvent").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) InsertFailedJob(ctx context.Context, rawJson string) (database.FailedJob, error) {
 	start := time.Now()
 	r0, r1 := m.s.InsertFailedJob(ctx, rawJson)
@@ -255,6 +486,20 @@ func (m queryMetricsStore) InsertFailedJob(ctx context.Context, rawJson string)
 	return r0, r1
 }
 
+func (m queryMetricsStore) InsertHeatmapOptOut(ctx context.Context, athleteID int64) error {
+	start := time.Now()
+	r0 := m.s.InsertHeatmapOptOut(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("InsertHeatmapOptOut").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) InsertLeaderboardOptOut(ctx context.Context, athleteID int64) error {
+	start := time.Now()
+	r0 := m.s.InsertLeaderboardOptOut(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("InsertLeaderboardOptOut").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) InsertWebhookDump(ctx context.Context, rawJson string) (database.WebhookDump, error) {
 	start := time.Now()
 	r0, r1 := m.s.InsertWebhookDump(ctx, rawJson)
@@ -262,6 +507,13 @@ func (m queryMetricsStore) InsertWebhookDump(ctx context.Context, rawJson string
 	return r0, r1
 }
 
+func (m queryMetricsStore) LeaderboardOptedOut(ctx context.Context, athleteID int64) (bool, error) {
+	start := time.Now()
+	r0, r1 := m.s.LeaderboardOptedOut(ctx, athleteID)
+	m.queryLatencies.WithLabelValues("LeaderboardOptedOut").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) LoadedSegments(ctx context.Context) ([]database.LoadedSegmentsRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.LoadedSegments(ctx)
@@ -269,6 +521,13 @@ func (m queryMetricsStore) LoadedSegments(ctx context.Context) ([]database.Loade
 	return r0, r1
 }
 
+func (m queryMetricsStore) MarkHugelDiscoveriesEnqueued(ctx context.Context, activityIds []int64) error {
+	start := time.Now()
+	r0 := m.s.MarkHugelDiscoveriesEnqueued(ctx, activityIds)
+	m.queryLatencies.WithLabelValues("MarkHugelDiscoveriesEnqueued").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) MissingHugelSegments(ctx context.Context, activityID int64) ([]database.Segment, error) {
 	start := time.Now()
 	r0, r1 := m.s.MissingHugelSegments(ctx, activityID)
@@ -276,6 +535,13 @@ func (m queryMetricsStore) MissingHugelSegments(ctx context.Context, activityID
 	return r0, r1
 }
 
+func (m queryMetricsStore) MissingSegmentLocations(ctx context.Context, maxSegments int32) ([]int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.MissingSegmentLocations(ctx, maxSegments)
+	m.queryLatencies.WithLabelValues("MissingSegmentLocations").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) MissingSegments(ctx context.Context, activitiesID int64) ([]string, error) {
 	start := time.Now()
 	r0, r1 := m.s.MissingSegments(ctx, activitiesID)
@@ -332,6 +598,48 @@ func (m queryMetricsStore) RefreshSuperHugelActivities(ctx context.Context) erro
 	return r0
 }
 
+func (m queryMetricsStore) RouteActivityResults(ctx context.Context, activityIds []int64) ([]database.RouteActivityResultsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.RouteActivityResults(ctx, activityIds)
+	m.queryLatencies.WithLabelValues("RouteActivityResults").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) RouteFatigueSamples(ctx context.Context, routeNames []string) ([]database.RouteFatigueSamplesRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.RouteFatigueSamples(ctx, routeNames)
+	m.queryLatencies.WithLabelValues("RouteFatigueSamples").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) SegmentElevationGains(ctx context.Context, segmentIds []int64) ([]database.SegmentElevationGainsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.SegmentElevationGains(ctx, segmentIds)
+	m.queryLatencies.WithLabelValues("SegmentElevationGains").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) SegmentLocationIndex(ctx context.Context) ([]database.SegmentLocationIndexRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.SegmentLocationIndex(ctx)
+	m.queryLatencies.WithLabelValues("SegmentLocationIndex").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) SetAthleteYearReviewShared(ctx context.Context, arg database.SetAthleteYearReviewSharedParams) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.SetAthleteYearReviewShared(ctx, arg)
+	m.queryLatencies.WithLabelValues("SetAthleteYearReviewShared").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) SetCompetitiveRouteReference(ctx context.Context, arg database.SetCompetitiveRouteReferenceParams) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.SetCompetitiveRouteReference(ctx, arg)
+	m.queryLatencies.WithLabelValues("SetCompetitiveRouteReference").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) StarSegments(ctx context.Context, arg database.StarSegmentsParams) error {
 	start := time.Now()
 	r0 := m.s.StarSegments(ctx, arg)
@@ -367,6 +675,13 @@ func (m queryMetricsStore) TotalRideActivitySummariesCount(ctx context.Context)
 	return r0, r1
 }
 
+func (m queryMetricsStore) UnofficialRouteResults(ctx context.Context, routeName string) ([]database.UnofficialRouteResultsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.UnofficialRouteResults(ctx, routeName)
+	m.queryLatencies.WithLabelValues("UnofficialRouteResults").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) UpdateActivityName(ctx context.Context, arg database.UpdateActivityNameParams) error {
 	start := time.Now()
 	r0 := m.s.UpdateActivityName(ctx, arg)
@@ -409,6 +724,13 @@ func (m queryMetricsStore) UpsertAthleteEddington(ctx context.Context, arg datab
 	return r0, r1
 }
 
+func (m queryMetricsStore) UpsertAthleteEddingtonMetric(ctx context.Context, arg database.UpsertAthleteEddingtonMetricParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertAthleteEddingtonMetric(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertAthleteEddingtonMetric").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) UpsertAthleteForwardLoad(ctx context.Context, arg database.UpsertAthleteForwardLoadParams) (database.AthleteForwardLoad, error) {
 	start := time.Now()
 	r0, r1 := m.s.UpsertAthleteForwardLoad(ctx, arg)
@@ -423,6 +745,41 @@ func (m queryMetricsStore) UpsertAthleteLogin(ctx context.Context, arg database.
 	return r0, r1
 }
 
+func (m queryMetricsStore) UpsertAthleteYearReview(ctx context.Context, arg database.UpsertAthleteYearReviewParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertAthleteYearReview(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertAthleteYearReview").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) UpsertEventGroupRides(ctx context.Context, arg database.UpsertEventGroupRidesParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertEventGroupRides(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertEventGroupRides").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) UpsertEventHeatmap(ctx context.Context, arg database.UpsertEventHeatmapParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertEventHeatmap(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertEventHeatmap").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) UpsertGPSSegmentEffort(ctx context.Context, arg database.UpsertGPSSegmentEffortParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertGPSSegmentEffort(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertGPSSegmentEffort").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) UpsertHugelDiscovery(ctx context.Context, arg database.UpsertHugelDiscoveryParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertHugelDiscovery(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertHugelDiscovery").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) UpsertMapData(ctx context.Context, arg database.UpsertMapDataParams) (database.Map, error) {
 	start := time.Now()
 	r0, r1 := m.s.UpsertMapData(ctx, arg)
@@ -444,6 +801,34 @@ func (m queryMetricsStore) UpsertSegmentEffort(ctx context.Context, arg database
 	return r0, r1
 }
 
+func (m queryMetricsStore) UpsertSegmentLocation(ctx context.Context, arg database.UpsertSegmentLocationParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertSegmentLocation(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertSegmentLocation").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) UpsertUnofficialRouteResult(ctx context.Context, arg database.UpsertUnofficialRouteResultParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertUnofficialRouteResult(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertUnofficialRouteResult").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) YearReviewActivities(ctx context.Context, arg database.YearReviewActivitiesParams) ([]database.YearReviewActivitiesRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.YearReviewActivities(ctx, arg)
+	m.queryLatencies.WithLabelValues("YearReviewActivities").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) YearReviewSegments(ctx context.Context, arg database.YearReviewSegmentsParams) ([]database.YearReviewSegmentsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.YearReviewSegments(ctx, arg)
+	m.queryLatencies.WithLabelValues("YearReviewSegments").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) YearlyHugelLeaderboard(ctx context.Context, arg database.YearlyHugelLeaderboardParams) ([]database.HugelLeaderboardRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.YearlyHugelLeaderboard(ctx, arg)
@@ -85,14 +85,67 @@ CREATE TABLE activity_summary (
 
 COMMENT ON TABLE activity_summary IS 'Activity is missing many detailed fields';
 
+CREATE TABLE athlete_daily_totals (
+    athlete_id bigint NOT NULL,
+    day date NOT NULL,
+    sport text NOT NULL,
+    distance double precision NOT NULL,
+    activities integer NOT NULL,
+    elevation_gain double precision DEFAULT 0 NOT NULL,
+    moving_time double precision DEFAULT 0 NOT NULL,
+    hugel_segments integer DEFAULT 0 NOT NULL
+);
+
+COMMENT ON TABLE athlete_daily_totals IS 'Ride distance per local calendar day, so a changed activity only touches its own day of the eddington numbers.';
+
+COMMENT ON COLUMN athlete_daily_totals.sport IS 'ride or virtualride.';
+
+COMMENT ON COLUMN athlete_daily_totals.hugel_segments IS 'Das Hugel segments ridden, counted once per activity.';
+
+CREATE TABLE athlete_eddington_breakdowns (
+    athlete_id bigint NOT NULL,
+    sport text NOT NULL,
+    year integer NOT NULL,
+    days integer NOT NULL,
+    miles_histogram integer[] NOT NULL,
+    eddington_miles integer NOT NULL,
+    kilometers_histogram integer[] NOT NULL,
+    eddington_kilometers integer NOT NULL
+);
+
+COMMENT ON TABLE athlete_eddington_breakdowns IS 'Eddington numbers per sport, for the lifetime and each calendar year.';
+
+COMMENT ON COLUMN athlete_eddington_breakdowns.sport IS 'all, ride or virtualride.';
+
+COMMENT ON COLUMN athlete_eddington_breakdowns.year IS '0 for the lifetime number.';
+
+CREATE TABLE athlete_eddington_metrics (
+    athlete_id bigint NOT NULL,
+    metric text NOT NULL,
+    days integer NOT NULL,
+    histogram integer[] NOT NULL,
+    eddington integer NOT NULL
+);
+
+COMMENT ON TABLE athlete_eddington_metrics IS 'Lifetime eddington numbers counted from something other than distance.';
+
+COMMENT ON COLUMN athlete_eddington_metrics.metric IS 'elevation_ft, elevation_m, hours or hugel_segments.';
+
 CREATE TABLE athlete_eddingtons (
     athlete_id bigint NOT NULL,
     miles_histogram integer[] DEFAULT '{}'::integer[] NOT NULL,
     current_eddington integer DEFAULT 0 NOT NULL,
     last_calculated timestamp with time zone DEFAULT now() NOT NULL,
-    total_activities integer DEFAULT 0 NOT NULL
+    total_activities integer DEFAULT 0 NOT NULL,
+    kilometers_histogram integer[] DEFAULT '{}'::integer[] NOT NULL,
+    current_eddington_km integer DEFAULT 0 NOT NULL,
+    total_days integer DEFAULT 0 NOT NULL
 );
 
+COMMENT ON COLUMN athlete_eddingtons.current_eddington IS 'Lifetime number in miles, from the total distance ridden each day.';
+
+COMMENT ON COLUMN athlete_eddingtons.total_days IS 'Days with at least one ride.';
+
 CREATE TABLE athlete_forward_load (
     athlete_id bigint NOT NULL,
     activity_time_after timestamp with time zone NOT NULL,
@@ -107,6 +160,20 @@ COMMENT ON COLUMN athlete_forward_load.last_touched IS 'Timestamp this row was l
 
 COMMENT ON COLUMN athlete_forward_load.next_load_not_before IS 'Timestamp when the next load can be attempted.';
 
+CREATE TABLE athlete_year_reviews (
+    athlete_id bigint NOT NULL,
+    year integer NOT NULL,
+    report jsonb NOT NULL,
+    computed_at timestamp with time zone NOT NULL,
+    shared boolean DEFAULT false NOT NULL
+);
+
+COMMENT ON TABLE athlete_year_reviews IS 'Year in review reports, precomputed because they read every activity of the year and the hugel boards.';
+
+COMMENT ON COLUMN athlete_year_reviews.report IS 'The report as served by the api.';
+
+COMMENT ON COLUMN athlete_year_reviews.shared IS 'The athlete chose to share the year card publicly.';
+
 CREATE TABLE athletes (
     id bigint NOT NULL,
     summit boolean NOT NULL,
@@ -136,9 +203,12 @@ CREATE TABLE competitive_routes (
     name text NOT NULL,
     display_name text NOT NULL,
     description text NOT NULL,
-    segments bigint[] NOT NULL
+    segments bigint[] NOT NULL,
+    reference_activity_id bigint
 );
 
+COMMENT ON COLUMN competitive_routes.reference_activity_id IS 'A finisher activity chosen by an admin, its track connects the segments of course exports.';
+
 CREATE TABLE segment_efforts (
     id bigint NOT NULL,
     athlete_id bigint NOT NULL,
@@ -304,6 +374,49 @@ CREATE TABLE athlete_logins (
 
 COMMENT ON COLUMN athlete_logins.provider_id IS 'Oauth app client ID';
 
+CREATE TABLE eddington_events (
+    athlete_id bigint NOT NULL,
+    eddington integer NOT NULL,
+    previous integer NOT NULL,
+    reached_on timestamp with time zone NOT NULL,
+    activity_id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE eddington_events IS 'An athlete''s lifetime number in miles went up. Only increases found after the first calculation are recorded, a backfill is not news.';
+
+COMMENT ON COLUMN eddington_events.reached_on IS 'Local day of the ride that reached the number.';
+
+CREATE TABLE event_group_rides (
+    route_name text NOT NULL,
+    groups jsonb NOT NULL,
+    seen bigint[] NOT NULL,
+    computed_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE event_group_rides IS 'Results of an edition that were ridden together.';
+
+COMMENT ON COLUMN event_group_rides.groups IS 'Activity ids of each group, a list of lists.';
+
+COMMENT ON COLUMN event_group_rides.seen IS 'Activity ids already compared against the field, new results are only compared from here.';
+
+CREATE TABLE event_heatmaps (
+    route_name text NOT NULL,
+    min_lat double precision NOT NULL,
+    min_lng double precision NOT NULL,
+    max_lat double precision NOT NULL,
+    max_lng double precision NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    cells integer[] NOT NULL,
+    activities integer NOT NULL,
+    computed_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE event_heatmaps IS 'Density of the result tracks of an edition, rasterized into a grid.';
+
+COMMENT ON COLUMN event_heatmaps.cells IS 'Activities passing through each cell, row by row from the north west corner.';
+
 CREATE TABLE failed_jobs (
     id uuid NOT NULL,
     recorded_at timestamp without time zone NOT NULL,
@@ -316,6 +429,26 @@ COMMENT ON COLUMN failed_jobs.id IS 'Some random uuid';
 
 COMMENT ON COLUMN failed_jobs.raw IS 'Some text. Probably a JSON string.';
 
+CREATE TABLE gps_segment_efforts (
+    activity_id bigint NOT NULL,
+    segment_id bigint NOT NULL,
+    athlete_id bigint NOT NULL,
+    source text DEFAULT 'gps_match'::text NOT NULL,
+    start_date timestamp with time zone NOT NULL,
+    elapsed_time double precision NOT NULL,
+    distance double precision NOT NULL,
+    start_index integer NOT NULL,
+    end_index integer NOT NULL,
+    coverage double precision NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE gps_segment_efforts IS 'Estimated efforts on segments Strava did not match, found from the activity''s GPS track. Never part of the official results.';
+
+COMMENT ON COLUMN gps_segment_efforts.source IS 'How the effort was found, always gps_match for now.';
+
+COMMENT ON COLUMN gps_segment_efforts.coverage IS 'Fraction of the segment the track follows within the match tolerance.';
+
 CREATE TABLE gue_jobs (
     job_id text NOT NULL,
     priority smallint NOT NULL,
@@ -329,6 +462,30 @@ CREATE TABLE gue_jobs (
     updated_at timestamp with time zone NOT NULL
 );
 
+CREATE TABLE heatmap_opt_outs (
+    athlete_id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE heatmap_opt_outs IS 'Athletes who do not want their rides in the event heatmaps.';
+
+CREATE TABLE hugel_discoveries (
+    activity_id bigint NOT NULL,
+    athlete_id bigint NOT NULL,
+    route_name text NOT NULL,
+    hits integer NOT NULL,
+    checkpoints integer NOT NULL,
+    score double precision NOT NULL,
+    discovered_at timestamp with time zone DEFAULT now() NOT NULL,
+    enqueued_at timestamp with time zone
+);
+
+COMMENT ON TABLE hugel_discoveries IS 'Stored ride summaries from past event dates whose track passes the route, found without the activity detail.';
+
+COMMENT ON COLUMN hugel_discoveries.score IS 'Fraction of the route''s segment starts and ends the summary track passes.';
+
+COMMENT ON COLUMN hugel_discoveries.enqueued_at IS 'When the detail fetch was queued, null while waiting for rate limit budget.';
+
 CREATE MATERIALIZED VIEW hugel_activities_2024 AS
  SELECT merged.activity_id,
     merged.athlete_id,
@@ -458,6 +615,51 @@ CREATE MATERIALIZED VIEW lite_hugel_activities_2024 AS
           WHERE (competitive_routes.name = 'lite-das-hugel-2024'::text)))
   WITH NO DATA;
 
+CREATE VIEW edition_results AS
+ SELECT 'das-hugel-2023'::text AS route_name,
+    hugel_activities_2023.activity_id,
+    hugel_activities_2023.athlete_id,
+    hugel_activities_2023.total_time_seconds,
+    hugel_activities_2023.efforts
+   FROM hugel_activities_2023
+UNION ALL
+ SELECT 'das-hugel-2024'::text AS route_name,
+    hugel_activities_2024.activity_id,
+    hugel_activities_2024.athlete_id,
+    hugel_activities_2024.total_time_seconds,
+    hugel_activities_2024.efforts
+   FROM hugel_activities_2024
+UNION ALL
+ SELECT 'das-hugel'::text AS route_name,
+    hugel_activities_2025.activity_id,
+    hugel_activities_2025.athlete_id,
+    hugel_activities_2025.total_time_seconds,
+    hugel_activities_2025.efforts
+   FROM hugel_activities_2025
+UNION ALL
+ SELECT 'lite-das-hugel-2024'::text AS route_name,
+    lite_hugel_activities_2024.activity_id,
+    lite_hugel_activities_2024.athlete_id,
+    lite_hugel_activities_2024.total_time_seconds,
+    lite_hugel_activities_2024.efforts
+   FROM lite_hugel_activities_2024
+UNION ALL
+ SELECT 'lite-das-hugel'::text AS route_name,
+    lite_hugel_activities_2025.activity_id,
+    lite_hugel_activities_2025.athlete_id,
+    lite_hugel_activities_2025.total_time_seconds,
+    lite_hugel_activities_2025.efforts
+   FROM lite_hugel_activities_2025;
+
+COMMENT ON VIEW edition_results IS 'Results of every edition, tagged with the competitive route they were scored against. A new edition adds its view here.';
+
+CREATE TABLE leaderboard_opt_outs (
+    athlete_id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE leaderboard_opt_outs IS 'Athletes who do not want to be ranked on the eddington leaderboards.';
+
 CREATE TABLE maps (
     id text NOT NULL,
     polyline text NOT NULL,
@@ -465,6 +667,30 @@ CREATE TABLE maps (
     updated_at timestamp with time zone NOT NULL
 );
 
+CREATE TABLE segment_locations (
+    id bigint NOT NULL,
+    name text NOT NULL,
+    activity_type text NOT NULL,
+    distance double precision NOT NULL,
+    average_grade double precision NOT NULL,
+    maximum_grade double precision NOT NULL,
+    elevation_high double precision NOT NULL,
+    elevation_low double precision NOT NULL,
+    start_lat double precision NOT NULL,
+    start_lng double precision NOT NULL,
+    end_lat double precision NOT NULL,
+    end_lng double precision NOT NULL,
+    climb_category integer NOT NULL,
+    city text NOT NULL,
+    state text NOT NULL,
+    country text NOT NULL,
+    private boolean NOT NULL,
+    hazardous boolean NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE segment_locations IS 'Where every known segment is, from the segment summaries of fetched efforts. Lighter than segments, which are only the fully loaded route segments.';
+
 CREATE TABLE segments (
     id bigint NOT NULL,
     name text NOT NULL,
@@ -544,6 +770,19 @@ CREATE MATERIALIZED VIEW super_hugel_activities AS
           WHERE (competitive_routes.name = 'das-hugel'::text)))
   WITH NO DATA;
 
+CREATE TABLE unofficial_route_results (
+    route_name text NOT NULL,
+    activity_id bigint NOT NULL,
+    athlete_id bigint NOT NULL,
+    total_time_seconds double precision NOT NULL,
+    gps_segment_ids bigint[] NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE unofficial_route_results IS 'Route results that only finish with GPS matched efforts. Shown apart from the official results.';
+
+COMMENT ON COLUMN unofficial_route_results.gps_segment_ids IS 'The segments of the result that come from gps_segment_efforts.';
+
 CREATE TABLE webhook_dump (
     id uuid NOT NULL,
     recorded_at timestamp without time zone NOT NULL,
@@ -556,6 +795,15 @@ ALTER TABLE ONLY activity_detail
 ALTER TABLE ONLY activity_summary
     ADD CONSTRAINT activity_summary_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY athlete_daily_totals
+    ADD CONSTRAINT athlete_daily_totals_pkey PRIMARY KEY (athlete_id, day, sport);
+
+ALTER TABLE ONLY athlete_eddington_breakdowns
+    ADD CONSTRAINT athlete_eddington_breakdowns_pkey PRIMARY KEY (athlete_id, sport, year);
+
+ALTER TABLE ONLY athlete_eddington_metrics
+    ADD CONSTRAINT athlete_eddington_metrics_pkey PRIMARY KEY (athlete_id, metric);
+
 ALTER TABLE ONLY athlete_eddingtons
     ADD CONSTRAINT athlete_eddingtons_pkey PRIMARY KEY (athlete_id);
 
@@ -568,30 +816,60 @@ ALTER TABLE ONLY athlete_load
 ALTER TABLE ONLY athlete_logins
     ADD CONSTRAINT athletes_pkey PRIMARY KEY (athlete_id);
 
+ALTER TABLE ONLY athlete_year_reviews
+    ADD CONSTRAINT athlete_year_reviews_pkey PRIMARY KEY (athlete_id, year);
+
 ALTER TABLE ONLY athletes
     ADD CONSTRAINT athletes_pkey1 PRIMARY KEY (id);
 
 ALTER TABLE ONLY competitive_routes
     ADD CONSTRAINT competitive_routes_pkey PRIMARY KEY (name);
 
+ALTER TABLE ONLY eddington_events
+    ADD CONSTRAINT eddington_events_pkey PRIMARY KEY (athlete_id, eddington);
+
+ALTER TABLE ONLY event_group_rides
+    ADD CONSTRAINT event_group_rides_pkey PRIMARY KEY (route_name);
+
+ALTER TABLE ONLY event_heatmaps
+    ADD CONSTRAINT event_heatmaps_pkey PRIMARY KEY (route_name);
+
 ALTER TABLE ONLY failed_jobs
     ADD CONSTRAINT failed_jobs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY gps_segment_efforts
+    ADD CONSTRAINT gps_segment_efforts_pkey PRIMARY KEY (activity_id, segment_id);
+
 ALTER TABLE ONLY gue_jobs
     ADD CONSTRAINT gue_jobs_pkey PRIMARY KEY (job_id);
 
+ALTER TABLE ONLY heatmap_opt_outs
+    ADD CONSTRAINT heatmap_opt_outs_pkey PRIMARY KEY (athlete_id);
+
+ALTER TABLE ONLY hugel_discoveries
+    ADD CONSTRAINT hugel_discoveries_pkey PRIMARY KEY (activity_id);
+
+ALTER TABLE ONLY leaderboard_opt_outs
+    ADD CONSTRAINT leaderboard_opt_outs_pkey PRIMARY KEY (athlete_id);
+
 ALTER TABLE ONLY maps
     ADD CONSTRAINT maps_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY segment_efforts
     ADD CONSTRAINT segment_efforts_pk PRIMARY KEY (id);
 
+ALTER TABLE ONLY segment_locations
+    ADD CONSTRAINT segment_locations_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY segments
     ADD CONSTRAINT segments_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY starred_segments
     ADD CONSTRAINT starred_segments_pkey PRIMARY KEY (athlete_id, segment_id);
 
+ALTER TABLE ONLY unofficial_route_results
+    ADD CONSTRAINT unofficial_route_results_pkey PRIMARY KEY (route_name, activity_id);
+
 ALTER TABLE ONLY webhook_dump
     ADD CONSTRAINT webhook_dump_pkey PRIMARY KEY (id);
 
@@ -621,6 +899,15 @@ ALTER TABLE ONLY activity_detail
 ALTER TABLE ONLY activity_summary
     ADD CONSTRAINT activity_summary_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY athlete_daily_totals
+    ADD CONSTRAINT athlete_daily_totals_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY athlete_eddington_breakdowns
+    ADD CONSTRAINT athlete_eddington_breakdowns_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY athlete_eddington_metrics
+    ADD CONSTRAINT athlete_eddington_metrics_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY athlete_eddingtons
     ADD CONSTRAINT athlete_eddingtons_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
 
@@ -630,6 +917,12 @@ ALTER TABLE ONLY athlete_forward_load
 ALTER TABLE ONLY athlete_load
     ADD CONSTRAINT athlete_load_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY athlete_year_reviews
+    ADD CONSTRAINT athlete_year_reviews_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY eddington_events
+    ADD CONSTRAINT eddington_events_athlete_id_fkey FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY segment_efforts
     ADD CONSTRAINT segment_efforts_activities_id_fk FOREIGN KEY (activities_id) REFERENCES activity_detail(id) ON DELETE CASCADE;
 
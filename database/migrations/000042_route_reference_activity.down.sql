@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE competitive_routes DROP COLUMN IF EXISTS reference_activity_id;
+
+COMMIT;
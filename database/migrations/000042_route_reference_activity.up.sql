@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE competitive_routes ADD COLUMN reference_activity_id bigint;
+COMMENT ON COLUMN competitive_routes.reference_activity_id IS 'A finisher activity chosen by an admin, its track connects the segments of course exports.';
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS hugel_discoveries (
+    activity_id bigint NOT NULL PRIMARY KEY,
+    athlete_id bigint NOT NULL,
+    route_name text NOT NULL,
+    hits integer NOT NULL,
+    checkpoints integer NOT NULL,
+    score double precision NOT NULL,
+    discovered_at timestamp with time zone DEFAULT now() NOT NULL,
+    enqueued_at timestamp with time zone
+);
+
+COMMENT ON TABLE hugel_discoveries IS 'Stored ride summaries from past event dates whose track passes the route, found without the activity detail.';
+COMMENT ON COLUMN hugel_discoveries.score IS 'Fraction of the route''s segment starts and ends the summary track passes.';
+COMMENT ON COLUMN hugel_discoveries.enqueued_at IS 'When the detail fetch was queued, null while waiting for rate limit budget.';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS unofficial_route_results;
+DROP TABLE IF EXISTS gps_segment_efforts;
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS gps_segment_efforts (
+    activity_id bigint NOT NULL,
+    segment_id bigint NOT NULL,
+    athlete_id bigint NOT NULL,
+    source text DEFAULT 'gps_match' NOT NULL,
+    start_date timestamp with time zone NOT NULL,
+    elapsed_time double precision NOT NULL,
+    distance double precision NOT NULL,
+    start_index integer NOT NULL,
+    end_index integer NOT NULL,
+    coverage double precision NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (activity_id, segment_id)
+);
+
+COMMENT ON TABLE gps_segment_efforts IS 'Estimated efforts on segments Strava did not match, found from the activity''s GPS track. Never part of the official results.';
+COMMENT ON COLUMN gps_segment_efforts.source IS 'How the effort was found, always gps_match for now.';
+COMMENT ON COLUMN gps_segment_efforts.coverage IS 'Fraction of the segment the track follows within the match tolerance.';
+
+CREATE TABLE IF NOT EXISTS unofficial_route_results (
+    route_name text NOT NULL,
+    activity_id bigint NOT NULL,
+    athlete_id bigint NOT NULL,
+    total_time_seconds double precision NOT NULL,
+    gps_segment_ids bigint[] NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (route_name, activity_id)
+);
+
+COMMENT ON TABLE unofficial_route_results IS 'Route results that only finish with GPS matched efforts. Shown apart from the official results.';
+COMMENT ON COLUMN unofficial_route_results.gps_segment_ids IS 'The segments of the result that come from gps_segment_efforts.';
+
+COMMIT;
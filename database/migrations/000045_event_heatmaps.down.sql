@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS event_heatmaps;
+DROP TABLE IF EXISTS heatmap_opt_outs;
+
+COMMIT;
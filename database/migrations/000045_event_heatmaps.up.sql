@@ -0,0 +1,26 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS heatmap_opt_outs (
+    athlete_id bigint NOT NULL PRIMARY KEY,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE heatmap_opt_outs IS 'Athletes who do not want their rides in the event heatmaps.';
+
+CREATE TABLE IF NOT EXISTS event_heatmaps (
+    route_name text NOT NULL PRIMARY KEY,
+    min_lat double precision NOT NULL,
+    min_lng double precision NOT NULL,
+    max_lat double precision NOT NULL,
+    max_lng double precision NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    cells integer[] NOT NULL,
+    activities integer NOT NULL,
+    computed_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE event_heatmaps IS 'Density of the result tracks of an edition, rasterized into a grid.';
+COMMENT ON COLUMN event_heatmaps.cells IS 'Activities passing through each cell, row by row from the north west corner.';
+
+COMMIT;
@@ -0,0 +1,27 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS segment_locations (
+    id bigint NOT NULL PRIMARY KEY,
+    name text NOT NULL,
+    activity_type text NOT NULL,
+    distance double precision NOT NULL,
+    average_grade double precision NOT NULL,
+    maximum_grade double precision NOT NULL,
+    elevation_high double precision NOT NULL,
+    elevation_low double precision NOT NULL,
+    start_lat double precision NOT NULL,
+    start_lng double precision NOT NULL,
+    end_lat double precision NOT NULL,
+    end_lng double precision NOT NULL,
+    climb_category integer NOT NULL,
+    city text NOT NULL,
+    state text NOT NULL,
+    country text NOT NULL,
+    private boolean NOT NULL,
+    hazardous boolean NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE segment_locations IS 'Where every known segment is, from the segment summaries of fetched efforts. Lighter than segments, which are only the fully loaded route segments.';
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP TABLE IF EXISTS athlete_eddington_breakdowns;
+
+ALTER TABLE athlete_eddingtons
+    DROP COLUMN IF EXISTS kilometers_histogram,
+    DROP COLUMN IF EXISTS current_eddington_km,
+    DROP COLUMN IF EXISTS total_days;
+
+COMMENT ON COLUMN athlete_eddingtons.current_eddington IS NULL;
+
+COMMIT;
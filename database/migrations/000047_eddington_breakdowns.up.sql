@@ -0,0 +1,27 @@
+BEGIN;
+
+ALTER TABLE athlete_eddingtons
+    ADD COLUMN IF NOT EXISTS kilometers_histogram integer[] DEFAULT '{}'::integer[] NOT NULL,
+    ADD COLUMN IF NOT EXISTS current_eddington_km integer DEFAULT 0 NOT NULL,
+    ADD COLUMN IF NOT EXISTS total_days integer DEFAULT 0 NOT NULL;
+
+COMMENT ON COLUMN athlete_eddingtons.current_eddington IS 'Lifetime number in miles, from the total distance ridden each day.';
+COMMENT ON COLUMN athlete_eddingtons.total_days IS 'Days with at least one ride.';
+
+CREATE TABLE IF NOT EXISTS athlete_eddington_breakdowns (
+    athlete_id bigint NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+    sport text NOT NULL,
+    year integer NOT NULL,
+    days integer NOT NULL,
+    miles_histogram integer[] NOT NULL,
+    eddington_miles integer NOT NULL,
+    kilometers_histogram integer[] NOT NULL,
+    eddington_kilometers integer NOT NULL,
+    PRIMARY KEY (athlete_id, sport, year)
+);
+
+COMMENT ON TABLE athlete_eddington_breakdowns IS 'Eddington numbers per sport, for the lifetime and each calendar year.';
+COMMENT ON COLUMN athlete_eddington_breakdowns.sport IS 'all, ride or virtualride.';
+COMMENT ON COLUMN athlete_eddington_breakdowns.year IS '0 for the lifetime number.';
+
+COMMIT;
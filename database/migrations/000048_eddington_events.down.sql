@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS eddington_events;
+
+COMMIT;
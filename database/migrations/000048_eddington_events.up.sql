@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS eddington_events (
+    athlete_id bigint NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+    eddington integer NOT NULL,
+    previous integer NOT NULL,
+    reached_on timestamp with time zone NOT NULL,
+    activity_id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (athlete_id, eddington)
+);
+
+COMMENT ON TABLE eddington_events IS 'An athlete''s lifetime number in miles went up. Only increases found after the first calculation are recorded, a backfill is not news.';
+COMMENT ON COLUMN eddington_events.reached_on IS 'Local day of the ride that reached the number.';
+
+COMMIT;
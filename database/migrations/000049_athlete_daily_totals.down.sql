@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS athlete_daily_totals;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS athlete_daily_totals (
+    athlete_id bigint NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+    day date NOT NULL,
+    sport text NOT NULL,
+    distance double precision NOT NULL,
+    activities integer NOT NULL,
+    PRIMARY KEY (athlete_id, day, sport)
+);
+
+COMMENT ON TABLE athlete_daily_totals IS 'Ride distance per local calendar day, so a changed activity only touches its own day of the eddington numbers.';
+COMMENT ON COLUMN athlete_daily_totals.sport IS 'ride or virtualride.';
+
+COMMIT;
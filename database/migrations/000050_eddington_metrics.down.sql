@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS athlete_eddington_metrics;
+
+ALTER TABLE athlete_daily_totals
+    DROP COLUMN IF EXISTS elevation_gain,
+    DROP COLUMN IF EXISTS moving_time,
+    DROP COLUMN IF EXISTS hugel_segments;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+ALTER TABLE athlete_daily_totals
+    ADD COLUMN IF NOT EXISTS elevation_gain double precision DEFAULT 0 NOT NULL,
+    ADD COLUMN IF NOT EXISTS moving_time double precision DEFAULT 0 NOT NULL,
+    ADD COLUMN IF NOT EXISTS hugel_segments integer DEFAULT 0 NOT NULL;
+
+COMMENT ON COLUMN athlete_daily_totals.hugel_segments IS 'Das Hugel segments ridden, counted once per activity.';
+
+CREATE TABLE IF NOT EXISTS athlete_eddington_metrics (
+    athlete_id bigint NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+    metric text NOT NULL,
+    days integer NOT NULL,
+    histogram integer[] NOT NULL,
+    eddington integer NOT NULL,
+    PRIMARY KEY (athlete_id, metric)
+);
+
+COMMENT ON TABLE athlete_eddington_metrics IS 'Lifetime eddington numbers counted from something other than distance.';
+COMMENT ON COLUMN athlete_eddington_metrics.metric IS 'elevation_ft, elevation_m, hours or hugel_segments.';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS leaderboard_opt_outs;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS leaderboard_opt_outs (
+    athlete_id bigint NOT NULL PRIMARY KEY,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE leaderboard_opt_outs IS 'Athletes who do not want to be ranked on the eddington leaderboards.';
+
+COMMIT;
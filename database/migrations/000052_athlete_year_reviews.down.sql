@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS athlete_year_reviews;
+
+COMMIT;
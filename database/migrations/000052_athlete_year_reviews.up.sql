@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS athlete_year_reviews (
+    athlete_id bigint NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+    year integer NOT NULL,
+    report jsonb NOT NULL,
+    computed_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (athlete_id, year)
+);
+
+COMMENT ON TABLE athlete_year_reviews IS 'Year in review reports, precomputed because they read every activity of the year and the hugel boards.';
+COMMENT ON COLUMN athlete_year_reviews.report IS 'The report as served by the api.';
+
+COMMIT;
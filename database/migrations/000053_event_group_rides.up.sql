@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS event_group_rides (
+    route_name text NOT NULL PRIMARY KEY,
+    groups jsonb NOT NULL,
+    seen bigint[] NOT NULL,
+    computed_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE event_group_rides IS 'Results of an edition that were ridden together.';
+COMMENT ON COLUMN event_group_rides.groups IS 'Activity ids of each group, a list of lists.';
+COMMENT ON COLUMN event_group_rides.seen IS 'Activity ids already compared against the field, new results are only compared from here.';
+
+COMMIT;
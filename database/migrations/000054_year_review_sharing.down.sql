@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE athlete_year_reviews DROP COLUMN IF EXISTS shared;
+
+COMMIT;
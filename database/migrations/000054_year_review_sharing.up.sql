@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE athlete_year_reviews ADD COLUMN IF NOT EXISTS shared boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN athlete_year_reviews.shared IS 'The athlete chose to share the year card publicly.';
+
+COMMIT;
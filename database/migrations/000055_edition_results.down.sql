@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP VIEW IF EXISTS edition_results;
+
+COMMIT;
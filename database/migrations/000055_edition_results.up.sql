@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE VIEW edition_results AS
+SELECT 'das-hugel-2023'::text AS route_name, activity_id, athlete_id, total_time_seconds, efforts FROM hugel_activities_2023
+UNION ALL
+SELECT 'das-hugel-2024'::text AS route_name, activity_id, athlete_id, total_time_seconds, efforts FROM hugel_activities_2024
+UNION ALL
+SELECT 'das-hugel'::text AS route_name, activity_id, athlete_id, total_time_seconds, efforts FROM hugel_activities_2025
+UNION ALL
+SELECT 'lite-das-hugel-2024'::text AS route_name, activity_id, athlete_id, total_time_seconds, efforts FROM lite_hugel_activities_2024
+UNION ALL
+SELECT 'lite-das-hugel'::text AS route_name, activity_id, athlete_id, total_time_seconds, efforts FROM lite_hugel_activities_2025;
+
+COMMENT ON VIEW edition_results IS 'Results of every edition, tagged with the competitive route they were scored against. A new edition adds its view here.';
+
+COMMIT;
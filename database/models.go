@@ -176,12 +176,55 @@ type Athlete struct {
 	ProfilePicLinkMedium  string             `db:"profile_pic_link_medium" json:"profile_pic_link_medium"`
 }
 
+// Ride distance per local calendar day, so a changed activity only touches its own day of the eddington numbers.
+type AthleteDailyTotal struct {
+	AthleteID int64       `db:"athlete_id" json:"athlete_id"`
+	Day       pgtype.Date `db:"day" json:"day"`
+	// ride or virtualride.
+	Sport         string  `db:"sport" json:"sport"`
+	Distance      float64 `db:"distance" json:"distance"`
+	Activities    int32   `db:"activities" json:"activities"`
+	ElevationGain float64 `db:"elevation_gain" json:"elevation_gain"`
+	MovingTime    float64 `db:"moving_time" json:"moving_time"`
+	// Das Hugel segments ridden, counted once per activity.
+	HugelSegments int32 `db:"hugel_segments" json:"hugel_segments"`
+}
+
 type AthleteEddington struct {
-	AthleteID        int64              `db:"athlete_id" json:"athlete_id"`
-	MilesHistogram   []int32            `db:"miles_histogram" json:"miles_histogram"`
-	CurrentEddington int32              `db:"current_eddington" json:"current_eddington"`
-	LastCalculated   pgtype.Timestamptz `db:"last_calculated" json:"last_calculated"`
-	TotalActivities  int32              `db:"total_activities" json:"total_activities"`
+	AthleteID      int64   `db:"athlete_id" json:"athlete_id"`
+	MilesHistogram []int32 `db:"miles_histogram" json:"miles_histogram"`
+	// Lifetime number in miles, from the total distance ridden each day.
+	CurrentEddington    int32              `db:"current_eddington" json:"current_eddington"`
+	LastCalculated      pgtype.Timestamptz `db:"last_calculated" json:"last_calculated"`
+	TotalActivities     int32              `db:"total_activities" json:"total_activities"`
+	KilometersHistogram []int32            `db:"kilometers_histogram" json:"kilometers_histogram"`
+	CurrentEddingtonKm  int32              `db:"current_eddington_km" json:"current_eddington_km"`
+	// Days with at least one ride.
+	TotalDays int32 `db:"total_days" json:"total_days"`
+}
+
+// Eddington numbers per sport, for the lifetime and each calendar year.
+type AthleteEddingtonBreakdown struct {
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	// all, ride or virtualride.
+	Sport string `db:"sport" json:"sport"`
+	// 0 for the lifetime number.
+	Year                int32   `db:"year" json:"year"`
+	Days                int32   `db:"days" json:"days"`
+	MilesHistogram      []int32 `db:"miles_histogram" json:"miles_histogram"`
+	EddingtonMiles      int32   `db:"eddington_miles" json:"eddington_miles"`
+	KilometersHistogram []int32 `db:"kilometers_histogram" json:"kilometers_histogram"`
+	EddingtonKilometers int32   `db:"eddington_kilometers" json:"eddington_kilometers"`
+}
+
+// Lifetime eddington numbers counted from something other than distance.
+type AthleteEddingtonMetric struct {
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	// elevation_ft, elevation_m, hours or hugel_segments.
+	Metric    string  `db:"metric" json:"metric"`
+	Days      int32   `db:"days" json:"days"`
+	Histogram []int32 `db:"histogram" json:"histogram"`
+	Eddington int32   `db:"eddington" json:"eddington"`
 }
 
 // Tracks loading athlete activities. Must be an authenticated athlete.
@@ -239,11 +282,69 @@ type AthleteLogin struct {
 	ID                pgtype.UUID        `db:"id" json:"id"`
 }
 
+// Year in review reports, precomputed because they read every activity of the year and the hugel boards.
+type AthleteYearReview struct {
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	Year      int32 `db:"year" json:"year"`
+	// The report as served by the api.
+	Report     []byte             `db:"report" json:"report"`
+	ComputedAt pgtype.Timestamptz `db:"computed_at" json:"computed_at"`
+	// The athlete chose to share the year card publicly.
+	Shared bool `db:"shared" json:"shared"`
+}
+
 type CompetitiveRoute struct {
 	Name        string  `db:"name" json:"name"`
 	DisplayName string  `db:"display_name" json:"display_name"`
 	Description string  `db:"description" json:"description"`
 	Segments    []int64 `db:"segments" json:"segments"`
+	// A finisher activity chosen by an admin, its track connects the segments of course exports.
+	ReferenceActivityID pgtype.Int8 `db:"reference_activity_id" json:"reference_activity_id"`
+}
+
+// An athlete's lifetime number in miles went up. Only increases found after the first calculation are recorded, a backfill is not news.
+type EddingtonEvent struct {
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	Eddington int32 `db:"eddington" json:"eddington"`
+	Previous  int32 `db:"previous" json:"previous"`
+	// Local day of the ride that reached the number.
+	ReachedOn  pgtype.Timestamptz `db:"reached_on" json:"reached_on"`
+	ActivityID int64              `db:"activity_id" json:"activity_id"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+// Results of every edition, tagged with the competitive route they were scored against. A new edition adds its view here.
+type EditionResult struct {
+	RouteName        string `db:"route_name" json:"route_name"`
+	ActivityID       int64  `db:"activity_id" json:"activity_id"`
+	AthleteID        int64  `db:"athlete_id" json:"athlete_id"`
+	TotalTimeSeconds int64  `db:"total_time_seconds" json:"total_time_seconds"`
+	Efforts          []byte `db:"efforts" json:"efforts"`
+}
+
+// Results of an edition that were ridden together.
+type EventGroupRide struct {
+	RouteName string `db:"route_name" json:"route_name"`
+	// Activity ids of each group, a list of lists.
+	Groups []byte `db:"groups" json:"groups"`
+	// Activity ids already compared against the field, new results are only compared from here.
+	Seen       []int64            `db:"seen" json:"seen"`
+	ComputedAt pgtype.Timestamptz `db:"computed_at" json:"computed_at"`
+}
+
+// Density of the result tracks of an edition, rasterized into a grid.
+type EventHeatmap struct {
+	RouteName string  `db:"route_name" json:"route_name"`
+	MinLat    float64 `db:"min_lat" json:"min_lat"`
+	MinLng    float64 `db:"min_lng" json:"min_lng"`
+	MaxLat    float64 `db:"max_lat" json:"max_lat"`
+	MaxLng    float64 `db:"max_lng" json:"max_lng"`
+	Width     int32   `db:"width" json:"width"`
+	Height    int32   `db:"height" json:"height"`
+	// Activities passing through each cell, row by row from the north west corner.
+	Cells      []int32            `db:"cells" json:"cells"`
+	Activities int32              `db:"activities" json:"activities"`
+	ComputedAt pgtype.Timestamptz `db:"computed_at" json:"computed_at"`
 }
 
 // A table to store failed job information for potential debugging.
@@ -255,6 +356,23 @@ type FailedJob struct {
 	Raw string `db:"raw" json:"raw"`
 }
 
+// Estimated efforts on segments Strava did not match, found from the activity's GPS track. Never part of the official results.
+type GpsSegmentEffort struct {
+	ActivityID int64 `db:"activity_id" json:"activity_id"`
+	SegmentID  int64 `db:"segment_id" json:"segment_id"`
+	AthleteID  int64 `db:"athlete_id" json:"athlete_id"`
+	// How the effort was found, always gps_match for now.
+	Source      string             `db:"source" json:"source"`
+	StartDate   pgtype.Timestamptz `db:"start_date" json:"start_date"`
+	ElapsedTime float64            `db:"elapsed_time" json:"elapsed_time"`
+	Distance    float64            `db:"distance" json:"distance"`
+	StartIndex  int32              `db:"start_index" json:"start_index"`
+	EndIndex    int32              `db:"end_index" json:"end_index"`
+	// Fraction of the segment the track follows within the match tolerance.
+	Coverage  float64            `db:"coverage" json:"coverage"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type GueJob struct {
 	JobID      string             `db:"job_id" json:"job_id"`
 	Priority   int16              `db:"priority" json:"priority"`
@@ -268,6 +386,12 @@ type GueJob struct {
 	UpdatedAt  pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
+// Athletes who do not want their rides in the event heatmaps.
+type HeatmapOptOut struct {
+	AthleteID int64              `db:"athlete_id" json:"athlete_id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type HugelActivities2023 struct {
 	ActivityID       int64       `db:"activity_id" json:"activity_id"`
 	AthleteID        int64       `db:"athlete_id" json:"athlete_id"`
@@ -300,6 +424,26 @@ type HugelActivity struct {
 	Efforts          HugelSegmentEfforts `db:"efforts" json:"efforts"`
 }
 
+// Stored ride summaries from past event dates whose track passes the route, found without the activity detail.
+type HugelDiscovery struct {
+	ActivityID  int64  `db:"activity_id" json:"activity_id"`
+	AthleteID   int64  `db:"athlete_id" json:"athlete_id"`
+	RouteName   string `db:"route_name" json:"route_name"`
+	Hits        int32  `db:"hits" json:"hits"`
+	Checkpoints int32  `db:"checkpoints" json:"checkpoints"`
+	// Fraction of the route's segment starts and ends the summary track passes.
+	Score        float64            `db:"score" json:"score"`
+	DiscoveredAt pgtype.Timestamptz `db:"discovered_at" json:"discovered_at"`
+	// When the detail fetch was queued, null while waiting for rate limit budget.
+	EnqueuedAt pgtype.Timestamptz `db:"enqueued_at" json:"enqueued_at"`
+}
+
+// Athletes who do not want to be ranked on the eddington leaderboards.
+type LeaderboardOptOut struct {
+	AthleteID int64              `db:"athlete_id" json:"athlete_id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type LiteHugelActivities2024 struct {
 	ActivityID       int64       `db:"activity_id" json:"activity_id"`
 	AthleteID        int64       `db:"athlete_id" json:"athlete_id"`
@@ -385,6 +529,29 @@ type SegmentEffort struct {
 	ActivitiesID int64 `db:"activities_id" json:"activities_id"`
 }
 
+// Where every known segment is, from the segment summaries of fetched efforts. Lighter than segments, which are only the fully loaded route segments.
+type SegmentLocation struct {
+	ID            int64              `db:"id" json:"id"`
+	Name          string             `db:"name" json:"name"`
+	ActivityType  string             `db:"activity_type" json:"activity_type"`
+	Distance      float64            `db:"distance" json:"distance"`
+	AverageGrade  float64            `db:"average_grade" json:"average_grade"`
+	MaximumGrade  float64            `db:"maximum_grade" json:"maximum_grade"`
+	ElevationHigh float64            `db:"elevation_high" json:"elevation_high"`
+	ElevationLow  float64            `db:"elevation_low" json:"elevation_low"`
+	StartLat      float64            `db:"start_lat" json:"start_lat"`
+	StartLng      float64            `db:"start_lng" json:"start_lng"`
+	EndLat        float64            `db:"end_lat" json:"end_lat"`
+	EndLng        float64            `db:"end_lng" json:"end_lng"`
+	ClimbCategory int32              `db:"climb_category" json:"climb_category"`
+	City          string             `db:"city" json:"city"`
+	State         string             `db:"state" json:"state"`
+	Country       string             `db:"country" json:"country"`
+	Private       bool               `db:"private" json:"private"`
+	Hazardous     bool               `db:"hazardous" json:"hazardous"`
+	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type StarredSegment struct {
 	AthleteID int64              `db:"athlete_id" json:"athlete_id"`
 	SegmentID int64              `db:"segment_id" json:"segment_id"`
@@ -399,6 +566,17 @@ type SuperHugelActivity struct {
 	Efforts          []byte      `db:"efforts" json:"efforts"`
 }
 
+// Route results that only finish with GPS matched efforts. Shown apart from the official results.
+type UnofficialRouteResult struct {
+	RouteName        string  `db:"route_name" json:"route_name"`
+	ActivityID       int64   `db:"activity_id" json:"activity_id"`
+	AthleteID        int64   `db:"athlete_id" json:"athlete_id"`
+	TotalTimeSeconds float64 `db:"total_time_seconds" json:"total_time_seconds"`
+	// The segments of the result that come from gps_segment_efforts.
+	GpsSegmentIds []int64            `db:"gps_segment_ids" json:"gps_segment_ids"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type WebhookDump struct {
 	ID         pgtype.UUID      `db:"id" json:"id"`
 	RecordedAt pgtype.Timestamp `db:"recorded_at" json:"recorded_at"`
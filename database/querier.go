@@ -11,41 +11,113 @@ import (
 )
 
 type sqlcQuerier interface {
+	// ActivityAthleteCounts is how many athletes Strava saw on each activity,
+	// including its own.
+	ActivityAthleteCounts(ctx context.Context, activityIds []int64) ([]ActivityAthleteCountsRow, error)
+	// ActivityCalendar is the activities of an athlete started in the local time
+	// range, with the power and effort from the detail when it has been fetched.
+	ActivityCalendar(ctx context.Context, arg ActivityCalendarParams) ([]ActivityCalendarRow, error)
 	AllCompetitiveRoutes(ctx context.Context) ([]CompetitiveRoute, error)
 	AllEddingtons(ctx context.Context) ([]AllEddingtonsRow, error)
+	AthleteDailyTotals(ctx context.Context, arg AthleteDailyTotalsParams) ([]AthleteDailyTotal, error)
+	AthleteHasDailyTotals(ctx context.Context, athleteID int64) (bool, error)
 	AthleteHugelActivites(ctx context.Context, athleteID int64) ([]AthleteHugelActivitesRow, error)
 	AthleteSyncedActivities(ctx context.Context, arg AthleteSyncedActivitiesParams) ([]AthleteSyncedActivitiesRow, error)
+	// AthleteWeights are the weights in kilograms Strava has for the athletes,
+	// zero when the athlete has not set one.
+	AthleteWeights(ctx context.Context, athleteIds []int64) ([]AthleteWeightsRow, error)
+	// AthletesNeedingEddington are due a full recalculation. Changed activities
+	// update the numbers as they happen, this only catches drift.
 	AthletesNeedingEddington(ctx context.Context) ([]AthletesNeedingEddingtonRow, error)
+	// AthletesNeedingYearReview have ridden in the year, and have no report or
+	// one older than a week.
+	AthletesNeedingYearReview(ctx context.Context, year int32) ([]int64, error)
 	// BestRouteEfforts returns all activities that have efforts on all the provided segments.
 	// The returned activities include the best effort for each segment.
 	// This isn't used in the app, but is the foundation for the hugel view.
 	BestRouteEfforts(ctx context.Context, expectedSegments []int64) ([]BestRouteEffortsRow, error)
 	DeleteActivity(ctx context.Context, id int64) (ActivitySummary, error)
+	DeleteAthleteDailyTotals(ctx context.Context, arg DeleteAthleteDailyTotalsParams) error
+	DeleteAthleteEddingtonBreakdowns(ctx context.Context, athleteID int64) error
 	DeleteAthleteLogin(ctx context.Context, athleteID int64) error
+	DeleteHeatmapOptOut(ctx context.Context, athleteID int64) error
+	DeleteLeaderboardOptOut(ctx context.Context, athleteID int64) error
 	DeleteWebhookDump(ctx context.Context, id pgtype.UUID) error
 	EddingtonActivities(ctx context.Context, athleteID int64) ([]EddingtonActivitiesRow, error)
+	// EddingtonLeaderboard is the number of all rides for every athlete, for the
+	// lifetime and each year, with their profile. Athletes who opted out of the
+	// leaderboards are left out.
+	EddingtonLeaderboard(ctx context.Context) ([]EddingtonLeaderboardRow, error)
+	// EddingtonMetricLeaderboard ranks every athlete with the metric, ties share
+	// a rank.
+	EddingtonMetricLeaderboard(ctx context.Context, metric string) ([]EddingtonMetricLeaderboardRow, error)
+	EventGroupRides(ctx context.Context, routeName string) (EventGroupRide, error)
+	EventHeatmap(ctx context.Context, routeName string) (EventHeatmap, error)
+	// FillSegmentLocations copies the loaded route segments that are not indexed
+	// yet, they are already known without asking Strava.
+	FillSegmentLocations(ctx context.Context) (int64, error)
 	GetActivityDetail(ctx context.Context, id int64) (ActivityDetail, error)
+	GetActivityMap(ctx context.Context, activityID int64) (Map, error)
+	// GetActivitySegmentEfforts returns the efforts of an activity in the order
+	// they were ridden.
+	GetActivitySegmentEfforts(ctx context.Context, activityID int64) ([]SegmentEffort, error)
 	GetActivitySummariesByDate(ctx context.Context, startDate pgtype.Timestamptz) ([]ActivitySummary, error)
 	GetActivitySummary(ctx context.Context, id int64) (ActivitySummary, error)
+	GetActivitySummaryPolylines(ctx context.Context, activityIds []int64) ([]GetActivitySummaryPolylinesRow, error)
 	GetAthlete(ctx context.Context, athleteID int64) (Athlete, error)
 	GetAthleteEddington(ctx context.Context, athleteID int64) (AthleteEddington, error)
+	GetAthleteEddingtonBreakdowns(ctx context.Context, athleteID int64) ([]AthleteEddingtonBreakdown, error)
+	GetAthleteEddingtonMetrics(ctx context.Context, athleteID int64) ([]AthleteEddingtonMetric, error)
 	GetAthleteFull(ctx context.Context, athleteID int64) (GetAthleteFullRow, error)
 	GetAthleteLoad(ctx context.Context, athleteID int64) (AthleteForwardLoad, error)
 	GetAthleteLoadDetailed(ctx context.Context, athleteID int64) (GetAthleteLoadDetailedRow, error)
 	GetAthleteLogin(ctx context.Context, athleteID int64) (AthleteLogin, error)
 	GetAthleteLoginFull(ctx context.Context, athleteID int64) (GetAthleteLoginFullRow, error)
 	GetAthleteNeedsForwardLoad(ctx context.Context) ([]GetAthleteNeedsForwardLoadRow, error)
+	GetAthleteYearReview(ctx context.Context, arg GetAthleteYearReviewParams) (AthleteYearReview, error)
 	GetBestPersonalSegmentEffort(ctx context.Context, arg GetBestPersonalSegmentEffortParams) ([]SegmentEffort, error)
 	GetCompetitiveRoute(ctx context.Context, routeName string) (GetCompetitiveRouteRow, error)
+	GetCompetitiveRouteByName(ctx context.Context, routeName string) (CompetitiveRoute, error)
 	GetDeleteActivityWebhooks(ctx context.Context) ([]WebhookDump, error)
+	GetGPSSegmentEfforts(ctx context.Context, activityID int64) ([]GpsSegmentEffort, error)
+	// GetPersonalSegmentEfforts returns every effort of an athlete on the given
+	// segments, newest first.
+	GetPersonalSegmentEfforts(ctx context.Context, arg GetPersonalSegmentEffortsParams) ([]SegmentEffort, error)
+	GetSegmentLocations(ctx context.Context, segmentIds []int64) ([]SegmentLocation, error)
 	GetSegments(ctx context.Context, segmentIds []int64) ([]GetSegmentsRow, error)
+	// GetSummaryPolylinesBetween returns the summary track of every ride that
+	// started in the window.
+	GetSummaryPolylinesBetween(ctx context.Context, arg GetSummaryPolylinesBetweenParams) ([]GetSummaryPolylinesBetweenRow, error)
+	HeatmapOptedOut(ctx context.Context, athleteID int64) (bool, error)
+	// HeatmapPolylines returns the summary tracks of the activities that may be
+	// shown on a heatmap. Private activities and athletes who opted out are left
+	// out.
+	HeatmapPolylines(ctx context.Context, activityIds []int64) ([]HeatmapPolylinesRow, error)
+	HugelDiscoveries(ctx context.Context) ([]HugelDiscovery, error)
+	// HugelDiscoveryCandidates returns the summary track of every ride in the
+	// window that was never fetched in detail and is not already queued.
+	HugelDiscoveryCandidates(ctx context.Context, arg HugelDiscoveryCandidatesParams) ([]HugelDiscoveryCandidatesRow, error)
 	// This query needs to be simplified
 	HugelLeaderboard(ctx context.Context, arg HugelLeaderboardParams) ([]HugelLeaderboardRow, error)
 	IncrementActivitySummaryDownload(ctx context.Context, id int64) error
+	// InsertAthleteDailyTotals sums the rides of the days from the activities,
+	// or of every day. The day is the local day the ride started.
+	InsertAthleteDailyTotals(ctx context.Context, arg InsertAthleteDailyTotalsParams) (int64, error)
+	InsertAthleteEddingtonBreakdown(ctx context.Context, arg InsertAthleteEddingtonBreakdownParams) error
+	// InsertEddingtonEvent records a number going up. A number that is reached
+	// again, after a ride was deleted and redone, keeps its first event.
+	InsertEddingtonEvent(ctx context.Context, arg InsertEddingtonEventParams) (int64, error)
 	InsertFailedJob(ctx context.Context, rawJson string) (FailedJob, error)
+	InsertHeatmapOptOut(ctx context.Context, athleteID int64) error
+	InsertLeaderboardOptOut(ctx context.Context, athleteID int64) error
 	InsertWebhookDump(ctx context.Context, rawJson string) (WebhookDump, error)
+	LeaderboardOptedOut(ctx context.Context, athleteID int64) (bool, error)
 	LoadedSegments(ctx context.Context) ([]LoadedSegmentsRow, error)
+	MarkHugelDiscoveriesEnqueued(ctx context.Context, activityIds []int64) error
 	MissingHugelSegments(ctx context.Context, activityID int64) ([]Segment, error)
+	// MissingSegmentLocations returns segments with efforts that are not indexed,
+	// the most ridden first.
+	MissingSegmentLocations(ctx context.Context, maxSegments int32) ([]int64, error)
 	MissingSegments(ctx context.Context, activitiesID int64) ([]string, error)
 	NeedsARefresh(ctx context.Context) ([]NeedsARefreshRow, error)
 	RefreshHugel2023Activities(ctx context.Context) error
@@ -54,22 +126,55 @@ type sqlcQuerier interface {
 	RefreshHugelLite2024Activities(ctx context.Context) error
 	RefreshHugelLite2025Activities(ctx context.Context) error
 	RefreshSuperHugelActivities(ctx context.Context) error
+	// RouteActivityResults returns every edition result of the given activities.
+	// Each row is tagged with the competitive route it was scored against, so
+	// results from different editions can be aligned by segment.
+	RouteActivityResults(ctx context.Context, activityIds []int64) ([]RouteActivityResultsRow, error)
+	// RouteFatigueSamples pairs every climb of the given routes' results with the
+	// athlete's efforts on that climb from before the result was ridden. The ratio
+	// between the day and the form those efforts show is how much slower riders
+	// climb on the day.
+	RouteFatigueSamples(ctx context.Context, routeNames []string) ([]RouteFatigueSamplesRow, error)
+	// SegmentElevationGains is the climb of each segment, from its lowest to its
+	// highest point, in meters.
+	SegmentElevationGains(ctx context.Context, segmentIds []int64) ([]SegmentElevationGainsRow, error)
+	// SegmentLocationIndex is the minimum needed to search segments by location.
+	SegmentLocationIndex(ctx context.Context) ([]SegmentLocationIndexRow, error)
+	SetAthleteYearReviewShared(ctx context.Context, arg SetAthleteYearReviewSharedParams) (int64, error)
+	SetCompetitiveRouteReference(ctx context.Context, arg SetCompetitiveRouteReferenceParams) (int64, error)
 	StarSegments(ctx context.Context, arg StarSegmentsParams) error
 	SuperHugelLeaderboard(ctx context.Context, athleteID interface{}) ([]SuperHugelLeaderboardRow, error)
 	TotalActivityDetailsCount(ctx context.Context) (int64, error)
 	TotalJobCount(ctx context.Context) (int64, error)
 	TotalRideActivitySummariesCount(ctx context.Context) (int64, error)
+	// UnofficialRouteResults returns the GPS matched results of a route, fastest
+	// first. Activities that have since become official results are left out.
+	UnofficialRouteResults(ctx context.Context, routeName string) ([]UnofficialRouteResultsRow, error)
 	UpdateActivityName(ctx context.Context, arg UpdateActivityNameParams) error
 	UpdateActivityType(ctx context.Context, arg UpdateActivityTypeParams) error
 	UpsertActivityDetail(ctx context.Context, arg UpsertActivityDetailParams) (ActivityDetail, error)
 	UpsertActivitySummary(ctx context.Context, arg UpsertActivitySummaryParams) (ActivitySummary, error)
 	UpsertAthlete(ctx context.Context, arg UpsertAthleteParams) (Athlete, error)
 	UpsertAthleteEddington(ctx context.Context, arg UpsertAthleteEddingtonParams) (AthleteEddington, error)
+	UpsertAthleteEddingtonMetric(ctx context.Context, arg UpsertAthleteEddingtonMetricParams) error
 	UpsertAthleteForwardLoad(ctx context.Context, arg UpsertAthleteForwardLoadParams) (AthleteForwardLoad, error)
 	UpsertAthleteLogin(ctx context.Context, arg UpsertAthleteLoginParams) (AthleteLogin, error)
+	UpsertAthleteYearReview(ctx context.Context, arg UpsertAthleteYearReviewParams) error
+	UpsertEventGroupRides(ctx context.Context, arg UpsertEventGroupRidesParams) error
+	UpsertEventHeatmap(ctx context.Context, arg UpsertEventHeatmapParams) error
+	UpsertGPSSegmentEffort(ctx context.Context, arg UpsertGPSSegmentEffortParams) error
+	UpsertHugelDiscovery(ctx context.Context, arg UpsertHugelDiscoveryParams) error
 	UpsertMapData(ctx context.Context, arg UpsertMapDataParams) (Map, error)
 	UpsertSegment(ctx context.Context, arg UpsertSegmentParams) (Segment, error)
 	UpsertSegmentEffort(ctx context.Context, arg UpsertSegmentEffortParams) (SegmentEffort, error)
+	UpsertSegmentLocation(ctx context.Context, arg UpsertSegmentLocationParams) error
+	UpsertUnofficialRouteResult(ctx context.Context, arg UpsertUnofficialRouteResultParams) error
+	// YearReviewActivities are the public activities of every sport started in
+	// the local calendar year.
+	YearReviewActivities(ctx context.Context, arg YearReviewActivitiesParams) ([]YearReviewActivitiesRow, error)
+	// YearReviewSegments are the segments the athlete rode most in the year, on
+	// public activities.
+	YearReviewSegments(ctx context.Context, arg YearReviewSegmentsParams) ([]YearReviewSegmentsRow, error)
 }
 
 var _ sqlcQuerier = (*sqlQuerier)(nil)
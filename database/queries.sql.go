@@ -10,6 +10,125 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const activityAthleteCounts = `-- name: ActivityAthleteCounts :many
+SELECT
+	id, athlete_count
+FROM
+	activity_summary
+WHERE
+	id = ANY($1 :: bigint[])
+`
+
+type ActivityAthleteCountsRow struct {
+	ID           int64 `db:"id" json:"id"`
+	AthleteCount int32 `db:"athlete_count" json:"athlete_count"`
+}
+
+// ActivityAthleteCounts is how many athletes Strava saw on each activity,
+// including its own.
+func (q *sqlQuerier) ActivityAthleteCounts(ctx context.Context, activityIds []int64) ([]ActivityAthleteCountsRow, error) {
+	rows, err := q.db.Query(ctx, activityAthleteCounts, activityIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ActivityAthleteCountsRow
+	for rows.Next() {
+		var i ActivityAthleteCountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const activityCalendar = `-- name: ActivityCalendar :many
+SELECT
+	activity_summary.id,
+	activity_summary.start_date_local,
+	activity_summary.distance,
+	activity_summary.total_elevation_gain,
+	activity_summary.moving_time,
+	activity_summary.device_watts,
+	activity_summary.average_heartrate,
+	COALESCE(activity_detail.kilojoules, 0) :: double precision AS kilojoules,
+	COALESCE(activity_detail.suffer_score, 0) :: integer AS suffer_score,
+	COALESCE(activity_detail.average_watts, 0) :: double precision AS average_watts,
+	COALESCE(activity_detail.weighted_average_watts, 0) :: double precision AS weighted_average_watts
+FROM
+	activity_summary
+	LEFT JOIN
+		activity_detail
+		ON activity_detail.id = activity_summary.id
+WHERE
+	activity_summary.athlete_id = $1
+	AND activity_summary.start_date_local >= $2 :: timestamptz
+	AND activity_summary.start_date_local < $3 :: timestamptz
+ORDER BY
+	activity_summary.start_date_local
+`
+
+type ActivityCalendarParams struct {
+	AthleteID int64              `db:"athlete_id" json:"athlete_id"`
+	After     pgtype.Timestamptz `db:"after" json:"after"`
+	Before    pgtype.Timestamptz `db:"before" json:"before"`
+}
+
+type ActivityCalendarRow struct {
+	ID                   int64              `db:"id" json:"id"`
+	StartDateLocal       pgtype.Timestamptz `db:"start_date_local" json:"start_date_local"`
+	Distance             float64            `db:"distance" json:"distance"`
+	TotalElevationGain   float64            `db:"total_elevation_gain" json:"total_elevation_gain"`
+	MovingTime           float64            `db:"moving_time" json:"moving_time"`
+	DeviceWatts          bool               `db:"device_watts" json:"device_watts"`
+	AverageHeartrate     float64            `db:"average_heartrate" json:"average_heartrate"`
+	Kilojoules           float64            `db:"kilojoules" json:"kilojoules"`
+	SufferScore          int32              `db:"suffer_score" json:"suffer_score"`
+	AverageWatts         float64            `db:"average_watts" json:"average_watts"`
+	WeightedAverageWatts float64            `db:"weighted_average_watts" json:"weighted_average_watts"`
+}
+
+// ActivityCalendar is the activities of an athlete started in the local time
+// range, with the power and effort from the detail when it has been fetched.
+func (q *sqlQuerier) ActivityCalendar(ctx context.Context, arg ActivityCalendarParams) ([]ActivityCalendarRow, error) {
+	rows, err := q.db.Query(ctx, activityCalendar, arg.AthleteID, arg.After, arg.Before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ActivityCalendarRow
+	for rows.Next() {
+		var i ActivityCalendarRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartDateLocal,
+			&i.Distance,
+			&i.TotalElevationGain,
+			&i.MovingTime,
+			&i.DeviceWatts,
+			&i.AverageHeartrate,
+			&i.Kilojoules,
+			&i.SufferScore,
+			&i.AverageWatts,
+			&i.WeightedAverageWatts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteActivity = `-- name: DeleteActivity :one
 DELETE FROM
 	activity_summary
@@ -751,6 +870,45 @@ func (q *sqlQuerier) AthleteSyncedActivities(ctx context.Context, arg AthleteSyn
 	return items, nil
 }
 
+const athleteWeights = `-- name: AthleteWeights :many
+SELECT
+	id, weight
+FROM
+	athletes
+WHERE
+	id = ANY($1 :: bigint[])
+`
+
+type AthleteWeightsRow struct {
+	ID     int64   `db:"id" json:"id"`
+	Weight float64 `db:"weight" json:"weight"`
+}
+
+// AthleteWeights are the weights in kilograms Strava has for the athletes,
+// zero when the athlete has not set one.
+func (q *sqlQuerier) AthleteWeights(ctx context.Context, athleteIds []int64) ([]AthleteWeightsRow, error) {
+	rows, err := q.db.Query(ctx, athleteWeights, athleteIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AthleteWeightsRow
+	for rows.Next() {
+		var i AthleteWeightsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Weight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteAthleteLogin = `-- name: DeleteAthleteLogin :exec
 DELETE FROM athlete_logins WHERE athlete_id = $1
 `
@@ -1272,6 +1430,145 @@ func (q *sqlQuerier) UpsertAthleteLogin(ctx context.Context, arg UpsertAthleteLo
 	return i, err
 }
 
+const hugelDiscoveries = `-- name: HugelDiscoveries :many
+SELECT
+	activity_id, athlete_id, route_name, hits, checkpoints, score, discovered_at, enqueued_at
+FROM
+	hugel_discoveries
+ORDER BY
+	score DESC, activity_id
+`
+
+func (q *sqlQuerier) HugelDiscoveries(ctx context.Context) ([]HugelDiscovery, error) {
+	rows, err := q.db.Query(ctx, hugelDiscoveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HugelDiscovery
+	for rows.Next() {
+		var i HugelDiscovery
+		if err := rows.Scan(
+			&i.ActivityID,
+			&i.AthleteID,
+			&i.RouteName,
+			&i.Hits,
+			&i.Checkpoints,
+			&i.Score,
+			&i.DiscoveredAt,
+			&i.EnqueuedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hugelDiscoveryCandidates = `-- name: HugelDiscoveryCandidates :many
+SELECT
+	activity_summary.id AS activity_id,
+	activity_summary.athlete_id,
+	maps.summary_polyline
+FROM
+	activity_summary
+INNER JOIN
+	maps ON activity_summary.map_id = maps.id
+LEFT JOIN
+	activity_detail ON activity_summary.id = activity_detail.id
+LEFT JOIN
+	hugel_discoveries ON activity_summary.id = hugel_discoveries.activity_id
+WHERE
+	activity_detail.id IS NULL
+	AND hugel_discoveries.enqueued_at IS NULL
+	AND activity_summary.start_date >= $1
+	AND activity_summary.start_date < $2
+	AND (activity_summary.sport_type ILIKE '%ride%' OR activity_summary.sport_type ILIKE '%bike%')
+	AND maps.summary_polyline != ''
+`
+
+type HugelDiscoveryCandidatesParams struct {
+	StartDate pgtype.Timestamptz `db:"start_date" json:"start_date"`
+	EndDate   pgtype.Timestamptz `db:"end_date" json:"end_date"`
+}
+
+type HugelDiscoveryCandidatesRow struct {
+	ActivityID      int64  `db:"activity_id" json:"activity_id"`
+	AthleteID       int64  `db:"athlete_id" json:"athlete_id"`
+	SummaryPolyline string `db:"summary_polyline" json:"summary_polyline"`
+}
+
+// HugelDiscoveryCandidates returns the summary track of every ride in the
+// window that was never fetched in detail and is not already queued.
+func (q *sqlQuerier) HugelDiscoveryCandidates(ctx context.Context, arg HugelDiscoveryCandidatesParams) ([]HugelDiscoveryCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, hugelDiscoveryCandidates, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HugelDiscoveryCandidatesRow
+	for rows.Next() {
+		var i HugelDiscoveryCandidatesRow
+		if err := rows.Scan(
+			&i.ActivityID,
+			&i.AthleteID,
+			&i.SummaryPolyline,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markHugelDiscoveriesEnqueued = `-- name: MarkHugelDiscoveriesEnqueued :exec
+UPDATE
+	hugel_discoveries
+SET
+	enqueued_at = Now()
+WHERE
+	activity_id = ANY($1 :: bigint[])
+`
+
+func (q *sqlQuerier) MarkHugelDiscoveriesEnqueued(ctx context.Context, activityIds []int64) error {
+	_, err := q.db.Exec(ctx, markHugelDiscoveriesEnqueued, activityIds)
+	return err
+}
+
+const upsertHugelDiscovery = `-- name: UpsertHugelDiscovery :exec
+INSERT INTO
+	hugel_discoveries(activity_id, athlete_id, route_name, hits, checkpoints, score)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT
+	(activity_id)
+	DO UPDATE SET
+		route_name = $3,
+		hits = $4,
+		checkpoints = $5,
+		score = $6
+`
+
+type UpsertHugelDiscoveryParams struct {
+	ActivityID  int64   `db:"activity_id" json:"activity_id"`
+	AthleteID   int64   `db:"athlete_id" json:"athlete_id"`
+	RouteName   string  `db:"route_name" json:"route_name"`
+	Hits        int32   `db:"hits" json:"hits"`
+	Checkpoints int32   `db:"checkpoints" json:"checkpoints"`
+	Score       float64 `db:"score" json:"score"`
+}
+
+func (q *sqlQuerier) UpsertHugelDiscovery(ctx context.Context, arg UpsertHugelDiscoveryParams) error {
+	_, err := q.db.Exec(ctx, upsertHugelDiscovery, arg.ActivityID, arg.AthleteID, arg.RouteName, arg.Hits, arg.Checkpoints, arg.Score)
+	return err
+}
+
 const allEddingtons = `-- name: AllEddingtons :many
 SELECT
 	athlete_eddingtons.athlete_id,
@@ -1307,6 +1604,63 @@ func (q *sqlQuerier) AllEddingtons(ctx context.Context) ([]AllEddingtonsRow, err
 	return items, nil
 }
 
+const athleteDailyTotals = `-- name: AthleteDailyTotals :many
+SELECT
+	*
+FROM
+	athlete_daily_totals
+WHERE
+	athlete_id = $1 AND
+	day = ANY($2::date[])
+`
+
+type AthleteDailyTotalsParams struct {
+	AthleteID int64         `db:"athlete_id" json:"athlete_id"`
+	Days      []pgtype.Date `db:"days" json:"days"`
+}
+
+func (q *sqlQuerier) AthleteDailyTotals(ctx context.Context, arg AthleteDailyTotalsParams) ([]AthleteDailyTotal, error) {
+	rows, err := q.db.Query(ctx, athleteDailyTotals, arg.AthleteID, arg.Days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AthleteDailyTotal
+	for rows.Next() {
+		var i AthleteDailyTotal
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.Day,
+			&i.Sport,
+			&i.Distance,
+			&i.Activities,
+			&i.ElevationGain,
+			&i.MovingTime,
+			&i.HugelSegments,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const athleteHasDailyTotals = `-- name: AthleteHasDailyTotals :one
+SELECT EXISTS(
+	SELECT 1 FROM athlete_daily_totals WHERE athlete_id = $1
+)
+`
+
+func (q *sqlQuerier) AthleteHasDailyTotals(ctx context.Context, athleteID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, athleteHasDailyTotals, athleteID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const athletesNeedingEddington = `-- name: AthletesNeedingEddington :many
 SELECT
 	athlete_logins.athlete_id, athlete_eddingtons.last_calculated
@@ -1325,6 +1679,8 @@ type AthletesNeedingEddingtonRow struct {
 	LastCalculated pgtype.Timestamptz `db:"last_calculated" json:"last_calculated"`
 }
 
+// AthletesNeedingEddington are due a full recalculation. Changed activities
+// update the numbers as they happen, this only catches drift.
 func (q *sqlQuerier) AthletesNeedingEddington(ctx context.Context) ([]AthletesNeedingEddingtonRow, error) {
 	rows, err := q.db.Query(ctx, athletesNeedingEddington)
 	if err != nil {
@@ -1334,7 +1690,10 @@ func (q *sqlQuerier) AthletesNeedingEddington(ctx context.Context) ([]AthletesNe
 	var items []AthletesNeedingEddingtonRow
 	for rows.Next() {
 		var i AthletesNeedingEddingtonRow
-		if err := rows.Scan(&i.AthleteID, &i.LastCalculated); err != nil {
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.LastCalculated,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1345,9 +1704,61 @@ func (q *sqlQuerier) AthletesNeedingEddington(ctx context.Context) ([]AthletesNe
 	return items, nil
 }
 
+const deleteAthleteDailyTotals = `-- name: DeleteAthleteDailyTotals :exec
+DELETE FROM
+	athlete_daily_totals
+WHERE
+	athlete_id = $1 AND
+	($2::boolean OR day = ANY($3::date[]))
+`
+
+type DeleteAthleteDailyTotalsParams struct {
+	AthleteID int64         `db:"athlete_id" json:"athlete_id"`
+	AllDays   bool          `db:"all_days" json:"all_days"`
+	Days      []pgtype.Date `db:"days" json:"days"`
+}
+
+func (q *sqlQuerier) DeleteAthleteDailyTotals(ctx context.Context, arg DeleteAthleteDailyTotalsParams) error {
+	_, err := q.db.Exec(ctx, deleteAthleteDailyTotals, arg.AthleteID, arg.AllDays, arg.Days)
+	return err
+}
+
+const deleteAthleteEddingtonBreakdowns = `-- name: DeleteAthleteEddingtonBreakdowns :exec
+DELETE FROM
+	athlete_eddington_breakdowns
+WHERE
+	athlete_id = $1
+`
+
+func (q *sqlQuerier) DeleteAthleteEddingtonBreakdowns(ctx context.Context, athleteID int64) error {
+	_, err := q.db.Exec(ctx, deleteAthleteEddingtonBreakdowns, athleteID)
+	return err
+}
+
+const deleteLeaderboardOptOut = `-- name: DeleteLeaderboardOptOut :exec
+DELETE FROM
+	leaderboard_opt_outs
+WHERE
+	athlete_id = $1
+`
+
+func (q *sqlQuerier) DeleteLeaderboardOptOut(ctx context.Context, athleteID int64) error {
+	_, err := q.db.Exec(ctx, deleteLeaderboardOptOut, athleteID)
+	return err
+}
+
 const eddingtonActivities = `-- name: EddingtonActivities :many
 SELECT
-	id, distance, total_elevation_gain
+	id, distance, total_elevation_gain, start_date_local, activity_type, moving_time,
+	(
+		SELECT
+			count(DISTINCT segment_efforts.segment_id)
+		FROM
+			segment_efforts
+		WHERE
+			segment_efforts.activities_id = activity_summary.id
+			AND segment_efforts.segment_id = ANY(SELECT unnest(segments) FROM competitive_routes WHERE name = 'das-hugel')
+	) :: integer AS hugel_segments
 FROM
 	activity_summary
 WHERE
@@ -1356,9 +1767,13 @@ WHERE
 `
 
 type EddingtonActivitiesRow struct {
-	ID                 int64   `db:"id" json:"id"`
-	Distance           float64 `db:"distance" json:"distance"`
-	TotalElevationGain float64 `db:"total_elevation_gain" json:"total_elevation_gain"`
+	ID                 int64              `db:"id" json:"id"`
+	Distance           float64            `db:"distance" json:"distance"`
+	TotalElevationGain float64            `db:"total_elevation_gain" json:"total_elevation_gain"`
+	StartDateLocal     pgtype.Timestamptz `db:"start_date_local" json:"start_date_local"`
+	ActivityType       string             `db:"activity_type" json:"activity_type"`
+	MovingTime         float64            `db:"moving_time" json:"moving_time"`
+	HugelSegments      int32              `db:"hugel_segments" json:"hugel_segments"`
 }
 
 func (q *sqlQuerier) EddingtonActivities(ctx context.Context, athleteID int64) ([]EddingtonActivitiesRow, error) {
@@ -1370,7 +1785,15 @@ func (q *sqlQuerier) EddingtonActivities(ctx context.Context, athleteID int64) (
 	var items []EddingtonActivitiesRow
 	for rows.Next() {
 		var i EddingtonActivitiesRow
-		if err := rows.Scan(&i.ID, &i.Distance, &i.TotalElevationGain); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Distance,
+			&i.TotalElevationGain,
+			&i.StartDateLocal,
+			&i.ActivityType,
+			&i.MovingTime,
+			&i.HugelSegments,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1381,9 +1804,139 @@ func (q *sqlQuerier) EddingtonActivities(ctx context.Context, athleteID int64) (
 	return items, nil
 }
 
-const getAthleteEddington = `-- name: GetAthleteEddington :one
+const eddingtonLeaderboard = `-- name: EddingtonLeaderboard :many
 SELECT
-	athlete_id, miles_histogram, current_eddington, last_calculated, total_activities
+	athlete_eddington_breakdowns.athlete_id,
+	athlete_eddington_breakdowns.year,
+	athlete_eddington_breakdowns.days,
+	athlete_eddington_breakdowns.eddington_miles,
+	athlete_eddington_breakdowns.eddington_kilometers,
+	athlete_eddingtons.total_activities,
+	athletes.username,
+	athletes.firstname,
+	athletes.lastname,
+	athletes.sex,
+	athletes.city,
+	athletes.profile_pic_link
+FROM
+	athlete_eddington_breakdowns
+INNER JOIN
+	athlete_eddingtons ON athlete_eddington_breakdowns.athlete_id = athlete_eddingtons.athlete_id
+INNER JOIN
+	athletes ON athlete_eddington_breakdowns.athlete_id = athletes.id
+WHERE
+	athlete_eddington_breakdowns.sport = 'all'
+	AND NOT EXISTS (
+		SELECT 1 FROM leaderboard_opt_outs WHERE leaderboard_opt_outs.athlete_id = athlete_eddington_breakdowns.athlete_id
+	)
+`
+
+type EddingtonLeaderboardRow struct {
+	AthleteID           int64  `db:"athlete_id" json:"athlete_id"`
+	Year                int32  `db:"year" json:"year"`
+	Days                int32  `db:"days" json:"days"`
+	EddingtonMiles      int32  `db:"eddington_miles" json:"eddington_miles"`
+	EddingtonKilometers int32  `db:"eddington_kilometers" json:"eddington_kilometers"`
+	TotalActivities     int32  `db:"total_activities" json:"total_activities"`
+	Username            string `db:"username" json:"username"`
+	Firstname           string `db:"firstname" json:"firstname"`
+	Lastname            string `db:"lastname" json:"lastname"`
+	Sex                 string `db:"sex" json:"sex"`
+	City                string `db:"city" json:"city"`
+	ProfilePicLink      string `db:"profile_pic_link" json:"profile_pic_link"`
+}
+
+// EddingtonLeaderboard is the number of all rides for every athlete, for the
+// lifetime and each year, with their profile. Athletes who opted out of the
+// leaderboards are left out.
+func (q *sqlQuerier) EddingtonLeaderboard(ctx context.Context) ([]EddingtonLeaderboardRow, error) {
+	rows, err := q.db.Query(ctx, eddingtonLeaderboard)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EddingtonLeaderboardRow
+	for rows.Next() {
+		var i EddingtonLeaderboardRow
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.Year,
+			&i.Days,
+			&i.EddingtonMiles,
+			&i.EddingtonKilometers,
+			&i.TotalActivities,
+			&i.Username,
+			&i.Firstname,
+			&i.Lastname,
+			&i.Sex,
+			&i.City,
+			&i.ProfilePicLink,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const eddingtonMetricLeaderboard = `-- name: EddingtonMetricLeaderboard :many
+SELECT
+	RANK() OVER (ORDER BY athlete_eddington_metrics.eddington DESC) AS rank,
+	athlete_eddington_metrics.athlete_id,
+	athlete_eddington_metrics.eddington,
+	athlete_eddington_metrics.days
+FROM
+	athlete_eddington_metrics
+WHERE
+	athlete_eddington_metrics.metric = $1
+	AND athlete_eddington_metrics.eddington > 0
+	AND NOT EXISTS (
+		SELECT 1 FROM leaderboard_opt_outs WHERE leaderboard_opt_outs.athlete_id = athlete_eddington_metrics.athlete_id
+	)
+ORDER BY
+	rank, athlete_eddington_metrics.athlete_id
+`
+
+type EddingtonMetricLeaderboardRow struct {
+	Rank      int64 `db:"rank" json:"rank"`
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	Eddington int32 `db:"eddington" json:"eddington"`
+	Days      int32 `db:"days" json:"days"`
+}
+
+// EddingtonMetricLeaderboard ranks every athlete with the metric, ties share
+// a rank.
+func (q *sqlQuerier) EddingtonMetricLeaderboard(ctx context.Context, metric string) ([]EddingtonMetricLeaderboardRow, error) {
+	rows, err := q.db.Query(ctx, eddingtonMetricLeaderboard, metric)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EddingtonMetricLeaderboardRow
+	for rows.Next() {
+		var i EddingtonMetricLeaderboardRow
+		if err := rows.Scan(
+			&i.Rank,
+			&i.AthleteID,
+			&i.Eddington,
+			&i.Days,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAthleteEddington = `-- name: GetAthleteEddington :one
+SELECT
+	athlete_id, miles_histogram, current_eddington, last_calculated, total_activities, kilometers_histogram, current_eddington_km, total_days
 FROM
 	athlete_eddingtons
 WHERE
@@ -1399,10 +1952,228 @@ func (q *sqlQuerier) GetAthleteEddington(ctx context.Context, athleteID int64) (
 		&i.CurrentEddington,
 		&i.LastCalculated,
 		&i.TotalActivities,
+		&i.KilometersHistogram,
+		&i.CurrentEddingtonKm,
+		&i.TotalDays,
 	)
 	return i, err
 }
 
+const getAthleteEddingtonBreakdowns = `-- name: GetAthleteEddingtonBreakdowns :many
+SELECT
+	athlete_id, sport, year, days, miles_histogram, eddington_miles, kilometers_histogram, eddington_kilometers
+FROM
+	athlete_eddington_breakdowns
+WHERE
+	athlete_id = $1
+ORDER BY
+	sport, year
+`
+
+func (q *sqlQuerier) GetAthleteEddingtonBreakdowns(ctx context.Context, athleteID int64) ([]AthleteEddingtonBreakdown, error) {
+	rows, err := q.db.Query(ctx, getAthleteEddingtonBreakdowns, athleteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AthleteEddingtonBreakdown
+	for rows.Next() {
+		var i AthleteEddingtonBreakdown
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.Sport,
+			&i.Year,
+			&i.Days,
+			&i.MilesHistogram,
+			&i.EddingtonMiles,
+			&i.KilometersHistogram,
+			&i.EddingtonKilometers,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAthleteEddingtonMetrics = `-- name: GetAthleteEddingtonMetrics :many
+SELECT
+	*
+FROM
+	athlete_eddington_metrics
+WHERE
+	athlete_id = $1
+ORDER BY
+	metric
+`
+
+func (q *sqlQuerier) GetAthleteEddingtonMetrics(ctx context.Context, athleteID int64) ([]AthleteEddingtonMetric, error) {
+	rows, err := q.db.Query(ctx, getAthleteEddingtonMetrics, athleteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AthleteEddingtonMetric
+	for rows.Next() {
+		var i AthleteEddingtonMetric
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.Metric,
+			&i.Days,
+			&i.Histogram,
+			&i.Eddington,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAthleteDailyTotals = `-- name: InsertAthleteDailyTotals :execrows
+INSERT INTO
+	athlete_daily_totals(
+		athlete_id, day, sport, distance, activities, elevation_gain, moving_time, hugel_segments
+	)
+SELECT
+	athlete_id,
+	(start_date_local AT TIME ZONE 'UTC')::date AS day,
+	lower(activity_type) AS sport,
+	sum(distance),
+	count(*),
+	sum(total_elevation_gain),
+	sum(moving_time),
+	sum(hugel_segments)
+FROM
+	(
+		SELECT
+			athlete_id, start_date_local, activity_type, distance, total_elevation_gain, moving_time,
+			(
+				SELECT
+					count(DISTINCT segment_efforts.segment_id)
+				FROM
+					segment_efforts
+				WHERE
+					segment_efforts.activities_id = activity_summary.id
+					AND segment_efforts.segment_id = ANY(SELECT unnest(segments) FROM competitive_routes WHERE name = 'das-hugel')
+			) AS hugel_segments
+		FROM
+			activity_summary
+		WHERE
+			athlete_id = $1
+			AND lower(activity_type) = ANY(ARRAY['ride', 'virtualride'])
+			AND ($2::boolean OR (start_date_local AT TIME ZONE 'UTC')::date = ANY($3::date[]))
+	) AS rides
+GROUP BY
+	athlete_id, day, sport
+`
+
+type InsertAthleteDailyTotalsParams struct {
+	AthleteID int64         `db:"athlete_id" json:"athlete_id"`
+	AllDays   bool          `db:"all_days" json:"all_days"`
+	Days      []pgtype.Date `db:"days" json:"days"`
+}
+
+// InsertAthleteDailyTotals sums the rides of the days from the activities,
+// or of every day. The day is the local day the ride started.
+func (q *sqlQuerier) InsertAthleteDailyTotals(ctx context.Context, arg InsertAthleteDailyTotalsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertAthleteDailyTotals, arg.AthleteID, arg.AllDays, arg.Days)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const insertAthleteEddingtonBreakdown = `-- name: InsertAthleteEddingtonBreakdown :exec
+INSERT INTO
+	athlete_eddington_breakdowns(
+		athlete_id, sport, year, days,
+		miles_histogram, eddington_miles,
+		kilometers_histogram, eddington_kilometers
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type InsertAthleteEddingtonBreakdownParams struct {
+	AthleteID           int64   `db:"athlete_id" json:"athlete_id"`
+	Sport               string  `db:"sport" json:"sport"`
+	Year                int32   `db:"year" json:"year"`
+	Days                int32   `db:"days" json:"days"`
+	MilesHistogram      []int32 `db:"miles_histogram" json:"miles_histogram"`
+	EddingtonMiles      int32   `db:"eddington_miles" json:"eddington_miles"`
+	KilometersHistogram []int32 `db:"kilometers_histogram" json:"kilometers_histogram"`
+	EddingtonKilometers int32   `db:"eddington_kilometers" json:"eddington_kilometers"`
+}
+
+func (q *sqlQuerier) InsertAthleteEddingtonBreakdown(ctx context.Context, arg InsertAthleteEddingtonBreakdownParams) error {
+	_, err := q.db.Exec(ctx, insertAthleteEddingtonBreakdown, arg.AthleteID, arg.Sport, arg.Year, arg.Days, arg.MilesHistogram, arg.EddingtonMiles, arg.KilometersHistogram, arg.EddingtonKilometers)
+	return err
+}
+
+const insertEddingtonEvent = `-- name: InsertEddingtonEvent :execrows
+INSERT INTO
+	eddington_events(
+		athlete_id, eddington, previous, reached_on, activity_id
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(athlete_id, eddington) DO NOTHING
+`
+
+type InsertEddingtonEventParams struct {
+	AthleteID  int64              `db:"athlete_id" json:"athlete_id"`
+	Eddington  int32              `db:"eddington" json:"eddington"`
+	Previous   int32              `db:"previous" json:"previous"`
+	ReachedOn  pgtype.Timestamptz `db:"reached_on" json:"reached_on"`
+	ActivityID int64              `db:"activity_id" json:"activity_id"`
+}
+
+// InsertEddingtonEvent records a number going up. A number that is reached
+// again, after a ride was deleted and redone, keeps its first event.
+func (q *sqlQuerier) InsertEddingtonEvent(ctx context.Context, arg InsertEddingtonEventParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertEddingtonEvent, arg.AthleteID, arg.Eddington, arg.Previous, arg.ReachedOn, arg.ActivityID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const insertLeaderboardOptOut = `-- name: InsertLeaderboardOptOut :exec
+INSERT INTO
+	leaderboard_opt_outs(athlete_id)
+VALUES
+	($1)
+ON CONFLICT
+	(athlete_id)
+	DO NOTHING
+`
+
+func (q *sqlQuerier) InsertLeaderboardOptOut(ctx context.Context, athleteID int64) error {
+	_, err := q.db.Exec(ctx, insertLeaderboardOptOut, athleteID)
+	return err
+}
+
+const leaderboardOptedOut = `-- name: LeaderboardOptedOut :one
+SELECT EXISTS(
+	SELECT 1 FROM leaderboard_opt_outs WHERE athlete_id = $1
+)
+`
+
+func (q *sqlQuerier) LeaderboardOptedOut(ctx context.Context, athleteID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, leaderboardOptedOut, athleteID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const upsertAthleteEddington = `-- name: UpsertAthleteEddington :one
 INSERT INTO
 	athlete_eddingtons(
@@ -1410,36 +2181,39 @@ INSERT INTO
 		miles_histogram,
 		current_eddington,
 		last_calculated,
-		total_activities
+		total_activities,
+		kilometers_histogram,
+		current_eddington_km,
+		total_days
 )
 VALUES
-	($1, $2, $3, $4, $5)
+	($1, $2, $3, $4, $5, $6, $7, $8)
 ON CONFLICT
 	(athlete_id)
 	DO UPDATE SET
 		miles_histogram = $2,
 		current_eddington = $3,
 		last_calculated = $4,
-		total_activities = $5
-RETURNING athlete_id, miles_histogram, current_eddington, last_calculated, total_activities
+		total_activities = $5,
+		kilometers_histogram = $6,
+		current_eddington_km = $7,
+		total_days = $8
+RETURNING athlete_id, miles_histogram, current_eddington, last_calculated, total_activities, kilometers_histogram, current_eddington_km, total_days
 `
 
 type UpsertAthleteEddingtonParams struct {
-	AthleteID        int64              `db:"athlete_id" json:"athlete_id"`
-	MilesHistogram   []int32            `db:"miles_histogram" json:"miles_histogram"`
-	CurrentEddington int32              `db:"current_eddington" json:"current_eddington"`
-	LastCalculated   pgtype.Timestamptz `db:"last_calculated" json:"last_calculated"`
-	TotalActivities  int32              `db:"total_activities" json:"total_activities"`
+	AthleteID           int64              `db:"athlete_id" json:"athlete_id"`
+	MilesHistogram      []int32            `db:"miles_histogram" json:"miles_histogram"`
+	CurrentEddington    int32              `db:"current_eddington" json:"current_eddington"`
+	LastCalculated      pgtype.Timestamptz `db:"last_calculated" json:"last_calculated"`
+	TotalActivities     int32              `db:"total_activities" json:"total_activities"`
+	KilometersHistogram []int32            `db:"kilometers_histogram" json:"kilometers_histogram"`
+	CurrentEddingtonKm  int32              `db:"current_eddington_km" json:"current_eddington_km"`
+	TotalDays           int32              `db:"total_days" json:"total_days"`
 }
 
 func (q *sqlQuerier) UpsertAthleteEddington(ctx context.Context, arg UpsertAthleteEddingtonParams) (AthleteEddington, error) {
-	row := q.db.QueryRow(ctx, upsertAthleteEddington,
-		arg.AthleteID,
-		arg.MilesHistogram,
-		arg.CurrentEddington,
-		arg.LastCalculated,
-		arg.TotalActivities,
-	)
+	row := q.db.QueryRow(ctx, upsertAthleteEddington, arg.AthleteID, arg.MilesHistogram, arg.CurrentEddington, arg.LastCalculated, arg.TotalActivities, arg.KilometersHistogram, arg.CurrentEddingtonKm, arg.TotalDays)
 	var i AthleteEddington
 	err := row.Scan(
 		&i.AthleteID,
@@ -1447,10 +2221,431 @@ func (q *sqlQuerier) UpsertAthleteEddington(ctx context.Context, arg UpsertAthle
 		&i.CurrentEddington,
 		&i.LastCalculated,
 		&i.TotalActivities,
+		&i.KilometersHistogram,
+		&i.CurrentEddingtonKm,
+		&i.TotalDays,
 	)
 	return i, err
 }
 
+const upsertAthleteEddingtonMetric = `-- name: UpsertAthleteEddingtonMetric :exec
+INSERT INTO
+	athlete_eddington_metrics(
+		athlete_id, metric, days, histogram, eddington
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(athlete_id, metric)
+	DO UPDATE SET
+		days = $3,
+		histogram = $4,
+		eddington = $5
+`
+
+type UpsertAthleteEddingtonMetricParams struct {
+	AthleteID int64   `db:"athlete_id" json:"athlete_id"`
+	Metric    string  `db:"metric" json:"metric"`
+	Days      int32   `db:"days" json:"days"`
+	Histogram []int32 `db:"histogram" json:"histogram"`
+	Eddington int32   `db:"eddington" json:"eddington"`
+}
+
+func (q *sqlQuerier) UpsertAthleteEddingtonMetric(ctx context.Context, arg UpsertAthleteEddingtonMetricParams) error {
+	_, err := q.db.Exec(ctx, upsertAthleteEddingtonMetric, arg.AthleteID, arg.Metric, arg.Days, arg.Histogram, arg.Eddington)
+	return err
+}
+
+const getGPSSegmentEfforts = `-- name: GetGPSSegmentEfforts :many
+SELECT
+	activity_id, segment_id, athlete_id, source, start_date, elapsed_time, distance, start_index, end_index, coverage, created_at
+FROM
+	gps_segment_efforts
+WHERE
+	activity_id = $1
+ORDER BY
+	start_date ASC
+`
+
+func (q *sqlQuerier) GetGPSSegmentEfforts(ctx context.Context, activityID int64) ([]GpsSegmentEffort, error) {
+	rows, err := q.db.Query(ctx, getGPSSegmentEfforts, activityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GpsSegmentEffort
+	for rows.Next() {
+		var i GpsSegmentEffort
+		if err := rows.Scan(
+			&i.ActivityID,
+			&i.SegmentID,
+			&i.AthleteID,
+			&i.Source,
+			&i.StartDate,
+			&i.ElapsedTime,
+			&i.Distance,
+			&i.StartIndex,
+			&i.EndIndex,
+			&i.Coverage,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unofficialRouteResults = `-- name: UnofficialRouteResults :many
+SELECT
+	unofficial_route_results.activity_id,
+	unofficial_route_results.athlete_id,
+	unofficial_route_results.total_time_seconds,
+	unofficial_route_results.gps_segment_ids,
+
+	activity_summary.name,
+	activity_summary.start_date,
+
+	athletes.firstname,
+	athletes.lastname,
+	athletes.username,
+	athletes.profile_pic_link,
+	athletes.sex
+FROM
+	unofficial_route_results
+INNER JOIN
+	competitive_routes ON unofficial_route_results.route_name = competitive_routes.name
+INNER JOIN
+	activity_summary ON unofficial_route_results.activity_id = activity_summary.id
+INNER JOIN
+	athletes ON unofficial_route_results.athlete_id = athletes.id
+WHERE
+	unofficial_route_results.route_name = $1
+	AND NOT (
+		competitive_routes.segments <@ ARRAY(
+			SELECT segment_id FROM segment_efforts
+			WHERE segment_efforts.activities_id = unofficial_route_results.activity_id
+		)
+	)
+ORDER BY
+	unofficial_route_results.total_time_seconds ASC
+`
+
+type UnofficialRouteResultsRow struct {
+	ActivityID       int64              `db:"activity_id" json:"activity_id"`
+	AthleteID        int64              `db:"athlete_id" json:"athlete_id"`
+	TotalTimeSeconds float64            `db:"total_time_seconds" json:"total_time_seconds"`
+	GpsSegmentIds    []int64            `db:"gps_segment_ids" json:"gps_segment_ids"`
+	Name             string             `db:"name" json:"name"`
+	StartDate        pgtype.Timestamptz `db:"start_date" json:"start_date"`
+	Firstname        string             `db:"firstname" json:"firstname"`
+	Lastname         string             `db:"lastname" json:"lastname"`
+	Username         string             `db:"username" json:"username"`
+	ProfilePicLink   string             `db:"profile_pic_link" json:"profile_pic_link"`
+	Sex              string             `db:"sex" json:"sex"`
+}
+
+// UnofficialRouteResults returns the GPS matched results of a route, fastest
+// first. Activities that have since become official results are left out.
+func (q *sqlQuerier) UnofficialRouteResults(ctx context.Context, routeName string) ([]UnofficialRouteResultsRow, error) {
+	rows, err := q.db.Query(ctx, unofficialRouteResults, routeName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnofficialRouteResultsRow
+	for rows.Next() {
+		var i UnofficialRouteResultsRow
+		if err := rows.Scan(
+			&i.ActivityID,
+			&i.AthleteID,
+			&i.TotalTimeSeconds,
+			&i.GpsSegmentIds,
+			&i.Name,
+			&i.StartDate,
+			&i.Firstname,
+			&i.Lastname,
+			&i.Username,
+			&i.ProfilePicLink,
+			&i.Sex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertGPSSegmentEffort = `-- name: UpsertGPSSegmentEffort :exec
+INSERT INTO
+	gps_segment_efforts(
+		activity_id, segment_id, athlete_id, start_date, elapsed_time,
+		distance, start_index, end_index, coverage
+	)
+VALUES
+	($1, $2, $3, $4, $5,
+	 $6, $7, $8, $9)
+ON CONFLICT
+	(activity_id, segment_id)
+	DO UPDATE SET
+		start_date = $4,
+		elapsed_time = $5,
+		distance = $6,
+		start_index = $7,
+		end_index = $8,
+		coverage = $9
+`
+
+type UpsertGPSSegmentEffortParams struct {
+	ActivityID  int64              `db:"activity_id" json:"activity_id"`
+	SegmentID   int64              `db:"segment_id" json:"segment_id"`
+	AthleteID   int64              `db:"athlete_id" json:"athlete_id"`
+	StartDate   pgtype.Timestamptz `db:"start_date" json:"start_date"`
+	ElapsedTime float64            `db:"elapsed_time" json:"elapsed_time"`
+	Distance    float64            `db:"distance" json:"distance"`
+	StartIndex  int32              `db:"start_index" json:"start_index"`
+	EndIndex    int32              `db:"end_index" json:"end_index"`
+	Coverage    float64            `db:"coverage" json:"coverage"`
+}
+
+func (q *sqlQuerier) UpsertGPSSegmentEffort(ctx context.Context, arg UpsertGPSSegmentEffortParams) error {
+	_, err := q.db.Exec(ctx, upsertGPSSegmentEffort, arg.ActivityID, arg.SegmentID, arg.AthleteID, arg.StartDate, arg.ElapsedTime, arg.Distance, arg.StartIndex, arg.EndIndex, arg.Coverage)
+	return err
+}
+
+const upsertUnofficialRouteResult = `-- name: UpsertUnofficialRouteResult :exec
+INSERT INTO
+	unofficial_route_results(route_name, activity_id, athlete_id, total_time_seconds, gps_segment_ids)
+VALUES
+	($1, $2, $3, $4, $5 :: bigint[])
+ON CONFLICT
+	(route_name, activity_id)
+	DO UPDATE SET
+		total_time_seconds = $4,
+		gps_segment_ids = $5 :: bigint[]
+`
+
+type UpsertUnofficialRouteResultParams struct {
+	RouteName        string  `db:"route_name" json:"route_name"`
+	ActivityID       int64   `db:"activity_id" json:"activity_id"`
+	AthleteID        int64   `db:"athlete_id" json:"athlete_id"`
+	TotalTimeSeconds float64 `db:"total_time_seconds" json:"total_time_seconds"`
+	GpsSegmentIds    []int64 `db:"gps_segment_ids" json:"gps_segment_ids"`
+}
+
+func (q *sqlQuerier) UpsertUnofficialRouteResult(ctx context.Context, arg UpsertUnofficialRouteResultParams) error {
+	_, err := q.db.Exec(ctx, upsertUnofficialRouteResult, arg.RouteName, arg.ActivityID, arg.AthleteID, arg.TotalTimeSeconds, arg.GpsSegmentIds)
+	return err
+}
+
+const eventGroupRides = `-- name: EventGroupRides :one
+SELECT
+	*
+FROM
+	event_group_rides
+WHERE
+	route_name = $1
+`
+
+func (q *sqlQuerier) EventGroupRides(ctx context.Context, routeName string) (EventGroupRide, error) {
+	row := q.db.QueryRow(ctx, eventGroupRides, routeName)
+	var i EventGroupRide
+	err := row.Scan(
+		&i.RouteName,
+		&i.Groups,
+		&i.Seen,
+		&i.ComputedAt,
+	)
+	return i, err
+}
+
+const upsertEventGroupRides = `-- name: UpsertEventGroupRides :exec
+INSERT INTO
+	event_group_rides(
+		route_name, groups, seen, computed_at
+	)
+VALUES
+	($1, $2, $3 :: bigint[], Now())
+ON CONFLICT
+	(route_name)
+	DO UPDATE SET
+		groups = $2,
+		seen = $3 :: bigint[],
+		computed_at = Now()
+`
+
+type UpsertEventGroupRidesParams struct {
+	RouteName string  `db:"route_name" json:"route_name"`
+	Groups    []byte  `db:"groups" json:"groups"`
+	Seen      []int64 `db:"seen" json:"seen"`
+}
+
+func (q *sqlQuerier) UpsertEventGroupRides(ctx context.Context, arg UpsertEventGroupRidesParams) error {
+	_, err := q.db.Exec(ctx, upsertEventGroupRides, arg.RouteName, arg.Groups, arg.Seen)
+	return err
+}
+
+const deleteHeatmapOptOut = `-- name: DeleteHeatmapOptOut :exec
+DELETE FROM
+	heatmap_opt_outs
+WHERE
+	athlete_id = $1
+`
+
+func (q *sqlQuerier) DeleteHeatmapOptOut(ctx context.Context, athleteID int64) error {
+	_, err := q.db.Exec(ctx, deleteHeatmapOptOut, athleteID)
+	return err
+}
+
+const eventHeatmap = `-- name: EventHeatmap :one
+SELECT
+	route_name, min_lat, min_lng, max_lat, max_lng, width, height, cells, activities, computed_at
+FROM
+	event_heatmaps
+WHERE
+	route_name = $1
+`
+
+func (q *sqlQuerier) EventHeatmap(ctx context.Context, routeName string) (EventHeatmap, error) {
+	row := q.db.QueryRow(ctx, eventHeatmap, routeName)
+	var i EventHeatmap
+	err := row.Scan(
+		&i.RouteName,
+		&i.MinLat,
+		&i.MinLng,
+		&i.MaxLat,
+		&i.MaxLng,
+		&i.Width,
+		&i.Height,
+		&i.Cells,
+		&i.Activities,
+		&i.ComputedAt,
+	)
+	return i, err
+}
+
+const heatmapOptedOut = `-- name: HeatmapOptedOut :one
+SELECT EXISTS(
+	SELECT 1 FROM heatmap_opt_outs WHERE athlete_id = $1
+)
+`
+
+func (q *sqlQuerier) HeatmapOptedOut(ctx context.Context, athleteID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, heatmapOptedOut, athleteID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const heatmapPolylines = `-- name: HeatmapPolylines :many
+SELECT
+	activity_summary.id AS activity_id,
+	maps.summary_polyline
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.id = ANY($1 :: bigint[])
+	AND NOT activity_summary.private
+	AND NOT EXISTS(
+		SELECT 1 FROM heatmap_opt_outs
+		WHERE heatmap_opt_outs.athlete_id = activity_summary.athlete_id
+	)
+`
+
+type HeatmapPolylinesRow struct {
+	ActivityID      int64  `db:"activity_id" json:"activity_id"`
+	SummaryPolyline string `db:"summary_polyline" json:"summary_polyline"`
+}
+
+// HeatmapPolylines returns the summary tracks of the activities that may be
+// shown on a heatmap. Private activities and athletes who opted out are left
+// out.
+func (q *sqlQuerier) HeatmapPolylines(ctx context.Context, activityIds []int64) ([]HeatmapPolylinesRow, error) {
+	rows, err := q.db.Query(ctx, heatmapPolylines, activityIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HeatmapPolylinesRow
+	for rows.Next() {
+		var i HeatmapPolylinesRow
+		if err := rows.Scan(
+			&i.ActivityID,
+			&i.SummaryPolyline,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertHeatmapOptOut = `-- name: InsertHeatmapOptOut :exec
+INSERT INTO
+	heatmap_opt_outs(athlete_id)
+VALUES
+	($1)
+ON CONFLICT
+	(athlete_id)
+	DO NOTHING
+`
+
+func (q *sqlQuerier) InsertHeatmapOptOut(ctx context.Context, athleteID int64) error {
+	_, err := q.db.Exec(ctx, insertHeatmapOptOut, athleteID)
+	return err
+}
+
+const upsertEventHeatmap = `-- name: UpsertEventHeatmap :exec
+INSERT INTO
+	event_heatmaps(
+		route_name, min_lat, min_lng, max_lat, max_lng,
+		width, height, cells, activities, computed_at
+	)
+VALUES
+	($1, $2, $3, $4, $5,
+	 $6, $7, $8 :: integer[], $9, Now())
+ON CONFLICT
+	(route_name)
+	DO UPDATE SET
+		min_lat = $2,
+		min_lng = $3,
+		max_lat = $4,
+		max_lng = $5,
+		width = $6,
+		height = $7,
+		cells = $8 :: integer[],
+		activities = $9,
+		computed_at = Now()
+`
+
+type UpsertEventHeatmapParams struct {
+	RouteName  string  `db:"route_name" json:"route_name"`
+	MinLat     float64 `db:"min_lat" json:"min_lat"`
+	MinLng     float64 `db:"min_lng" json:"min_lng"`
+	MaxLat     float64 `db:"max_lat" json:"max_lat"`
+	MaxLng     float64 `db:"max_lng" json:"max_lng"`
+	Width      int32   `db:"width" json:"width"`
+	Height     int32   `db:"height" json:"height"`
+	Cells      []int32 `db:"cells" json:"cells"`
+	Activities int32   `db:"activities" json:"activities"`
+}
+
+func (q *sqlQuerier) UpsertEventHeatmap(ctx context.Context, arg UpsertEventHeatmapParams) error {
+	_, err := q.db.Exec(ctx, upsertEventHeatmap, arg.RouteName, arg.MinLat, arg.MinLng, arg.MaxLat, arg.MaxLng, arg.Width, arg.Height, arg.Cells, arg.Activities)
+	return err
+}
+
 const athleteHugelActivites = `-- name: AthleteHugelActivites :many
 SELECT
     hugel_activities_2024.activity_id, hugel_activities_2024.athlete_id, hugel_activities_2024.segment_ids, hugel_activities_2024.total_time_seconds, hugel_activities_2024.efforts,
@@ -1847,6 +3042,180 @@ func (q *sqlQuerier) RefreshSuperHugelActivities(ctx context.Context) error {
 	return err
 }
 
+const routeActivityResults = `-- name: RouteActivityResults :many
+SELECT
+	results.route_name,
+	results.activity_id,
+	results.athlete_id,
+	results.total_time_seconds,
+	results.efforts,
+
+	activity_summary.name,
+	activity_summary.start_date,
+	activity_summary.elapsed_time,
+	activity_summary.moving_time,
+	activity_summary.average_heartrate,
+	activity_summary.device_watts,
+	COALESCE(activity_detail.average_watts, 0) :: double precision AS average_watts,
+
+	athletes.firstname,
+	athletes.lastname,
+	athletes.username,
+	athletes.profile_pic_link,
+	athletes.sex
+FROM
+	edition_results AS results
+INNER JOIN
+	activity_summary ON results.activity_id = activity_summary.id
+LEFT JOIN
+	activity_detail ON results.activity_id = activity_detail.id
+INNER JOIN
+	athletes ON results.athlete_id = athletes.id
+WHERE
+	results.activity_id = ANY($1 :: bigint[])
+`
+
+type RouteActivityResultsRow struct {
+	RouteName        string             `db:"route_name" json:"route_name"`
+	ActivityID       int64              `db:"activity_id" json:"activity_id"`
+	AthleteID        int64              `db:"athlete_id" json:"athlete_id"`
+	TotalTimeSeconds int64              `db:"total_time_seconds" json:"total_time_seconds"`
+	Efforts          []byte             `db:"efforts" json:"efforts"`
+	Name             string             `db:"name" json:"name"`
+	StartDate        pgtype.Timestamptz `db:"start_date" json:"start_date"`
+	ElapsedTime      float64            `db:"elapsed_time" json:"elapsed_time"`
+	MovingTime       float64            `db:"moving_time" json:"moving_time"`
+	AverageHeartrate float64            `db:"average_heartrate" json:"average_heartrate"`
+	DeviceWatts      bool               `db:"device_watts" json:"device_watts"`
+	AverageWatts     float64            `db:"average_watts" json:"average_watts"`
+	Firstname        string             `db:"firstname" json:"firstname"`
+	Lastname         string             `db:"lastname" json:"lastname"`
+	Username         string             `db:"username" json:"username"`
+	ProfilePicLink   string             `db:"profile_pic_link" json:"profile_pic_link"`
+	Sex              string             `db:"sex" json:"sex"`
+}
+
+// RouteActivityResults returns every edition result of the given activities.
+// Each row is tagged with the competitive route it was scored against, so
+// results from different editions can be aligned by segment.
+func (q *sqlQuerier) RouteActivityResults(ctx context.Context, activityIds []int64) ([]RouteActivityResultsRow, error) {
+	rows, err := q.db.Query(ctx, routeActivityResults, activityIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RouteActivityResultsRow
+	for rows.Next() {
+		var i RouteActivityResultsRow
+		if err := rows.Scan(
+			&i.RouteName,
+			&i.ActivityID,
+			&i.AthleteID,
+			&i.TotalTimeSeconds,
+			&i.Efforts,
+			&i.Name,
+			&i.StartDate,
+			&i.ElapsedTime,
+			&i.MovingTime,
+			&i.AverageHeartrate,
+			&i.DeviceWatts,
+			&i.AverageWatts,
+			&i.Firstname,
+			&i.Lastname,
+			&i.Username,
+			&i.ProfilePicLink,
+			&i.Sex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const routeFatigueSamples = `-- name: RouteFatigueSamples :many
+SELECT
+	results.route_name,
+	results.activity_id,
+	results.athlete_id,
+	activity_summary.start_date,
+	effort.segment_id :: bigint AS segment_id,
+	effort.start_date :: timestamptz AS effort_start_date,
+	effort.elapsed_time :: double precision AS elapsed_time,
+	prior.elapsed_times :: double precision[] AS prior_elapsed_times,
+	prior.start_dates :: timestamptz[] AS prior_start_dates
+FROM
+	edition_results AS results
+INNER JOIN
+	activity_summary ON results.activity_id = activity_summary.id
+CROSS JOIN LATERAL
+	jsonb_to_recordset(results.efforts :: jsonb) AS effort(segment_id bigint, elapsed_time bigint, start_date timestamptz)
+INNER JOIN LATERAL
+	(
+		SELECT
+			array_agg(segment_efforts.elapsed_time ORDER BY segment_efforts.start_date) AS elapsed_times,
+			array_agg(segment_efforts.start_date ORDER BY segment_efforts.start_date) AS start_dates
+		FROM
+			segment_efforts
+		WHERE
+			segment_efforts.athlete_id = results.athlete_id AND
+			segment_efforts.segment_id = effort.segment_id AND
+			segment_efforts.activities_id != results.activity_id AND
+			segment_efforts.start_date < effort.start_date
+	) AS prior ON prior.elapsed_times IS NOT NULL
+WHERE
+	results.route_name = ANY($1 :: text[])
+`
+
+type RouteFatigueSamplesRow struct {
+	RouteName         string               `db:"route_name" json:"route_name"`
+	ActivityID        int64                `db:"activity_id" json:"activity_id"`
+	AthleteID         int64                `db:"athlete_id" json:"athlete_id"`
+	StartDate         pgtype.Timestamptz   `db:"start_date" json:"start_date"`
+	SegmentID         int64                `db:"segment_id" json:"segment_id"`
+	EffortStartDate   pgtype.Timestamptz   `db:"effort_start_date" json:"effort_start_date"`
+	ElapsedTime       float64              `db:"elapsed_time" json:"elapsed_time"`
+	PriorElapsedTimes []float64            `db:"prior_elapsed_times" json:"prior_elapsed_times"`
+	PriorStartDates   []pgtype.Timestamptz `db:"prior_start_dates" json:"prior_start_dates"`
+}
+
+// RouteFatigueSamples pairs every climb of the given routes' results with the
+// athlete's efforts on that climb from before the result was ridden. The ratio
+// between the day and the form those efforts show is how much slower riders
+// climb on the day.
+func (q *sqlQuerier) RouteFatigueSamples(ctx context.Context, routeNames []string) ([]RouteFatigueSamplesRow, error) {
+	rows, err := q.db.Query(ctx, routeFatigueSamples, routeNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RouteFatigueSamplesRow
+	for rows.Next() {
+		var i RouteFatigueSamplesRow
+		if err := rows.Scan(
+			&i.RouteName,
+			&i.ActivityID,
+			&i.AthleteID,
+			&i.StartDate,
+			&i.SegmentID,
+			&i.EffortStartDate,
+			&i.ElapsedTime,
+			&i.PriorElapsedTimes,
+			&i.PriorStartDates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const superHugelLeaderboard = `-- name: SuperHugelLeaderboard :many
 SELECT
 	(SELECT min(total_time_seconds) FROM super_hugel_activities) :: BIGINT AS best_time,
@@ -1955,6 +3324,118 @@ func (q *sqlQuerier) TotalJobCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const getActivityMap = `-- name: GetActivityMap :one
+SELECT
+	maps.id, maps.polyline, maps.summary_polyline, maps.updated_at
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.id = $1
+`
+
+func (q *sqlQuerier) GetActivityMap(ctx context.Context, activityID int64) (Map, error) {
+	row := q.db.QueryRow(ctx, getActivityMap, activityID)
+	var i Map
+	err := row.Scan(
+		&i.ID,
+		&i.Polyline,
+		&i.SummaryPolyline,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getActivitySummaryPolylines = `-- name: GetActivitySummaryPolylines :many
+SELECT
+	activity_summary.id AS activity_id,
+	maps.summary_polyline
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.id = ANY($1 :: bigint[])
+`
+
+type GetActivitySummaryPolylinesRow struct {
+	ActivityID      int64  `db:"activity_id" json:"activity_id"`
+	SummaryPolyline string `db:"summary_polyline" json:"summary_polyline"`
+}
+
+func (q *sqlQuerier) GetActivitySummaryPolylines(ctx context.Context, activityIds []int64) ([]GetActivitySummaryPolylinesRow, error) {
+	rows, err := q.db.Query(ctx, getActivitySummaryPolylines, activityIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActivitySummaryPolylinesRow
+	for rows.Next() {
+		var i GetActivitySummaryPolylinesRow
+		if err := rows.Scan(
+			&i.ActivityID,
+			&i.SummaryPolyline,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSummaryPolylinesBetween = `-- name: GetSummaryPolylinesBetween :many
+SELECT
+	activity_summary.id AS activity_id,
+	maps.summary_polyline
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.start_date >= $1
+	AND activity_summary.start_date < $2
+	AND (activity_summary.sport_type ILIKE '%ride%' OR activity_summary.sport_type ILIKE '%bike%')
+`
+
+type GetSummaryPolylinesBetweenParams struct {
+	StartDate pgtype.Timestamptz `db:"start_date" json:"start_date"`
+	EndDate   pgtype.Timestamptz `db:"end_date" json:"end_date"`
+}
+
+type GetSummaryPolylinesBetweenRow struct {
+	ActivityID      int64  `db:"activity_id" json:"activity_id"`
+	SummaryPolyline string `db:"summary_polyline" json:"summary_polyline"`
+}
+
+// GetSummaryPolylinesBetween returns the summary track of every ride that
+// started in the window.
+func (q *sqlQuerier) GetSummaryPolylinesBetween(ctx context.Context, arg GetSummaryPolylinesBetweenParams) ([]GetSummaryPolylinesBetweenRow, error) {
+	rows, err := q.db.Query(ctx, getSummaryPolylinesBetween, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSummaryPolylinesBetweenRow
+	for rows.Next() {
+		var i GetSummaryPolylinesBetweenRow
+		if err := rows.Scan(
+			&i.ActivityID,
+			&i.SummaryPolyline,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const upsertMapData = `-- name: UpsertMapData :one
 INSERT INTO
 	maps(
@@ -1998,7 +3479,7 @@ func (q *sqlQuerier) UpsertMapData(ctx context.Context, arg UpsertMapDataParams)
 }
 
 const allCompetitiveRoutes = `-- name: AllCompetitiveRoutes :many
-SELECT name, display_name, description, segments FROM competitive_routes
+SELECT name, display_name, description, segments, reference_activity_id FROM competitive_routes
 `
 
 func (q *sqlQuerier) AllCompetitiveRoutes(ctx context.Context) ([]CompetitiveRoute, error) {
@@ -2015,6 +3496,7 @@ func (q *sqlQuerier) AllCompetitiveRoutes(ctx context.Context) ([]CompetitiveRou
 			&i.DisplayName,
 			&i.Description,
 			&i.Segments,
+			&i.ReferenceActivityID,
 		); err != nil {
 			return nil, err
 		}
@@ -2104,6 +3586,126 @@ func (q *sqlQuerier) BestRouteEfforts(ctx context.Context, expectedSegments []in
 	return items, nil
 }
 
+const getCompetitiveRouteByName = `-- name: GetCompetitiveRouteByName :one
+SELECT name, display_name, description, segments, reference_activity_id FROM competitive_routes WHERE name = $1
+`
+
+func (q *sqlQuerier) GetCompetitiveRouteByName(ctx context.Context, routeName string) (CompetitiveRoute, error) {
+	row := q.db.QueryRow(ctx, getCompetitiveRouteByName, routeName)
+	var i CompetitiveRoute
+	err := row.Scan(
+		&i.Name,
+		&i.DisplayName,
+		&i.Description,
+		&i.Segments,
+		&i.ReferenceActivityID,
+	)
+	return i, err
+}
+
+const setCompetitiveRouteReference = `-- name: SetCompetitiveRouteReference :execrows
+UPDATE
+	competitive_routes
+SET
+	reference_activity_id = $1
+WHERE
+	name = $2
+`
+
+type SetCompetitiveRouteReferenceParams struct {
+	ReferenceActivityID pgtype.Int8 `db:"reference_activity_id" json:"reference_activity_id"`
+	RouteName           string      `db:"route_name" json:"route_name"`
+}
+
+func (q *sqlQuerier) SetCompetitiveRouteReference(ctx context.Context, arg SetCompetitiveRouteReferenceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setCompetitiveRouteReference, arg.ReferenceActivityID, arg.RouteName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const fillSegmentLocations = `-- name: FillSegmentLocations :execrows
+INSERT INTO
+	segment_locations(
+		id, name, activity_type, distance, average_grade, maximum_grade,
+		elevation_high, elevation_low, start_lat, start_lng, end_lat, end_lng,
+		climb_category, city, state, country, private, hazardous, updated_at
+	)
+SELECT
+	id, name, activity_type, distance, average_grade, maximum_grade,
+	elevation_high, elevation_low, start_latlng[1], start_latlng[2], end_latlng[1], end_latlng[2],
+	climb_category, city, state, country, private, hazardous, Now()
+FROM
+	segments
+WHERE
+	array_length(start_latlng, 1) = 2 AND
+	array_length(end_latlng, 1) = 2
+ON CONFLICT
+	(id) DO NOTHING
+`
+
+// FillSegmentLocations copies the loaded route segments that are not indexed
+// yet, they are already known without asking Strava.
+func (q *sqlQuerier) FillSegmentLocations(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, fillSegmentLocations)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActivitySegmentEfforts = `-- name: GetActivitySegmentEfforts :many
+SELECT
+	id, athlete_id, segment_id, name, elapsed_time, moving_time, start_date, start_date_local, distance, start_index, end_index, device_watts, average_watts, kom_rank, pr_rank, updated_at, activities_id
+FROM
+	segment_efforts
+WHERE
+	activities_id = $1
+ORDER BY
+	start_date ASC
+`
+
+// GetActivitySegmentEfforts returns the efforts of an activity in the order
+// they were ridden.
+func (q *sqlQuerier) GetActivitySegmentEfforts(ctx context.Context, activityID int64) ([]SegmentEffort, error) {
+	rows, err := q.db.Query(ctx, getActivitySegmentEfforts, activityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SegmentEffort
+	for rows.Next() {
+		var i SegmentEffort
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.SegmentID,
+			&i.Name,
+			&i.ElapsedTime,
+			&i.MovingTime,
+			&i.StartDate,
+			&i.StartDateLocal,
+			&i.Distance,
+			&i.StartIndex,
+			&i.EndIndex,
+			&i.DeviceWatts,
+			&i.AverageWatts,
+			&i.KomRank,
+			&i.PrRank,
+			&i.UpdatedAt,
+			&i.ActivitiesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getBestPersonalSegmentEffort = `-- name: GetBestPersonalSegmentEffort :many
 SELECT DISTINCT ON (segment_efforts.athlete_id, segment_efforts.segment_id)
 	id, athlete_id, segment_id, name, elapsed_time, moving_time, start_date, start_date_local, distance, start_index, end_index, device_watts, average_watts, kom_rank, pr_rank, updated_at, activities_id
@@ -2121,33 +3723,139 @@ type GetBestPersonalSegmentEffortParams struct {
 	SegmentIds []int64 `db:"segment_ids" json:"segment_ids"`
 }
 
-func (q *sqlQuerier) GetBestPersonalSegmentEffort(ctx context.Context, arg GetBestPersonalSegmentEffortParams) ([]SegmentEffort, error) {
-	rows, err := q.db.Query(ctx, getBestPersonalSegmentEffort, arg.AthleteID, arg.SegmentIds)
+func (q *sqlQuerier) GetBestPersonalSegmentEffort(ctx context.Context, arg GetBestPersonalSegmentEffortParams) ([]SegmentEffort, error) {
+	rows, err := q.db.Query(ctx, getBestPersonalSegmentEffort, arg.AthleteID, arg.SegmentIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SegmentEffort
+	for rows.Next() {
+		var i SegmentEffort
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.SegmentID,
+			&i.Name,
+			&i.ElapsedTime,
+			&i.MovingTime,
+			&i.StartDate,
+			&i.StartDateLocal,
+			&i.Distance,
+			&i.StartIndex,
+			&i.EndIndex,
+			&i.DeviceWatts,
+			&i.AverageWatts,
+			&i.KomRank,
+			&i.PrRank,
+			&i.UpdatedAt,
+			&i.ActivitiesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPersonalSegmentEfforts = `-- name: GetPersonalSegmentEfforts :many
+SELECT
+	id, athlete_id, segment_id, name, elapsed_time, moving_time, start_date, start_date_local, distance, start_index, end_index, device_watts, average_watts, kom_rank, pr_rank, updated_at, activities_id
+FROM
+	segment_efforts
+WHERE
+	athlete_id = $1 AND
+	segment_id = ANY($2::bigint[])
+ORDER BY
+	start_date DESC
+`
+
+type GetPersonalSegmentEffortsParams struct {
+	AthleteID  int64   `db:"athlete_id" json:"athlete_id"`
+	SegmentIds []int64 `db:"segment_ids" json:"segment_ids"`
+}
+
+// GetPersonalSegmentEfforts returns every effort of an athlete on the given
+// segments, newest first.
+func (q *sqlQuerier) GetPersonalSegmentEfforts(ctx context.Context, arg GetPersonalSegmentEffortsParams) ([]SegmentEffort, error) {
+	rows, err := q.db.Query(ctx, getPersonalSegmentEfforts, arg.AthleteID, arg.SegmentIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SegmentEffort
+	for rows.Next() {
+		var i SegmentEffort
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.SegmentID,
+			&i.Name,
+			&i.ElapsedTime,
+			&i.MovingTime,
+			&i.StartDate,
+			&i.StartDateLocal,
+			&i.Distance,
+			&i.StartIndex,
+			&i.EndIndex,
+			&i.DeviceWatts,
+			&i.AverageWatts,
+			&i.KomRank,
+			&i.PrRank,
+			&i.UpdatedAt,
+			&i.ActivitiesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSegmentLocations = `-- name: GetSegmentLocations :many
+SELECT
+	id, name, activity_type, distance, average_grade, maximum_grade, elevation_high, elevation_low, start_lat, start_lng, end_lat, end_lng, climb_category, city, state, country, private, hazardous, updated_at
+FROM
+	segment_locations
+WHERE
+	id = ANY($1::bigint[])
+`
+
+func (q *sqlQuerier) GetSegmentLocations(ctx context.Context, segmentIds []int64) ([]SegmentLocation, error) {
+	rows, err := q.db.Query(ctx, getSegmentLocations, segmentIds)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SegmentEffort
+	var items []SegmentLocation
 	for rows.Next() {
-		var i SegmentEffort
+		var i SegmentLocation
 		if err := rows.Scan(
 			&i.ID,
-			&i.AthleteID,
-			&i.SegmentID,
 			&i.Name,
-			&i.ElapsedTime,
-			&i.MovingTime,
-			&i.StartDate,
-			&i.StartDateLocal,
+			&i.ActivityType,
 			&i.Distance,
-			&i.StartIndex,
-			&i.EndIndex,
-			&i.DeviceWatts,
-			&i.AverageWatts,
-			&i.KomRank,
-			&i.PrRank,
+			&i.AverageGrade,
+			&i.MaximumGrade,
+			&i.ElevationHigh,
+			&i.ElevationLow,
+			&i.StartLat,
+			&i.StartLng,
+			&i.EndLat,
+			&i.EndLng,
+			&i.ClimbCategory,
+			&i.City,
+			&i.State,
+			&i.Country,
+			&i.Private,
+			&i.Hazardous,
 			&i.UpdatedAt,
-			&i.ActivitiesID,
 		); err != nil {
 			return nil, err
 		}
@@ -2254,6 +3962,123 @@ func (q *sqlQuerier) LoadedSegments(ctx context.Context) ([]LoadedSegmentsRow, e
 	return items, nil
 }
 
+const missingSegmentLocations = `-- name: MissingSegmentLocations :many
+SELECT
+	segment_id
+FROM
+	segment_efforts
+WHERE
+	NOT EXISTS (SELECT 1 FROM segment_locations WHERE segment_locations.id = segment_efforts.segment_id)
+GROUP BY
+	segment_id
+ORDER BY
+	count(*) DESC
+LIMIT $1
+`
+
+// MissingSegmentLocations returns segments with efforts that are not indexed,
+// the most ridden first.
+func (q *sqlQuerier) MissingSegmentLocations(ctx context.Context, maxSegments int32) ([]int64, error) {
+	rows, err := q.db.Query(ctx, missingSegmentLocations, maxSegments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var segment_id int64
+		if err := rows.Scan(&segment_id); err != nil {
+			return nil, err
+		}
+		items = append(items, segment_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const segmentElevationGains = `-- name: SegmentElevationGains :many
+SELECT
+	id, (elevation_high - elevation_low) :: double precision AS elevation_gain
+FROM
+	segments
+WHERE
+	id = ANY($1 :: bigint[])
+`
+
+type SegmentElevationGainsRow struct {
+	ID            int64   `db:"id" json:"id"`
+	ElevationGain float64 `db:"elevation_gain" json:"elevation_gain"`
+}
+
+// SegmentElevationGains is the climb of each segment, from its lowest to its
+// highest point, in meters.
+func (q *sqlQuerier) SegmentElevationGains(ctx context.Context, segmentIds []int64) ([]SegmentElevationGainsRow, error) {
+	rows, err := q.db.Query(ctx, segmentElevationGains, segmentIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SegmentElevationGainsRow
+	for rows.Next() {
+		var i SegmentElevationGainsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ElevationGain,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const segmentLocationIndex = `-- name: SegmentLocationIndex :many
+SELECT
+	id, start_lat, start_lng, average_grade
+FROM
+	segment_locations
+WHERE
+	NOT private
+`
+
+type SegmentLocationIndexRow struct {
+	ID           int64   `db:"id" json:"id"`
+	StartLat     float64 `db:"start_lat" json:"start_lat"`
+	StartLng     float64 `db:"start_lng" json:"start_lng"`
+	AverageGrade float64 `db:"average_grade" json:"average_grade"`
+}
+
+// SegmentLocationIndex is the minimum needed to search segments by location.
+func (q *sqlQuerier) SegmentLocationIndex(ctx context.Context) ([]SegmentLocationIndexRow, error) {
+	rows, err := q.db.Query(ctx, segmentLocationIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SegmentLocationIndexRow
+	for rows.Next() {
+		var i SegmentLocationIndexRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartLat,
+			&i.StartLng,
+			&i.AverageGrade,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const starSegments = `-- name: StarSegments :exec
 INSERT INTO
 	starred_segments(
@@ -2514,6 +4339,64 @@ func (q *sqlQuerier) UpsertSegmentEffort(ctx context.Context, arg UpsertSegmentE
 	return i, err
 }
 
+const upsertSegmentLocation = `-- name: UpsertSegmentLocation :exec
+INSERT INTO
+	segment_locations(
+		id, name, activity_type, distance, average_grade, maximum_grade,
+		elevation_high, elevation_low, start_lat, start_lng, end_lat, end_lng,
+		climb_category, city, state, country, private, hazardous, updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, Now())
+ON CONFLICT
+	(id)
+	DO UPDATE SET
+		name = $2,
+		activity_type = $3,
+		distance = $4,
+		average_grade = $5,
+		maximum_grade = $6,
+		elevation_high = $7,
+		elevation_low = $8,
+		start_lat = $9,
+		start_lng = $10,
+		end_lat = $11,
+		end_lng = $12,
+		climb_category = $13,
+		city = $14,
+		state = $15,
+		country = $16,
+		private = $17,
+		hazardous = $18,
+		updated_at = Now()
+`
+
+type UpsertSegmentLocationParams struct {
+	ID            int64   `db:"id" json:"id"`
+	Name          string  `db:"name" json:"name"`
+	ActivityType  string  `db:"activity_type" json:"activity_type"`
+	Distance      float64 `db:"distance" json:"distance"`
+	AverageGrade  float64 `db:"average_grade" json:"average_grade"`
+	MaximumGrade  float64 `db:"maximum_grade" json:"maximum_grade"`
+	ElevationHigh float64 `db:"elevation_high" json:"elevation_high"`
+	ElevationLow  float64 `db:"elevation_low" json:"elevation_low"`
+	StartLat      float64 `db:"start_lat" json:"start_lat"`
+	StartLng      float64 `db:"start_lng" json:"start_lng"`
+	EndLat        float64 `db:"end_lat" json:"end_lat"`
+	EndLng        float64 `db:"end_lng" json:"end_lng"`
+	ClimbCategory int32   `db:"climb_category" json:"climb_category"`
+	City          string  `db:"city" json:"city"`
+	State         string  `db:"state" json:"state"`
+	Country       string  `db:"country" json:"country"`
+	Private       bool    `db:"private" json:"private"`
+	Hazardous     bool    `db:"hazardous" json:"hazardous"`
+}
+
+func (q *sqlQuerier) UpsertSegmentLocation(ctx context.Context, arg UpsertSegmentLocationParams) error {
+	_, err := q.db.Exec(ctx, upsertSegmentLocation, arg.ID, arg.Name, arg.ActivityType, arg.Distance, arg.AverageGrade, arg.MaximumGrade, arg.ElevationHigh, arg.ElevationLow, arg.StartLat, arg.StartLng, arg.EndLat, arg.EndLng, arg.ClimbCategory, arg.City, arg.State, arg.Country, arg.Private, arg.Hazardous)
+	return err
+}
+
 const deleteWebhookDump = `-- name: DeleteWebhookDump :exec
 DELETE FROM webhook_dump
 WHERE
@@ -2572,3 +4455,244 @@ func (q *sqlQuerier) InsertWebhookDump(ctx context.Context, rawJson string) (Web
 	err := row.Scan(&i.ID, &i.RecordedAt, &i.Raw)
 	return i, err
 }
+
+const athletesNeedingYearReview = `-- name: AthletesNeedingYearReview :many
+SELECT
+	athlete_logins.athlete_id
+FROM
+	athlete_logins
+	LEFT JOIN
+		athlete_year_reviews
+		ON athlete_year_reviews.athlete_id = athlete_logins.athlete_id
+		AND athlete_year_reviews.year = $1 :: integer
+WHERE
+	(
+		athlete_year_reviews.computed_at IS NULL
+		OR athlete_year_reviews.computed_at < (now() - interval '7 days')
+	)
+	AND EXISTS (
+		SELECT 1 FROM activity_summary
+		WHERE
+			activity_summary.athlete_id = athlete_logins.athlete_id
+			AND extract(year FROM activity_summary.start_date_local AT TIME ZONE 'UTC') = $1 :: integer
+	)
+`
+
+// AthletesNeedingYearReview have ridden in the year, and have no report or
+// one older than a week.
+func (q *sqlQuerier) AthletesNeedingYearReview(ctx context.Context, year int32) ([]int64, error) {
+	rows, err := q.db.Query(ctx, athletesNeedingYearReview, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var athlete_id int64
+		if err := rows.Scan(&athlete_id); err != nil {
+			return nil, err
+		}
+		items = append(items, athlete_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAthleteYearReview = `-- name: GetAthleteYearReview :one
+SELECT
+	*
+FROM
+	athlete_year_reviews
+WHERE
+	athlete_id = $1
+	AND year = $2
+`
+
+type GetAthleteYearReviewParams struct {
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	Year      int32 `db:"year" json:"year"`
+}
+
+func (q *sqlQuerier) GetAthleteYearReview(ctx context.Context, arg GetAthleteYearReviewParams) (AthleteYearReview, error) {
+	row := q.db.QueryRow(ctx, getAthleteYearReview, arg.AthleteID, arg.Year)
+	var i AthleteYearReview
+	err := row.Scan(
+		&i.AthleteID,
+		&i.Year,
+		&i.Report,
+		&i.ComputedAt,
+		&i.Shared,
+	)
+	return i, err
+}
+
+const setAthleteYearReviewShared = `-- name: SetAthleteYearReviewShared :execrows
+UPDATE
+	athlete_year_reviews
+SET
+	shared = $1
+WHERE
+	athlete_id = $2
+	AND year = $3
+`
+
+type SetAthleteYearReviewSharedParams struct {
+	Shared    bool  `db:"shared" json:"shared"`
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	Year      int32 `db:"year" json:"year"`
+}
+
+func (q *sqlQuerier) SetAthleteYearReviewShared(ctx context.Context, arg SetAthleteYearReviewSharedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setAthleteYearReviewShared, arg.Shared, arg.AthleteID, arg.Year)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertAthleteYearReview = `-- name: UpsertAthleteYearReview :exec
+INSERT INTO
+	athlete_year_reviews(
+		athlete_id, year, report, computed_at
+	)
+VALUES
+	($1, $2, $3, Now())
+ON CONFLICT
+	(athlete_id, year)
+	DO UPDATE SET
+		report = $3,
+		computed_at = Now()
+`
+
+type UpsertAthleteYearReviewParams struct {
+	AthleteID int64  `db:"athlete_id" json:"athlete_id"`
+	Year      int32  `db:"year" json:"year"`
+	Report    []byte `db:"report" json:"report"`
+}
+
+func (q *sqlQuerier) UpsertAthleteYearReview(ctx context.Context, arg UpsertAthleteYearReviewParams) error {
+	_, err := q.db.Exec(ctx, upsertAthleteYearReview, arg.AthleteID, arg.Year, arg.Report)
+	return err
+}
+
+const yearReviewActivities = `-- name: YearReviewActivities :many
+SELECT
+	id, name, start_date_local, sport_type, distance, total_elevation_gain, moving_time
+FROM
+	activity_summary
+WHERE
+	athlete_id = $1
+	AND extract(year FROM start_date_local AT TIME ZONE 'UTC') = $2 :: integer
+	AND NOT private
+ORDER BY
+	start_date_local
+`
+
+type YearReviewActivitiesParams struct {
+	AthleteID int64 `db:"athlete_id" json:"athlete_id"`
+	Year      int32 `db:"year" json:"year"`
+}
+
+type YearReviewActivitiesRow struct {
+	ID                 int64              `db:"id" json:"id"`
+	Name               string             `db:"name" json:"name"`
+	StartDateLocal     pgtype.Timestamptz `db:"start_date_local" json:"start_date_local"`
+	SportType          string             `db:"sport_type" json:"sport_type"`
+	Distance           float64            `db:"distance" json:"distance"`
+	TotalElevationGain float64            `db:"total_elevation_gain" json:"total_elevation_gain"`
+	MovingTime         float64            `db:"moving_time" json:"moving_time"`
+}
+
+// YearReviewActivities are the public activities of every sport started in
+// the local calendar year.
+func (q *sqlQuerier) YearReviewActivities(ctx context.Context, arg YearReviewActivitiesParams) ([]YearReviewActivitiesRow, error) {
+	rows, err := q.db.Query(ctx, yearReviewActivities, arg.AthleteID, arg.Year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []YearReviewActivitiesRow
+	for rows.Next() {
+		var i YearReviewActivitiesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.StartDateLocal,
+			&i.SportType,
+			&i.Distance,
+			&i.TotalElevationGain,
+			&i.MovingTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const yearReviewSegments = `-- name: YearReviewSegments :many
+SELECT
+	segment_efforts.segment_id,
+	max(segment_efforts.name) :: text AS name,
+	count(*) AS efforts,
+	min(segment_efforts.elapsed_time) :: double precision AS best_time
+FROM
+	segment_efforts
+	INNER JOIN
+		activity_summary
+		ON activity_summary.id = segment_efforts.activities_id
+WHERE
+	segment_efforts.athlete_id = $1
+	AND NOT activity_summary.private
+	AND extract(year FROM segment_efforts.start_date_local AT TIME ZONE 'UTC') = $2 :: integer
+GROUP BY
+	segment_efforts.segment_id
+ORDER BY
+	efforts DESC, segment_efforts.segment_id
+LIMIT $3 :: integer
+`
+
+type YearReviewSegmentsParams struct {
+	AthleteID   int64 `db:"athlete_id" json:"athlete_id"`
+	Year        int32 `db:"year" json:"year"`
+	MaxSegments int32 `db:"max_segments" json:"max_segments"`
+}
+
+type YearReviewSegmentsRow struct {
+	SegmentID int64   `db:"segment_id" json:"segment_id"`
+	Name      string  `db:"name" json:"name"`
+	Efforts   int64   `db:"efforts" json:"efforts"`
+	BestTime  float64 `db:"best_time" json:"best_time"`
+}
+
+// YearReviewSegments are the segments the athlete rode most in the year, on
+// public activities.
+func (q *sqlQuerier) YearReviewSegments(ctx context.Context, arg YearReviewSegmentsParams) ([]YearReviewSegmentsRow, error) {
+	rows, err := q.db.Query(ctx, yearReviewSegments, arg.AthleteID, arg.Year, arg.MaxSegments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []YearReviewSegmentsRow
+	for rows.Next() {
+		var i YearReviewSegmentsRow
+		if err := rows.Scan(
+			&i.SegmentID,
+			&i.Name,
+			&i.Efforts,
+			&i.BestTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
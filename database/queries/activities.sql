@@ -177,4 +177,43 @@ WHERE
   AND lower(activity_summary.activity_type) = ANY(ARRAY['ride', 'virtualride'])
   AND
 	(SELECT count(*) FROM segment_efforts WHERE activities_id = activity_detail.id) = 0
-;
\ No newline at end of file
+;
+
+-- name: ActivityCalendar :many
+-- ActivityCalendar is the activities of an athlete started in the local time
+-- range, with the power and effort from the detail when it has been fetched.
+SELECT
+	activity_summary.id,
+	activity_summary.start_date_local,
+	activity_summary.distance,
+	activity_summary.total_elevation_gain,
+	activity_summary.moving_time,
+	activity_summary.device_watts,
+	activity_summary.average_heartrate,
+	COALESCE(activity_detail.kilojoules, 0) :: double precision AS kilojoules,
+	COALESCE(activity_detail.suffer_score, 0) :: integer AS suffer_score,
+	COALESCE(activity_detail.average_watts, 0) :: double precision AS average_watts,
+	COALESCE(activity_detail.weighted_average_watts, 0) :: double precision AS weighted_average_watts
+FROM
+	activity_summary
+	LEFT JOIN
+		activity_detail
+		ON activity_detail.id = activity_summary.id
+WHERE
+	activity_summary.athlete_id = @athlete_id
+	AND activity_summary.start_date_local >= @after :: timestamptz
+	AND activity_summary.start_date_local < @before :: timestamptz
+ORDER BY
+	activity_summary.start_date_local
+;
+
+-- name: ActivityAthleteCounts :many
+-- ActivityAthleteCounts is how many athletes Strava saw on each activity,
+-- including its own.
+SELECT
+	id, athlete_count
+FROM
+	activity_summary
+WHERE
+	id = ANY(@activity_ids :: bigint[])
+;
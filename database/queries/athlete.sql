@@ -169,4 +169,13 @@ ON CONFLICT
 		profile_pic_link_medium = $19
 RETURNING *;
 
-
+-- name: AthleteWeights :many
+-- AthleteWeights are the weights in kilograms Strava has for the athletes,
+-- zero when the athlete has not set one.
+SELECT
+	id, weight
+FROM
+	athletes
+WHERE
+	id = ANY(@athlete_ids :: bigint[])
+;
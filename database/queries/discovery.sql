@@ -0,0 +1,55 @@
+-- name: HugelDiscoveryCandidates :many
+-- HugelDiscoveryCandidates returns the summary track of every ride in the
+-- window that was never fetched in detail and is not already queued.
+SELECT
+	activity_summary.id AS activity_id,
+	activity_summary.athlete_id,
+	maps.summary_polyline
+FROM
+	activity_summary
+INNER JOIN
+	maps ON activity_summary.map_id = maps.id
+LEFT JOIN
+	activity_detail ON activity_summary.id = activity_detail.id
+LEFT JOIN
+	hugel_discoveries ON activity_summary.id = hugel_discoveries.activity_id
+WHERE
+	activity_detail.id IS NULL
+	AND hugel_discoveries.enqueued_at IS NULL
+	AND activity_summary.start_date >= @start_date
+	AND activity_summary.start_date < @end_date
+	AND (activity_summary.sport_type ILIKE '%ride%' OR activity_summary.sport_type ILIKE '%bike%')
+	AND maps.summary_polyline != ''
+;
+
+-- name: UpsertHugelDiscovery :exec
+INSERT INTO
+	hugel_discoveries(activity_id, athlete_id, route_name, hits, checkpoints, score)
+VALUES
+	(@activity_id, @athlete_id, @route_name, @hits, @checkpoints, @score)
+ON CONFLICT
+	(activity_id)
+	DO UPDATE SET
+		route_name = @route_name,
+		hits = @hits,
+		checkpoints = @checkpoints,
+		score = @score
+;
+
+-- name: MarkHugelDiscoveriesEnqueued :exec
+UPDATE
+	hugel_discoveries
+SET
+	enqueued_at = Now()
+WHERE
+	activity_id = ANY(@activity_ids :: bigint[])
+;
+
+-- name: HugelDiscoveries :many
+SELECT
+	*
+FROM
+	hugel_discoveries
+ORDER BY
+	score DESC, activity_id
+;
@@ -5,22 +5,37 @@ INSERT INTO
 		miles_histogram,
 		current_eddington,
 		last_calculated,
-		total_activities
+		total_activities,
+		kilometers_histogram,
+		current_eddington_km,
+		total_days
 )
 VALUES
-	($1, $2, $3, $4, $5)
+	($1, $2, $3, $4, $5, $6, $7, $8)
 ON CONFLICT
 	(athlete_id)
 	DO UPDATE SET
 		miles_histogram = $2,
 		current_eddington = $3,
 		last_calculated = $4,
-		total_activities = $5
+		total_activities = $5,
+		kilometers_histogram = $6,
+		current_eddington_km = $7,
+		total_days = $8
 RETURNING *;
 
 -- name: EddingtonActivities :many
 SELECT
-	id, distance, total_elevation_gain
+	id, distance, total_elevation_gain, start_date_local, activity_type, moving_time,
+	(
+		SELECT
+			count(DISTINCT segment_efforts.segment_id)
+		FROM
+			segment_efforts
+		WHERE
+			segment_efforts.activities_id = activity_summary.id
+			AND segment_efforts.segment_id = ANY(SELECT unnest(segments) FROM competitive_routes WHERE name = 'das-hugel')
+	) :: integer AS hugel_segments
 FROM
 	activity_summary
 WHERE
@@ -39,6 +54,8 @@ WHERE
 ;
 
 -- name: AthletesNeedingEddington :many
+-- AthletesNeedingEddington are due a full recalculation. Changed activities
+-- update the numbers as they happen, this only catches drift.
 SELECT
 	athlete_logins.athlete_id, athlete_eddingtons.last_calculated
 FROM
@@ -59,4 +76,207 @@ SELECT
 	athlete_eddingtons.total_activities
 FROM
 	athlete_eddingtons
-;
\ No newline at end of file
+;
+
+-- name: DeleteAthleteEddingtonBreakdowns :exec
+DELETE FROM
+	athlete_eddington_breakdowns
+WHERE
+	athlete_id = @athlete_id
+;
+
+-- name: InsertAthleteEddingtonBreakdown :exec
+INSERT INTO
+	athlete_eddington_breakdowns(
+		athlete_id, sport, year, days,
+		miles_histogram, eddington_miles,
+		kilometers_histogram, eddington_kilometers
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+;
+
+-- name: GetAthleteEddingtonBreakdowns :many
+SELECT
+	*
+FROM
+	athlete_eddington_breakdowns
+WHERE
+	athlete_id = @athlete_id
+ORDER BY
+	sport, year
+;
+
+-- name: InsertEddingtonEvent :execrows
+-- InsertEddingtonEvent records a number going up. A number that is reached
+-- again, after a ride was deleted and redone, keeps its first event.
+INSERT INTO
+	eddington_events(
+		athlete_id, eddington, previous, reached_on, activity_id
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(athlete_id, eddington) DO NOTHING
+;
+
+-- name: AthleteDailyTotals :many
+SELECT
+	*
+FROM
+	athlete_daily_totals
+WHERE
+	athlete_id = @athlete_id AND
+	day = ANY(@days::date[])
+;
+
+-- name: AthleteHasDailyTotals :one
+SELECT EXISTS(
+	SELECT 1 FROM athlete_daily_totals WHERE athlete_id = @athlete_id
+);
+
+-- name: DeleteAthleteDailyTotals :exec
+DELETE FROM
+	athlete_daily_totals
+WHERE
+	athlete_id = @athlete_id AND
+	(@all_days::boolean OR day = ANY(@days::date[]))
+;
+
+-- name: InsertAthleteDailyTotals :execrows
+-- InsertAthleteDailyTotals sums the rides of the days from the activities,
+-- or of every day. The day is the local day the ride started.
+INSERT INTO
+	athlete_daily_totals(
+		athlete_id, day, sport, distance, activities, elevation_gain, moving_time, hugel_segments
+	)
+SELECT
+	athlete_id,
+	(start_date_local AT TIME ZONE 'UTC')::date AS day,
+	lower(activity_type) AS sport,
+	sum(distance),
+	count(*),
+	sum(total_elevation_gain),
+	sum(moving_time),
+	sum(hugel_segments)
+FROM
+	(
+		SELECT
+			athlete_id, start_date_local, activity_type, distance, total_elevation_gain, moving_time,
+			(
+				SELECT
+					count(DISTINCT segment_efforts.segment_id)
+				FROM
+					segment_efforts
+				WHERE
+					segment_efforts.activities_id = activity_summary.id
+					AND segment_efforts.segment_id = ANY(SELECT unnest(segments) FROM competitive_routes WHERE name = 'das-hugel')
+			) AS hugel_segments
+		FROM
+			activity_summary
+		WHERE
+			athlete_id = @athlete_id
+			AND lower(activity_type) = ANY(ARRAY['ride', 'virtualride'])
+			AND (@all_days::boolean OR (start_date_local AT TIME ZONE 'UTC')::date = ANY(@days::date[]))
+	) AS rides
+GROUP BY
+	athlete_id, day, sport
+;
+
+-- name: UpsertAthleteEddingtonMetric :exec
+INSERT INTO
+	athlete_eddington_metrics(
+		athlete_id, metric, days, histogram, eddington
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(athlete_id, metric)
+	DO UPDATE SET
+		days = $3,
+		histogram = $4,
+		eddington = $5
+;
+
+-- name: GetAthleteEddingtonMetrics :many
+SELECT
+	*
+FROM
+	athlete_eddington_metrics
+WHERE
+	athlete_id = @athlete_id
+ORDER BY
+	metric
+;
+
+-- name: EddingtonMetricLeaderboard :many
+-- EddingtonMetricLeaderboard ranks every athlete with the metric, ties share
+-- a rank.
+SELECT
+	RANK() OVER (ORDER BY athlete_eddington_metrics.eddington DESC) AS rank,
+	athlete_eddington_metrics.athlete_id,
+	athlete_eddington_metrics.eddington,
+	athlete_eddington_metrics.days
+FROM
+	athlete_eddington_metrics
+WHERE
+	athlete_eddington_metrics.metric = @metric
+	AND athlete_eddington_metrics.eddington > 0
+	AND NOT EXISTS (
+		SELECT 1 FROM leaderboard_opt_outs WHERE leaderboard_opt_outs.athlete_id = athlete_eddington_metrics.athlete_id
+	)
+ORDER BY
+	rank, athlete_eddington_metrics.athlete_id
+;
+
+-- name: EddingtonLeaderboard :many
+-- EddingtonLeaderboard is the number of all rides for every athlete, for the
+-- lifetime and each year, with their profile. Athletes who opted out of the
+-- leaderboards are left out.
+SELECT
+	athlete_eddington_breakdowns.athlete_id,
+	athlete_eddington_breakdowns.year,
+	athlete_eddington_breakdowns.days,
+	athlete_eddington_breakdowns.eddington_miles,
+	athlete_eddington_breakdowns.eddington_kilometers,
+	athlete_eddingtons.total_activities,
+	athletes.username,
+	athletes.firstname,
+	athletes.lastname,
+	athletes.sex,
+	athletes.city,
+	athletes.profile_pic_link
+FROM
+	athlete_eddington_breakdowns
+INNER JOIN
+	athlete_eddingtons ON athlete_eddington_breakdowns.athlete_id = athlete_eddingtons.athlete_id
+INNER JOIN
+	athletes ON athlete_eddington_breakdowns.athlete_id = athletes.id
+WHERE
+	athlete_eddington_breakdowns.sport = 'all'
+	AND NOT EXISTS (
+		SELECT 1 FROM leaderboard_opt_outs WHERE leaderboard_opt_outs.athlete_id = athlete_eddington_breakdowns.athlete_id
+	)
+;
+
+-- name: LeaderboardOptedOut :one
+SELECT EXISTS(
+	SELECT 1 FROM leaderboard_opt_outs WHERE athlete_id = @athlete_id
+);
+
+-- name: InsertLeaderboardOptOut :exec
+INSERT INTO
+	leaderboard_opt_outs(athlete_id)
+VALUES
+	(@athlete_id)
+ON CONFLICT
+	(athlete_id)
+	DO NOTHING
+;
+
+-- name: DeleteLeaderboardOptOut :exec
+DELETE FROM
+	leaderboard_opt_outs
+WHERE
+	athlete_id = @athlete_id
+;
@@ -0,0 +1,79 @@
+-- name: GetGPSSegmentEfforts :many
+SELECT
+	*
+FROM
+	gps_segment_efforts
+WHERE
+	activity_id = @activity_id
+ORDER BY
+	start_date ASC
+;
+
+-- name: UpsertGPSSegmentEffort :exec
+INSERT INTO
+	gps_segment_efforts(
+		activity_id, segment_id, athlete_id, start_date, elapsed_time,
+		distance, start_index, end_index, coverage
+	)
+VALUES
+	(@activity_id, @segment_id, @athlete_id, @start_date, @elapsed_time,
+	 @distance, @start_index, @end_index, @coverage)
+ON CONFLICT
+	(activity_id, segment_id)
+	DO UPDATE SET
+		start_date = @start_date,
+		elapsed_time = @elapsed_time,
+		distance = @distance,
+		start_index = @start_index,
+		end_index = @end_index,
+		coverage = @coverage
+;
+
+-- name: UpsertUnofficialRouteResult :exec
+INSERT INTO
+	unofficial_route_results(route_name, activity_id, athlete_id, total_time_seconds, gps_segment_ids)
+VALUES
+	(@route_name, @activity_id, @athlete_id, @total_time_seconds, @gps_segment_ids :: bigint[])
+ON CONFLICT
+	(route_name, activity_id)
+	DO UPDATE SET
+		total_time_seconds = @total_time_seconds,
+		gps_segment_ids = @gps_segment_ids :: bigint[]
+;
+
+-- name: UnofficialRouteResults :many
+-- UnofficialRouteResults returns the GPS matched results of a route, fastest
+-- first. Activities that have since become official results are left out.
+SELECT
+	unofficial_route_results.activity_id,
+	unofficial_route_results.athlete_id,
+	unofficial_route_results.total_time_seconds,
+	unofficial_route_results.gps_segment_ids,
+
+	activity_summary.name,
+	activity_summary.start_date,
+
+	athletes.firstname,
+	athletes.lastname,
+	athletes.username,
+	athletes.profile_pic_link,
+	athletes.sex
+FROM
+	unofficial_route_results
+INNER JOIN
+	competitive_routes ON unofficial_route_results.route_name = competitive_routes.name
+INNER JOIN
+	activity_summary ON unofficial_route_results.activity_id = activity_summary.id
+INNER JOIN
+	athletes ON unofficial_route_results.athlete_id = athletes.id
+WHERE
+	unofficial_route_results.route_name = @route_name
+	AND NOT (
+		competitive_routes.segments <@ ARRAY(
+			SELECT segment_id FROM segment_efforts
+			WHERE segment_efforts.activities_id = unofficial_route_results.activity_id
+		)
+	)
+ORDER BY
+	unofficial_route_results.total_time_seconds ASC
+;
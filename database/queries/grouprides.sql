@@ -0,0 +1,23 @@
+-- name: EventGroupRides :one
+SELECT
+	*
+FROM
+	event_group_rides
+WHERE
+	route_name = @route_name
+;
+
+-- name: UpsertEventGroupRides :exec
+INSERT INTO
+	event_group_rides(
+		route_name, groups, seen, computed_at
+	)
+VALUES
+	(@route_name, @groups, @seen :: bigint[], Now())
+ON CONFLICT
+	(route_name)
+	DO UPDATE SET
+		groups = @groups,
+		seen = @seen :: bigint[],
+		computed_at = Now()
+;
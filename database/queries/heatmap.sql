@@ -0,0 +1,73 @@
+-- name: DeleteHeatmapOptOut :exec
+DELETE FROM
+	heatmap_opt_outs
+WHERE
+	athlete_id = @athlete_id
+;
+
+-- name: EventHeatmap :one
+SELECT
+	*
+FROM
+	event_heatmaps
+WHERE
+	route_name = @route_name
+;
+
+-- name: HeatmapOptedOut :one
+SELECT EXISTS(
+	SELECT 1 FROM heatmap_opt_outs WHERE athlete_id = @athlete_id
+);
+
+-- name: HeatmapPolylines :many
+-- HeatmapPolylines returns the summary tracks of the activities that may be
+-- shown on a heatmap. Private activities and athletes who opted out are left
+-- out.
+SELECT
+	activity_summary.id AS activity_id,
+	maps.summary_polyline
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.id = ANY(@activity_ids :: bigint[])
+	AND NOT activity_summary.private
+	AND NOT EXISTS(
+		SELECT 1 FROM heatmap_opt_outs
+		WHERE heatmap_opt_outs.athlete_id = activity_summary.athlete_id
+	)
+;
+
+-- name: InsertHeatmapOptOut :exec
+INSERT INTO
+	heatmap_opt_outs(athlete_id)
+VALUES
+	(@athlete_id)
+ON CONFLICT
+	(athlete_id)
+	DO NOTHING
+;
+
+-- name: UpsertEventHeatmap :exec
+INSERT INTO
+	event_heatmaps(
+		route_name, min_lat, min_lng, max_lat, max_lng,
+		width, height, cells, activities, computed_at
+	)
+VALUES
+	(@route_name, @min_lat, @min_lng, @max_lat, @max_lng,
+	 @width, @height, @cells :: integer[], @activities, Now())
+ON CONFLICT
+	(route_name)
+	DO UPDATE SET
+		min_lat = @min_lat,
+		min_lng = @min_lng,
+		max_lat = @max_lat,
+		max_lng = @max_lng,
+		width = @width,
+		height = @height,
+		cells = @cells :: integer[],
+		activities = @activities,
+		computed_at = Now()
+;
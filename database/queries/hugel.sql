@@ -176,3 +176,77 @@ WHERE
 		from segment_efforts WHERE
 		    activities_id = @activity_id
 	);
+
+-- name: RouteActivityResults :many
+-- RouteActivityResults returns every edition result of the given activities.
+-- Each row is tagged with the competitive route it was scored against, so
+-- results from different editions can be aligned by segment.
+SELECT
+	results.route_name,
+	results.activity_id,
+	results.athlete_id,
+	results.total_time_seconds,
+	results.efforts,
+
+	activity_summary.name,
+	activity_summary.start_date,
+	activity_summary.elapsed_time,
+	activity_summary.moving_time,
+	activity_summary.average_heartrate,
+	activity_summary.device_watts,
+	COALESCE(activity_detail.average_watts, 0) :: double precision AS average_watts,
+
+	athletes.firstname,
+	athletes.lastname,
+	athletes.username,
+	athletes.profile_pic_link,
+	athletes.sex
+FROM
+	edition_results AS results
+INNER JOIN
+	activity_summary ON results.activity_id = activity_summary.id
+LEFT JOIN
+	activity_detail ON results.activity_id = activity_detail.id
+INNER JOIN
+	athletes ON results.athlete_id = athletes.id
+WHERE
+	results.activity_id = ANY(@activity_ids :: bigint[])
+;
+
+-- name: RouteFatigueSamples :many
+-- RouteFatigueSamples pairs every climb of the given routes' results with the
+-- athlete's efforts on that climb from before the result was ridden. The ratio
+-- between the day and the form those efforts show is how much slower riders
+-- climb on the day.
+SELECT
+	results.route_name,
+	results.activity_id,
+	results.athlete_id,
+	activity_summary.start_date,
+	effort.segment_id :: bigint AS segment_id,
+	effort.start_date :: timestamptz AS effort_start_date,
+	effort.elapsed_time :: double precision AS elapsed_time,
+	prior.elapsed_times :: double precision[] AS prior_elapsed_times,
+	prior.start_dates :: timestamptz[] AS prior_start_dates
+FROM
+	edition_results AS results
+INNER JOIN
+	activity_summary ON results.activity_id = activity_summary.id
+CROSS JOIN LATERAL
+	jsonb_to_recordset(results.efforts :: jsonb) AS effort(segment_id bigint, elapsed_time bigint, start_date timestamptz)
+INNER JOIN LATERAL
+	(
+		SELECT
+			array_agg(segment_efforts.elapsed_time ORDER BY segment_efforts.start_date) AS elapsed_times,
+			array_agg(segment_efforts.start_date ORDER BY segment_efforts.start_date) AS start_dates
+		FROM
+			segment_efforts
+		WHERE
+			segment_efforts.athlete_id = results.athlete_id AND
+			segment_efforts.segment_id = effort.segment_id AND
+			segment_efforts.activities_id != results.activity_id AND
+			segment_efforts.start_date < effort.start_date
+	) AS prior ON prior.elapsed_times IS NOT NULL
+WHERE
+	results.route_name = ANY(@route_names :: text[])
+;
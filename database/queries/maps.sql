@@ -1,3 +1,14 @@
+-- name: GetActivityMap :one
+SELECT
+	maps.*
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.id = @activity_id
+;
+
 -- name: UpsertMapData :one
 INSERT INTO
 	maps(
@@ -21,3 +32,28 @@ ON CONFLICT
 			END
 RETURNING *;
 
+-- name: GetActivitySummaryPolylines :many
+SELECT
+	activity_summary.id AS activity_id,
+	maps.summary_polyline
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.id = ANY(@activity_ids :: bigint[]);
+
+-- name: GetSummaryPolylinesBetween :many
+-- GetSummaryPolylinesBetween returns the summary track of every ride that
+-- started in the window.
+SELECT
+	activity_summary.id AS activity_id,
+	maps.summary_polyline
+FROM
+	maps
+INNER JOIN
+	activity_summary ON activity_summary.map_id = maps.id
+WHERE
+	activity_summary.start_date >= @start_date
+	AND activity_summary.start_date < @end_date
+	AND (activity_summary.sport_type ILIKE '%ride%' OR activity_summary.sport_type ILIKE '%bike%');
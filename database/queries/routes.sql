@@ -45,4 +45,15 @@ WHERE
 ;
 
 -- name: AllCompetitiveRoutes :many
-SELECT * FROM competitive_routes;
\ No newline at end of file
+SELECT * FROM competitive_routes;
+
+-- name: GetCompetitiveRouteByName :one
+SELECT * FROM competitive_routes WHERE name = @route_name;
+
+-- name: SetCompetitiveRouteReference :execrows
+UPDATE
+	competitive_routes
+SET
+	reference_activity_id = @reference_activity_id
+WHERE
+	name = @route_name;
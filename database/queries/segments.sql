@@ -123,3 +123,130 @@ ON CONFLICT
 	fetched_at = Now()
 
 RETURNING *;
+
+-- name: GetPersonalSegmentEfforts :many
+-- GetPersonalSegmentEfforts returns every effort of an athlete on the given
+-- segments, newest first.
+SELECT
+	*
+FROM
+	segment_efforts
+WHERE
+	athlete_id = @athlete_id AND
+	segment_id = ANY(@segment_ids::bigint[])
+ORDER BY
+	start_date DESC
+;
+
+-- name: GetActivitySegmentEfforts :many
+-- GetActivitySegmentEfforts returns the efforts of an activity in the order
+-- they were ridden.
+SELECT
+	*
+FROM
+	segment_efforts
+WHERE
+	activities_id = @activity_id
+ORDER BY
+	start_date ASC
+;
+
+-- name: UpsertSegmentLocation :exec
+INSERT INTO
+	segment_locations(
+		id, name, activity_type, distance, average_grade, maximum_grade,
+		elevation_high, elevation_low, start_lat, start_lng, end_lat, end_lng,
+		climb_category, city, state, country, private, hazardous, updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, Now())
+ON CONFLICT
+	(id)
+	DO UPDATE SET
+		name = $2,
+		activity_type = $3,
+		distance = $4,
+		average_grade = $5,
+		maximum_grade = $6,
+		elevation_high = $7,
+		elevation_low = $8,
+		start_lat = $9,
+		start_lng = $10,
+		end_lat = $11,
+		end_lng = $12,
+		climb_category = $13,
+		city = $14,
+		state = $15,
+		country = $16,
+		private = $17,
+		hazardous = $18,
+		updated_at = Now()
+;
+
+-- name: FillSegmentLocations :execrows
+-- FillSegmentLocations copies the loaded route segments that are not indexed
+-- yet, they are already known without asking Strava.
+INSERT INTO
+	segment_locations(
+		id, name, activity_type, distance, average_grade, maximum_grade,
+		elevation_high, elevation_low, start_lat, start_lng, end_lat, end_lng,
+		climb_category, city, state, country, private, hazardous, updated_at
+	)
+SELECT
+	id, name, activity_type, distance, average_grade, maximum_grade,
+	elevation_high, elevation_low, start_latlng[1], start_latlng[2], end_latlng[1], end_latlng[2],
+	climb_category, city, state, country, private, hazardous, Now()
+FROM
+	segments
+WHERE
+	array_length(start_latlng, 1) = 2 AND
+	array_length(end_latlng, 1) = 2
+ON CONFLICT
+	(id) DO NOTHING
+;
+
+-- name: MissingSegmentLocations :many
+-- MissingSegmentLocations returns segments with efforts that are not indexed,
+-- the most ridden first.
+SELECT
+	segment_id
+FROM
+	segment_efforts
+WHERE
+	NOT EXISTS (SELECT 1 FROM segment_locations WHERE segment_locations.id = segment_efforts.segment_id)
+GROUP BY
+	segment_id
+ORDER BY
+	count(*) DESC
+LIMIT @max_segments
+;
+
+-- name: SegmentLocationIndex :many
+-- SegmentLocationIndex is the minimum needed to search segments by location.
+SELECT
+	id, start_lat, start_lng, average_grade
+FROM
+	segment_locations
+WHERE
+	NOT private
+;
+
+-- name: GetSegmentLocations :many
+SELECT
+	*
+FROM
+	segment_locations
+WHERE
+	id = ANY(@segment_ids::bigint[])
+;
+
+-- name: SegmentElevationGains :many
+-- SegmentElevationGains is the climb of each segment, from its lowest to its
+-- highest point, in meters.
+SELECT
+	id, (elevation_high - elevation_low) :: double precision AS elevation_gain
+FROM
+	segments
+WHERE
+	id = ANY(@segment_ids :: bigint[])
+;
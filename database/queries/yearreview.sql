@@ -0,0 +1,96 @@
+-- name: YearReviewActivities :many
+-- YearReviewActivities are the public activities of every sport started in
+-- the local calendar year.
+SELECT
+	id, name, start_date_local, sport_type, distance, total_elevation_gain, moving_time
+FROM
+	activity_summary
+WHERE
+	athlete_id = @athlete_id
+	AND extract(year FROM start_date_local AT TIME ZONE 'UTC') = @year :: integer
+	AND NOT private
+ORDER BY
+	start_date_local
+;
+
+-- name: YearReviewSegments :many
+-- YearReviewSegments are the segments the athlete rode most in the year, on
+-- public activities.
+SELECT
+	segment_efforts.segment_id,
+	max(segment_efforts.name) :: text AS name,
+	count(*) AS efforts,
+	min(segment_efforts.elapsed_time) :: double precision AS best_time
+FROM
+	segment_efforts
+	INNER JOIN
+		activity_summary
+		ON activity_summary.id = segment_efforts.activities_id
+WHERE
+	segment_efforts.athlete_id = @athlete_id
+	AND NOT activity_summary.private
+	AND extract(year FROM segment_efforts.start_date_local AT TIME ZONE 'UTC') = @year :: integer
+GROUP BY
+	segment_efforts.segment_id
+ORDER BY
+	efforts DESC, segment_efforts.segment_id
+LIMIT @max_segments :: integer
+;
+
+-- name: SetAthleteYearReviewShared :execrows
+UPDATE
+	athlete_year_reviews
+SET
+	shared = @shared
+WHERE
+	athlete_id = @athlete_id
+	AND year = @year
+;
+
+-- name: UpsertAthleteYearReview :exec
+INSERT INTO
+	athlete_year_reviews(
+		athlete_id, year, report, computed_at
+	)
+VALUES
+	(@athlete_id, @year, @report, Now())
+ON CONFLICT
+	(athlete_id, year)
+	DO UPDATE SET
+		report = @report,
+		computed_at = Now()
+;
+
+-- name: GetAthleteYearReview :one
+SELECT
+	*
+FROM
+	athlete_year_reviews
+WHERE
+	athlete_id = @athlete_id
+	AND year = @year
+;
+
+-- name: AthletesNeedingYearReview :many
+-- AthletesNeedingYearReview have ridden in the year, and have no report or
+-- one older than a week.
+SELECT
+	athlete_logins.athlete_id
+FROM
+	athlete_logins
+	LEFT JOIN
+		athlete_year_reviews
+		ON athlete_year_reviews.athlete_id = athlete_logins.athlete_id
+		AND athlete_year_reviews.year = @year :: integer
+WHERE
+	(
+		athlete_year_reviews.computed_at IS NULL
+		OR athlete_year_reviews.computed_at < (now() - interval '7 days')
+	)
+	AND EXISTS (
+		SELECT 1 FROM activity_summary
+		WHERE
+			activity_summary.athlete_id = athlete_logins.athlete_id
+			AND extract(year FROM activity_summary.start_date_local AT TIME ZONE 'UTC') = @year :: integer
+	)
+;
@@ -0,0 +1,132 @@
+// Package candidate guesses from the summary track of an activity whether it
+// could be a result on a competitive route. Activity lists from Strava come
+// with a summary polyline, so the guess is free, unlike the detail fetch that
+// returns the segment efforts the results are actually scored from.
+package candidate
+
+import (
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+const (
+	// DefaultRadius is how close in meters the track has to pass a segment's
+	// start or end. Summary polylines are simplified, so they can cut corners
+	// by a fair amount.
+	DefaultRadius = 150
+	// DefaultThreshold is the fraction of checkpoints a candidate passes.
+	// Riders skip climbs and lite routes share climbs with the full route,
+	// so it is well below 1.
+	DefaultThreshold = 0.5
+)
+
+// Route is a competitive route reduced to the start and end points of its
+// segments.
+type Route struct {
+	Name        string
+	Checkpoints []geo.Point
+}
+
+// NewRoute returns the checkpoints of the given route segments. Segments
+// without a location are skipped.
+func NewRoute(name string, segments []database.Segment) Route {
+	r := Route{Name: name}
+	for _, seg := range segments {
+		for _, latlng := range [][]float64{seg.StartLatlng, seg.EndLatlng} {
+			if len(latlng) != 2 {
+				continue
+			}
+			r.Checkpoints = append(r.Checkpoints, geo.Point{Lat: latlng[0], Lng: latlng[1]})
+		}
+	}
+	return r
+}
+
+// Score is how well a track matches its best route.
+type Score struct {
+	RouteName string
+	// Hits is how many checkpoints the track passes within the radius.
+	Hits        int
+	Checkpoints int
+}
+
+// Fraction is the share of the route's checkpoints the track passes.
+func (s Score) Fraction() float64 {
+	if s.Checkpoints == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Checkpoints)
+}
+
+type Scorer struct {
+	Radius    float64
+	Threshold float64
+	routes    []scoredRoute
+}
+
+type scoredRoute struct {
+	Route
+	// bounds of the checkpoints padded by the radius. Tracks that miss it
+	// entirely are not checked point by point.
+	bounds geo.BBox
+}
+
+// NewScorer returns a scorer with the default radius and threshold.
+func NewScorer(routes ...Route) *Scorer {
+	s := &Scorer{
+		Radius:    DefaultRadius,
+		Threshold: DefaultThreshold,
+	}
+	for _, r := range routes {
+		if len(r.Checkpoints) == 0 {
+			continue
+		}
+		s.routes = append(s.routes, scoredRoute{
+			Route:  r,
+			bounds: geo.Bounds(r.Checkpoints),
+		})
+	}
+	return s
+}
+
+// Score returns the score of the route the track matches best.
+func (s *Scorer) Score(track []geo.Point) Score {
+	var best Score
+	if len(track) == 0 {
+		return best
+	}
+
+	trackBounds := geo.Bounds(track)
+	for _, r := range s.routes {
+		score := Score{RouteName: r.Name, Checkpoints: len(r.Checkpoints)}
+		if trackBounds.Intersects(r.bounds.Pad(s.Radius)) {
+			for _, p := range r.Checkpoints {
+				if geo.DistanceToPolyline(p, track) <= s.Radius {
+					score.Hits++
+				}
+			}
+		}
+		if best.RouteName == "" || score.Fraction() > best.Fraction() {
+			best = score
+		}
+	}
+	return best
+}
+
+// ScorePolyline decodes an encoded summary polyline and scores it. The bool
+// is false if there is no track to score, e.g. trainer rides and activities
+// with a hidden map.
+func (s *Scorer) ScorePolyline(encoded string) (Score, bool) {
+	// A broken polyline still scores the part that decoded.
+	track, _ := geo.DecodePolyline(encoded)
+	if len(track) < 2 {
+		return Score{}, false
+	}
+	return s.Score(track), true
+}
+
+// Candidate is true if the score is high enough that the activity might be a
+// result.
+func (s *Scorer) Candidate(score Score) bool {
+	return score.Checkpoints > 0 && score.Fraction() >= s.Threshold
+}
@@ -0,0 +1,101 @@
+package candidate_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/candidate"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+type fixture struct {
+	Map struct {
+		SummaryPolyline string `json:"summary_polyline"`
+	} `json:"map"`
+	Segments []struct {
+		StartLatlng []float64 `json:"start_latlng"`
+		EndLatlng   []float64 `json:"end_latlng"`
+	} `json:"segments"`
+}
+
+func loadFixture(t *testing.T, name string) fixture {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "strava", "testdata", name))
+	require.NoError(t, err)
+	var f fixture
+	require.NoError(t, json.Unmarshal(data, &f))
+	return f
+}
+
+func TestScorer(t *testing.T) {
+	t.Parallel()
+
+	route := loadFixture(t, "route.json")
+	segments := make([]database.Segment, 0, len(route.Segments))
+	for _, seg := range route.Segments {
+		segments = append(segments, database.Segment{StartLatlng: seg.StartLatlng, EndLatlng: seg.EndLatlng})
+	}
+	// A segment without a location has no checkpoints.
+	segments = append(segments, database.Segment{})
+
+	scorer := candidate.NewScorer(
+		candidate.NewRoute("empty", nil),
+		candidate.NewRoute("route", segments),
+	)
+
+	score, ok := scorer.ScorePolyline(route.Map.SummaryPolyline)
+	require.True(t, ok)
+	require.Equal(t, "route", score.RouteName)
+	require.Equal(t, len(route.Segments)*2, score.Checkpoints)
+	require.Greater(t, score.Fraction(), 0.9)
+	require.True(t, scorer.Candidate(score))
+
+	// The activity is a short ride across town.
+	score, ok = scorer.ScorePolyline(loadFixture(t, "activity.json").Map.SummaryPolyline)
+	require.True(t, ok)
+	require.Less(t, score.Fraction(), candidate.DefaultThreshold)
+	require.False(t, scorer.Candidate(score))
+
+	// Far away from everything.
+	score = scorer.Score([]geo.Point{{Lat: 40, Lng: -105}, {Lat: 40.1, Lng: -105}})
+	require.Zero(t, score.Hits)
+
+	_, ok = scorer.ScorePolyline("")
+	require.False(t, ok)
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	scorer := candidate.NewScorer()
+	score := func(hits int) candidate.Score {
+		return candidate.Score{Hits: hits, Checkpoints: 10}
+	}
+
+	e := scorer.Evaluate([]candidate.Case{
+		{Score: score(10), Finisher: true},
+		{Score: score(6), Finisher: true},
+		{Score: score(2), Finisher: true},
+		{Score: score(5), Finisher: false},
+		{Score: score(0), Finisher: false},
+		{Score: score(1), Finisher: false},
+	})
+	require.Equal(t, candidate.Evaluation{
+		TruePositives:  2,
+		FalsePositives: 1,
+		FalseNegatives: 1,
+		TrueNegatives:  2,
+	}, e)
+	require.InDelta(t, 2.0/3, e.Precision(), 1e-9)
+	require.InDelta(t, 2.0/3, e.Recall(), 1e-9)
+	require.InDelta(t, 0.5, e.Fetched(), 1e-9)
+
+	require.Zero(t, candidate.Evaluation{}.Precision())
+	require.Zero(t, candidate.Evaluation{}.Recall())
+}
@@ -0,0 +1,61 @@
+package candidate
+
+// Case is a past activity with a known outcome.
+type Case struct {
+	ActivityID int64
+	Score      Score
+	// Finisher is true if the activity is a result of the route.
+	Finisher bool
+}
+
+type Evaluation struct {
+	TruePositives  int
+	FalsePositives int
+	FalseNegatives int
+	TrueNegatives  int
+}
+
+// Evaluate counts how the scorer's threshold sorts the cases. Missed
+// finishers are expensive, they are not fetched during the event. Extra
+// candidates only cost a detail fetch.
+func (s *Scorer) Evaluate(cases []Case) Evaluation {
+	var e Evaluation
+	for _, c := range cases {
+		switch candidate := s.Candidate(c.Score); {
+		case candidate && c.Finisher:
+			e.TruePositives++
+		case candidate && !c.Finisher:
+			e.FalsePositives++
+		case !candidate && c.Finisher:
+			e.FalseNegatives++
+		default:
+			e.TrueNegatives++
+		}
+	}
+	return e
+}
+
+// Precision is the fraction of candidates that are finishers.
+func (e Evaluation) Precision() float64 {
+	if e.TruePositives+e.FalsePositives == 0 {
+		return 0
+	}
+	return float64(e.TruePositives) / float64(e.TruePositives+e.FalsePositives)
+}
+
+// Recall is the fraction of finishers that are candidates.
+func (e Evaluation) Recall() float64 {
+	if e.TruePositives+e.FalseNegatives == 0 {
+		return 0
+	}
+	return float64(e.TruePositives) / float64(e.TruePositives+e.FalseNegatives)
+}
+
+// Fetched is the fraction of all cases that would be fetched during the event.
+func (e Evaluation) Fetched() float64 {
+	total := e.TruePositives + e.FalsePositives + e.FalseNegatives + e.TrueNegatives
+	if total == 0 {
+		return 0
+	}
+	return float64(e.TruePositives+e.FalsePositives) / float64(total)
+}
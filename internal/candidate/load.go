@@ -0,0 +1,55 @@
+package candidate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// LatestYear is the year of the most recent editions.
+func LatestYear() int {
+	latest := 0
+	for _, e := range hugeldate.Editions {
+		latest = max(latest, e.Year)
+	}
+	return latest
+}
+
+// LoadYear returns a scorer for every edition of a year, the full and lite
+// routes run on the same days.
+func LoadYear(ctx context.Context, db database.Store, year int) (*Scorer, error) {
+	var routes []Route
+	for _, e := range hugeldate.Editions {
+		if e.Year != year {
+			continue
+		}
+		route, err := LoadEdition(ctx, db, e)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no editions in %d", year)
+	}
+	return NewScorer(routes...), nil
+}
+
+// LoadEdition returns the checkpoints of an edition's competitive route.
+func LoadEdition(ctx context.Context, db database.Store, e hugeldate.Edition) (Route, error) {
+	route, err := db.GetCompetitiveRouteByName(ctx, e.RouteName)
+	if err != nil {
+		return Route{}, fmt.Errorf("get route %q: %w", e.RouteName, err)
+	}
+	segments, err := db.GetSegments(ctx, route.Segments)
+	if err != nil {
+		return Route{}, fmt.Errorf("get segments of %q: %w", e.RouteName, err)
+	}
+	segs := make([]database.Segment, 0, len(segments))
+	for _, seg := range segments {
+		segs = append(segs, seg.Segment)
+	}
+	return NewRoute(route.Name, segs), nil
+}
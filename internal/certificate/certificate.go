@@ -0,0 +1,203 @@
+// Package certificate renders finisher certificates for competitive route
+// results, and the cards of an athlete's year. A certificate is laid out once as a scene of shapes and text, which
+// is then drawn as either SVG or PNG so both formats always match.
+package certificate
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/Emyrk/strava/internal/staticmap"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+type Certificate struct {
+	AthleteName string
+	// Edition is the title of the event, e.g. "Tour Das Hugel 2024".
+	Edition string
+	Date    time.Time
+	// Rank is 0 if the result is not ranked, e.g. it was not the athlete's
+	// best ride of the edition.
+	Rank      int64
+	Finishers int
+	// ClimbingTime is the scored time, the sum of the splits.
+	ClimbingTime time.Duration
+	Splits       []Split
+	// Route is the activity's encoded polyline, empty to skip the outline.
+	Route string
+}
+
+type Split struct {
+	Name    string
+	Elapsed time.Duration
+}
+
+// Layout is the size and contents of a rendered certificate.
+type Layout struct {
+	Width  int
+	Height int
+	// Splits are only drawn on layouts with room for them.
+	Splits bool
+}
+
+var (
+	// Full is the printable certificate.
+	Full = Layout{Width: 1200, Height: 1600, Splits: true}
+	// OpenGraph is sized for link previews.
+	OpenGraph = Layout{Width: 1200, Height: 630}
+)
+
+var (
+	background = color.RGBA{R: 0xfd, G: 0xf6, B: 0xe3, A: 0xff}
+	accent     = color.RGBA{R: 0xfc, G: 0x4c, B: 0x02, A: 0xff}
+	ink        = color.RGBA{R: 0x22, G: 0x22, B: 0x22, A: 0xff}
+	muted      = color.RGBA{R: 0x6b, G: 0x6b, B: 0x6b, A: 0xff}
+)
+
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+type text struct {
+	X, Y   float64
+	Size   float64
+	Bold   bool
+	Anchor anchor
+	Color  color.RGBA
+	Value  string
+}
+
+type rect struct {
+	X, Y, W, H float64
+	Color      color.RGBA
+}
+
+type line struct {
+	Points [][2]float64
+	Width  float64
+	Color  color.RGBA
+}
+
+// scene is everything drawn on a certificate or card, in drawing order.
+type scene struct {
+	Width, Height int
+	Background    color.RGBA
+	Rects         []rect
+	Lines         []line
+	Texts         []text
+}
+
+func (c Certificate) scene(l Layout) scene {
+	w, h := float64(l.Width), float64(l.Height)
+	s := scene{
+		Width:      l.Width,
+		Height:     l.Height,
+		Background: background,
+	}
+
+	// Frame
+	const border = 12.0
+	const inset = 24.0
+	s.Rects = append(s.Rects,
+		rect{X: inset, Y: inset, W: w - inset*2, H: border, Color: accent},
+		rect{X: inset, Y: h - inset - border, W: w - inset*2, H: border, Color: accent},
+		rect{X: inset, Y: inset, W: border, H: h - inset*2, Color: accent},
+		rect{X: w - inset - border, Y: inset, W: border, H: h - inset*2, Color: accent},
+	)
+
+	if !l.Splits {
+		// Link preview: text on the left, route on the right.
+		x := 90.0
+		s.Texts = append(s.Texts,
+			text{X: x, Y: 140, Size: 36, Color: accent, Bold: true, Value: c.Edition},
+			text{X: x, Y: 230, Size: 64, Color: ink, Bold: true, Value: c.AthleteName},
+			text{X: x, Y: 330, Size: 40, Color: ink, Value: c.rankLine()},
+			text{X: x, Y: 420, Size: 56, Color: ink, Bold: true, Value: FormatDuration(c.ClimbingTime)},
+			text{X: x, Y: 470, Size: 28, Color: muted, Value: "climbing time"},
+			text{X: x, Y: 540, Size: 28, Color: muted, Value: c.Date.Format("January 2, 2006")},
+		)
+		s.Lines = append(s.Lines, c.outline(760, 90, 360, 450)...)
+		return s
+	}
+
+	mid := w / 2
+	s.Texts = append(s.Texts,
+		text{X: mid, Y: 150, Size: 40, Color: accent, Bold: true, Anchor: anchorMiddle, Value: c.Edition},
+		text{X: mid, Y: 210, Size: 30, Color: muted, Anchor: anchorMiddle, Value: "Certificate of Completion"},
+		text{X: mid, Y: 310, Size: 72, Color: ink, Bold: true, Anchor: anchorMiddle, Value: c.AthleteName},
+		text{X: mid, Y: 390, Size: 36, Color: ink, Anchor: anchorMiddle, Value: c.rankLine()},
+		text{X: mid, Y: 480, Size: 64, Color: ink, Bold: true, Anchor: anchorMiddle, Value: FormatDuration(c.ClimbingTime)},
+		text{X: mid, Y: 525, Size: 28, Color: muted, Anchor: anchorMiddle, Value: "climbing time on " + c.Date.Format("January 2, 2006")},
+	)
+	s.Lines = append(s.Lines, c.outline(150, 570, 900, 500)...)
+
+	// Splits in two columns under the route.
+	top := 1140.0
+	rows := int(math.Ceil(float64(len(c.Splits)) / 2))
+	rowHeight := 40.0
+	if rows > 0 {
+		rowHeight = math.Min(rowHeight, (h-top-90)/float64(rows))
+	}
+	size := math.Min(28, rowHeight*0.7)
+	for i, split := range c.Splits {
+		col, row := i/max(rows, 1), i%max(rows, 1)
+		left := 110 + float64(col)*520
+		y := top + float64(row)*rowHeight
+		s.Texts = append(s.Texts,
+			text{X: left, Y: y, Size: size, Color: ink, Value: fmt.Sprintf("%d. %s", i+1, truncate(split.Name, 26))},
+			text{X: left + 460, Y: y, Size: size, Color: ink, Bold: true, Anchor: anchorEnd, Value: FormatDuration(split.Elapsed)},
+		)
+	}
+	return s
+}
+
+func (c Certificate) rankLine() string {
+	switch {
+	case c.Rank > 0 && c.Finishers > 0:
+		return fmt.Sprintf("Rank %d of %d finishers", c.Rank, c.Finishers)
+	case c.Rank > 0:
+		return fmt.Sprintf("Rank %d", c.Rank)
+	default:
+		return "Finisher"
+	}
+}
+
+// outline fits the route polyline into the box, keeping its aspect ratio.
+func (c Certificate) outline(x, y, w, h float64) []line {
+	// A broken polyline still draws the part that decoded.
+	points, _ := geo.DecodePolyline(c.Route)
+	if len(points) < 2 {
+		return nil
+	}
+	if b := geo.Bounds(points); b.Min == b.Max {
+		return nil
+	}
+
+	proj := staticmap.Fit(points, x, y, w, h)
+	return []line{{Points: proj.Line(points), Width: 5, Color: accent}}
+}
+
+// FormatDuration formats a duration as h:mm:ss, or m:ss under an hour.
+func FormatDuration(d time.Duration) string {
+	secs := int64(d.Round(time.Second) / time.Second)
+	if secs < 3600 {
+		return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d:%02d", secs/3600, (secs%3600)/60, secs%60)
+}
+
+// truncate shortens s to at most n runes, so it does not run into the
+// column next to it.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
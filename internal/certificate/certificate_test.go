@@ -0,0 +1,130 @@
+package certificate_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Emyrk/strava/internal/certificate"
+	"github.com/stretchr/testify/require"
+)
+
+func testCertificate() certificate.Certificate {
+	return certificate.Certificate{
+		AthleteName:  `Steven "Hugel" <Masley> & co`,
+		Edition:      "Tour Das Hugel 2024",
+		Date:         time.Date(2024, time.November, 9, 0, 0, 0, 0, time.UTC),
+		Rank:         3,
+		Finishers:    120,
+		ClimbingTime: time.Hour + time.Minute*2 + time.Second*3,
+		Splits: []certificate.Split{
+			{Name: "Mount Bonnell", Elapsed: time.Minute},
+			{Name: "Jester", Elapsed: time.Minute * 5},
+			{Name: "Courtyard", Elapsed: time.Minute * 3},
+		},
+		// The example from Google's polyline documentation.
+		Route: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	t.Parallel()
+
+	for _, layout := range []certificate.Layout{certificate.Full, certificate.OpenGraph} {
+		var buf bytes.Buffer
+		err := certificate.WriteSVG(&buf, testCertificate(), layout)
+		require.NoError(t, err)
+
+		// Must be well formed, names are user controlled.
+		dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+		}
+
+		out := buf.String()
+		require.Contains(t, out, "&lt;Masley&gt; &amp; co")
+		require.Contains(t, out, "1:02:03")
+		require.Contains(t, out, "Rank 3 of 120 finishers")
+		require.Contains(t, out, "<polyline")
+		if layout.Splits {
+			require.Contains(t, out, "2. Jester")
+		} else {
+			require.NotContains(t, out, "Jester")
+		}
+	}
+}
+
+func TestWriteSVGNoRoute(t *testing.T) {
+	t.Parallel()
+
+	c := testCertificate()
+	c.Route = ""
+	c.Rank = 0
+
+	var buf bytes.Buffer
+	require.NoError(t, certificate.WriteSVG(&buf, c, certificate.Full))
+	require.NotContains(t, buf.String(), "<polyline")
+	require.Contains(t, buf.String(), "Finisher")
+}
+
+func TestWritePNG(t *testing.T) {
+	t.Parallel()
+
+	for _, layout := range []certificate.Layout{certificate.Full, certificate.OpenGraph} {
+		var buf bytes.Buffer
+		err := certificate.WritePNG(&buf, testCertificate(), layout)
+		require.NoError(t, err)
+
+		img, err := png.Decode(&buf)
+		require.NoError(t, err)
+		require.Equal(t, layout.Width, img.Bounds().Dx())
+		require.Equal(t, layout.Height, img.Bounds().Dy())
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "0:59", certificate.FormatDuration(time.Second*59))
+	require.Equal(t, "59:59", certificate.FormatDuration(time.Minute*59+time.Second*59))
+	require.True(t, strings.HasPrefix(certificate.FormatDuration(time.Hour*10), "10:00:00"))
+}
+
+func TestYearCard(t *testing.T) {
+	t.Parallel()
+
+	card := certificate.YearCard{
+		AthleteName: "Steven <Masley>",
+		Year:        2024,
+		Activities:  212,
+		Distance:    1609.344 * 4000,
+		Elevation:   30480,
+		MovingTime:  time.Hour * 250,
+		Eddington:   61,
+		Hugels:      2,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, certificate.WriteYearSVG(&buf, card))
+	out := buf.String()
+	require.Contains(t, out, "2024 in review")
+	require.Contains(t, out, "&lt;Masley&gt;")
+	require.Contains(t, out, ">4000<")
+	require.Contains(t, out, ">100000<")
+	require.Contains(t, out, ">250<")
+
+	buf.Reset()
+	require.NoError(t, certificate.WriteYearPNG(&buf, card))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, certificate.OpenGraph.Width, img.Bounds().Dx())
+	require.Equal(t, certificate.OpenGraph.Height, img.Bounds().Dy())
+}
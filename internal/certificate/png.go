@@ -0,0 +1,125 @@
+package certificate
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/Emyrk/strava/internal/staticmap"
+)
+
+// WritePNG renders the certificate as a PNG image.
+func WritePNG(w io.Writer, c Certificate, l Layout) error {
+	img, err := Image(c, l)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// WriteYearPNG renders the year card as a PNG image.
+func WriteYearPNG(w io.Writer, y YearCard) error {
+	img, err := rasterize(y.scene())
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Image rasterizes the certificate.
+func Image(c Certificate, l Layout) (*image.RGBA, error) {
+	return rasterize(c.scene(l))
+}
+
+func rasterize(s scene) (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, s.Width, s.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(s.Background), image.Point{}, draw.Src)
+
+	for _, r := range s.Rects {
+		bounds := image.Rect(int(r.X), int(r.Y), int(math.Round(r.X+r.W)), int(math.Round(r.Y+r.H)))
+		draw.Draw(img, bounds, image.NewUniform(r.Color), image.Point{}, draw.Over)
+	}
+
+	for _, ln := range s.Lines {
+		staticmap.Stroke(img, ln.Points, ln.Width, ln.Color)
+	}
+
+	// Faces are not safe for concurrent use, so each render gets its own.
+	faces := make(map[faceKey]font.Face)
+	for _, t := range s.Texts {
+		face, err := fontFace(faces, t.Bold, t.Size)
+		if err != nil {
+			return nil, err
+		}
+		x := t.X
+		switch t.Anchor {
+		case anchorMiddle:
+			x -= float64(font.MeasureString(face, t.Value)) / 64 / 2
+		case anchorEnd:
+			x -= float64(font.MeasureString(face, t.Value)) / 64
+		}
+		d := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(t.Color),
+			Face: face,
+			Dot:  fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(t.Y * 64)},
+		}
+		d.DrawString(t.Value)
+	}
+	return img, nil
+}
+
+var (
+	fontsOnce sync.Once
+	fontsErr  error
+	regular   *opentype.Font
+	bold      *opentype.Font
+)
+
+type faceKey struct {
+	bold bool
+	size float64
+}
+
+func fontFace(faces map[faceKey]font.Face, isBold bool, size float64) (font.Face, error) {
+	fontsOnce.Do(func() {
+		regular, fontsErr = opentype.Parse(goregular.TTF)
+		if fontsErr != nil {
+			return
+		}
+		bold, fontsErr = opentype.Parse(gobold.TTF)
+	})
+	if fontsErr != nil {
+		return nil, fmt.Errorf("parse fonts: %w", fontsErr)
+	}
+
+	key := faceKey{bold: isBold, size: size}
+	if face, ok := faces[key]; ok {
+		return face, nil
+	}
+
+	f := regular
+	if isBold {
+		f = bold
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("new face: %w", err)
+	}
+	faces[key] = face
+	return face, nil
+}
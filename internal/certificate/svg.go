@@ -0,0 +1,66 @@
+package certificate
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// WriteSVG renders the certificate as an SVG document.
+func WriteSVG(w io.Writer, c Certificate, l Layout) error {
+	return writeSVG(w, c.scene(l))
+}
+
+// WriteYearSVG renders the year card as an SVG document.
+func WriteYearSVG(w io.Writer, y YearCard) error {
+	return writeSVG(w, y.scene())
+}
+
+func writeSVG(w io.Writer, s scene) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		s.Width, s.Height, s.Width, s.Height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(s.Background))
+	for _, r := range s.Rects {
+		fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n",
+			r.X, r.Y, r.W, r.H, hex(r.Color))
+	}
+	for _, ln := range s.Lines {
+		points := make([]string, 0, len(ln.Points))
+		for _, p := range ln.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", p[0], p[1]))
+		}
+		fmt.Fprintf(&buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%.1f" stroke-linejoin="round" stroke-linecap="round"/>`+"\n",
+			strings.Join(points, " "), hex(ln.Color), ln.Width)
+	}
+	for _, t := range s.Texts {
+		weight := "normal"
+		if t.Bold {
+			weight = "bold"
+		}
+		anchor := "start"
+		switch t.Anchor {
+		case anchorMiddle:
+			anchor = "middle"
+		case anchorEnd:
+			anchor = "end"
+		}
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" font-family="Helvetica, Arial, sans-serif" font-size="%.1f" font-weight="%s" text-anchor="%s" fill="%s">`,
+			t.X, t.Y, t.Size, weight, anchor, hex(t.Color))
+		if err := xml.EscapeText(&buf, []byte(t.Value)); err != nil {
+			return err
+		}
+		buf.WriteString("</text>\n")
+	}
+	buf.WriteString("</svg>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
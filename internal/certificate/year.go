@@ -0,0 +1,67 @@
+package certificate
+
+import (
+	"fmt"
+	"time"
+)
+
+// YearCard is the shareable summary of an athlete's year, sized for link
+// previews.
+type YearCard struct {
+	AthleteName string
+	Year        int
+	Activities  int
+	// Distance and Elevation are in meters.
+	Distance   float64
+	Elevation  float64
+	MovingTime time.Duration
+	// Eddington is the lifetime number in miles at the end of the year.
+	Eddington int32
+	// Hugels is the editions finished that year.
+	Hugels int
+}
+
+const (
+	metersPerMile = 1609.344
+	feetPerMeter  = 3.28084
+)
+
+func (y YearCard) scene() scene {
+	w, h := float64(OpenGraph.Width), float64(OpenGraph.Height)
+	s := scene{
+		Width:      OpenGraph.Width,
+		Height:     OpenGraph.Height,
+		Background: background,
+	}
+
+	const inset = 24.0
+	s.Rects = append(s.Rects,
+		rect{X: inset, Y: inset, W: w - inset*2, H: 12, Color: accent},
+		rect{X: inset, Y: h - inset - 12, W: w - inset*2, H: 12, Color: accent},
+	)
+
+	x := 90.0
+	s.Texts = append(s.Texts,
+		text{X: x, Y: 130, Size: 36, Color: accent, Bold: true, Value: fmt.Sprintf("%d in review", y.Year)},
+		text{X: x, Y: 210, Size: 60, Color: ink, Bold: true, Value: truncate(y.AthleteName, 32)},
+	)
+
+	// Two rows of three stats.
+	stats := [][2]string{
+		{fmt.Sprintf("%.0f", y.Distance/metersPerMile), "miles"},
+		{fmt.Sprintf("%.0f", y.Elevation*feetPerMeter), "feet climbed"},
+		{fmt.Sprintf("%.0f", y.MovingTime.Hours()), "hours moving"},
+		{fmt.Sprintf("%d", y.Activities), "activities"},
+		{fmt.Sprintf("%d", y.Eddington), "eddington"},
+		{fmt.Sprintf("%d", y.Hugels), "hugels finished"},
+	}
+	for i, stat := range stats {
+		left := x + float64(i%3)*340
+		top := 330 + float64(i/3)*150
+		s.Texts = append(s.Texts,
+			text{X: left, Y: top, Size: 56, Color: ink, Bold: true, Value: stat[0]},
+			text{X: left, Y: top + 45, Size: 28, Color: muted, Value: stat[1]},
+		)
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+// Package climbpower scores climbing efforts by physics instead of raw time:
+// power to weight (W/kg) and VAM, the vertical meters climbed per hour. VAM
+// only needs the clock and the segment, so every effort has one. W/kg needs
+// the rider's weight and watts, and Strava estimates watts for riders without
+// a power meter, so every result says where its power came from.
+package climbpower
+
+const (
+	// Power sources, best first.
+	SourceDevice    = "device"
+	SourceEstimated = "estimated"
+	SourceMissing   = "missing"
+)
+
+// Effort is a single climb.
+type Effort struct {
+	SegmentID int64
+	// Elapsed is in seconds.
+	Elapsed      float64
+	AverageWatts float64
+	DeviceWatts  bool
+}
+
+// Metrics are the physics of an effort, or of a whole route.
+type Metrics struct {
+	// VAM is in vertical meters per hour, zero without elevation or time.
+	VAM float64
+	// WattsPerKg is nil without a weight or watts.
+	WattsPerKg *float64
+	// PowerSource is one of the Source constants. A route is only as good as
+	// its worst effort.
+	PowerSource string
+}
+
+// Source is where the watts of an effort came from.
+func Source(e Effort) string {
+	switch {
+	case e.AverageWatts <= 0:
+		return SourceMissing
+	case e.DeviceWatts:
+		return SourceDevice
+	default:
+		return SourceEstimated
+	}
+}
+
+// ForEffort scores one effort. Elevation is the gain of the segment in meters,
+// weight the rider's in kilograms.
+func ForEffort(e Effort, elevation, weight float64) Metrics {
+	m := Metrics{PowerSource: Source(e)}
+	if e.Elapsed > 0 && elevation > 0 {
+		m.VAM = elevation / (e.Elapsed / 3600)
+	}
+	if weight > 0 && e.AverageWatts > 0 {
+		wkg := e.AverageWatts / weight
+		m.WattsPerKg = &wkg
+	}
+	return m
+}
+
+// ForRoute scores the climbs together, as if they were one climb: VAM is the
+// total elevation over the total time, watts are averaged by time. Efforts
+// on segments without an elevation only count towards the watts.
+func ForRoute(efforts []Effort, elevations map[int64]float64, weight float64) Metrics {
+	m := Metrics{PowerSource: SourceDevice}
+	if len(efforts) == 0 {
+		m.PowerSource = SourceMissing
+		return m
+	}
+
+	var (
+		climbed, climbTime float64
+		joules, powerTime  float64
+	)
+	for _, e := range efforts {
+		switch Source(e) {
+		case SourceMissing:
+			m.PowerSource = SourceMissing
+		case SourceEstimated:
+			if m.PowerSource == SourceDevice {
+				m.PowerSource = SourceEstimated
+			}
+		}
+		if e.Elapsed <= 0 {
+			continue
+		}
+		if elevation := elevations[e.SegmentID]; elevation > 0 {
+			climbed += elevation
+			climbTime += e.Elapsed
+		}
+		if e.AverageWatts > 0 {
+			joules += e.AverageWatts * e.Elapsed
+			powerTime += e.Elapsed
+		}
+	}
+
+	if climbTime > 0 {
+		m.VAM = climbed / (climbTime / 3600)
+	}
+	// Watts from only some of the climbs would flatter the route.
+	if weight > 0 && powerTime > 0 && m.PowerSource != SourceMissing {
+		wkg := joules / powerTime / weight
+		m.WattsPerKg = &wkg
+	}
+	return m
+}
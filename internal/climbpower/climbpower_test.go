@@ -0,0 +1,57 @@
+package climbpower_test
+
+import (
+	"testing"
+
+	"github.com/Emyrk/strava/internal/climbpower"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEffort(t *testing.T) {
+	t.Parallel()
+
+	m := climbpower.ForEffort(climbpower.Effort{Elapsed: 360, AverageWatts: 300, DeviceWatts: true}, 100, 75)
+	require.InDelta(t, 1000, m.VAM, 1e-9)
+	require.NotNil(t, m.WattsPerKg)
+	require.InDelta(t, 4, *m.WattsPerKg, 1e-9)
+	require.Equal(t, climbpower.SourceDevice, m.PowerSource)
+
+	m = climbpower.ForEffort(climbpower.Effort{Elapsed: 360, AverageWatts: 300}, 100, 0)
+	require.Nil(t, m.WattsPerKg)
+	require.Equal(t, climbpower.SourceEstimated, m.PowerSource)
+
+	m = climbpower.ForEffort(climbpower.Effort{Elapsed: 360}, 0, 75)
+	require.Zero(t, m.VAM)
+	require.Nil(t, m.WattsPerKg)
+	require.Equal(t, climbpower.SourceMissing, m.PowerSource)
+}
+
+func TestForRoute(t *testing.T) {
+	t.Parallel()
+
+	elevations := map[int64]float64{1: 100, 2: 50}
+	efforts := []climbpower.Effort{
+		{SegmentID: 1, Elapsed: 360, AverageWatts: 300, DeviceWatts: true},
+		{SegmentID: 2, Elapsed: 180, AverageWatts: 240, DeviceWatts: true},
+		// No elevation, only counts towards the watts.
+		{SegmentID: 3, Elapsed: 60, AverageWatts: 600, DeviceWatts: true},
+	}
+
+	m := climbpower.ForRoute(efforts, elevations, 60)
+	require.InDelta(t, 1000, m.VAM, 1e-9)
+	require.NotNil(t, m.WattsPerKg)
+	require.InDelta(t, (300*360+240*180+600*60)/600.0/60, *m.WattsPerKg, 1e-9)
+	require.Equal(t, climbpower.SourceDevice, m.PowerSource)
+
+	efforts[1].DeviceWatts = false
+	m = climbpower.ForRoute(efforts, elevations, 60)
+	require.Equal(t, climbpower.SourceEstimated, m.PowerSource)
+	require.NotNil(t, m.WattsPerKg)
+
+	efforts[2].AverageWatts = 0
+	m = climbpower.ForRoute(efforts, elevations, 60)
+	require.Equal(t, climbpower.SourceMissing, m.PowerSource)
+	require.Nil(t, m.WattsPerKg)
+
+	require.Equal(t, climbpower.SourceMissing, climbpower.ForRoute(nil, elevations, 60).PowerSource)
+}
@@ -1,17 +1,174 @@
 package eddington
 
 import (
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/Emyrk/strava/database"
 )
 
+// Unit is the distance of one step of the number, in meters.
+type Unit float64
+
+const (
+	Miles      Unit = 1609.34
+	Kilometers Unit = 1000
+)
+
+const (
+	// SportAll is every ride, indoor or not.
+	SportAll         = "all"
+	SportRide        = "ride"
+	SportVirtualRide = "virtualride"
+)
+
+// Sports are the breakdowns by sport, all of them first.
+var Sports = []string{SportAll, SportRide, SportVirtualRide}
+
+// Activity is the part of an activity the number is counted from.
+type Activity struct {
+	ID int64
+	// StartDateLocal is the wall clock time the activity started, stored as
+	// if it were UTC. The day is taken from it, so a ride at 11pm counts for
+	// the day it was ridden wherever it was ridden.
+	StartDateLocal time.Time
+	// Distance is in meters.
+	Distance float64
+	// Sport is the lower cased activity type.
+	Sport string
+	// ElevationGain is in meters.
+	ElevationGain float64
+	// MovingTime is in seconds.
+	MovingTime    float64
+	HugelSegments int
+}
+
+// Day is the total distance ridden on a calendar day.
+type Day struct {
+	Date time.Time
+	// Distance is in meters.
+	Distance float64
+	// ActivityID is the longest ride of the day.
+	ActivityID int64
+}
+
+// FromRows reads the activities of EddingtonActivities.
+func FromRows(rows []database.EddingtonActivitiesRow) []Activity {
+	acts := make([]Activity, 0, len(rows))
+	for _, row := range rows {
+		acts = append(acts, Activity{
+			ID:             row.ID,
+			StartDateLocal: row.StartDateLocal.Time,
+			Distance:       row.Distance,
+			Sport:          strings.ToLower(row.ActivityType),
+			ElevationGain:  row.TotalElevationGain,
+			MovingTime:     row.MovingTime,
+			HugelSegments:  int(row.HugelSegments),
+		})
+	}
+	return acts
+}
+
+// FromActivities is the lifetime number in miles, counting every ride of a
+// day as one.
 func FromActivities(acts []database.EddingtonActivitiesRow) Sums {
-	edds := Sums{}
+	return FromDays(DailyTotals(FromRows(acts), SportAll), Miles)
+}
+
+// DailyTotals sums the distance of the sport for each day, oldest first.
+func DailyTotals(acts []Activity, sport string) []Day {
+	type total struct {
+		distance float64
+		longest  Activity
+	}
+	totals := make(map[time.Time]total)
 	for _, act := range acts {
-		edds.Add(int(database.DistanceToMiles(act.Distance)))
+		if sport != SportAll && act.Sport != sport {
+			continue
+		}
+		day := localDay(act.StartDateLocal)
+		t := totals[day]
+		t.distance += act.Distance
+		if act.Distance > t.longest.Distance || t.longest.ID == 0 {
+			t.longest = act
+		}
+		totals[day] = t
+	}
+
+	days := make([]Day, 0, len(totals))
+	for date, t := range totals {
+		days = append(days, Day{Date: date, Distance: t.distance, ActivityID: t.longest.ID})
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days
+}
+
+// localDay is the calendar day of a start_date_local.
+func localDay(local time.Time) time.Time {
+	local = local.UTC()
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// FromDays counts each day in whole units.
+func FromDays(days []Day, unit Unit) Sums {
+	edds := Sums{}
+	for _, day := range days {
+		edds.Add(int(day.Distance / float64(unit)))
 	}
 	return edds
 }
 
+// Breakdown is the number for one sport over a calendar year, or over every
+// year.
+type Breakdown struct {
+	Sport string
+	// Year is 0 for the lifetime number.
+	Year       int
+	Days       int
+	Miles      Sums
+	Kilometers Sums
+}
+
+// Breakdowns is every sport for the lifetime and for each year that has a
+// ride, lifetime first then by year. Sports without a ride are left out.
+func Breakdowns(acts []Activity) []Breakdown {
+	var out []Breakdown
+	for _, sport := range Sports {
+		days := DailyTotals(acts, sport)
+		if len(days) == 0 {
+			continue
+		}
+		out = append(out, newBreakdown(sport, 0, days))
+
+		var years []int
+		byYear := make(map[int][]Day)
+		for _, day := range days {
+			y := day.Date.Year()
+			if _, ok := byYear[y]; !ok {
+				years = append(years, y)
+			}
+			byYear[y] = append(byYear[y], day)
+		}
+		for _, y := range years {
+			out = append(out, newBreakdown(sport, y, byYear[y]))
+		}
+	}
+	return out
+}
+
+func newBreakdown(sport string, year int, days []Day) Breakdown {
+	return Breakdown{
+		Sport:      sport,
+		Year:       year,
+		Days:       len(days),
+		Miles:      FromDays(days, Miles),
+		Kilometers: FromDays(days, Kilometers),
+	}
+}
+
 // Sums is a slice of integers representing the total number of entries with at
 // least a value equal to the index.
 type Sums []int32
@@ -24,11 +181,8 @@ func (e Sums) Current() int32 {
 		}
 	}
 
-	if len(e) == 0 {
-		return 0
-	}
-
-	return e[len(e)-1]
+	// Every distance has enough days, e.g. 5 days of exactly 2 miles.
+	return int32(len(e))
 }
 
 func (e *Sums) Add(value int) {
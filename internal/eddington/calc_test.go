@@ -2,6 +2,7 @@ package eddington_test
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -49,4 +50,286 @@ func TestEddingtonNumber(t *testing.T) {
 		require.Equal(t, e, eddington.Sums{5, 5, 5, 4, 3, 2, 2})
 		require.Equal(t, e.Current(), int32(4))
 	})
+
+	t.Run("EveryDistanceFilled", func(t *testing.T) {
+		e := eddington.Sums{}
+		for i := 0; i < 5; i++ {
+			e.Add(2)
+		}
+		require.Equal(t, eddington.Sums{5, 5}, e)
+		require.Equal(t, int32(2), e.Current())
+
+		e = eddington.Sums{}
+		e.Add(1)
+		require.Equal(t, int32(1), e.Current())
+	})
+}
+
+func TestDailyTotals(t *testing.T) {
+	t.Parallel()
+
+	at := func(date string) time.Time {
+		d, err := time.Parse(time.DateTime, date)
+		require.NoError(t, err)
+		return d
+	}
+
+	acts := []eddington.Activity{
+		// Two rides on the same day count as one 25 km day.
+		{StartDateLocal: at("2024-06-01 07:00:00"), Distance: 10_000, Sport: eddington.SportRide},
+		{StartDateLocal: at("2024-06-01 23:30:00"), Distance: 15_000, Sport: eddington.SportVirtualRide},
+		{StartDateLocal: at("2024-06-02 00:10:00"), Distance: 3_000, Sport: eddington.SportRide},
+		{StartDateLocal: at("2023-12-31 12:00:00"), Distance: 5_000, Sport: eddington.SportRide},
+	}
+
+	days := eddington.DailyTotals(acts, eddington.SportAll)
+	require.Equal(t, []eddington.Day{
+		{Date: at("2023-12-31 00:00:00"), Distance: 5_000},
+		{Date: at("2024-06-01 00:00:00"), Distance: 25_000},
+		{Date: at("2024-06-02 00:00:00"), Distance: 3_000},
+	}, days)
+
+	rides := eddington.DailyTotals(acts, eddington.SportRide)
+	require.Len(t, rides, 3)
+	require.Equal(t, float64(10_000), rides[1].Distance)
+
+	virtual := eddington.DailyTotals(acts, eddington.SportVirtualRide)
+	require.Len(t, virtual, 1)
+
+	// Two short rides make a whole mile together.
+	rows := []database.EddingtonActivitiesRow{
+		{Distance: 900, StartDateLocal: database.Timestamptz(at("2024-06-01 07:00:00")), ActivityType: "Ride"},
+		{Distance: 900, StartDateLocal: database.Timestamptz(at("2024-06-01 18:00:00")), ActivityType: "VirtualRide"},
+	}
+	require.Equal(t, eddington.Sums{1}, eddington.FromActivities(rows))
+}
+
+func TestBreakdowns(t *testing.T) {
+	t.Parallel()
+
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+	const mile = float64(eddington.Miles)
+
+	// 2023: outdoor days of 3, 3, 2 miles. Split over two rides, the 3s
+	// would only be an E of 2.
+	// 2024: outdoor days of 1 and 4 miles, an indoor day of 5 miles.
+	acts := []eddington.Activity{
+		{StartDateLocal: day(2023, 5, 1), Distance: 1.5 * mile, Sport: eddington.SportRide},
+		{StartDateLocal: day(2023, 5, 1), Distance: 1.5 * mile, Sport: eddington.SportRide},
+		{StartDateLocal: day(2023, 5, 2), Distance: 3 * mile, Sport: eddington.SportRide},
+		{StartDateLocal: day(2023, 5, 3), Distance: 2 * mile, Sport: eddington.SportRide},
+		{StartDateLocal: day(2024, 1, 1), Distance: 1 * mile, Sport: eddington.SportRide},
+		{StartDateLocal: day(2024, 1, 2), Distance: 4 * mile, Sport: eddington.SportRide},
+		{StartDateLocal: day(2024, 1, 3), Distance: 5 * mile, Sport: eddington.SportVirtualRide},
+	}
+
+	type want struct {
+		sport string
+		year  int
+		days  int
+		miles int32
+		km    int32
+	}
+	var got []want
+	for _, b := range eddington.Breakdowns(acts) {
+		got = append(got, want{b.Sport, b.Year, b.Days, b.Miles.Current(), b.Kilometers.Current()})
+	}
+
+	// Days in km: 2023 4.8, 4.8, 3.2; 2024 1.6, 6.4, 8.0.
+	require.Equal(t, []want{
+		{eddington.SportAll, 0, 6, 3, 4},
+		{eddington.SportAll, 2023, 3, 2, 3},
+		{eddington.SportAll, 2024, 3, 2, 2},
+		{eddington.SportRide, 0, 5, 3, 3},
+		{eddington.SportRide, 2023, 3, 2, 3},
+		{eddington.SportRide, 2024, 2, 1, 1},
+		{eddington.SportVirtualRide, 0, 1, 1, 1},
+		{eddington.SportVirtualRide, 2024, 1, 1, 1},
+	}, got)
+
+	// The stored histogram keeps its meaning, days with at least i+1 miles.
+	require.Equal(t, eddington.Sums{6, 5, 4, 2, 1}, eddington.Breakdowns(acts)[0].Miles)
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+	// Miles per day: 2, 1, 3, 2, 5, 4, 3
+	// E after each day: 1, 1, 2, 2, 2, 3, 3
+	var days []eddington.Day
+	for i, miles := range []float64{2, 1, 3, 2, 5, 4, 3} {
+		days = append(days, eddington.Day{
+			Date:       day(i + 1),
+			Distance:   miles * float64(eddington.Miles),
+			ActivityID: int64(100 + i),
+		})
+	}
+
+	require.Equal(t, []eddington.Milestone{
+		{Eddington: 1, Date: day(1), ActivityID: 100},
+		{Eddington: 2, Date: day(3), ActivityID: 102},
+		{Eddington: 3, Date: day(6), ActivityID: 105},
+	}, eddington.History(days, eddington.Miles))
+
+	sums := eddington.FromDays(days, eddington.Miles)
+	require.Equal(t, eddington.Sums{7, 6, 4, 2, 1}, sums)
+	// 4 needs 2 more days of 4+ miles, 5 needs 4 more, 6 needs all 6.
+	require.Equal(t, []eddington.Goal{
+		{Eddington: 4, Have: 2, Need: 2},
+		{Eddington: 5, Have: 1, Need: 4},
+		{Eddington: 6, Have: 0, Need: 6},
+	}, eddington.Plan(sums, 3))
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 8, 0, 0, 0, time.UTC)
+	}
+	const km = float64(eddington.Kilometers)
+
+	// dayTotals is what the daily totals table holds for the days.
+	dayTotals := func(acts []eddington.Activity, dates ...time.Time) []eddington.DayTotal {
+		var out []eddington.DayTotal
+		for _, sport := range []string{eddington.SportRide, eddington.SportVirtualRide} {
+			for _, d := range eddington.DailyTotals(acts, sport) {
+				for _, date := range dates {
+					if d.Date.Equal(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)) {
+						out = append(out, eddington.DayTotal{Date: d.Date, Sport: sport, Distance: d.Distance, Activities: 1})
+					}
+				}
+			}
+		}
+		return out
+	}
+
+	base := []eddington.Activity{
+		{ID: 1, StartDateLocal: day(2023, 7, 1), Distance: 40 * km, Sport: eddington.SportRide},
+		{ID: 2, StartDateLocal: day(2023, 7, 2), Distance: 20 * km, Sport: eddington.SportRide},
+		{ID: 3, StartDateLocal: day(2024, 2, 1), Distance: 30 * km, Sport: eddington.SportVirtualRide},
+		{ID: 4, StartDateLocal: day(2024, 2, 1), Distance: 5 * km, Sport: eddington.SportRide},
+		{ID: 5, StartDateLocal: day(2024, 2, 3), Distance: 12 * km, Sport: eddington.SportRide},
+	}
+
+	for _, tc := range []struct {
+		name  string
+		after []eddington.Activity
+		dates []time.Time
+	}{
+		{
+			name:  "New",
+			after: append(slices.Clone(base), eddington.Activity{ID: 6, StartDateLocal: day(2024, 2, 3), Distance: 25 * km, Sport: eddington.SportRide}),
+			dates: []time.Time{day(2024, 2, 3)},
+		},
+		{
+			name:  "NewYear",
+			after: append(slices.Clone(base), eddington.Activity{ID: 6, StartDateLocal: day(2025, 1, 1), Distance: 50 * km, Sport: eddington.SportVirtualRide}),
+			dates: []time.Time{day(2025, 1, 1)},
+		},
+		{
+			name:  "Deleted",
+			after: slices.Delete(slices.Clone(base), 0, 1),
+			dates: []time.Time{day(2023, 7, 1)},
+		},
+		{
+			name: "Retyped",
+			after: func() []eddington.Activity {
+				acts := slices.Clone(base)
+				acts[2].Sport = eddington.SportRide
+				return acts
+			}(),
+			dates: []time.Time{day(2024, 2, 1)},
+		},
+		{
+			name:  "EverythingDeleted",
+			after: nil,
+			dates: []time.Time{day(2023, 7, 1), day(2023, 7, 2), day(2024, 2, 1), day(2024, 2, 3)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := eddington.Update(eddington.Breakdowns(base), dayTotals(base, tc.dates...), dayTotals(tc.after, tc.dates...))
+			want := eddington.Breakdowns(tc.after)
+			if len(want) == 0 {
+				require.Empty(t, got)
+				return
+			}
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int, hour int) time.Time {
+		return time.Date(2024, 5, d, hour, 0, 0, 0, time.UTC)
+	}
+	const hour = 3600.0
+
+	acts := []eddington.Activity{
+		// Two rides on one day count as one day of 1,200 m and 3 hours.
+		{ID: 1, StartDateLocal: day(1, 8), ElevationGain: 700, MovingTime: 2 * hour, HugelSegments: 4},
+		{ID: 2, StartDateLocal: day(1, 18), ElevationGain: 500, MovingTime: hour, HugelSegments: 4},
+		{ID: 3, StartDateLocal: day(2, 8), ElevationGain: 100, MovingTime: 4 * hour},
+		{ID: 4, StartDateLocal: day(3, 8), ElevationGain: 90, MovingTime: 3.5 * hour, HugelSegments: 1},
+	}
+
+	metric := func(name string) eddington.Metric {
+		m, ok := eddington.MetricByName(name)
+		require.True(t, ok, name)
+		return m
+	}
+
+	for _, tc := range []struct {
+		metric string
+		want   int32
+	}{
+		// 39, 3 and 2 hundreds of feet.
+		{metric: eddington.MetricElevationFeet, want: 2},
+		// 24, 2 and 1 fifties of meters.
+		{metric: eddington.MetricElevationMeters, want: 2},
+		{metric: eddington.MetricHours, want: 3},
+		{metric: eddington.MetricHugelSegments, want: 1},
+	} {
+		sums, days := eddington.MetricSums(acts, metric(tc.metric))
+		require.Equal(t, tc.want, sums.Current(), tc.metric)
+		require.Equal(t, 3, days, tc.metric)
+	}
+
+	_, ok := eddington.MetricByName("watts")
+	require.False(t, ok)
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+
+		// The second ride of the first day is deleted.
+		after := slices.Delete(slices.Clone(acts), 1, 2)
+		date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		before := []eddington.DayTotal{{Date: date, Sport: eddington.SportRide, Activities: 2, ElevationGain: 1200, MovingTime: 3 * hour, HugelSegments: 8}}
+		now := []eddington.DayTotal{{Date: date, Sport: eddington.SportRide, Activities: 1, ElevationGain: 700, MovingTime: 2 * hour, HugelSegments: 4}}
+
+		for _, m := range eddington.Metrics {
+			sums, days := eddington.MetricSums(acts, m)
+			gotSums, gotDays := eddington.UpdateMetric(m, sums, days, before, now)
+			wantSums, wantDays := eddington.MetricSums(after, m)
+			require.Equal(t, wantSums, gotSums, m.Name)
+			require.Equal(t, wantDays, gotDays, m.Name)
+		}
+	})
+}
+
+func TestRanks(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []int{1, 2, 2, 4, 5, 5, 5}, eddington.Ranks([]int32{50, 40, 40, 30, 10, 10, 10}))
+	require.Equal(t, []int{1, 1}, eddington.Ranks([]int32{7, 7}))
+	require.Empty(t, eddington.Ranks(nil))
 }
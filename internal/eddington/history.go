@@ -0,0 +1,61 @@
+package eddington
+
+import "time"
+
+// Milestone is the day a number was first reached.
+type Milestone struct {
+	Eddington int32
+	Date      time.Time
+	// ActivityID is the longest ride of the day, the ride that got there.
+	ActivityID int64
+}
+
+// History replays the days in order, returning a milestone each time the
+// number goes up. A single day can only raise it by one, so every number up
+// to the current one has a milestone.
+func History(days []Day, unit Unit) []Milestone {
+	var (
+		sums    Sums
+		current int32
+		out     []Milestone
+	)
+	for _, day := range days {
+		sums.Add(int(day.Distance / float64(unit)))
+		if next := sums.Current(); next > current {
+			current = next
+			out = append(out, Milestone{
+				Eddington:  current,
+				Date:       day.Date,
+				ActivityID: day.ActivityID,
+			})
+		}
+	}
+	return out
+}
+
+// Goal is how far away a number is.
+type Goal struct {
+	Eddington int32
+	// Have is the days with at least Eddington units.
+	Have int32
+	// Need is the more days of at least Eddington units needed to reach it.
+	Need int32
+}
+
+// Plan is the goals for the next few numbers after the current one.
+func Plan(sums Sums, next int) []Goal {
+	current := sums.Current()
+	goals := make([]Goal, 0, next)
+	for target := current + 1; target <= current+int32(next); target++ {
+		var have int32
+		if int(target) <= len(sums) {
+			have = sums[target-1]
+		}
+		goals = append(goals, Goal{
+			Eddington: target,
+			Have:      have,
+			Need:      max(target-have, 0),
+		})
+	}
+	return goals
+}
@@ -0,0 +1,114 @@
+package eddington
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// DayTotal is the rides of one sport on a calendar day, as stored per day.
+type DayTotal struct {
+	Date time.Time
+	// Sport is ride or virtualride, never all.
+	Sport string
+	// Distance is in meters.
+	Distance   float64
+	Activities int
+	// ElevationGain is in meters.
+	ElevationGain float64
+	// MovingTime is in seconds.
+	MovingTime    float64
+	HugelSegments int
+}
+
+// activity is the day as if it were one ride, which is how a Metric sees it.
+func (t DayTotal) activity() Activity {
+	return Activity{
+		StartDateLocal: t.Date,
+		Distance:       t.Distance,
+		Sport:          t.Sport,
+		ElevationGain:  t.ElevationGain,
+		MovingTime:     t.MovingTime,
+		HugelSegments:  t.HugelSegments,
+	}
+}
+
+// Remove takes back an Add of the same value.
+func (e *Sums) Remove(value int) {
+	for i := 0; i < value && i < len(*e); i++ {
+		(*e)[i]--
+	}
+	// Keep the same shape as counting from scratch.
+	for len(*e) > 0 && (*e)[len(*e)-1] <= 0 {
+		*e = (*e)[:len(*e)-1]
+	}
+}
+
+// Update moves the breakdowns from the totals of some days before a change to
+// the totals after it. Only the touched days are visited, before and after
+// must cover the same days. A day without rides has no totals.
+func Update(breakdowns []Breakdown, before, after []DayTotal) []Breakdown {
+	type key struct {
+		sport string
+		year  int
+	}
+	byKey := make(map[key]*Breakdown, len(breakdowns))
+	for i := range breakdowns {
+		b := breakdowns[i]
+		b.Miles = slices.Clone(b.Miles)
+		b.Kilometers = slices.Clone(b.Kilometers)
+		byKey[key{b.Sport, b.Year}] = &b
+	}
+
+	apply := func(sport string, date time.Time, distance float64, sign int) {
+		for _, year := range []int{0, date.Year()} {
+			b, ok := byKey[key{sport, year}]
+			if !ok {
+				b = &Breakdown{Sport: sport, Year: year, Miles: Sums{}, Kilometers: Sums{}}
+				byKey[key{sport, year}] = b
+			}
+			b.Days += sign
+			if sign > 0 {
+				b.Miles.Add(int(distance / float64(Miles)))
+				b.Kilometers.Add(int(distance / float64(Kilometers)))
+			} else {
+				b.Miles.Remove(int(distance / float64(Miles)))
+				b.Kilometers.Remove(int(distance / float64(Kilometers)))
+			}
+		}
+	}
+
+	// Each side of the change is applied per sport and for all sports, where
+	// the day total is the sum of its sports.
+	side := func(totals []DayTotal, sign int) {
+		all := make(map[time.Time]float64)
+		for _, t := range totals {
+			if t.Activities == 0 {
+				continue
+			}
+			apply(t.Sport, t.Date, t.Distance, sign)
+			all[t.Date] += t.Distance
+		}
+		for date, distance := range all {
+			apply(SportAll, date, distance, sign)
+		}
+	}
+	side(before, -1)
+	side(after, 1)
+
+	out := make([]Breakdown, 0, len(byKey))
+	for _, b := range byKey {
+		if b.Days <= 0 {
+			continue
+		}
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		si, sj := slices.Index(Sports, out[i].Sport), slices.Index(Sports, out[j].Sport)
+		if si != sj {
+			return si < sj
+		}
+		return out[i].Year < out[j].Year
+	})
+	return out
+}
@@ -0,0 +1,115 @@
+package eddington
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	MetricElevationFeet   = "elevation_ft"
+	MetricElevationMeters = "elevation_m"
+	MetricHours           = "hours"
+	MetricHugelSegments   = "hugel_segments"
+)
+
+// Metric is a number counted from something other than distance. A day counts
+// the whole buckets of the total of its rides, so an E of 20 in hours is 20
+// days of at least 20 hours of riding.
+type Metric struct {
+	Name string
+	// Bucket is one step of the number, in the unit of Value.
+	Bucket float64
+	// Value is the amount of an activity. Metrics must add up, the value of a
+	// day is the value of its rides summed.
+	Value func(Activity) float64
+}
+
+// Metrics are every number stored per athlete besides distance.
+var Metrics = []Metric{
+	{
+		Name: MetricElevationFeet,
+		// 100 feet in meters.
+		Bucket: 30.48,
+		Value:  func(a Activity) float64 { return a.ElevationGain },
+	},
+	{
+		Name:   MetricElevationMeters,
+		Bucket: 50,
+		Value:  func(a Activity) float64 { return a.ElevationGain },
+	},
+	{
+		Name:   MetricHours,
+		Bucket: time.Hour.Seconds(),
+		Value:  func(a Activity) float64 { return a.MovingTime },
+	},
+	{
+		Name:   MetricHugelSegments,
+		Bucket: 1,
+		Value:  func(a Activity) float64 { return float64(a.HugelSegments) },
+	},
+}
+
+// MetricByName finds one of the Metrics.
+func MetricByName(name string) (Metric, bool) {
+	for _, m := range Metrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Metric{}, false
+}
+
+// MetricSums is the lifetime number of every ride, and the days it was
+// counted from.
+func MetricSums(acts []Activity, m Metric) (Sums, int) {
+	totals := make(map[time.Time]float64)
+	for _, act := range acts {
+		totals[localDay(act.StartDateLocal)] += m.Value(act)
+	}
+	sums := Sums{}
+	for _, total := range totals {
+		sums.Add(m.count(total))
+	}
+	return sums, len(totals)
+}
+
+// UpdateMetric moves the number from the totals of some days before a change
+// to the totals after it, like Update does for distance.
+func UpdateMetric(m Metric, sums Sums, days int, before, after []DayTotal) (Sums, int) {
+	sums = append(Sums{}, sums...)
+	for _, t := range dayValues(m, before) {
+		sums.Remove(m.count(t))
+		days--
+	}
+	for _, t := range dayValues(m, after) {
+		sums.Add(m.count(t))
+		days++
+	}
+	return sums, days
+}
+
+func (m Metric) count(total float64) int {
+	return int(total / m.Bucket)
+}
+
+// dayValues sums the sports of each day, in date order.
+func dayValues(m Metric, totals []DayTotal) []float64 {
+	byDate := make(map[time.Time]float64)
+	for _, t := range totals {
+		if t.Activities == 0 {
+			continue
+		}
+		byDate[t.Date] += m.Value(t.activity())
+	}
+	dates := make([]time.Time, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	values := make([]float64, 0, len(dates))
+	for _, date := range dates {
+		values = append(values, byDate[date])
+	}
+	return values
+}
@@ -0,0 +1,15 @@
+package eddington
+
+// Ranks are the places of numbers sorted highest first. Equal numbers share
+// the higher place and the next place is skipped, e.g. 1, 2, 2, 4.
+func Ranks(sorted []int32) []int {
+	ranks := make([]int, len(sorted))
+	for i, n := range sorted {
+		if i > 0 && n == sorted[i-1] {
+			ranks[i] = ranks[i-1]
+			continue
+		}
+		ranks[i] = i + 1
+	}
+	return ranks
+}
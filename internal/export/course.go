@@ -0,0 +1,339 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// Course is a competitive route laid out to be followed on a head unit.
+type Course struct {
+	Name        string
+	Description string
+	// Start is the time of the first track point. Head units only use course
+	// times to pace a virtual partner, so any time works.
+	Start time.Time
+	// Climbs are the segments of the route, in the order they are ridden.
+	Climbs []Climb
+	// Reference is the track of a finisher's ride, which connects the climbs
+	// along real roads. Without one the climbs are connected by straight
+	// lines.
+	Reference []geo.Point
+}
+
+type Climb struct {
+	ID            int64
+	Name          string
+	Line          []geo.Point
+	Distance      float64
+	AverageGrade  float64
+	ElevationLow  float64
+	ElevationHigh float64
+	ClimbCategory int32
+}
+
+// Leg is a part of the course track.
+type Leg struct {
+	Points []geo.Point
+	// Gap is a straight line between two climbs, not a road.
+	Gap bool
+}
+
+// courseSpeed is the pace of the course times, in meters per second.
+const courseSpeed = 20.0 / 3.6
+
+// Legs returns the track of the course in riding order.
+func (c Course) Legs() []Leg {
+	if len(c.Climbs) == 0 {
+		return nil
+	}
+
+	if len(c.Reference) > 1 {
+		first := c.Climbs[0].Line
+		last := c.Climbs[len(c.Climbs)-1].Line
+		if len(first) > 0 && len(last) > 0 {
+			// Trim the ride to and from the route.
+			start := nearestIndex(c.Reference, first[0], 0)
+			end := nearestIndex(c.Reference, last[len(last)-1], start)
+			if end > start {
+				return []Leg{{Points: c.Reference[start : end+1]}}
+			}
+		}
+	}
+
+	var legs []Leg
+	var prev []geo.Point
+	for _, climb := range c.Climbs {
+		if len(climb.Line) == 0 {
+			continue
+		}
+		if len(prev) > 0 {
+			from, to := prev[len(prev)-1], climb.Line[0]
+			if geo.Distance(from, to) > 1 {
+				legs = append(legs, Leg{Points: []geo.Point{from, to}, Gap: true})
+			}
+		}
+		legs = append(legs, Leg{Points: climb.Line})
+		prev = climb.Line
+	}
+	return legs
+}
+
+// nearestIndex returns the index of the point in the line closest to p, at or
+// after from. Routes can pass the same place twice, searching forward keeps
+// the matches in riding order.
+func nearestIndex(line []geo.Point, p geo.Point, from int) int {
+	best, bestDistance := from, math.Inf(1)
+	for i := from; i < len(line); i++ {
+		if d := geo.Distance(line[i], p); d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+// marker is a named point along the course.
+type marker struct {
+	Point       geo.Point
+	Name        string
+	Description string
+	Elevation   *float64
+	Gap         bool
+	Category    int32
+}
+
+func (c Course) markers(legs []Leg) []marker {
+	var markers []marker
+	for _, climb := range c.Climbs {
+		if len(climb.Line) == 0 {
+			continue
+		}
+		elevation := climb.ElevationLow
+		markers = append(markers, marker{
+			Point: climb.Line[0],
+			Name:  climb.Name,
+			Description: fmt.Sprintf("%.1f km at %.1f%%, %.0f m to %.0f m",
+				climb.Distance/1000, climb.AverageGrade, climb.ElevationLow, climb.ElevationHigh),
+			Elevation: &elevation,
+			Category:  climb.ClimbCategory,
+		})
+	}
+
+	for i, leg := range legs {
+		if !leg.Gap {
+			continue
+		}
+		next := "the next climb"
+		if i+1 < len(legs) && len(legs[i+1].Points) > 0 {
+			for _, climb := range c.Climbs {
+				if len(climb.Line) > 0 && climb.Line[0] == legs[i+1].Points[0] {
+					next = climb.Name
+				}
+			}
+		}
+		markers = append(markers, marker{
+			Point:       leg.Points[0],
+			Name:        "Gap",
+			Description: fmt.Sprintf("Straight line to %s, %.1f km. Find your own road.", next, geo.Length(leg.Points)/1000),
+			Gap:         true,
+		})
+	}
+	return markers
+}
+
+// WriteCourseGPX writes the course as a GPX track, with a waypoint at the
+// start of every climb and at every gap between climbs.
+func WriteCourseGPX(w io.Writer, c Course) error {
+	legs := c.Legs()
+	doc := gpx{
+		Version: "1.1",
+		Creator: "Das Hugel",
+		Track: gpxTrack{
+			Name:        c.Name,
+			Description: c.Description,
+			Segments:    make([]gpxTrackSegment, 0, len(legs)),
+		},
+	}
+	for _, leg := range legs {
+		doc.Track.Segments = append(doc.Track.Segments, newGPXTrackSegment(leg.Points))
+	}
+	for _, m := range c.markers(legs) {
+		kind := "climb"
+		if m.Gap {
+			kind = "gap"
+		}
+		doc.Waypoints = append(doc.Waypoints, gpxWaypoint{
+			Lat:         m.Point.Lat,
+			Lon:         m.Point.Lng,
+			Elevation:   m.Elevation,
+			Name:        m.Name,
+			Description: m.Description,
+			Type:        kind,
+		})
+	}
+	return writeXML(w, doc)
+}
+
+type tcx struct {
+	XMLName xml.Name    `xml:"http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2 TrainingCenterDatabase"`
+	Courses []tcxCourse `xml:"Courses>Course"`
+}
+
+type tcxCourse struct {
+	Name        string           `xml:"Name"`
+	Lap         tcxLap           `xml:"Lap"`
+	Track       []tcxTrackpoint  `xml:"Track>Trackpoint"`
+	CoursePoint []tcxCoursePoint `xml:"CoursePoint"`
+}
+
+type tcxLap struct {
+	TotalTimeSeconds float64     `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64     `xml:"DistanceMeters"`
+	BeginPosition    tcxPosition `xml:"BeginPosition"`
+	EndPosition      tcxPosition `xml:"EndPosition"`
+	Intensity        string      `xml:"Intensity"`
+}
+
+type tcxTrackpoint struct {
+	Time           string      `xml:"Time"`
+	Position       tcxPosition `xml:"Position"`
+	DistanceMeters float64     `xml:"DistanceMeters"`
+}
+
+type tcxPosition struct {
+	Lat float64 `xml:"LatitudeDegrees"`
+	Lng float64 `xml:"LongitudeDegrees"`
+}
+
+type tcxCoursePoint struct {
+	Name      string      `xml:"Name"`
+	Time      string      `xml:"Time"`
+	Position  tcxPosition `xml:"Position"`
+	Altitude  *float64    `xml:"AltitudeMeters,omitempty"`
+	PointType string      `xml:"PointType"`
+	Notes     string      `xml:"Notes,omitempty"`
+}
+
+// WriteCourseTCX writes the course as a TCX course, with a course point at the
+// start of every climb and at every gap between climbs.
+func WriteCourseTCX(w io.Writer, c Course) error {
+	var points []geo.Point
+	for _, leg := range c.Legs() {
+		for _, p := range leg.Points {
+			// Legs share their end points.
+			if len(points) > 0 && points[len(points)-1] == p {
+				continue
+			}
+			points = append(points, p)
+		}
+	}
+
+	start := c.Start
+	if start.IsZero() {
+		start = time.Unix(0, 0)
+	}
+	timeAt := func(distance float64) string {
+		return start.Add(time.Duration(distance / courseSpeed * float64(time.Second))).UTC().Format(time.RFC3339)
+	}
+
+	course := tcxCourse{
+		// Garmin devices only show the first 15 characters.
+		Name: truncate(c.Name, 15),
+		Lap: tcxLap{
+			Intensity: "Active",
+		},
+		Track: make([]tcxTrackpoint, 0, len(points)),
+	}
+	distances := make([]float64, len(points))
+	for i, p := range points {
+		if i > 0 {
+			distances[i] = distances[i-1] + geo.Distance(points[i-1], p)
+		}
+		course.Track = append(course.Track, tcxTrackpoint{
+			Time:           timeAt(distances[i]),
+			Position:       tcxPosition{Lat: p.Lat, Lng: p.Lng},
+			DistanceMeters: distances[i],
+		})
+	}
+	if len(points) > 0 {
+		total := distances[len(distances)-1]
+		course.Lap.DistanceMeters = total
+		course.Lap.TotalTimeSeconds = math.Round(total / courseSpeed)
+		course.Lap.BeginPosition = tcxPosition{Lat: points[0].Lat, Lng: points[0].Lng}
+		course.Lap.EndPosition = tcxPosition{Lat: points[len(points)-1].Lat, Lng: points[len(points)-1].Lng}
+	}
+
+	// Course points must be on the track, so place each on its nearest point.
+	// Climbs are searched for in order, as the route can pass a climb's start
+	// more than once.
+	type placed struct {
+		index int
+		point tcxCoursePoint
+	}
+	var coursePoints []placed
+	from := 0
+	for _, m := range c.markers(c.Legs()) {
+		if len(points) == 0 {
+			break
+		}
+		var i int
+		cp := tcxCoursePoint{Name: m.Name, Notes: m.Description, PointType: "Danger"}
+		if m.Gap {
+			// Gaps start exactly on a track point.
+			i = nearestIndex(points, m.Point, 0)
+		} else {
+			i = nearestIndex(points, m.Point, from)
+			from = i
+			cp = tcxCoursePoint{
+				Name:      truncate(m.Name, 10),
+				Altitude:  m.Elevation,
+				PointType: climbPointType(m.Category),
+				Notes:     m.Name + ": " + m.Description,
+			}
+		}
+		cp.Time = timeAt(distances[i])
+		cp.Position = tcxPosition{Lat: points[i].Lat, Lng: points[i].Lng}
+		coursePoints = append(coursePoints, placed{index: i, point: cp})
+	}
+	sort.SliceStable(coursePoints, func(a, b int) bool {
+		return coursePoints[a].index < coursePoints[b].index
+	})
+	for _, cp := range coursePoints {
+		course.CoursePoint = append(course.CoursePoint, cp.point)
+	}
+
+	return writeXML(w, tcx{Courses: []tcxCourse{course}})
+}
+
+// climbPointType is the TCX point type of a Strava climb category.
+func climbPointType(category int32) string {
+	switch category {
+	case 1:
+		return "4th Category"
+	case 2:
+		return "3rd Category"
+	case 3:
+		return "2nd Category"
+	case 4:
+		return "1st Category"
+	case 5:
+		return "Hors Category"
+	default:
+		return "Generic"
+	}
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n]))
+}
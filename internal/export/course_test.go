@@ -0,0 +1,151 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/internal/export"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+func testCourse() export.Course {
+	return export.Course{
+		Name:  "Tour Das Hugel Lite",
+		Start: time.Date(2024, time.November, 9, 7, 0, 0, 0, time.UTC),
+		Climbs: []export.Climb{
+			{
+				ID:            1,
+				Name:          "Mount Bonnell",
+				Line:          []geo.Point{{Lat: 30.300, Lng: -97.800}, {Lat: 30.301, Lng: -97.800}},
+				Distance:      111,
+				AverageGrade:  8.5,
+				ElevationLow:  150,
+				ElevationHigh: 230,
+				ClimbCategory: 1,
+			},
+			{
+				ID:           2,
+				Name:         "Jester",
+				Line:         []geo.Point{{Lat: 30.310, Lng: -97.800}, {Lat: 30.311, Lng: -97.800}},
+				ElevationLow: 200,
+			},
+			{
+				// Continues from the end of Jester, no gap.
+				ID:   3,
+				Name: "Courtyard",
+				Line: []geo.Point{{Lat: 30.311, Lng: -97.800}, {Lat: 30.312, Lng: -97.800}},
+			},
+		},
+	}
+}
+
+func TestCourseLegs(t *testing.T) {
+	t.Parallel()
+
+	c := testCourse()
+	legs := c.Legs()
+	require.Len(t, legs, 4)
+	require.False(t, legs[0].Gap)
+	require.True(t, legs[1].Gap)
+	require.Equal(t, []geo.Point{c.Climbs[0].Line[1], c.Climbs[1].Line[0]}, legs[1].Points)
+	require.False(t, legs[2].Gap)
+	require.False(t, legs[3].Gap)
+
+	require.Empty(t, export.Course{}.Legs())
+}
+
+func TestCourseReference(t *testing.T) {
+	t.Parallel()
+
+	c := testCourse()
+	c.Reference = []geo.Point{
+		// Riding to the start
+		{Lat: 30.290, Lng: -97.800},
+		{Lat: 30.300, Lng: -97.800},
+		{Lat: 30.305, Lng: -97.801},
+		{Lat: 30.312, Lng: -97.800},
+		// Riding home
+		{Lat: 30.290, Lng: -97.790},
+	}
+
+	legs := c.Legs()
+	require.Len(t, legs, 1)
+	require.False(t, legs[0].Gap)
+	require.Equal(t, c.Reference[1:4], legs[0].Points)
+}
+
+func TestWriteCourseGPX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteCourseGPX(&buf, testCourse()))
+
+	var doc struct {
+		Waypoints []struct {
+			Lat       float64  `xml:"lat,attr"`
+			Elevation *float64 `xml:"ele"`
+			Name      string   `xml:"name"`
+			Type      string   `xml:"type"`
+		} `xml:"wpt"`
+		Segments []struct {
+			Points []struct{} `xml:"trkpt"`
+		} `xml:"trk>trkseg"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+	require.Len(t, doc.Segments, 4)
+	require.Len(t, doc.Waypoints, 4)
+	require.Equal(t, "Mount Bonnell", doc.Waypoints[0].Name)
+	require.Equal(t, "climb", doc.Waypoints[0].Type)
+	require.Equal(t, 150.0, *doc.Waypoints[0].Elevation)
+	require.Equal(t, "Gap", doc.Waypoints[3].Name)
+	require.Equal(t, "gap", doc.Waypoints[3].Type)
+	require.Nil(t, doc.Waypoints[3].Elevation)
+	require.Equal(t, 30.301, doc.Waypoints[3].Lat)
+}
+
+func TestWriteCourseTCX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteCourseTCX(&buf, testCourse()))
+
+	var doc struct {
+		Name  string `xml:"Courses>Course>Name"`
+		Track []struct {
+			Time           time.Time `xml:"Time"`
+			DistanceMeters float64   `xml:"DistanceMeters"`
+		} `xml:"Courses>Course>Track>Trackpoint"`
+		CoursePoints []struct {
+			Name      string    `xml:"Name"`
+			Time      time.Time `xml:"Time"`
+			PointType string    `xml:"PointType"`
+		} `xml:"Courses>Course>CoursePoint"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+	require.Equal(t, "Tour Das Hugel", doc.Name)
+	// Shared end points are only in the track once.
+	require.Len(t, doc.Track, 5)
+	for i := 1; i < len(doc.Track); i++ {
+		require.Greater(t, doc.Track[i].DistanceMeters, doc.Track[i-1].DistanceMeters)
+		require.True(t, doc.Track[i].Time.After(doc.Track[i-1].Time))
+	}
+
+	// Course points are in riding order, on the track.
+	names := make([]string, 0, len(doc.CoursePoints))
+	for i, cp := range doc.CoursePoints {
+		names = append(names, cp.Name)
+		if i > 0 {
+			require.False(t, cp.Time.Before(doc.CoursePoints[i-1].Time))
+		}
+	}
+	require.Equal(t, []string{"Mount Bonn", "Gap", "Jester", "Courtyard"}, names)
+	require.Equal(t, "4th Category", doc.CoursePoints[0].PointType)
+	require.Equal(t, "Danger", doc.CoursePoints[1].PointType)
+	require.Equal(t, "Generic", doc.CoursePoints[2].PointType)
+}
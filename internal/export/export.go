@@ -0,0 +1,214 @@
+// Package export writes competitive route results in formats meant for other
+// tools, e.g. spreadsheets kept by event organisers and clubs.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// Results is the ranked leaderboard of an edition.
+type Results struct {
+	Edition hugeldate.Edition
+	// Segments are the split columns, in the order they are ridden.
+	Segments []Segment
+	Rows     []database.HugelLeaderboardRow
+}
+
+type Segment struct {
+	ID   int64
+	Name string
+}
+
+// Leaderboard loads the ranked results of an edition.
+func Leaderboard(ctx context.Context, db database.Store, edition hugeldate.Edition) ([]database.HugelLeaderboardRow, error) {
+	return db.YearlyHugelLeaderboard(ctx, database.YearlyHugelLeaderboardParams{
+		RouteYear: edition.Year,
+		Lite:      edition.Lite,
+		HugelLeaderboardParams: database.HugelLeaderboardParams{
+			AthleteID: -1,
+			After:     database.Timestamp(edition.Dates.Start),
+			Before:    database.Timestamp(edition.Dates.End),
+		},
+	})
+}
+
+// Load names the split columns of the leaderboard rows. Segments use their
+// friendly name when one is set.
+func Load(ctx context.Context, db database.Store, edition hugeldate.Edition, rows []database.HugelLeaderboardRow) (Results, error) {
+	ids := segmentOrder(rows)
+	segments, err := db.GetSegments(ctx, ids)
+	if err != nil {
+		return Results{}, fmt.Errorf("segments: %w", err)
+	}
+
+	names := make(map[int64]string, len(segments))
+	for _, seg := range segments {
+		names[seg.Segment.ID] = seg.Segment.Name
+		if seg.Segment.FriendlyName != "" {
+			names[seg.Segment.ID] = seg.Segment.FriendlyName
+		}
+	}
+
+	results := Results{
+		Edition: edition,
+		Rows:    rows,
+	}
+	used := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		name := names[id]
+		if name == "" || used[name] {
+			// Column names must be unique to be useful in a spreadsheet.
+			name = fmt.Sprintf("%s (%d)", name, id)
+		}
+		used[name] = true
+		results.Segments = append(results.Segments, Segment{ID: id, Name: name})
+	}
+	return results, nil
+}
+
+// segmentOrder returns every segment in the results, ordered as the fastest
+// rider climbed them. Segments they did not ride go last.
+func segmentOrder(rows []database.HugelLeaderboardRow) []int64 {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, row := range rows {
+		efforts := make(database.HugelSegmentEfforts, len(row.Efforts))
+		copy(efforts, row.Efforts)
+		sort.SliceStable(efforts, func(i, j int) bool {
+			return efforts[i].StartDate.Before(efforts[j].StartDate)
+		})
+		for _, e := range efforts {
+			id := int64(e.SegmentID)
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// Filename is the suggested name of an export file, e.g.
+// "tour-das-hugel-2024-results.csv".
+func (r Results) Filename(ext string) string {
+	lite := ""
+	if r.Edition.Lite {
+		lite = "lite-"
+	}
+	return fmt.Sprintf("tour-das-hugel-%s%d-results.%s", lite, r.Edition.Year, ext)
+}
+
+// splits returns the elapsed seconds of each segment column, -1 for
+// segments without an effort.
+func (r Results) splits(row database.HugelLeaderboardRow) []int64 {
+	elapsed := make(map[int64]int64, len(row.Efforts))
+	for _, e := range row.Efforts {
+		elapsed[int64(e.SegmentID)] = int64(e.ElapsedTime)
+	}
+
+	splits := make([]int64, 0, len(r.Segments))
+	for _, seg := range r.Segments {
+		secs, ok := elapsed[seg.ID]
+		if !ok {
+			secs = -1
+		}
+		splits = append(splits, secs)
+	}
+	return splits
+}
+
+// WriteCSV writes one row per ranked result, with a column of elapsed
+// seconds per segment.
+func WriteCSV(w io.Writer, r Results) error {
+	cw := csv.NewWriter(w)
+	header := []string{"rank", "athlete_id", "firstname", "lastname", "activity_id", "activity_name", "start_date", "total_time_seconds"}
+	for _, seg := range r.Segments {
+		header = append(header, seg.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range r.Rows {
+		record := []string{
+			strconv.FormatInt(row.Rank, 10),
+			strconv.FormatInt(row.AthleteID, 10),
+			row.Firstname,
+			row.Lastname,
+			strconv.FormatInt(row.ActivityID, 10),
+			row.Name,
+			row.StartDate.Time.Format(time.RFC3339),
+			strconv.FormatInt(row.TotalTimeSeconds, 10),
+		}
+		for _, secs := range r.splits(row) {
+			if secs < 0 {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, strconv.FormatInt(secs, 10))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type jsonRow struct {
+	Rank             int64       `json:"rank"`
+	AthleteID        int64       `json:"athlete_id"`
+	Firstname        string      `json:"firstname"`
+	Lastname         string      `json:"lastname"`
+	ActivityID       int64       `json:"activity_id"`
+	ActivityName     string      `json:"activity_name"`
+	StartDate        time.Time   `json:"start_date"`
+	TotalTimeSeconds int64       `json:"total_time_seconds"`
+	Splits           []jsonSplit `json:"splits"`
+}
+
+type jsonSplit struct {
+	SegmentID          int64  `json:"segment_id"`
+	Name               string `json:"name"`
+	ElapsedTimeSeconds *int64 `json:"elapsed_time_seconds"`
+}
+
+// WriteJSONL writes one JSON object per ranked result, one per line.
+func WriteJSONL(w io.Writer, r Results) error {
+	enc := json.NewEncoder(w)
+	for _, row := range r.Rows {
+		out := jsonRow{
+			Rank:             row.Rank,
+			AthleteID:        row.AthleteID,
+			Firstname:        row.Firstname,
+			Lastname:         row.Lastname,
+			ActivityID:       row.ActivityID,
+			ActivityName:     row.Name,
+			StartDate:        row.StartDate.Time,
+			TotalTimeSeconds: row.TotalTimeSeconds,
+			Splits:           make([]jsonSplit, 0, len(r.Segments)),
+		}
+		for i, secs := range r.splits(row) {
+			split := jsonSplit{SegmentID: r.Segments[i].ID, Name: r.Segments[i].Name}
+			if secs >= 0 {
+				secs := secs
+				split.ElapsedTimeSeconds = &secs
+			}
+			out.Splits = append(out.Splits, split)
+		}
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
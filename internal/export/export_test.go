@@ -0,0 +1,151 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/export"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+func testResults() export.Results {
+	start := time.Date(2024, time.November, 9, 14, 0, 0, 0, time.UTC)
+	edition, _ := hugeldate.EditionByYear(2024, false)
+	return export.Results{
+		Edition: edition,
+		Segments: []export.Segment{
+			{ID: 10, Name: "Mount Bonnell"},
+			{ID: 20, Name: "Jester, \"the wall\""},
+		},
+		Rows: []database.HugelLeaderboardRow{
+			{
+				Rank:             1,
+				AthleteID:        100,
+				ActivityID:       1000,
+				Firstname:        "Steven",
+				Lastname:         "Masley",
+				Name:             "Das Hugel",
+				StartDate:        pgtype.Timestamptz{Time: start, Valid: true},
+				TotalTimeSeconds: 360,
+				Efforts: database.HugelSegmentEfforts{
+					{SegmentID: 10, ElapsedTime: 60},
+					{SegmentID: 20, ElapsedTime: 300},
+				},
+			},
+			{
+				Rank:             2,
+				AthleteID:        200,
+				ActivityID:       2000,
+				Firstname:        "Jane",
+				Lastname:         "Doe",
+				Name:             "Hills",
+				StartDate:        pgtype.Timestamptz{Time: start, Valid: true},
+				TotalTimeSeconds: 90,
+				Efforts: database.HugelSegmentEfforts{
+					{SegmentID: 10, ElapsedTime: 90},
+				},
+			},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteCSV(&buf, testResults()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, []string{"rank", "athlete_id", "firstname", "lastname", "activity_id", "activity_name", "start_date", "total_time_seconds", "Mount Bonnell", "Jester, \"the wall\""}, records[0])
+	require.Equal(t, []string{"1", "100", "Steven", "Masley", "1000", "Das Hugel", "2024-11-09T14:00:00Z", "360", "60", "300"}, records[1])
+	// Missing efforts are empty cells
+	require.Equal(t, []string{"2", "200", "Jane", "Doe", "2000", "Hills", "2024-11-09T14:00:00Z", "90", "90", ""}, records[2])
+}
+
+func TestWriteJSONL(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteJSONL(&buf, testResults()))
+
+	type split struct {
+		SegmentID          int64  `json:"segment_id"`
+		Name               string `json:"name"`
+		ElapsedTimeSeconds *int64 `json:"elapsed_time_seconds"`
+	}
+	type row struct {
+		Rank       int64   `json:"rank"`
+		ActivityID int64   `json:"activity_id"`
+		Splits     []split `json:"splits"`
+	}
+
+	var rows []row
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r row
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		rows = append(rows, r)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, rows, 2)
+	require.Equal(t, int64(2000), rows[1].ActivityID)
+	require.Len(t, rows[1].Splits, 2)
+	require.Equal(t, int64(90), *rows[1].Splits[0].ElapsedTimeSeconds)
+	require.Nil(t, rows[1].Splits[1].ElapsedTimeSeconds)
+}
+
+func TestWriteGPXZip(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := export.WriteGPXZip(&buf, testResults(), map[int64]string{
+		// The example from Google's polyline documentation.
+		1000: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+	})
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	// Results without a polyline are left out
+	require.Len(t, zr.File, 1)
+	require.Equal(t, "001-steven-masley-1000.gpx", zr.File[0].Name)
+
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	var doc struct {
+		Points []struct {
+			Lat float64 `xml:"lat,attr"`
+			Lon float64 `xml:"lon,attr"`
+		} `xml:"trk>trkseg>trkpt"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	require.Len(t, doc.Points, 3)
+	require.InDelta(t, 38.5, doc.Points[0].Lat, 1e-9)
+	require.InDelta(t, -120.2, doc.Points[0].Lon, 1e-9)
+	require.InDelta(t, 43.252, doc.Points[2].Lat, 1e-9)
+}
+
+func TestFilename(t *testing.T) {
+	t.Parallel()
+
+	r := testResults()
+	require.Equal(t, "tour-das-hugel-2024-results.csv", r.Filename("csv"))
+	r.Edition.Lite = true
+	require.Equal(t, "tour-das-hugel-lite-2024-results.jsonl", r.Filename("jsonl"))
+}
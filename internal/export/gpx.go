@@ -0,0 +1,101 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+type gpx struct {
+	XMLName   xml.Name      `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Track     gpxTrack      `xml:"trk"`
+}
+
+type gpxWaypoint struct {
+	Lat         float64  `xml:"lat,attr"`
+	Lon         float64  `xml:"lon,attr"`
+	Elevation   *float64 `xml:"ele,omitempty"`
+	Name        string   `xml:"name"`
+	Description string   `xml:"desc,omitempty"`
+	Type        string   `xml:"type,omitempty"`
+}
+
+type gpxTrack struct {
+	Name        string            `xml:"name"`
+	Description string            `xml:"desc,omitempty"`
+	Segments    []gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+var unsafeFilename = regexp.MustCompile(`[^a-z0-9]+`)
+
+// WriteGPXZip writes a zip with a GPX track per ranked result. Polylines are
+// the encoded summary polylines keyed by activity id, results without one are
+// left out. Summary polylines are simplified, so the tracks are only good
+// enough for drawing.
+func WriteGPXZip(w io.Writer, r Results, polylines map[int64]string) error {
+	zw := zip.NewWriter(w)
+	for _, row := range r.Rows {
+		points, _ := geo.DecodePolyline(polylines[row.ActivityID])
+		if len(points) == 0 {
+			continue
+		}
+
+		name := strings.TrimSpace(fmt.Sprintf("%s %s", row.Firstname, row.Lastname))
+		doc := gpx{
+			Version: "1.1",
+			Creator: "Das Hugel",
+			Track: gpxTrack{
+				Name: fmt.Sprintf("%d. %s - %s", row.Rank, name, row.Name),
+			},
+		}
+		doc.Track.Segments = []gpxTrackSegment{newGPXTrackSegment(points)}
+
+		slug := strings.Trim(unsafeFilename.ReplaceAllString(strings.ToLower(name), "-"), "-")
+		f, err := zw.Create(fmt.Sprintf("%03d-%s-%d.gpx", row.Rank, slug, row.ActivityID))
+		if err != nil {
+			return err
+		}
+		if err := writeXML(f, doc); err != nil {
+			return fmt.Errorf("activity %d: %w", row.ActivityID, err)
+		}
+	}
+	return zw.Close()
+}
+
+func newGPXTrackSegment(points []geo.Point) gpxTrackSegment {
+	seg := gpxTrackSegment{Points: make([]gpxPoint, 0, len(points))}
+	for _, p := range points {
+		seg.Points = append(seg.Points, gpxPoint{Lat: p.Lat, Lon: p.Lng})
+	}
+	return seg
+}
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,240 @@
+// Package gpsmatch finds the segments an activity rode from its own GPS track.
+// Strava sometimes fails to match a segment, from GPS drift, a paused
+// recording or a hidden segment. A match here is an estimate, it is never as
+// exact as Strava's own efforts.
+package gpsmatch
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Emyrk/strava/lib/geo"
+	"github.com/Emyrk/strava/strava"
+)
+
+const (
+	// DefaultTolerance is how far in meters the track may stray from the
+	// segment. Consumer GPS drifts about 5-15 m, a bit more under trees.
+	DefaultTolerance = 25
+	// DefaultMinCoverage is the fraction of the segment the track has to
+	// follow within the tolerance.
+	DefaultMinCoverage = 0.9
+)
+
+// Track is the recorded path of an activity.
+type Track struct {
+	Points []geo.Point
+	// Times are seconds since the start of the activity, one per point. Nil if
+	// the track came from a polyline, matches then have no elapsed time.
+	Times []float64
+}
+
+// TrackFromStreams builds a track from the latlng and time streams of an
+// activity. Samples without a position are dropped.
+func TrackFromStreams(streams strava.StreamSet) (Track, error) {
+	if streams.LatLng == nil || len(streams.LatLng.Data) == 0 {
+		return Track{}, fmt.Errorf("activity has no latlng stream")
+	}
+	if streams.Time == nil || len(streams.Time.Data) != len(streams.LatLng.Data) {
+		return Track{}, fmt.Errorf("activity time stream does not line up with latlng")
+	}
+
+	t := Track{
+		Points: make([]geo.Point, 0, len(streams.LatLng.Data)),
+		Times:  make([]float64, 0, len(streams.Time.Data)),
+	}
+	for i, ll := range streams.LatLng.Data {
+		if ll[0] == 0 && ll[1] == 0 {
+			continue
+		}
+		t.Points = append(t.Points, geo.Point{Lat: ll[0], Lng: ll[1]})
+		t.Times = append(t.Times, float64(streams.Time.Data[i]))
+	}
+	return t, nil
+}
+
+// Segment is the geometry of a segment to look for.
+type Segment struct {
+	ID   int64
+	Line []geo.Point
+}
+
+// Reason is why a segment did or did not match.
+type Reason string
+
+const (
+	ReasonMatched         Reason = "matched"
+	ReasonNoTrack         Reason = "no_track"
+	ReasonNeverNearStart  Reason = "never_near_start"
+	ReasonNeverReachedEnd Reason = "never_reached_end"
+	ReasonOffCourse       Reason = "off_course"
+)
+
+// Match is the best traversal of a segment found in a track.
+type Match struct {
+	SegmentID int64
+	Reason    Reason
+	// StartIndex and EndIndex are the track points closest to the segment's
+	// start and end.
+	StartIndex int
+	EndIndex   int
+	// Coverage is the fraction of the segment the track follows within the
+	// tolerance. It is set for near misses too.
+	Coverage float64
+	// Distance of the track from StartIndex to EndIndex in meters.
+	Distance float64
+	// ElapsedTime in seconds, 0 if the track has no times.
+	ElapsedTime float64
+}
+
+func (m Match) Matched() bool {
+	return m.Reason == ReasonMatched
+}
+
+// Explain describes the match for a person.
+func (m Match) Explain(tolerance float64) string {
+	switch m.Reason {
+	case ReasonMatched:
+		return fmt.Sprintf("Matched by GPS, the track follows %.0f%% of the segment within %.0f m", m.Coverage*100, tolerance)
+	case ReasonNoTrack:
+		return "The activity has no GPS track to match"
+	case ReasonNeverNearStart:
+		return fmt.Sprintf("The track never comes within %.0f m of the segment start", tolerance)
+	case ReasonNeverReachedEnd:
+		return fmt.Sprintf("The track passes the segment start, but never reaches the end within %.0f m", tolerance)
+	case ReasonOffCourse:
+		return fmt.Sprintf("The track passes the start and end, but only follows %.0f%% of the segment within %.0f m", m.Coverage*100, tolerance)
+	default:
+		return string(m.Reason)
+	}
+}
+
+type Matcher struct {
+	Tolerance   float64
+	MinCoverage float64
+}
+
+// New returns a matcher with the default tolerance and coverage.
+func New() Matcher {
+	return Matcher{
+		Tolerance:   DefaultTolerance,
+		MinCoverage: DefaultMinCoverage,
+	}
+}
+
+// Match finds the fastest traversal of the segment in the track. A track
+// without times returns the traversal that follows the segment best. If
+// nothing matches, the Reason of the returned match says how close it got.
+func (m Matcher) Match(track Track, seg Segment) Match {
+	best := Match{SegmentID: seg.ID, Reason: ReasonNoTrack}
+	points := track.Points
+	if len(points) < 2 || len(seg.Line) < 2 {
+		return best
+	}
+	best.Reason = ReasonNeverNearStart
+
+	start, end := seg.Line[0], seg.Line[len(seg.Line)-1]
+	// A traversal longer than this went somewhere else in between.
+	maxLength := geo.Length(seg.Line)*1.5 + 2*m.Tolerance
+
+	for _, s := range m.passes(points, start) {
+		if best.Reason == ReasonNeverNearStart {
+			best.Reason = ReasonNeverReachedEnd
+		}
+
+		e, length := -1, 0.0
+		for i := s; i < len(points)-1; i++ {
+			length += geo.Distance(points[i], points[i+1])
+			if length > maxLength {
+				break
+			}
+			if geo.DistanceToSegment(end, points[i], points[i+1]) <= m.Tolerance {
+				e = m.closest(points, end, i)
+				break
+			}
+		}
+		if e <= s {
+			continue
+		}
+
+		candidate := Match{
+			SegmentID:  seg.ID,
+			Reason:     ReasonOffCourse,
+			StartIndex: s,
+			EndIndex:   e,
+			Coverage:   geo.Overlap(seg.Line, points[s:e+1], m.Tolerance),
+			Distance:   geo.Length(points[s : e+1]),
+		}
+		if len(track.Times) == len(points) {
+			candidate.ElapsedTime = track.Times[e] - track.Times[s]
+		}
+		if candidate.Coverage >= m.MinCoverage {
+			candidate.Reason = ReasonMatched
+		}
+
+		if better(candidate, best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// better is true if a beats b. Matches beat near misses, faster matches beat
+// slower ones, and the rest is decided by how close they got.
+func better(a, b Match) bool {
+	if rank(a.Reason) != rank(b.Reason) {
+		return rank(a.Reason) > rank(b.Reason)
+	}
+	if a.Matched() && a.ElapsedTime > 0 && b.ElapsedTime > 0 {
+		return a.ElapsedTime < b.ElapsedTime
+	}
+	return a.Coverage > b.Coverage
+}
+
+func rank(r Reason) int {
+	switch r {
+	case ReasonMatched:
+		return 4
+	case ReasonOffCourse:
+		return 3
+	case ReasonNeverReachedEnd:
+		return 2
+	case ReasonNeverNearStart:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// passes returns the index closest to p of every separate time the track
+// passes within the tolerance of p.
+func (m Matcher) passes(points []geo.Point, p geo.Point) []int {
+	var found []int
+	for i := 0; i < len(points)-1; i++ {
+		if geo.DistanceToSegment(p, points[i], points[i+1]) > m.Tolerance {
+			continue
+		}
+		found = append(found, m.closest(points, p, i))
+		// Skip the rest of this pass.
+		for i < len(points)-1 && geo.DistanceToSegment(p, points[i], points[i+1]) <= m.Tolerance {
+			i++
+		}
+	}
+	return found
+}
+
+// closest returns the index of the point nearest to p, in the run of points
+// within the tolerance of p that starts at from.
+func (m Matcher) closest(points []geo.Point, p geo.Point, from int) int {
+	best, bestDistance := from, math.Inf(1)
+	for i := from; i < len(points); i++ {
+		d := geo.Distance(points[i], p)
+		if d > m.Tolerance && i > from+1 {
+			break
+		}
+		if d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
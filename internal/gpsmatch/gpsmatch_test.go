@@ -0,0 +1,159 @@
+package gpsmatch_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/internal/gpsmatch"
+	"github.com/Emyrk/strava/lib/geo"
+	"github.com/Emyrk/strava/strava"
+)
+
+func loadLine(t *testing.T, name string) []geo.Point {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "strava", "testdata", name))
+	require.NoError(t, err)
+	var f struct {
+		Map struct {
+			Polyline string `json:"polyline"`
+		} `json:"map"`
+	}
+	require.NoError(t, json.Unmarshal(data, &f))
+	line, err := geo.DecodePolyline(f.Map.Polyline)
+	require.NoError(t, err)
+	return line
+}
+
+// ride samples the line every 5 meters, one sample a second, starting at
+// offset seconds.
+func ride(line []geo.Point, offset float64) gpsmatch.Track {
+	var track gpsmatch.Track
+	for i := 0; i < len(line)-1; i++ {
+		a, b := line[i], line[i+1]
+		steps := max(1, int(geo.Distance(a, b)/5))
+		for j := 0; j < steps; j++ {
+			f := float64(j) / float64(steps)
+			track.Points = append(track.Points, geo.Point{
+				Lat: a.Lat + (b.Lat-a.Lat)*f,
+				Lng: a.Lng + (b.Lng-a.Lng)*f,
+			})
+			track.Times = append(track.Times, offset+float64(len(track.Times)))
+		}
+	}
+	track.Points = append(track.Points, line[len(line)-1])
+	track.Times = append(track.Times, offset+float64(len(track.Times)))
+	return track
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	matcher := gpsmatch.New()
+	segment := gpsmatch.Segment{ID: 1, Line: loadLine(t, "segment.json")}
+
+	t.Run("Polyline", func(t *testing.T) {
+		t.Parallel()
+
+		// The route goes over the segment, but has no times.
+		m := matcher.Match(gpsmatch.Track{Points: loadLine(t, "route.json")}, segment)
+		require.True(t, m.Matched(), m.Explain(matcher.Tolerance))
+		require.Greater(t, m.Coverage, 0.95)
+		require.Zero(t, m.ElapsedTime)
+		require.InDelta(t, geo.Length(segment.Line), m.Distance, geo.Length(segment.Line)*0.1)
+	})
+
+	t.Run("Fastest", func(t *testing.T) {
+		t.Parallel()
+
+		// Ridden twice, the second time twice as fast.
+		first := ride(segment.Line, 0)
+		fast := ride(segment.Line, 0)
+		track := gpsmatch.Track{Points: first.Points, Times: first.Times}
+		last := first.Times[len(first.Times)-1]
+		for i := 0; i < len(fast.Points); i += 2 {
+			track.Points = append(track.Points, fast.Points[i])
+			track.Times = append(track.Times, last+600+float64(i/2))
+		}
+
+		m := matcher.Match(track, segment)
+		require.True(t, m.Matched())
+		require.Greater(t, m.StartIndex, len(first.Points)-1)
+		require.InDelta(t, last/2, m.ElapsedTime, 2)
+	})
+
+	t.Run("NeverNearStart", func(t *testing.T) {
+		t.Parallel()
+
+		m := matcher.Match(gpsmatch.Track{Points: loadLine(t, "activity.json")}, segment)
+		require.Equal(t, gpsmatch.ReasonNeverNearStart, m.Reason)
+		require.False(t, m.Matched())
+	})
+
+	t.Run("NeverReachedEnd", func(t *testing.T) {
+		t.Parallel()
+
+		half := ride(segment.Line[:len(segment.Line)/2], 0)
+		m := matcher.Match(half, segment)
+		require.Equal(t, gpsmatch.ReasonNeverReachedEnd, m.Reason)
+	})
+
+	t.Run("OffCourse", func(t *testing.T) {
+		t.Parallel()
+
+		// A straight line from start to end skips the switchbacks.
+		start, end := segment.Line[0], segment.Line[len(segment.Line)-1]
+		m := matcher.Match(ride([]geo.Point{start, end}, 0), segment)
+		require.Equal(t, gpsmatch.ReasonOffCourse, m.Reason)
+		require.Less(t, m.Coverage, matcher.MinCoverage)
+	})
+
+	t.Run("NoTrack", func(t *testing.T) {
+		t.Parallel()
+
+		m := matcher.Match(gpsmatch.Track{}, segment)
+		require.Equal(t, gpsmatch.ReasonNoTrack, m.Reason)
+	})
+}
+
+func TestTrackFromStreams(t *testing.T) {
+	t.Parallel()
+
+	track, err := gpsmatch.TrackFromStreams(strava.StreamSet{
+		Time:   &strava.Stream[int]{Data: []int{0, 1, 2}},
+		LatLng: &strava.Stream[[2]float64]{Data: [][2]float64{{30.1, -97.1}, {0, 0}, {30.2, -97.2}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []geo.Point{{Lat: 30.1, Lng: -97.1}, {Lat: 30.2, Lng: -97.2}}, track.Points)
+	require.Equal(t, []float64{0, 2}, track.Times)
+
+	_, err = gpsmatch.TrackFromStreams(strava.StreamSet{})
+	require.Error(t, err)
+	_, err = gpsmatch.TrackFromStreams(strava.StreamSet{
+		Time:   &strava.Stream[int]{Data: []int{0}},
+		LatLng: &strava.Stream[[2]float64]{Data: [][2]float64{{30.1, -97.1}, {30.2, -97.2}}},
+	})
+	require.Error(t, err)
+}
+
+func TestCombine(t *testing.T) {
+	t.Parallel()
+
+	route := []int64{1, 2, 3}
+	official := map[int64]float64{1: 100, 3: 300}
+
+	res, ok := gpsmatch.Combine(route, official, map[int64]float64{2: 200, 3: 250})
+	require.True(t, ok)
+	require.Equal(t, 600.0, res.TotalTime)
+	require.Equal(t, []int64{2}, res.GPSSegments)
+
+	_, ok = gpsmatch.Combine(route, official, nil)
+	require.False(t, ok, "still missing a segment")
+
+	_, ok = gpsmatch.Combine(route, map[int64]float64{1: 1, 2: 2, 3: 3}, nil)
+	require.False(t, ok, "official results are not unofficial")
+}
@@ -0,0 +1,37 @@
+package gpsmatch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// LoadSegments returns the geometry of the segments, in the order given.
+// Segments without a stored map have no line and never match.
+func LoadSegments(ctx context.Context, db database.Store, ids []int64) ([]Segment, error) {
+	rows, err := db.GetSegments(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get segments: %w", err)
+	}
+
+	lines := make(map[int64][]geo.Point, len(rows))
+	for _, row := range rows {
+		encoded := row.Map.Polyline
+		if encoded == "" {
+			encoded = row.Map.SummaryPolyline
+		}
+		line, err := geo.DecodePolyline(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode segment %d: %w", row.Segment.ID, err)
+		}
+		lines[row.Segment.ID] = line
+	}
+
+	segs := make([]Segment, 0, len(ids))
+	for _, id := range ids {
+		segs = append(segs, Segment{ID: id, Line: lines[id]})
+	}
+	return segs, nil
+}
@@ -0,0 +1,29 @@
+package gpsmatch
+
+// Result is a route result that only finishes with GPS matched efforts.
+type Result struct {
+	// TotalTime in seconds, the sum of the best effort on every segment.
+	TotalTime float64
+	// GPSSegments are the segments that come from GPS matches.
+	GPSSegments []int64
+}
+
+// Combine fills the segments Strava missed with GPS matches. Both maps are
+// elapsed times keyed by segment, official times win over matches. The bool
+// is false if the route is still not finished, or finished without a match.
+func Combine(route []int64, official, matched map[int64]float64) (Result, bool) {
+	var res Result
+	for _, id := range route {
+		if elapsed, ok := official[id]; ok {
+			res.TotalTime += elapsed
+			continue
+		}
+		elapsed, ok := matched[id]
+		if !ok {
+			return Result{}, false
+		}
+		res.TotalTime += elapsed
+		res.GPSSegments = append(res.GPSSegments, id)
+	}
+	return res, len(res.GPSSegments) > 0
+}
@@ -0,0 +1,210 @@
+// Package grouprides finds the results of an edition that were ridden
+// together. Two results are linked when their rides overlap and they started
+// the shared climbs at nearly the same moments, and a group is everyone
+// linked to each other through a chain of such pairs. Strava's own count of
+// the athletes on an activity is used as a cross-check.
+//
+// Detection is incremental: groups found before are kept, and only the new
+// results are compared against the field.
+package grouprides
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Emyrk/strava/database"
+)
+
+const (
+	// StartTolerance is how far apart two riders can start a climb and still
+	// be riding together.
+	StartTolerance = 90 * time.Second
+	// MinSharedClimbs is how many climbs two results must both have.
+	MinSharedClimbs = 2
+	// MatchFraction is the share of the shared climbs that must start within
+	// the tolerance. A group splits on the steep climbs and waits at the top,
+	// so not every climb has to match.
+	MatchFraction = 0.75
+)
+
+// Result is one result of the edition.
+type Result struct {
+	ActivityID int64
+	AthleteID  int64
+	Start      time.Time
+	End        time.Time
+	// AthleteCount is Strava's count of the athletes on the activity,
+	// including the rider. Zero when unknown.
+	AthleteCount int32
+	// Climbs are the effort start times by segment.
+	Climbs map[int64]time.Time
+}
+
+// FromRows converts the leaderboard, with the athlete counts by activity.
+func FromRows(rows []database.HugelLeaderboardRow, athleteCounts map[int64]int32) []Result {
+	results := make([]Result, 0, len(rows))
+	for _, row := range rows {
+		start := row.StartDate.Time
+		r := Result{
+			ActivityID:   row.ActivityID,
+			AthleteID:    row.AthleteID,
+			Start:        start,
+			End:          start.Add(time.Duration(row.ElapsedTime) * time.Second),
+			AthleteCount: athleteCounts[row.ActivityID],
+			Climbs:       make(map[int64]time.Time, len(row.Efforts)),
+		}
+		for _, e := range row.Efforts {
+			r.Climbs[int64(e.SegmentID)] = e.StartDate
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// State is what is kept between detections.
+type State struct {
+	// Groups are the activity ids of each group, ordered.
+	Groups [][]int64
+	// Seen are the activity ids already compared against the field.
+	Seen []int64
+}
+
+// Together reports if the two results were ridden together.
+func Together(a, b Result) bool {
+	if a.AthleteID == b.AthleteID {
+		return false
+	}
+	if !a.Start.Before(b.End) || !b.Start.Before(a.End) {
+		return false
+	}
+
+	var shared, matched int
+	for segment, aStart := range a.Climbs {
+		bStart, ok := b.Climbs[segment]
+		if !ok {
+			continue
+		}
+		shared++
+		if gap := aStart.Sub(bStart).Abs(); gap <= StartTolerance {
+			matched++
+		}
+	}
+	if shared < MinSharedClimbs {
+		return false
+	}
+
+	// Strava saw both riders alone. It misses groups with private or
+	// unsynced riders, so that is not a veto, but the timing has to agree
+	// on every climb.
+	if a.AthleteCount == 1 && b.AthleteCount == 1 {
+		return matched == shared
+	}
+	return float64(matched)/float64(shared) >= MatchFraction
+}
+
+// Detect groups the results. The groups of the previous state are kept for
+// the results still in the field, and only results not seen before are
+// compared. An empty previous state detects from scratch.
+func Detect(results []Result, previous State) State {
+	index := make(map[int64]int, len(results))
+	for i, r := range results {
+		index[r.ActivityID] = i
+	}
+	seen := make(map[int64]bool, len(previous.Seen))
+	for _, id := range previous.Seen {
+		seen[id] = true
+	}
+
+	parent := make([]int, len(results))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		parent[find(i)] = find(j)
+	}
+
+	// Results that have left the field, deleted or opted out, drop out of
+	// their groups. A group they held together stays together until the
+	// next detection from scratch.
+	for _, group := range previous.Groups {
+		first := -1
+		for _, id := range group {
+			i, ok := index[id]
+			if !ok {
+				continue
+			}
+			if first < 0 {
+				first = i
+				continue
+			}
+			union(first, i)
+		}
+	}
+
+	byStart := make([]int, len(results))
+	for i := range byStart {
+		byStart[i] = i
+	}
+	sort.Slice(byStart, func(i, j int) bool {
+		return results[byStart[i]].Start.Before(results[byStart[j]].Start)
+	})
+	for x, i := range byStart {
+		for _, j := range byStart[x+1:] {
+			// Sorted by start, nothing later can overlap.
+			if !results[j].Start.Before(results[i].End) {
+				break
+			}
+			if seen[results[i].ActivityID] && seen[results[j].ActivityID] {
+				continue
+			}
+			if Together(results[i], results[j]) {
+				union(i, j)
+			}
+		}
+	}
+
+	members := make(map[int][]int64)
+	state := State{Groups: [][]int64{}, Seen: make([]int64, 0, len(results))}
+	for i, r := range results {
+		root := find(i)
+		members[root] = append(members[root], r.ActivityID)
+		state.Seen = append(state.Seen, r.ActivityID)
+	}
+	for _, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		slices.Sort(group)
+		state.Groups = append(state.Groups, group)
+	}
+	sort.Slice(state.Groups, func(i, j int) bool {
+		return state.Groups[i][0] < state.Groups[j][0]
+	})
+	slices.Sort(state.Seen)
+	return state
+}
+
+// RodeWith is the other activities in the group of each activity.
+func (s State) RodeWith() map[int64][]int64 {
+	with := make(map[int64][]int64)
+	for _, group := range s.Groups {
+		for _, id := range group {
+			others := make([]int64, 0, len(group)-1)
+			for _, other := range group {
+				if other != id {
+					others = append(others, other)
+				}
+			}
+			with[id] = others
+		}
+	}
+	return with
+}
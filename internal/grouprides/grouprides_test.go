@@ -0,0 +1,117 @@
+package grouprides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Emyrk/strava/internal/grouprides"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, time.November, 9, 8, 0, 0, 0, time.UTC)
+
+// ride starts a result at the offset in minutes, with a climb every 20
+// minutes shifted by the seconds given.
+func ride(id int64, offset int, climbs ...int) grouprides.Result {
+	r := grouprides.Result{
+		ActivityID: id,
+		AthleteID:  id * 10,
+		Start:      start.Add(time.Duration(offset) * time.Minute),
+		Climbs:     make(map[int64]time.Time, len(climbs)),
+	}
+	r.End = r.Start.Add(3 * time.Hour)
+	for i, shift := range climbs {
+		at := r.Start.Add(time.Duration(20*(i+1))*time.Minute + time.Duration(shift)*time.Second)
+		r.Climbs[int64(i+1)] = at
+	}
+	return r
+}
+
+func TestTogether(t *testing.T) {
+	t.Parallel()
+
+	a := ride(1, 0, 0, 0, 0, 0)
+
+	require.True(t, grouprides.Together(a, ride(2, 0, 30, -30, 60, 0)))
+	// Split up on one climb out of four.
+	require.True(t, grouprides.Together(a, ride(2, 0, 0, 0, 600, 0)))
+	// Split up on two.
+	require.False(t, grouprides.Together(a, ride(2, 0, 0, 0, 600, 600)))
+	// Same route, an hour apart.
+	require.False(t, grouprides.Together(a, ride(2, 60, 0, 0, 0, 0)))
+	// Only one climb to compare.
+	require.False(t, grouprides.Together(a, ride(2, 0, 0)))
+
+	same := ride(2, 0, 0, 0, 0, 0)
+	same.AthleteID = a.AthleteID
+	require.False(t, grouprides.Together(a, same), "an athlete does not ride with themselves")
+
+	t.Run("AthleteCount", func(t *testing.T) {
+		t.Parallel()
+
+		a := ride(1, 0, 0, 0, 0, 0)
+		b := ride(2, 0, 0, 0, 0, 600)
+		require.True(t, grouprides.Together(a, b))
+
+		// Strava saw both alone, every climb has to match.
+		a.AthleteCount, b.AthleteCount = 1, 1
+		require.False(t, grouprides.Together(a, b))
+		b.Climbs[4] = a.Climbs[4]
+		require.True(t, grouprides.Together(a, b))
+	})
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	results := []grouprides.Result{
+		ride(1, 0, 0, 0, 0, 0),
+		ride(2, 0, 60, 60, 60, 60),
+		// Chained to 1 through 2.
+		ride(3, 0, 120, 120, 120, 120),
+		ride(4, 90, 0, 0, 0, 0),
+		ride(5, 90, 10, 10, 10, 10),
+		// Alone.
+		ride(6, 45, 0, 0, 0, 0),
+	}
+
+	state := grouprides.Detect(results, grouprides.State{})
+	require.Equal(t, [][]int64{{1, 2, 3}, {4, 5}}, state.Groups)
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6}, state.Seen)
+	require.Equal(t, map[int64][]int64{
+		1: {2, 3}, 2: {1, 3}, 3: {1, 2},
+		4: {5}, 5: {4},
+	}, state.RodeWith())
+
+	t.Run("Incremental", func(t *testing.T) {
+		t.Parallel()
+
+		// The previous groups are trusted, seen results are not compared.
+		previous := grouprides.State{
+			Groups: [][]int64{{1, 6}},
+			Seen:   []int64{1, 2, 3, 6},
+		}
+		state := grouprides.Detect(results, previous)
+		require.Equal(t, [][]int64{{1, 6}, {4, 5}}, state.Groups)
+		require.Equal(t, []int64{1, 2, 3, 4, 5, 6}, state.Seen)
+
+		// New results are compared with everyone, seen or not.
+		state = grouprides.Detect(append(results, ride(7, 0, 170, 170, 170, 170)), state)
+		require.Equal(t, [][]int64{{1, 6}, {3, 7}, {4, 5}}, state.Groups)
+
+		// And join groups through any member.
+		state = grouprides.Detect(append(results, ride(7, 0, 170, 170, 170, 170), ride(8, 0, 30, 30, 30, 30)), state)
+		require.Equal(t, [][]int64{{1, 2, 3, 6, 7, 8}, {4, 5}}, state.Groups)
+	})
+
+	t.Run("LeftTheField", func(t *testing.T) {
+		t.Parallel()
+
+		state := grouprides.Detect(results[3:], grouprides.State{
+			Groups: [][]int64{{1, 2, 3}, {4, 5}},
+			Seen:   []int64{1, 2, 3, 4, 5, 6},
+		})
+		require.Equal(t, [][]int64{{4, 5}}, state.Groups)
+		require.Equal(t, []int64{4, 5, 6}, state.Seen)
+	})
+}
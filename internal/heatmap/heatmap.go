@@ -0,0 +1,149 @@
+// Package heatmap rasterizes ride tracks into a density grid, how many rides
+// passed through each cell.
+package heatmap
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+const (
+	// DefaultResolution is the number of cells along the longer side of the
+	// grid. An event area of ~100 km gets cells of ~200 m.
+	DefaultResolution = 512
+	// padding keeps tracks on the edge of the box off the edge of the image.
+	padding = 250
+)
+
+// Grid counts the tracks through each cell of a lat/lng box. Cells are row by
+// row, starting in the north west corner, the same as an image.
+type Grid struct {
+	BBox   geo.BBox
+	Width  int
+	Height int
+	Cells  []int32
+	// Tracks is how many tracks were added.
+	Tracks int
+}
+
+// NewGrid covers the box with cells that are close to square on the ground.
+// The longer side of the box gets resolution cells.
+func NewGrid(bbox geo.BBox, resolution int) *Grid {
+	center := bbox.Center()
+	wide := geo.Distance(geo.Point{Lat: center.Lat, Lng: bbox.Min.Lng}, geo.Point{Lat: center.Lat, Lng: bbox.Max.Lng})
+	tall := geo.Distance(geo.Point{Lat: bbox.Min.Lat, Lng: center.Lng}, geo.Point{Lat: bbox.Max.Lat, Lng: center.Lng})
+
+	width, height := resolution, resolution
+	if wide > tall {
+		height = max(1, int(math.Round(float64(resolution)*tall/wide)))
+	} else if tall > 0 {
+		width = max(1, int(math.Round(float64(resolution)*wide/tall)))
+	}
+
+	return &Grid{
+		BBox:   bbox,
+		Width:  width,
+		Height: height,
+		Cells:  make([]int32, width*height),
+	}
+}
+
+// Build rasterizes the tracks into a grid around all of them.
+func Build(tracks [][]geo.Point, resolution int) *Grid {
+	var all []geo.Point
+	for _, t := range tracks {
+		all = append(all, t...)
+	}
+	g := NewGrid(geo.Bounds(all).Pad(padding), resolution)
+	for _, t := range tracks {
+		g.Add(t)
+	}
+	return g
+}
+
+// Add rasterizes a track. A track counts once per cell, even if it passes the
+// same cell more than once.
+func (g *Grid) Add(track []geo.Point) {
+	if len(track) == 0 {
+		return
+	}
+	g.Tracks++
+
+	seen := make(map[int]bool)
+	mark := func(x, y float64) {
+		cx, cy := int(x), int(y)
+		if cx < 0 || cy < 0 || cx >= g.Width || cy >= g.Height {
+			return
+		}
+		i := cy*g.Width + cx
+		if seen[i] {
+			return
+		}
+		seen[i] = true
+		g.Cells[i]++
+	}
+
+	px, py := g.position(track[0])
+	mark(px, py)
+	for _, p := range track[1:] {
+		x, y := g.position(p)
+		// Step through every cell between the two points, points in a
+		// summary track are often a few cells apart.
+		steps := int(math.Ceil(math.Max(math.Abs(x-px), math.Abs(y-py))))
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			mark(px+(x-px)*t, py+(y-py)*t)
+		}
+		px, py = x, y
+	}
+}
+
+// position is the point in cell units, from the north west corner.
+func (g *Grid) position(p geo.Point) (x, y float64) {
+	x = (p.Lng - g.BBox.Min.Lng) / (g.BBox.Max.Lng - g.BBox.Min.Lng) * float64(g.Width)
+	y = (g.BBox.Max.Lat - p.Lat) / (g.BBox.Max.Lat - g.BBox.Min.Lat) * float64(g.Height)
+	return x, y
+}
+
+// Max is the count of the busiest cell.
+func (g *Grid) Max() int32 {
+	var m int32
+	for _, c := range g.Cells {
+		m = max(m, c)
+	}
+	return m
+}
+
+// Image renders the grid as an overlay, one pixel per cell. Empty cells are
+// transparent, busy cells go from a faint red to an opaque yellow. The scale
+// is logarithmic, or the few roads everyone rides drown out the rest.
+func (g *Grid) Image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, g.Width, g.Height))
+	top := math.Log1p(float64(g.Max()))
+	if top == 0 {
+		return img
+	}
+	for i, c := range g.Cells {
+		if c == 0 {
+			continue
+		}
+		t := math.Log1p(float64(c)) / top
+		img.SetNRGBA(i%g.Width, i/g.Width, color.NRGBA{
+			R: 255,
+			G: uint8(255 * t),
+			B: 0,
+			A: uint8(96 + 159*t),
+		})
+	}
+	return img
+}
+
+// PNG writes the overlay image.
+func (g *Grid) PNG(w io.Writer) error {
+	return png.Encode(w, g.Image())
+}
@@ -0,0 +1,101 @@
+package heatmap_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/heatmap"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+func TestNewGrid(t *testing.T) {
+	t.Parallel()
+
+	// Twice as wide as tall on the ground, near the equator.
+	g := heatmap.NewGrid(geo.BBox{
+		Min: geo.Point{Lat: 0, Lng: 0},
+		Max: geo.Point{Lat: 0.1, Lng: 0.2},
+	}, 100)
+	require.Equal(t, 100, g.Width)
+	require.Equal(t, 50, g.Height)
+	require.Len(t, g.Cells, 100*50)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	g := heatmap.NewGrid(geo.BBox{
+		Min: geo.Point{Lat: 0, Lng: 0},
+		Max: geo.Point{Lat: 0.1, Lng: 0.1},
+	}, 10)
+
+	// West to east along the middle, with no points in between. Every cell
+	// of the row is filled in.
+	west := geo.Point{Lat: 0.055, Lng: 0.001}
+	east := geo.Point{Lat: 0.055, Lng: 0.099}
+	g.Add([]geo.Point{west, east})
+	for x := 0; x < 10; x++ {
+		require.EqualValues(t, 1, g.Cells[4*g.Width+x], "cell %d", x)
+	}
+
+	// An out and back counts once per cell.
+	g.Add([]geo.Point{west, east, west})
+	require.EqualValues(t, 2, g.Max())
+	require.Equal(t, 2, g.Tracks)
+
+	// The north west corner is the first cell.
+	g.Add([]geo.Point{{Lat: 0.099, Lng: 0.001}})
+	require.EqualValues(t, 1, g.Cells[0])
+}
+
+func TestPNG(t *testing.T) {
+	t.Parallel()
+
+	g := heatmap.Build([][]geo.Point{
+		{{Lat: 30.325, Lng: -97.80}, {Lat: 30.325, Lng: -97.75}},
+		{{Lat: 30.30, Lng: -97.775}, {Lat: 30.35, Lng: -97.775}},
+	}, 64)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.PNG(&buf))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, g.Width, img.Bounds().Dx())
+	require.Equal(t, g.Height, img.Bounds().Dy())
+	require.EqualValues(t, 2, g.Max(), "tracks cross in the middle")
+
+	// Empty cells are transparent.
+	_, _, _, a := img.At(0, 0).RGBA()
+	require.Zero(t, a)
+}
+
+// heatmapStore has two results, one with a track that does not decode.
+type heatmapStore struct {
+	database.Store
+}
+
+func (heatmapStore) YearlyHugelLeaderboard(context.Context, database.YearlyHugelLeaderboardParams) ([]database.HugelLeaderboardRow, error) {
+	return []database.HugelLeaderboardRow{{ActivityID: 1}, {ActivityID: 2}}, nil
+}
+
+func (heatmapStore) HeatmapPolylines(context.Context, []int64) ([]database.HeatmapPolylinesRow, error) {
+	return []database.HeatmapPolylinesRow{
+		{ActivityID: 1, SummaryPolyline: geo.EncodePolyline([]geo.Point{{Lat: 30.25, Lng: -97.75}, {Lat: 30.26, Lng: -97.74}})},
+		{ActivityID: 2, SummaryPolyline: "_p~iF~ps|U_ulL"},
+	}, nil
+}
+
+func TestEditionSkipsBrokenPolylines(t *testing.T) {
+	t.Parallel()
+
+	grid, skipped, err := heatmap.Edition(context.Background(), heatmapStore{}, hugeldate.Editions[0], heatmap.DefaultResolution)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2}, skipped)
+	require.Equal(t, 1, grid.Tracks)
+}
@@ -0,0 +1,74 @@
+package heatmap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/export"
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// Edition rasterizes the results of an edition. Private activities and
+// athletes who opted out are left out. Activities with a polyline that does
+// not decode are skipped and returned, so one bad track does not hold back
+// the rest.
+func Edition(ctx context.Context, db database.Store, edition hugeldate.Edition, resolution int) (*Grid, []int64, error) {
+	rows, err := export.Leaderboard(ctx, db, edition)
+	if err != nil {
+		return nil, nil, fmt.Errorf("leaderboard: %w", err)
+	}
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ActivityID)
+	}
+
+	polylines, err := db.HeatmapPolylines(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("polylines: %w", err)
+	}
+
+	var skipped []int64
+	tracks := make([][]geo.Point, 0, len(polylines))
+	for _, p := range polylines {
+		track, err := geo.DecodePolyline(p.SummaryPolyline)
+		if err != nil {
+			skipped = append(skipped, p.ActivityID)
+			continue
+		}
+		if len(track) > 0 {
+			tracks = append(tracks, track)
+		}
+	}
+	return Build(tracks, resolution), skipped, nil
+}
+
+// FromRow is the grid stored for an edition.
+func FromRow(row database.EventHeatmap) *Grid {
+	return &Grid{
+		BBox: geo.BBox{
+			Min: geo.Point{Lat: row.MinLat, Lng: row.MinLng},
+			Max: geo.Point{Lat: row.MaxLat, Lng: row.MaxLng},
+		},
+		Width:  int(row.Width),
+		Height: int(row.Height),
+		Cells:  row.Cells,
+		Tracks: int(row.Activities),
+	}
+}
+
+// Params stores the grid for an edition.
+func (g *Grid) Params(routeName string) database.UpsertEventHeatmapParams {
+	return database.UpsertEventHeatmapParams{
+		RouteName:  routeName,
+		MinLat:     g.BBox.Min.Lat,
+		MinLng:     g.BBox.Min.Lng,
+		MaxLat:     g.BBox.Max.Lat,
+		MaxLng:     g.BBox.Max.Lng,
+		Width:      int32(g.Width),
+		Height:     int32(g.Height),
+		Cells:      g.Cells,
+		Activities: int32(g.Tracks),
+	}
+}
@@ -1,7 +1,9 @@
 package hugeldate
 
 import (
+	"fmt"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -10,11 +12,23 @@ var Year2023 Dates
 var Year2024 Dates
 var Year2025 Dates
 
+// Editions is every scored running of a competitive route, oldest first.
+var Editions []Edition
+
 type Dates struct {
 	Start time.Time
 	End   time.Time
 }
 
+// Edition is a single year of a competitive route. RouteName matches the
+// `competitive_routes.name` the edition's results view is built from.
+type Edition struct {
+	Year      int
+	Lite      bool
+	RouteName string
+	Dates     Dates
+}
+
 func init() {
 	var err error
 	CentralTimeZone, err = time.LoadLocation("US/Central")
@@ -40,4 +54,52 @@ func init() {
 		Start: start2025,
 		End:   start2025.Add(time.Hour * 24 * 3),
 	}
+
+	Editions = []Edition{
+		{Year: 2023, RouteName: "das-hugel-2023", Dates: Year2023},
+		{Year: 2024, RouteName: "das-hugel-2024", Dates: Year2024},
+		{Year: 2024, Lite: true, RouteName: "lite-das-hugel-2024", Dates: Year2024},
+		{Year: 2025, RouteName: "das-hugel", Dates: Year2025},
+		{Year: 2025, Lite: true, RouteName: "lite-das-hugel", Dates: Year2025},
+	}
+}
+
+// Title is the name of the edition shown to riders, e.g. "Tour Das Hugel Lite 2024".
+func (e Edition) Title() string {
+	if e.Lite {
+		return fmt.Sprintf("Tour Das Hugel Lite %d", e.Year)
+	}
+	return fmt.Sprintf("Tour Das Hugel %d", e.Year)
+}
+
+// EditionByRoute returns the edition scored against the given competitive route.
+func EditionByRoute(routeName string) (Edition, bool) {
+	for _, e := range Editions {
+		if e.RouteName == routeName {
+			return e, true
+		}
+	}
+	return Edition{}, false
+}
+
+// EditionByYear returns the edition of the full or lite route for a year.
+func EditionByYear(year int, lite bool) (Edition, bool) {
+	for _, e := range Editions {
+		if e.Year == year && e.Lite == lite {
+			return e, true
+		}
+	}
+	return Edition{}, false
+}
+
+// RouteEditions returns every edition of a route family. "das-hugel" covers
+// "das-hugel", "das-hugel-2023", and so on, but not the lite route.
+func RouteEditions(routeName string) []Edition {
+	var found []Edition
+	for _, e := range Editions {
+		if e.RouteName == routeName || strings.HasPrefix(e.RouteName, routeName+"-") {
+			found = append(found, e)
+		}
+	}
+	return found
 }
@@ -0,0 +1,27 @@
+package nearby
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// Load indexes every public segment with a known location.
+func Load(ctx context.Context, db database.Store) (*Index, error) {
+	rows, err := db.SegmentLocationIndex(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("segment locations: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, Entry{
+			ID:           row.ID,
+			Start:        geo.Point{Lat: row.StartLat, Lng: row.StartLng},
+			AverageGrade: row.AverageGrade,
+		})
+	}
+	return New(entries), nil
+}
@@ -0,0 +1,98 @@
+// Package nearby finds segments close to a point. Segments are bucketed into
+// a lat/lng grid by where they start, so a search only measures the segments
+// in the few cells around the point.
+package nearby
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+const (
+	// cellDegrees is the size of a grid cell, ~2 km north to south.
+	cellDegrees = 0.02
+	// metersPerDegree is a degree of latitude.
+	metersPerDegree = 111_320.0
+)
+
+// Entry is a segment in the index.
+type Entry struct {
+	ID           int64
+	Start        geo.Point
+	AverageGrade float64
+}
+
+// Hit is a segment found by a search.
+type Hit struct {
+	Entry
+	// Distance is meters from the point to the start of the segment.
+	Distance float64
+}
+
+type cell struct {
+	lat, lng int
+}
+
+// Index is safe for concurrent searches, it is never changed after New.
+type Index struct {
+	cells map[cell][]Entry
+	size  int
+}
+
+// New indexes the entries.
+func New(entries []Entry) *Index {
+	idx := &Index{
+		cells: make(map[cell][]Entry),
+		size:  len(entries),
+	}
+	for _, e := range entries {
+		c := cellOf(e.Start)
+		idx.cells[c] = append(idx.cells[c], e)
+	}
+	return idx
+}
+
+// Len is the number of indexed segments.
+func (idx *Index) Len() int {
+	return idx.size
+}
+
+// Near returns the segments that start within radius meters of the point,
+// closest first.
+func (idx *Index) Near(p geo.Point, radius float64) []Hit {
+	// A degree of longitude shrinks towards the poles, so more cells are
+	// needed east to west than north to south.
+	latCells := int(math.Ceil(radius/metersPerDegree/cellDegrees)) + 1
+	lngScale := math.Max(math.Cos(p.Lat*math.Pi/180), 0.01)
+	lngCells := int(math.Ceil(radius/(metersPerDegree*lngScale)/cellDegrees)) + 1
+
+	center := cellOf(p)
+	var hits []Hit
+	for dLat := -latCells; dLat <= latCells; dLat++ {
+		for dLng := -lngCells; dLng <= lngCells; dLng++ {
+			for _, e := range idx.cells[cell{lat: center.lat + dLat, lng: center.lng + dLng}] {
+				d := geo.Distance(p, e.Start)
+				if d <= radius {
+					hits = append(hits, Hit{Entry: e, Distance: d})
+				}
+			}
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Distance != hits[j].Distance {
+			return hits[i].Distance < hits[j].Distance
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+func cellOf(p geo.Point) cell {
+	return cell{
+		lat: int(math.Floor(p.Lat / cellDegrees)),
+		lng: int(math.Floor(p.Lng / cellDegrees)),
+	}
+}
@@ -0,0 +1,43 @@
+package nearby_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/internal/nearby"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+func TestNear(t *testing.T) {
+	t.Parallel()
+
+	// Around Austin, about 1.1 km per 0.01 degree of latitude.
+	center := geo.Point{Lat: 30.27, Lng: -97.74}
+	idx := nearby.New([]nearby.Entry{
+		{ID: 1, Start: geo.Point{Lat: 30.27, Lng: -97.74}},
+		{ID: 2, Start: geo.Point{Lat: 30.28, Lng: -97.74}},
+		// Across a cell boundary from the center.
+		{ID: 3, Start: geo.Point{Lat: 30.255, Lng: -97.74}},
+		{ID: 4, Start: geo.Point{Lat: 30.40, Lng: -97.74}},
+		{ID: 5, Start: geo.Point{Lat: 30.27, Lng: -97.60}},
+	})
+	require.Equal(t, 5, idx.Len())
+
+	ids := func(hits []nearby.Hit) []int64 {
+		out := make([]int64, 0, len(hits))
+		for _, h := range hits {
+			out = append(out, h.ID)
+		}
+		return out
+	}
+
+	hits := idx.Near(center, 2000)
+	require.Equal(t, []int64{1, 2, 3}, ids(hits))
+	require.Zero(t, hits[0].Distance)
+	require.InDelta(t, 1112, hits[1].Distance, 10)
+
+	require.Equal(t, []int64{1}, ids(idx.Near(center, 500)))
+	require.Equal(t, []int64{1, 2, 3, 5}, ids(idx.Near(center, 14_000)))
+	require.Empty(t, idx.Near(geo.Point{Lat: 0, Lng: 0}, 15_000))
+}
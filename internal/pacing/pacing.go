@@ -0,0 +1,136 @@
+// Package pacing breaks a competitive route result into the time spent on the
+// climbs and the time spent getting between them. Each climb is measured
+// against the median of the field, so a slow climb means slow for that climb,
+// not a long one.
+package pacing
+
+import (
+	"sort"
+
+	"github.com/Emyrk/strava/database"
+)
+
+// Medians are the field's median elapsed seconds by segment.
+type Medians map[int64]float64
+
+// FieldMedians is the median time of every segment over the results.
+func FieldMedians(results []database.HugelSegmentEfforts) Medians {
+	times := make(map[int64][]float64)
+	for _, efforts := range results {
+		for _, e := range efforts {
+			if e.ElapsedTime > 0 {
+				times[int64(e.SegmentID)] = append(times[int64(e.SegmentID)], float64(e.ElapsedTime))
+			}
+		}
+	}
+
+	medians := make(Medians, len(times))
+	for id, ts := range times {
+		sort.Float64s(ts)
+		mid := len(ts) / 2
+		if len(ts)%2 == 1 {
+			medians[id] = ts[mid]
+		} else {
+			medians[id] = (ts[mid-1] + ts[mid]) / 2
+		}
+	}
+	return medians
+}
+
+type Climb struct {
+	SegmentID int64
+	Elapsed   int
+	// Relative is the elapsed time over the field median, 1 is the median
+	// and lower is faster. Zero if the field has no median for the climb.
+	Relative float64
+}
+
+// Transition is the gap between finishing one climb and starting the next.
+type Transition struct {
+	FromSegmentID int64
+	ToSegmentID   int64
+	Seconds       int
+}
+
+type Breakdown struct {
+	// Climbs are in the order they were ridden.
+	Climbs         []Climb
+	Transitions    []Transition
+	ClimbingTime   int
+	TransitionTime int
+	// Fastest and Slowest are the climbs best and worst against the field,
+	// nil without medians.
+	Fastest *Climb
+	Slowest *Climb
+	// FatigueIndex is the average relative time of the second half of the
+	// climbs over the first half. Above 1 the rider faded, below 1 they
+	// finished stronger. Zero with fewer than two climbs with a median.
+	FatigueIndex float64
+}
+
+// Analyze breaks down the efforts of a result against the field.
+func Analyze(efforts database.HugelSegmentEfforts, medians Medians) Breakdown {
+	sorted := make(database.HugelSegmentEfforts, len(efforts))
+	copy(sorted, efforts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartDate.Before(sorted[j].StartDate)
+	})
+
+	b := Breakdown{
+		Climbs:      make([]Climb, 0, len(sorted)),
+		Transitions: make([]Transition, 0, max(len(sorted)-1, 0)),
+	}
+	var relative []float64
+	for i, e := range sorted {
+		climb := Climb{SegmentID: int64(e.SegmentID), Elapsed: e.ElapsedTime}
+		if median := medians[climb.SegmentID]; median > 0 && e.ElapsedTime > 0 {
+			climb.Relative = float64(e.ElapsedTime) / median
+			relative = append(relative, climb.Relative)
+		}
+		b.Climbs = append(b.Climbs, climb)
+		b.ClimbingTime += e.ElapsedTime
+
+		if i > 0 {
+			prev := sorted[i-1]
+			gap := int(e.StartDate.Unix() - (prev.StartDate.Unix() + int64(prev.ElapsedTime)))
+			// Overlapping segments share road, there is no gap to ride.
+			gap = max(gap, 0)
+			b.Transitions = append(b.Transitions, Transition{
+				FromSegmentID: int64(prev.SegmentID),
+				ToSegmentID:   int64(e.SegmentID),
+				Seconds:       gap,
+			})
+			b.TransitionTime += gap
+		}
+	}
+
+	for i := range b.Climbs {
+		c := &b.Climbs[i]
+		if c.Relative == 0 {
+			continue
+		}
+		if b.Fastest == nil || c.Relative < b.Fastest.Relative {
+			b.Fastest = c
+		}
+		if b.Slowest == nil || c.Relative > b.Slowest.Relative {
+			b.Slowest = c
+		}
+	}
+
+	if len(relative) >= 2 {
+		half := len(relative) / 2
+		early := mean(relative[:half])
+		// An odd middle climb counts towards neither half.
+		late := mean(relative[len(relative)-half:])
+		b.FatigueIndex = late / early
+	}
+	return b
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
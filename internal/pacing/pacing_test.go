@@ -0,0 +1,65 @@
+package pacing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/pacing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldMedians(t *testing.T) {
+	t.Parallel()
+
+	medians := pacing.FieldMedians([]database.HugelSegmentEfforts{
+		{{SegmentID: 1, ElapsedTime: 100}, {SegmentID: 2, ElapsedTime: 50}},
+		{{SegmentID: 1, ElapsedTime: 300}, {SegmentID: 2, ElapsedTime: 0}},
+		{{SegmentID: 1, ElapsedTime: 200}, {SegmentID: 2, ElapsedTime: 70}},
+	})
+	require.Equal(t, pacing.Medians{1: 200, 2: 60}, medians)
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.November, 9, 8, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	efforts := database.HugelSegmentEfforts{
+		// Out of order, the breakdown follows the ride.
+		{SegmentID: 3, StartDate: at(1000), ElapsedTime: 150},
+		{SegmentID: 1, StartDate: at(0), ElapsedTime: 90},
+		{SegmentID: 2, StartDate: at(300), ElapsedTime: 100},
+		// Starts before the previous climb finished.
+		{SegmentID: 4, StartDate: at(1100), ElapsedTime: 60},
+	}
+	medians := pacing.Medians{1: 100, 2: 100, 3: 100, 4: 50}
+
+	b := pacing.Analyze(efforts, medians)
+	require.Equal(t, []int64{1, 2, 3, 4}, []int64{b.Climbs[0].SegmentID, b.Climbs[1].SegmentID, b.Climbs[2].SegmentID, b.Climbs[3].SegmentID})
+	require.Equal(t, 400, b.ClimbingTime)
+	require.Equal(t, []pacing.Transition{
+		{FromSegmentID: 1, ToSegmentID: 2, Seconds: 210},
+		{FromSegmentID: 2, ToSegmentID: 3, Seconds: 600},
+		{FromSegmentID: 3, ToSegmentID: 4, Seconds: 0},
+	}, b.Transitions)
+	require.Equal(t, 810, b.TransitionTime)
+
+	require.NotNil(t, b.Fastest)
+	require.Equal(t, int64(1), b.Fastest.SegmentID)
+	require.NotNil(t, b.Slowest)
+	require.Equal(t, int64(3), b.Slowest.SegmentID)
+	// Early (0.9 + 1.0) / 2, late (1.5 + 1.2) / 2.
+	require.InDelta(t, 1.35/0.95, b.FatigueIndex, 1e-9)
+}
+
+func TestAnalyzeNoField(t *testing.T) {
+	t.Parallel()
+
+	b := pacing.Analyze(database.HugelSegmentEfforts{{SegmentID: 1, ElapsedTime: 90}}, nil)
+	require.Nil(t, b.Fastest)
+	require.Nil(t, b.Slowest)
+	require.Zero(t, b.FatigueIndex)
+	require.Empty(t, b.Transitions)
+	require.Equal(t, 90, b.ClimbingTime)
+}
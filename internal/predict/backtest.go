@@ -0,0 +1,137 @@
+package predict
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// Climb is one climb of a past result, with the athlete's efforts on it from
+// before.
+type Climb struct {
+	RouteName  string
+	ActivityID int64
+	SegmentID  int64
+	// Start is when the result was ridden, At when this climb was.
+	Start   time.Time
+	At      time.Time
+	Elapsed float64
+	History []Effort
+}
+
+// Samples pairs every climb with the form the athlete was in, for learning
+// the fatigue ratios.
+func Samples(p Predictor, climbs []Climb) []Sample {
+	samples := make([]Sample, 0, len(climbs))
+	for _, c := range climbs {
+		form, ok := p.Form(c.SegmentID, c.History, c.At)
+		if !ok {
+			continue
+		}
+		samples = append(samples, Sample{
+			SegmentID: c.SegmentID,
+			Elapsed:   c.Elapsed,
+			Baseline:  form.Baseline(),
+		})
+	}
+	return samples
+}
+
+// Cases groups the climbs by result, in the order the results were ridden.
+func Cases(climbs []Climb) []BacktestCase {
+	byActivity := make(map[int64]*BacktestCase)
+	var order []int64
+	for _, c := range climbs {
+		bc, ok := byActivity[c.ActivityID]
+		if !ok {
+			bc = &BacktestCase{At: c.Start, Actual: make(map[int64]float64)}
+			byActivity[c.ActivityID] = bc
+			order = append(order, c.ActivityID)
+		}
+		bc.SegmentIDs = append(bc.SegmentIDs, c.SegmentID)
+		bc.History = append(bc.History, c.History...)
+		bc.Actual[c.SegmentID] = c.Elapsed
+	}
+
+	cases := make([]BacktestCase, 0, len(order))
+	for _, id := range order {
+		cases = append(cases, *byActivity[id])
+	}
+	sort.SliceStable(cases, func(i, j int) bool {
+		return cases[i].At.Before(cases[j].At)
+	})
+	return cases
+}
+
+// BacktestEdition learns the field's fatigue from the editions ridden before
+// `test`, and predicts every result of `test` with it.
+func BacktestEdition(climbs []Climb, test hugeldate.Edition) BacktestResult {
+	var train, results []Climb
+	for _, c := range climbs {
+		switch {
+		case c.RouteName == test.RouteName:
+			results = append(results, c)
+		case c.Start.Before(test.Dates.Start):
+			train = append(train, c)
+		}
+	}
+
+	p := Predictor{}
+	p.Fatigue = FieldFatigue(Samples(p, train))
+	return Backtest(p, Cases(results))
+}
+
+// BacktestCase is a past result, with the history the athlete had before it.
+type BacktestCase struct {
+	// At is when the result was ridden. History from on or after it is ignored.
+	At         time.Time
+	SegmentIDs []int64
+	History    []Effort
+	// Actual is the elapsed time of each segment on the day.
+	Actual map[int64]float64
+}
+
+type BacktestResult struct {
+	// Cases is how many results could be predicted. Results where the
+	// athlete had not ridden every segment before are skipped.
+	Cases int
+	// MeanAbsPctError of the predicted total climbing time.
+	MeanAbsPctError float64
+	// InRange is the fraction of results that finished inside the predicted range.
+	InRange float64
+}
+
+// Backtest predicts every case from the history the athlete had at the time,
+// and scores the predictions against what actually happened.
+func Backtest(p Predictor, cases []BacktestCase) BacktestResult {
+	var res BacktestResult
+	var errSum float64
+	var inRange int
+	for _, c := range cases {
+		pred := p.Route(c.SegmentIDs, c.History, c.At)
+		if !pred.Complete() {
+			continue
+		}
+
+		var actual float64
+		for _, id := range c.SegmentIDs {
+			actual += c.Actual[id]
+		}
+		if actual <= 0 {
+			continue
+		}
+
+		res.Cases++
+		errSum += math.Abs(pred.Predicted-actual) / actual
+		if actual >= pred.Low && actual <= pred.High {
+			inRange++
+		}
+	}
+	if res.Cases > 0 {
+		res.MeanAbsPctError = errSum / float64(res.Cases)
+		res.InRange = float64(inRange) / float64(res.Cases)
+	}
+	return res
+}
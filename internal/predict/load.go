@@ -0,0 +1,44 @@
+package predict
+
+import (
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/hugeldate"
+)
+
+// Climbs reads RouteFatigueSamples. Only results ridden during their edition
+// are kept, the views also score rides of the route from the rest of the year.
+func Climbs(rows []database.RouteFatigueSamplesRow) []Climb {
+	climbs := make([]Climb, 0, len(rows))
+	for _, row := range rows {
+		edition, ok := hugeldate.EditionByRoute(row.RouteName)
+		if !ok {
+			continue
+		}
+		start := row.StartDate.Time
+		if start.Before(edition.Dates.Start) || start.After(edition.Dates.End) {
+			continue
+		}
+
+		history := make([]Effort, 0, len(row.PriorElapsedTimes))
+		for i, elapsed := range row.PriorElapsedTimes {
+			if i >= len(row.PriorStartDates) {
+				break
+			}
+			history = append(history, Effort{
+				SegmentID: row.SegmentID,
+				Elapsed:   elapsed,
+				StartDate: row.PriorStartDates[i].Time,
+			})
+		}
+		climbs = append(climbs, Climb{
+			RouteName:  row.RouteName,
+			ActivityID: row.ActivityID,
+			SegmentID:  row.SegmentID,
+			Start:      start,
+			At:         row.EffortStartDate.Time,
+			Elapsed:    row.ElapsedTime,
+			History:    history,
+		})
+	}
+	return climbs
+}
@@ -0,0 +1,236 @@
+// Package predict estimates how long an athlete will take on the climbs of a
+// competitive route. Estimates start from the athlete's form on each climb, a
+// blend of their PR and their recent hard efforts, and are slowed down by how
+// much the field fades from that same blend on the day of the event.
+package predict
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultHalfLife is how quickly older efforts stop counting. An effort
+	// from DefaultHalfLife ago weighs half as much as one from today.
+	DefaultHalfLife = time.Hour * 24 * 180
+
+	// hardEffort is how close to the PR an effort must be to count as a real
+	// attempt. Cruising up a climb says little about race pace.
+	hardEffort = 1.25
+)
+
+// Effort is a single ride up a segment.
+type Effort struct {
+	SegmentID int64
+	Elapsed   float64
+	StartDate time.Time
+}
+
+type Predictor struct {
+	Fatigue Fatigue
+	// HalfLife defaults to DefaultHalfLife.
+	HalfLife time.Duration
+}
+
+// Form is what an athlete's history says about a climb before a date.
+type Form struct {
+	// PR is the fastest effort.
+	PR float64
+	// Recent is the recency weighted average of the hard efforts.
+	Recent float64
+	// Efforts is how many efforts the form is based on.
+	Efforts int
+}
+
+// Baseline blends the PR and recent form, it is what the fatigue ratios scale.
+func (f Form) Baseline() float64 {
+	return (f.PR + f.Recent) / 2
+}
+
+type SegmentPrediction struct {
+	SegmentID int64
+	Form
+	Predicted float64
+	Low       float64
+	High      float64
+}
+
+type Prediction struct {
+	Segments []SegmentPrediction
+	// Missing are segments the athlete has never ridden, they are not
+	// included in the totals.
+	Missing []int64
+	// Predicted is the total climbing time, the number the route is scored on.
+	Predicted float64
+	Low       float64
+	High      float64
+}
+
+// Complete is true if every segment of the route has a prediction.
+func (p Prediction) Complete() bool {
+	return len(p.Missing) == 0 && len(p.Segments) > 0
+}
+
+// Form reads the athlete's form on a segment from the efforts ridden before
+// `at`.
+func (p Predictor) Form(segmentID int64, efforts []Effort, at time.Time) (Form, bool) {
+	halfLife := p.HalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultHalfLife
+	}
+
+	prior := make([]Effort, 0, len(efforts))
+	for _, e := range efforts {
+		if e.SegmentID == segmentID && e.Elapsed > 0 && e.StartDate.Before(at) {
+			prior = append(prior, e)
+		}
+	}
+	if len(prior) == 0 {
+		return Form{}, false
+	}
+
+	pr := prior[0].Elapsed
+	for _, e := range prior {
+		pr = math.Min(pr, e.Elapsed)
+	}
+
+	var sum, weights float64
+	for _, e := range prior {
+		if e.Elapsed > pr*hardEffort {
+			continue
+		}
+		age := at.Sub(e.StartDate)
+		w := math.Pow(0.5, float64(age)/float64(halfLife))
+		sum += e.Elapsed * w
+		weights += w
+	}
+	return Form{PR: pr, Recent: sum / weights, Efforts: len(prior)}, true
+}
+
+// Segment predicts a single segment from the efforts ridden before `at`.
+func (p Predictor) Segment(segmentID int64, efforts []Effort, at time.Time) (SegmentPrediction, bool) {
+	form, ok := p.Form(segmentID, efforts, at)
+	if !ok {
+		return SegmentPrediction{}, false
+	}
+
+	// The ratios are fitted on the same baseline, so they scale it directly.
+	baseline := form.Baseline()
+	ratio := p.Fatigue.Segment(segmentID)
+	predicted := baseline * ratio.Median
+	return SegmentPrediction{
+		SegmentID: segmentID,
+		Form:      form,
+		Predicted: predicted,
+		Low:       math.Min(baseline*ratio.Low, predicted),
+		High:      math.Max(baseline*ratio.High, predicted),
+	}, true
+}
+
+// Route predicts every segment of a route from the efforts ridden before `at`.
+func (p Predictor) Route(segmentIDs []int64, efforts []Effort, at time.Time) Prediction {
+	var pred Prediction
+	for _, id := range segmentIDs {
+		seg, ok := p.Segment(id, efforts, at)
+		if !ok {
+			pred.Missing = append(pred.Missing, id)
+			continue
+		}
+		pred.Segments = append(pred.Segments, seg)
+		pred.Predicted += seg.Predicted
+		pred.Low += seg.Low
+		pred.High += seg.High
+	}
+	return pred
+}
+
+// Sample is one climb of a past result, next to the athlete's baseline on
+// that climb from before the event.
+type Sample struct {
+	SegmentID int64
+	Elapsed   float64
+	Baseline  float64
+}
+
+// Ratio is how much slower than their baseline riders climb on the day, as
+// the 25th, 50th and 75th percentile of event time / baseline.
+type Ratio struct {
+	Low     float64
+	Median  float64
+	High    float64
+	Samples int
+}
+
+// DefaultRatio is used when there are no past results to learn from.
+var DefaultRatio = Ratio{Low: 1.0, Median: 1.08, High: 1.2}
+
+// minSegmentSamples is how many samples a segment needs before its own ratio
+// is trusted over the whole route's.
+const minSegmentSamples = 10
+
+type Fatigue struct {
+	Overall  Ratio
+	Segments map[int64]Ratio
+}
+
+// Segment returns the fatigue ratio of a segment, falling back to the whole
+// route if the segment has too few samples.
+func (f Fatigue) Segment(segmentID int64) Ratio {
+	if r, ok := f.Segments[segmentID]; ok && r.Samples >= minSegmentSamples {
+		return r
+	}
+	if f.Overall.Samples == 0 {
+		return DefaultRatio
+	}
+	return f.Overall
+}
+
+// FieldFatigue learns the fatigue ratios from past results.
+func FieldFatigue(samples []Sample) Fatigue {
+	var all []float64
+	bySegment := make(map[int64][]float64)
+	for _, s := range samples {
+		if s.Baseline <= 0 {
+			continue
+		}
+		r := s.Elapsed / s.Baseline
+		// Outliers are bad data, or someone stopping halfway up for a photo.
+		if r < 0.5 || r > 3 {
+			continue
+		}
+		all = append(all, r)
+		bySegment[s.SegmentID] = append(bySegment[s.SegmentID], r)
+	}
+
+	f := Fatigue{
+		Overall:  ratio(all),
+		Segments: make(map[int64]Ratio, len(bySegment)),
+	}
+	for id, ratios := range bySegment {
+		f.Segments[id] = ratio(ratios)
+	}
+	return f
+}
+
+func ratio(values []float64) Ratio {
+	if len(values) == 0 {
+		return Ratio{}
+	}
+	sort.Float64s(values)
+	return Ratio{
+		Low:     percentile(values, 0.25),
+		Median:  percentile(values, 0.5),
+		High:    percentile(values, 0.75),
+		Samples: len(values),
+	}
+}
+
+// percentile linearly interpolates between the closest ranks of a sorted slice.
+func percentile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	frac := pos - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
@@ -0,0 +1,204 @@
+package predict_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Emyrk/strava/internal/hugeldate"
+	"github.com/Emyrk/strava/internal/predict"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldFatigue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		f := predict.FieldFatigue(nil)
+		require.Equal(t, predict.DefaultRatio, f.Segment(1))
+	})
+
+	t.Run("Percentiles", func(t *testing.T) {
+		t.Parallel()
+
+		var samples []predict.Sample
+		for i := 0; i <= 10; i++ {
+			samples = append(samples, predict.Sample{SegmentID: 1, Elapsed: 100 + float64(i)*2, Baseline: 100})
+		}
+		// Outliers are dropped
+		samples = append(samples, predict.Sample{SegmentID: 1, Elapsed: 1000, Baseline: 100})
+		samples = append(samples, predict.Sample{SegmentID: 1, Elapsed: 100, Baseline: 0})
+
+		f := predict.FieldFatigue(samples)
+		r := f.Segment(1)
+		require.Equal(t, 11, r.Samples)
+		require.InDelta(t, 1.05, r.Low, 0.0001)
+		require.InDelta(t, 1.10, r.Median, 0.0001)
+		require.InDelta(t, 1.15, r.High, 0.0001)
+	})
+
+	t.Run("FewSamplesUseOverall", func(t *testing.T) {
+		t.Parallel()
+
+		var samples []predict.Sample
+		for i := 0; i < 20; i++ {
+			samples = append(samples, predict.Sample{SegmentID: 1, Elapsed: 110, Baseline: 100})
+		}
+		samples = append(samples, predict.Sample{SegmentID: 2, Elapsed: 200, Baseline: 100})
+
+		f := predict.FieldFatigue(samples)
+		require.Equal(t, f.Overall, f.Segment(2))
+		require.Equal(t, f.Segments[1], f.Segment(1))
+	})
+}
+
+func TestSegment(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, time.November, 9, 0, 0, 0, 0, time.UTC)
+	p := predict.Predictor{
+		Fatigue: predict.Fatigue{Overall: predict.Ratio{Low: 1, Median: 1, High: 1, Samples: 1}},
+	}
+
+	t.Run("NoHistory", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := p.Segment(1, []predict.Effort{
+			{SegmentID: 2, Elapsed: 100, StartDate: at.Add(-time.Hour)},
+			// Efforts after the prediction date are ignored
+			{SegmentID: 1, Elapsed: 100, StartDate: at.Add(time.Hour)},
+		}, at)
+		require.False(t, ok)
+	})
+
+	t.Run("RecencyWeighted", func(t *testing.T) {
+		t.Parallel()
+
+		old := at.Add(-predict.DefaultHalfLife * 4)
+		pred, ok := p.Segment(1, []predict.Effort{
+			{SegmentID: 1, Elapsed: 100, StartDate: old},
+			{SegmentID: 1, Elapsed: 120, StartDate: at.Add(-time.Hour * 24)},
+			// Easy rides are not counted towards recent form
+			{SegmentID: 1, Elapsed: 200, StartDate: at.Add(-time.Hour * 24)},
+		}, at)
+		require.True(t, ok)
+		require.Equal(t, 3, pred.Efforts)
+		require.Equal(t, 100.0, pred.PR)
+		// The old PR barely counts, recent form is close to 120.
+		require.Greater(t, pred.Recent, 118.0)
+		require.Less(t, pred.Recent, 120.0)
+		// With no fade, the prediction is the blend of PR and recent form.
+		require.InDelta(t, (100+pred.Recent)/2, pred.Predicted, 0.0001)
+		require.InDelta(t, pred.Predicted, pred.Low, 0.0001)
+		require.InDelta(t, pred.Predicted, pred.High, 0.0001)
+	})
+
+	t.Run("RatioOfBaseline", func(t *testing.T) {
+		t.Parallel()
+
+		faded := predict.Predictor{
+			Fatigue: predict.Fatigue{Overall: predict.Ratio{Low: 1.05, Median: 1.1, High: 1.2, Samples: 1}},
+		}
+		pred, ok := faded.Segment(1, []predict.Effort{
+			{SegmentID: 1, Elapsed: 100, StartDate: at.Add(-time.Hour * 24)},
+		}, at)
+		require.True(t, ok)
+		require.InDelta(t, 100.0, pred.Baseline(), 0.0001)
+		require.InDelta(t, 110.0, pred.Predicted, 0.0001)
+		require.InDelta(t, 105.0, pred.Low, 0.0001)
+		require.InDelta(t, 120.0, pred.High, 0.0001)
+	})
+
+	t.Run("RecentFormMovesPrediction", func(t *testing.T) {
+		t.Parallel()
+
+		// The same PR, with sharp or slower efforts since.
+		history := func(recent float64) []predict.Effort {
+			return []predict.Effort{
+				{SegmentID: 1, Elapsed: 100, StartDate: at.Add(-predict.DefaultHalfLife * 2)},
+				{SegmentID: 1, Elapsed: recent, StartDate: at.Add(-time.Hour * 48)},
+				{SegmentID: 1, Elapsed: recent, StartDate: at.Add(-time.Hour * 24)},
+			}
+		}
+		sharp, ok := p.Segment(1, history(102), at)
+		require.True(t, ok)
+		slow, ok := p.Segment(1, history(118), at)
+		require.True(t, ok)
+
+		require.Equal(t, sharp.PR, slow.PR)
+		require.Greater(t, slow.Predicted, sharp.Predicted+5)
+	})
+}
+
+// TestBacktest checks how predictions are scored against results. The
+// backtest command runs it over the stored past editions.
+func TestBacktest(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, time.November, 9, 0, 0, 0, 0, time.UTC)
+	p := predict.Predictor{
+		Fatigue: predict.Fatigue{Overall: predict.Ratio{Low: 1, Median: 1.1, High: 1.2, Samples: 1}},
+	}
+	history := []predict.Effort{
+		{SegmentID: 1, Elapsed: 100, StartDate: at.Add(-time.Hour * 24)},
+		{SegmentID: 2, Elapsed: 200, StartDate: at.Add(-time.Hour * 24)},
+	}
+
+	res := predict.Backtest(p, []predict.BacktestCase{
+		// Predicted 330 in 300 to 360, off by 10%.
+		{At: at, SegmentIDs: []int64{1, 2}, History: history, Actual: map[int64]float64{1: 100, 2: 200}},
+		// Spot on.
+		{At: at, SegmentIDs: []int64{1, 2}, History: history, Actual: map[int64]float64{1: 110, 2: 220}},
+		// Slower than the range, off by 20%.
+		{At: at, SegmentIDs: []int64{1, 2}, History: history, Actual: map[int64]float64{1: 137.5, 2: 275}},
+		// Segment 3 was never ridden, so it is skipped.
+		{At: at, SegmentIDs: []int64{1, 3}, History: history, Actual: map[int64]float64{1: 100, 3: 100}},
+		// History from the day itself does not count.
+		{At: at.Add(-time.Hour * 48), SegmentIDs: []int64{1}, History: history, Actual: map[int64]float64{1: 100}},
+	})
+	require.Equal(t, 3, res.Cases)
+	require.InDelta(t, (0.1+0+0.2)/3, res.MeanAbsPctError, 0.0001)
+	require.InDelta(t, 2.0/3, res.InRange, 0.0001)
+}
+
+func TestBacktestEdition(t *testing.T) {
+	t.Parallel()
+
+	editions := make([]hugeldate.Edition, 0, 3)
+	for _, year := range []int{2023, 2024, 2025} {
+		e, ok := hugeldate.EditionByYear(year, false)
+		require.True(t, ok)
+		editions = append(editions, e)
+	}
+
+	// Every rider climbs at their form on each climb, slowed by the edition's
+	// fade. The edition after the one tested fades very differently, and
+	// must not be learned from.
+	fades := []float64{1.1, 1.1, 2}
+	var climbs []predict.Climb
+	for i, e := range editions {
+		start := e.Dates.Start.Add(time.Hour)
+		for athlete := int64(0); athlete < 20; athlete++ {
+			for segment := int64(1); segment <= 3; segment++ {
+				form := 100 * float64(segment) * (1 + float64(athlete)/20)
+				climbs = append(climbs, predict.Climb{
+					RouteName:  e.RouteName,
+					ActivityID: int64(i)*1000 + athlete,
+					SegmentID:  segment,
+					Start:      start,
+					At:         start.Add(time.Duration(segment) * time.Minute),
+					Elapsed:    form * fades[i],
+					History: []predict.Effort{
+						{SegmentID: segment, Elapsed: form, StartDate: start.AddDate(0, -1, 0)},
+					},
+				})
+			}
+		}
+	}
+
+	res := predict.BacktestEdition(climbs, editions[1])
+	require.Equal(t, 20, res.Cases)
+	require.Less(t, res.MeanAbsPctError, 0.001)
+	require.Equal(t, 1.0, res.InRange)
+}
@@ -0,0 +1,153 @@
+// Package routecompare lines up two results of a competitive route segment by
+// segment. The results can come from different editions of the route, only
+// the segments both results share are compared.
+package routecompare
+
+import (
+	"sort"
+
+	"github.com/Emyrk/strava/database"
+)
+
+// Result is a single scored activity on a competitive route.
+type Result struct {
+	ActivityID       int64
+	TotalTimeSeconds int64
+	Efforts          database.HugelSegmentEfforts
+	// AverageHeartrate of the whole activity, 0 if not recorded.
+	AverageHeartrate float64
+	// AverageWatts of the whole activity, only trusted if DeviceWatts.
+	AverageWatts float64
+	DeviceWatts  bool
+}
+
+type Comparison struct {
+	// Climbs are the shared segments, in the order A rode them.
+	Climbs []Climb
+	// Transitions are the gaps between consecutive shared climbs.
+	Transitions []Transition
+	// OnlyA and OnlyB are segments that only one of the results has.
+	OnlyA []int64
+	OnlyB []int64
+
+	// TotalDelta is B's total time minus A's total time. Across editions
+	// the totals cover different segments, so prefer the climb deltas.
+	TotalDelta int64
+	// HeartrateDelta is nil unless both activities recorded heartrate.
+	HeartrateDelta *float64
+	// WattsDelta is nil unless both activities have power meter data.
+	WattsDelta *float64
+}
+
+type Climb struct {
+	SegmentID int64
+	A         database.HugelSegmentEffort
+	B         database.HugelSegmentEffort
+	// Delta is B's elapsed time minus A's. Negative means B was faster.
+	Delta int
+	// CumulativeDelta is the running sum of Delta up to and including this climb.
+	CumulativeDelta int
+	// WattsDelta is nil unless both efforts have power meter data.
+	WattsDelta *float64
+}
+
+type Transition struct {
+	FromSegmentID int64
+	ToSegmentID   int64
+	// A and B are the seconds between finishing one climb and starting the
+	// next. Nil if the climbs were ridden in the other order.
+	A     *int
+	B     *int
+	Delta *int
+}
+
+// Compare aligns b against a. The boolean is false if the results do not
+// share a single segment.
+func Compare(a, b Result) (Comparison, bool) {
+	bySegment := make(map[int64]database.HugelSegmentEffort, len(b.Efforts))
+	for _, e := range b.Efforts {
+		bySegment[int64(e.SegmentID)] = e
+	}
+
+	aEfforts := make(database.HugelSegmentEfforts, len(a.Efforts))
+	copy(aEfforts, a.Efforts)
+	sort.SliceStable(aEfforts, func(i, j int) bool {
+		return aEfforts[i].StartDate.Before(aEfforts[j].StartDate)
+	})
+
+	cmp := Comparison{
+		TotalDelta: b.TotalTimeSeconds - a.TotalTimeSeconds,
+	}
+	shared := make(map[int64]bool)
+	cumulative := 0
+	for _, ae := range aEfforts {
+		id := int64(ae.SegmentID)
+		be, ok := bySegment[id]
+		if !ok {
+			cmp.OnlyA = append(cmp.OnlyA, id)
+			continue
+		}
+		shared[id] = true
+
+		delta := be.ElapsedTime - ae.ElapsedTime
+		cumulative += delta
+		climb := Climb{
+			SegmentID:       id,
+			A:               ae,
+			B:               be,
+			Delta:           delta,
+			CumulativeDelta: cumulative,
+		}
+		if ae.DeviceWatts && be.DeviceWatts {
+			climb.WattsDelta = ptr(be.AverageWatts - ae.AverageWatts)
+		}
+		cmp.Climbs = append(cmp.Climbs, climb)
+	}
+
+	for _, be := range b.Efforts {
+		if !shared[int64(be.SegmentID)] {
+			cmp.OnlyB = append(cmp.OnlyB, int64(be.SegmentID))
+		}
+	}
+
+	if len(cmp.Climbs) == 0 {
+		return Comparison{}, false
+	}
+
+	for i := 1; i < len(cmp.Climbs); i++ {
+		from, to := cmp.Climbs[i-1], cmp.Climbs[i]
+		t := Transition{
+			FromSegmentID: from.SegmentID,
+			ToSegmentID:   to.SegmentID,
+			A:             transition(from.A, to.A),
+			B:             transition(from.B, to.B),
+		}
+		if t.A != nil && t.B != nil {
+			t.Delta = ptr(*t.B - *t.A)
+		}
+		cmp.Transitions = append(cmp.Transitions, t)
+	}
+
+	if a.AverageHeartrate > 0 && b.AverageHeartrate > 0 {
+		cmp.HeartrateDelta = ptr(b.AverageHeartrate - a.AverageHeartrate)
+	}
+	if a.DeviceWatts && b.DeviceWatts {
+		cmp.WattsDelta = ptr(b.AverageWatts - a.AverageWatts)
+	}
+
+	return cmp, true
+}
+
+// transition is the time between finishing one effort and starting the next.
+func transition(from, to database.HugelSegmentEffort) *int {
+	finished := from.StartDate.Unix() + int64(from.ElapsedTime)
+	gap := int(to.StartDate.Unix() - finished)
+	if gap < 0 {
+		return nil
+	}
+	return &gap
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,111 @@
+package routecompare_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Emyrk/strava/database"
+	"github.com/Emyrk/strava/internal/routecompare"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.November, 9, 8, 0, 0, 0, time.UTC)
+	effort := func(segment int, offset time.Duration, elapsed int, watts float64) database.HugelSegmentEffort {
+		return database.HugelSegmentEffort{
+			SegmentID:    segment,
+			StartDate:    start.Add(offset),
+			ElapsedTime:  elapsed,
+			MovingTime:   elapsed,
+			DeviceWatts:  watts > 0,
+			AverageWatts: watts,
+		}
+	}
+
+	t.Run("SameEdition", func(t *testing.T) {
+		t.Parallel()
+
+		a := routecompare.Result{
+			TotalTimeSeconds: 300,
+			AverageHeartrate: 150,
+			Efforts: database.HugelSegmentEfforts{
+				// Out of order on purpose, climbs are sorted by start.
+				effort(2, time.Minute*10, 200, 250),
+				effort(1, 0, 100, 300),
+			},
+		}
+		b := routecompare.Result{
+			TotalTimeSeconds: 290,
+			AverageHeartrate: 160,
+			Efforts: database.HugelSegmentEfforts{
+				effort(1, 0, 110, 0),
+				effort(2, time.Minute*5, 180, 260),
+			},
+		}
+
+		cmp, ok := routecompare.Compare(a, b)
+		require.True(t, ok)
+		require.Len(t, cmp.Climbs, 2)
+		require.Equal(t, int64(1), cmp.Climbs[0].SegmentID)
+		require.Equal(t, 10, cmp.Climbs[0].Delta)
+		require.Equal(t, 10, cmp.Climbs[0].CumulativeDelta)
+		require.Nil(t, cmp.Climbs[0].WattsDelta, "b has no power on climb 1")
+		require.Equal(t, -20, cmp.Climbs[1].Delta)
+		require.Equal(t, -10, cmp.Climbs[1].CumulativeDelta)
+		require.Equal(t, 10.0, *cmp.Climbs[1].WattsDelta)
+
+		require.Len(t, cmp.Transitions, 1)
+		require.Equal(t, 500, *cmp.Transitions[0].A)
+		require.Equal(t, 190, *cmp.Transitions[0].B)
+		require.Equal(t, -310, *cmp.Transitions[0].Delta)
+
+		require.Equal(t, int64(-10), cmp.TotalDelta)
+		require.Equal(t, 10.0, *cmp.HeartrateDelta)
+		require.Nil(t, cmp.WattsDelta)
+	})
+
+	t.Run("AcrossEditions", func(t *testing.T) {
+		t.Parallel()
+
+		a := routecompare.Result{
+			Efforts: database.HugelSegmentEfforts{
+				effort(1, 0, 100, 0),
+				effort(2, time.Minute*5, 100, 0),
+				effort(3, time.Minute*10, 100, 0),
+			},
+		}
+		b := routecompare.Result{
+			Efforts: database.HugelSegmentEfforts{
+				// Ridden in the other order.
+				effort(3, 0, 90, 0),
+				effort(1, time.Minute*5, 90, 0),
+				effort(4, time.Minute*10, 90, 0),
+			},
+		}
+
+		cmp, ok := routecompare.Compare(a, b)
+		require.True(t, ok)
+		require.Len(t, cmp.Climbs, 2)
+		require.Equal(t, []int64{2}, cmp.OnlyA)
+		require.Equal(t, []int64{4}, cmp.OnlyB)
+		require.Equal(t, -20, cmp.Climbs[1].CumulativeDelta)
+
+		require.Len(t, cmp.Transitions, 1)
+		require.NotNil(t, cmp.Transitions[0].A)
+		require.Nil(t, cmp.Transitions[0].B)
+		require.Nil(t, cmp.Transitions[0].Delta)
+		require.Nil(t, cmp.HeartrateDelta)
+	})
+
+	t.Run("NoOverlap", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := routecompare.Compare(
+			routecompare.Result{Efforts: database.HugelSegmentEfforts{effort(1, 0, 100, 0)}},
+			routecompare.Result{Efforts: database.HugelSegmentEfforts{effort(2, 0, 100, 0)}},
+		)
+		require.False(t, ok)
+	})
+}
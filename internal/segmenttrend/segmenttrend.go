@@ -0,0 +1,182 @@
+// Package segmenttrend summarizes an athlete's history on one segment: how
+// their times and power moved over time, their best of each season, and how
+// their recent efforts compare to the PR.
+package segmenttrend
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// FormWindow is how far back efforts count as recent for the form.
+	FormWindow = time.Hour * 24 * 42
+
+	// Form levels, by how close the recent best is to the PR pace.
+	FormPeak     = "peak"
+	FormClose    = "close"
+	FormBuilding = "building"
+	// FormUnknown is an athlete without a recent effort.
+	FormUnknown = "unknown"
+
+	peakPace  = 0.98
+	closePace = 0.93
+)
+
+// Effort is a single ride up the segment.
+type Effort struct {
+	ID int64
+	// StartDate is when the effort started, StartDateLocal the wall clock
+	// time stored as if it were UTC, used for the season.
+	StartDate      time.Time
+	StartDateLocal time.Time
+	// Elapsed is in seconds.
+	Elapsed float64
+	// Watts is zero without a power meter.
+	Watts float64
+}
+
+// Line is a least squares fit through a series of efforts.
+type Line struct {
+	Start      time.Time
+	StartValue float64
+	End        time.Time
+	EndValue   float64
+	// PerDay is the change of the value per day, negative when times are
+	// getting faster.
+	PerDay float64
+}
+
+// SeasonBest is the fastest effort of a calendar year.
+type SeasonBest struct {
+	Year     int
+	EffortID int64
+	Elapsed  float64
+	Date     time.Time
+}
+
+type Form struct {
+	Level string
+	// PR is the fastest effort ever, RecentBest the fastest in the
+	// FormWindow. Both in seconds, RecentBest is zero without recent efforts.
+	PR         float64
+	RecentBest float64
+	// Pace is the PR divided by the recent best, 1 when matching the PR.
+	Pace float64
+	// Recent is how many efforts are in the FormWindow.
+	Recent int
+}
+
+type Summary struct {
+	// Elapsed is the trend of the times, Watts of the efforts with power.
+	// Either is nil if there is not enough to fit.
+	Elapsed *Line
+	Watts   *Line
+	// Seasons are the season bests, oldest first.
+	Seasons []SeasonBest
+	Form    Form
+}
+
+// Summarize trends the efforts as of now.
+func Summarize(efforts []Effort, now time.Time) Summary {
+	sorted := make([]Effort, 0, len(efforts))
+	for _, e := range efforts {
+		if e.Elapsed > 0 {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartDate.Before(sorted[j].StartDate)
+	})
+
+	s := Summary{
+		Elapsed: Fit(sorted, func(e Effort) float64 { return e.Elapsed }),
+		Watts:   Fit(sorted, func(e Effort) float64 { return e.Watts }),
+		Seasons: []SeasonBest{},
+		Form:    Form{Level: FormUnknown},
+	}
+
+	seasons := make(map[int]int)
+	for _, e := range sorted {
+		year := e.StartDateLocal.Year()
+		idx, ok := seasons[year]
+		if !ok {
+			seasons[year] = len(s.Seasons)
+			s.Seasons = append(s.Seasons, SeasonBest{Year: year, EffortID: e.ID, Elapsed: e.Elapsed, Date: e.StartDateLocal})
+			continue
+		}
+		if e.Elapsed < s.Seasons[idx].Elapsed {
+			s.Seasons[idx] = SeasonBest{Year: year, EffortID: e.ID, Elapsed: e.Elapsed, Date: e.StartDateLocal}
+		}
+	}
+
+	for _, e := range sorted {
+		if s.Form.PR == 0 || e.Elapsed < s.Form.PR {
+			s.Form.PR = e.Elapsed
+		}
+		if e.StartDate.After(now.Add(-FormWindow)) && !e.StartDate.After(now) {
+			s.Form.Recent++
+			if s.Form.RecentBest == 0 || e.Elapsed < s.Form.RecentBest {
+				s.Form.RecentBest = e.Elapsed
+			}
+		}
+	}
+	if s.Form.RecentBest > 0 {
+		s.Form.Pace = s.Form.PR / s.Form.RecentBest
+		switch {
+		case s.Form.Pace >= peakPace:
+			s.Form.Level = FormPeak
+		case s.Form.Pace >= closePace:
+			s.Form.Level = FormClose
+		default:
+			s.Form.Level = FormBuilding
+		}
+	}
+	return s
+}
+
+// Fit is the least squares line of the values over time. Efforts with a zero
+// value are skipped, it is nil unless the rest span more than a day.
+func Fit(efforts []Effort, value func(Effort) float64) *Line {
+	var first, last time.Time
+	for _, e := range efforts {
+		if value(e) <= 0 {
+			continue
+		}
+		if first.IsZero() || e.StartDate.Before(first) {
+			first = e.StartDate
+		}
+		if e.StartDate.After(last) {
+			last = e.StartDate
+		}
+	}
+	if first.IsZero() || last.Sub(first) < time.Hour*24 {
+		return nil
+	}
+
+	// Days since the first effort, so the sums stay small.
+	day := func(t time.Time) float64 { return t.Sub(first).Hours() / 24 }
+	var n, sx, sy, sxx, sxy float64
+	for _, e := range efforts {
+		v := value(e)
+		if v <= 0 {
+			continue
+		}
+		x := day(e.StartDate)
+		n++
+		sx += x
+		sy += v
+		sxx += x * x
+		sxy += x * v
+	}
+
+	slope := (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	intercept := (sy - slope*sx) / n
+	return &Line{
+		Start:      first,
+		StartValue: intercept,
+		End:        last,
+		EndValue:   intercept + slope*day(last),
+		PerDay:     slope,
+	}
+}
@@ -0,0 +1,77 @@
+package segmenttrend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Emyrk/strava/internal/segmenttrend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	day := func(daysAgo int) time.Time { return now.AddDate(0, 0, -daysAgo) }
+	efforts := []segmenttrend.Effort{
+		{ID: 4, StartDate: day(10), StartDateLocal: day(10), Elapsed: 102, Watts: 300},
+		{ID: 1, StartDate: day(400), StartDateLocal: day(400), Elapsed: 130},
+		{ID: 2, StartDate: day(380), StartDateLocal: day(380), Elapsed: 98, Watts: 260},
+		{ID: 3, StartDate: day(200), StartDateLocal: day(200), Elapsed: 110, Watts: 280},
+		// Broken efforts are ignored.
+		{ID: 5, StartDate: day(5), StartDateLocal: day(5)},
+	}
+
+	s := segmenttrend.Summarize(efforts, now)
+
+	require.Equal(t, []segmenttrend.SeasonBest{
+		{Year: 2023, EffortID: 2, Elapsed: 98, Date: day(380)},
+		{Year: 2024, EffortID: 4, Elapsed: 102, Date: day(10)},
+	}, s.Seasons)
+
+	require.NotNil(t, s.Elapsed)
+	require.Equal(t, day(400), s.Elapsed.Start)
+	require.Equal(t, day(10), s.Elapsed.End)
+	require.Negative(t, s.Elapsed.PerDay)
+
+	require.NotNil(t, s.Watts)
+	require.Equal(t, day(380), s.Watts.Start)
+	require.Positive(t, s.Watts.PerDay)
+
+	require.Equal(t, segmenttrend.Form{
+		Level:      segmenttrend.FormClose,
+		PR:         98,
+		RecentBest: 102,
+		Pace:       98.0 / 102,
+		Recent:     1,
+	}, s.Form)
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	t.Parallel()
+
+	s := segmenttrend.Summarize(nil, time.Now())
+	require.Nil(t, s.Elapsed)
+	require.Nil(t, s.Watts)
+	require.Empty(t, s.Seasons)
+	require.Equal(t, segmenttrend.FormUnknown, s.Form.Level)
+}
+
+func TestFit(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	efforts := []segmenttrend.Effort{
+		{StartDate: start, Elapsed: 100},
+		{StartDate: start.AddDate(0, 0, 10), Elapsed: 90},
+		{StartDate: start.AddDate(0, 0, 20), Elapsed: 80},
+	}
+	line := segmenttrend.Fit(efforts, func(e segmenttrend.Effort) float64 { return e.Elapsed })
+	require.NotNil(t, line)
+	require.InDelta(t, -1, line.PerDay, 1e-9)
+	require.InDelta(t, 100, line.StartValue, 1e-9)
+	require.InDelta(t, 80, line.EndValue, 1e-9)
+
+	// A single day is not a trend.
+	require.Nil(t, segmenttrend.Fit(efforts[:1], func(e segmenttrend.Effort) float64 { return e.Elapsed }))
+}
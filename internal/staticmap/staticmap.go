@@ -0,0 +1,205 @@
+// Package staticmap draws ride tracks as images, without map tiles. The track
+// is fit into the image, with the climbs drawn over it and an optional
+// elevation profile underneath.
+package staticmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/vector"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+var (
+	Background = color.RGBA{R: 0xfd, G: 0xf6, B: 0xe3, A: 0xff}
+	TrackColor = color.RGBA{R: 0x3a, G: 0x3a, B: 0x3a, A: 0xff}
+	ClimbColor = color.RGBA{R: 0xfc, G: 0x4c, B: 0x02, A: 0xff}
+	StartColor = color.RGBA{R: 0x2e, G: 0x9e, B: 0x44, A: 0xff}
+	EndColor   = color.RGBA{R: 0xd0, G: 0x21, B: 0x21, A: 0xff}
+)
+
+// Map is everything drawn on a map image.
+type Map struct {
+	Width  int
+	Height int
+	Track  []geo.Point
+	// Climbs are drawn over the track, e.g. the segments of a route.
+	Climbs [][]geo.Point
+	// Elevation is the altitude in meters along the track, evenly spaced or
+	// not. If set, a profile strip is drawn along the bottom.
+	Elevation []float64
+}
+
+const (
+	padding      = 24.0
+	trackWidth   = 4.0
+	climbWidth   = 6.0
+	markerRadius = 8.0
+	// profileShare is the fraction of the height the elevation strip takes.
+	profileShare = 0.2
+)
+
+// Image draws the map.
+func (m Map) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, m.Width, m.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(Background), image.Point{}, draw.Src)
+
+	w, h := float64(m.Width), float64(m.Height)
+	mapHeight := h
+	if len(m.Elevation) > 1 {
+		mapHeight = h * (1 - profileShare)
+		m.drawProfile(img, 0, mapHeight, w, h-mapHeight)
+	}
+
+	if len(m.Track) < 2 {
+		return img
+	}
+	proj := Fit(m.Track, padding, padding, w-padding*2, mapHeight-padding*2)
+	Stroke(img, proj.Line(m.Track), trackWidth, TrackColor)
+	for _, climb := range m.Climbs {
+		Stroke(img, proj.Line(climb), climbWidth, ClimbColor)
+	}
+	Dot(img, proj.Point(m.Track[0]), markerRadius, StartColor)
+	Dot(img, proj.Point(m.Track[len(m.Track)-1]), markerRadius, EndColor)
+	return img
+}
+
+// WritePNG draws the map as a PNG image.
+func (m Map) WritePNG(w io.Writer) error {
+	return png.Encode(w, m.Image())
+}
+
+// drawProfile fills the elevation profile into the box.
+func (m Map) drawProfile(img *image.RGBA, x, y, w, h float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, e := range m.Elevation {
+		low, high = math.Min(low, e), math.Max(high, e)
+	}
+	// Keep flat rides looking flat, at least 50m of range.
+	span := math.Max(high-low, 50)
+
+	top := y + h*0.15
+	r := vector.NewRasterizer(img.Bounds().Dx(), img.Bounds().Dy())
+	r.MoveTo(float32(x), float32(y+h))
+	for i, e := range m.Elevation {
+		px := x + w*float64(i)/float64(len(m.Elevation)-1)
+		py := top + (y+h-top)*(1-(e-low)/span)
+		r.LineTo(float32(px), float32(py))
+	}
+	r.LineTo(float32(x+w), float32(y+h))
+	r.ClosePath()
+	r.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 0x9a, G: 0x9a, B: 0x9a, A: 0xff}), image.Point{})
+}
+
+// Projection maps lat/lng onto image pixels.
+type Projection struct {
+	scaleX     float64
+	scale      float64
+	minX, minY float64
+	offX, offY float64
+}
+
+// Fit fits the points into the box, keeping their aspect ratio. The
+// equirectangular projection is plenty for a ride sized area.
+func Fit(points []geo.Point, x, y, w, h float64) Projection {
+	var meanLat float64
+	for _, p := range points {
+		meanLat += p.Lat
+	}
+	meanLat /= float64(max(len(points), 1))
+
+	p := Projection{scaleX: math.Cos(meanLat * math.Pi / 180)}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, pt := range points {
+		px, py := pt.Lng*p.scaleX, -pt.Lat
+		minX, maxX = math.Min(minX, px), math.Max(maxX, px)
+		minY, maxY = math.Min(minY, py), math.Max(maxY, py)
+	}
+	if len(points) == 0 {
+		minX, maxX, minY, maxY = 0, 0, 0, 0
+	}
+
+	spanX, spanY := maxX-minX, maxY-minY
+	p.scale = math.Min(w/math.Max(spanX, 1e-9), h/math.Max(spanY, 1e-9))
+	p.minX, p.minY = minX, minY
+	p.offX = x + (w-spanX*p.scale)/2
+	p.offY = y + (h-spanY*p.scale)/2
+	return p
+}
+
+// Point is the pixel of a point.
+func (p Projection) Point(pt geo.Point) [2]float64 {
+	return [2]float64{
+		p.offX + (pt.Lng*p.scaleX-p.minX)*p.scale,
+		p.offY + (-pt.Lat-p.minY)*p.scale,
+	}
+}
+
+// Line is the pixels of a line.
+func (p Projection) Line(line []geo.Point) [][2]float64 {
+	out := make([][2]float64, 0, len(line))
+	for _, pt := range line {
+		out = append(out, p.Point(pt))
+	}
+	return out
+}
+
+// Stroke draws each segment of the line as a quad, extended by half the
+// width on both ends so the joins are filled in.
+func Stroke(img *image.RGBA, points [][2]float64, width float64, c color.Color) {
+	b := img.Bounds()
+	r := vector.NewRasterizer(b.Dx(), b.Dy())
+	half := width / 2
+	for i := 1; i < len(points); i++ {
+		x0, y0 := points[i-1][0], points[i-1][1]
+		x1, y1 := points[i][0], points[i][1]
+		dx, dy := x1-x0, y1-y0
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		ux, uy := dx/length*half, dy/length*half
+		// Normal
+		nx, ny := -uy, ux
+		x0, y0 = x0-ux, y0-uy
+		x1, y1 = x1+ux, y1+uy
+
+		r.MoveTo(float32(x0+nx), float32(y0+ny))
+		r.LineTo(float32(x1+nx), float32(y1+ny))
+		r.LineTo(float32(x1-nx), float32(y1-ny))
+		r.LineTo(float32(x0-nx), float32(y0-ny))
+		r.ClosePath()
+	}
+	r.Draw(img, b, image.NewUniform(c), image.Point{})
+}
+
+// Dot draws a filled circle with a white ring, to stand out on the track.
+func Dot(img *image.RGBA, center [2]float64, radius float64, c color.Color) {
+	circle(img, center, radius+2, color.White)
+	circle(img, center, radius, c)
+}
+
+func circle(img *image.RGBA, center [2]float64, radius float64, c color.Color) {
+	b := img.Bounds()
+	r := vector.NewRasterizer(b.Dx(), b.Dy())
+	const steps = 32
+	for i := 0; i <= steps; i++ {
+		a := 2 * math.Pi * float64(i) / steps
+		x := float32(center[0] + radius*math.Cos(a))
+		y := float32(center[1] + radius*math.Sin(a))
+		if i == 0 {
+			r.MoveTo(x, y)
+			continue
+		}
+		r.LineTo(x, y)
+	}
+	r.ClosePath()
+	r.Draw(img, b, image.NewUniform(c), image.Point{})
+}
@@ -0,0 +1,58 @@
+package staticmap_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/internal/staticmap"
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+func TestFit(t *testing.T) {
+	t.Parallel()
+
+	points := []geo.Point{{Lat: 30.30, Lng: -97.80}, {Lat: 30.40, Lng: -97.70}}
+	proj := staticmap.Fit(points, 10, 10, 200, 100)
+
+	// North is up, and the taller side fills the box.
+	sw, ne := proj.Point(points[0]), proj.Point(points[1])
+	require.InDelta(t, 110, sw[1], 1e-6)
+	require.InDelta(t, 10, ne[1], 1e-6)
+	require.Less(t, sw[0], ne[0])
+	// Centered horizontally.
+	require.InDelta(t, 110, (sw[0]+ne[0])/2, 1e-6)
+}
+
+func TestImage(t *testing.T) {
+	t.Parallel()
+
+	track := []geo.Point{{Lat: 30.30, Lng: -97.80}, {Lat: 30.35, Lng: -97.75}, {Lat: 30.30, Lng: -97.70}}
+	m := staticmap.Map{
+		Width:     400,
+		Height:    300,
+		Track:     track,
+		Climbs:    [][]geo.Point{track[:2]},
+		Elevation: []float64{150, 250, 180},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, m.WritePNG(&buf))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 400, img.Bounds().Dx())
+	require.Equal(t, 300, img.Bounds().Dy())
+
+	// The corner is background, the bottom middle is under the profile.
+	require.Equal(t, staticmap.Background, img.At(1, 1))
+	require.NotEqual(t, staticmap.Background, img.At(200, 298))
+}
+
+func TestImageNoTrack(t *testing.T) {
+	t.Parallel()
+
+	img := staticmap.Map{Width: 10, Height: 10}.Image()
+	require.Equal(t, staticmap.Background, img.At(5, 5))
+}
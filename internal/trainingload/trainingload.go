@@ -0,0 +1,168 @@
+// Package trainingload builds an athlete's daily activity calendar, and the
+// acute and chronic training load over it. Each activity is scored by its
+// training stress, from power when the athlete has an FTP and the ride has
+// watts from a power meter, otherwise from Strava's suffer score.
+package trainingload
+
+import (
+	"time"
+
+	"github.com/Emyrk/strava/database"
+)
+
+const (
+	// AcuteDays and ChronicDays are the time constants of the fatigue and
+	// fitness averages.
+	AcuteDays   = 7
+	ChronicDays = 42
+	// Warmup is how much history before the first day of a calendar is
+	// needed for the chronic load to settle.
+	Warmup = ChronicDays * 3 * 24 * time.Hour
+)
+
+// Activity is the part of an activity the calendar is counted from.
+type Activity struct {
+	ID int64
+	// StartDateLocal is the wall clock time the activity started, stored as
+	// if it were UTC.
+	StartDateLocal time.Time
+	// Distance and Elevation are in meters, MovingTime in seconds.
+	Distance   float64
+	Elevation  float64
+	MovingTime float64
+	Kilojoules float64
+	// SufferScore is zero until the activity detail is fetched.
+	SufferScore int32
+	// Watts is the weighted average power if known, otherwise the average.
+	// Zero without power.
+	Watts float64
+	// DeviceWatts is true when Watts came from a power meter, not Strava's
+	// estimate.
+	DeviceWatts      bool
+	AverageHeartrate float64
+}
+
+// FromRows reads the activities of ActivityCalendar.
+func FromRows(rows []database.ActivityCalendarRow) []Activity {
+	acts := make([]Activity, 0, len(rows))
+	for _, row := range rows {
+		watts := row.WeightedAverageWatts
+		if watts == 0 {
+			watts = row.AverageWatts
+		}
+		acts = append(acts, Activity{
+			ID:               row.ID,
+			StartDateLocal:   row.StartDateLocal.Time,
+			Distance:         row.Distance,
+			Elevation:        row.TotalElevationGain,
+			MovingTime:       row.MovingTime,
+			Kilojoules:       row.Kilojoules,
+			SufferScore:      row.SufferScore,
+			Watts:            watts,
+			DeviceWatts:      row.DeviceWatts,
+			AverageHeartrate: row.AverageHeartrate,
+		})
+	}
+	return acts
+}
+
+// Stress is the training stress score of an activity. With power it is the
+// hours ridden times the squared intensity factor, times 100, so an hour at
+// FTP scores 100. Without it the suffer score stands in, which Strava scales
+// to be roughly comparable. Estimated power is too rough for the squared
+// intensity, so it counts as no power.
+func Stress(act Activity, ftp float64) float64 {
+	if ftp > 0 && act.DeviceWatts && act.Watts > 0 {
+		intensity := act.Watts / ftp
+		return act.MovingTime / 3600 * intensity * intensity * 100
+	}
+	return float64(act.SufferScore)
+}
+
+// Day is the activities of a calendar day and the training load after it.
+type Day struct {
+	Date       time.Time
+	Activities int
+	Distance   float64
+	Elevation  float64
+	MovingTime float64
+	Kilojoules float64
+	// SufferScore is the sum of the day's suffer scores.
+	SufferScore int32
+	// AverageHeartrate is weighted by the moving time of the activities with
+	// a heart rate, zero without any.
+	AverageHeartrate float64
+	Stress           float64
+	// ATL is the acute load, or fatigue, CTL the chronic load, or fitness.
+	// Both include the day.
+	ATL float64
+	CTL float64
+	// TSB is the form going into the day, yesterday's CTL less its ATL.
+	TSB float64
+}
+
+// Calendar returns every day from from to to, inclusive. Activities before
+// from only count towards the load, so pass Warmup of history to start the
+// calendar with a settled CTL.
+func Calendar(acts []Activity, ftp float64, from, to time.Time) []Day {
+	from, to = localDay(from), localDay(to)
+	if to.Before(from) {
+		return []Day{}
+	}
+
+	type heartrate struct{ beats, seconds float64 }
+	days := make(map[time.Time]*Day)
+	hr := make(map[time.Time]*heartrate)
+	start := from
+	for _, act := range acts {
+		date := localDay(act.StartDateLocal)
+		if date.After(to) {
+			continue
+		}
+		if date.Before(start) {
+			start = date
+		}
+		d, ok := days[date]
+		if !ok {
+			d = &Day{Date: date}
+			days[date] = d
+			hr[date] = &heartrate{}
+		}
+		d.Activities++
+		d.Distance += act.Distance
+		d.Elevation += act.Elevation
+		d.MovingTime += act.MovingTime
+		d.Kilojoules += act.Kilojoules
+		d.SufferScore += act.SufferScore
+		d.Stress += Stress(act, ftp)
+		if act.AverageHeartrate > 0 {
+			hr[date].beats += act.AverageHeartrate * act.MovingTime
+			hr[date].seconds += act.MovingTime
+		}
+	}
+
+	out := make([]Day, 0, int(to.Sub(from).Hours()/24)+1)
+	var atl, ctl float64
+	for date := start; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := Day{Date: date}
+		if d, ok := days[date]; ok {
+			day = *d
+			if h := hr[date]; h.seconds > 0 {
+				day.AverageHeartrate = h.beats / h.seconds
+			}
+		}
+		day.TSB = ctl - atl
+		atl += (day.Stress - atl) / AcuteDays
+		ctl += (day.Stress - ctl) / ChronicDays
+		day.ATL, day.CTL = atl, ctl
+		if !date.Before(from) {
+			out = append(out, day)
+		}
+	}
+	return out
+}
+
+func localDay(local time.Time) time.Time {
+	local = local.UTC()
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,63 @@
+package trainingload_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Emyrk/strava/internal/trainingload"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStress(t *testing.T) {
+	t.Parallel()
+
+	hour := trainingload.Activity{MovingTime: 3600, Watts: 250, DeviceWatts: true, SufferScore: 40}
+	require.InDelta(t, 100, trainingload.Stress(hour, 250), 0.001)
+	require.InDelta(t, 25, trainingload.Stress(trainingload.Activity{MovingTime: 3600, Watts: 125, DeviceWatts: true}, 250), 0.001)
+	// No ftp, estimated watts or no watts falls back to the suffer score.
+	require.Equal(t, float64(40), trainingload.Stress(hour, 0))
+	estimated := hour
+	estimated.DeviceWatts = false
+	require.Equal(t, float64(40), trainingload.Stress(estimated, 250))
+	hour.Watts = 0
+	require.Equal(t, float64(40), trainingload.Stress(hour, 250))
+}
+
+func TestCalendar(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int, hour int) time.Time {
+		return time.Date(2024, time.March, d, hour, 0, 0, 0, time.UTC)
+	}
+	acts := []trainingload.Activity{
+		// Before the calendar, only counts towards the load.
+		{StartDateLocal: day(1, 9), MovingTime: 3600, SufferScore: 70},
+		{StartDateLocal: day(3, 7), Distance: 1000, MovingTime: 1800, SufferScore: 10, AverageHeartrate: 120},
+		{StartDateLocal: day(3, 23), Distance: 2000, Elevation: 50, MovingTime: 3600, Kilojoules: 500, SufferScore: 20, AverageHeartrate: 150},
+		// After the calendar.
+		{StartDateLocal: day(9, 9), SufferScore: 500},
+	}
+
+	days := trainingload.Calendar(acts, 0, day(2, 0), day(4, 12))
+	require.Len(t, days, 3)
+	require.Equal(t, day(2, 0), days[0].Date)
+	require.Equal(t, day(4, 0), days[2].Date)
+
+	// The first day's load is carried from the ride the day before.
+	require.InDelta(t, 10.0*6/7, days[0].ATL, 0.001)
+	require.Zero(t, days[0].Activities)
+	require.InDelta(t, 70.0/42-70.0/7, days[0].TSB, 0.001)
+
+	mar3 := days[1]
+	require.Equal(t, 2, mar3.Activities)
+	require.Equal(t, float64(3000), mar3.Distance)
+	require.Equal(t, float64(50), mar3.Elevation)
+	require.Equal(t, float64(500), mar3.Kilojoules)
+	require.Equal(t, int32(30), mar3.SufferScore)
+	require.InDelta(t, 140, mar3.AverageHeartrate, 0.001)
+	require.Equal(t, float64(30), mar3.Stress)
+	require.Greater(t, mar3.ATL, days[0].ATL)
+	require.Equal(t, mar3.CTL-mar3.ATL, days[2].TSB)
+
+	require.Empty(t, trainingload.Calendar(acts, 0, day(4, 0), day(2, 0)))
+}
@@ -0,0 +1,150 @@
+// Package yearreview sums up an athlete's calendar year of activities. Days
+// and years are local to where the activity was ridden, like the eddington
+// numbers.
+package yearreview
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Emyrk/strava/internal/eddington"
+)
+
+type Activity struct {
+	ID             int64
+	Name           string
+	StartDateLocal time.Time
+	SportType      string
+	// Distance and Elevation are in meters, MovingTime in seconds.
+	Distance   float64
+	Elevation  float64
+	MovingTime float64
+}
+
+// Totals are the activities of one sport type, or of all of them.
+type Totals struct {
+	Sport      string
+	Activities int
+	Distance   float64
+	Elevation  float64
+	MovingTime float64
+}
+
+func (t *Totals) add(act Activity) {
+	t.Activities++
+	t.Distance += act.Distance
+	t.Elevation += act.Elevation
+	t.MovingTime += act.MovingTime
+}
+
+// Day is the climbing of a calendar day.
+type Day struct {
+	Date       time.Time
+	Elevation  float64
+	Activities int
+}
+
+type Summary struct {
+	// Totals has an empty Sport, it is every activity.
+	Totals Totals
+	// Sports are the totals by sport type, most activities first.
+	Sports []Totals
+	// LongestRide is the longest activity by distance, zero without any.
+	LongestRide Activity
+	// BiggestClimbDay is the day with the most elevation gained, zero
+	// without any.
+	BiggestClimbDay Day
+}
+
+// Summarize adds up the activities of a year.
+func Summarize(acts []Activity) Summary {
+	var s Summary
+	sports := make(map[string]*Totals)
+	days := make(map[time.Time]*Day)
+	for _, act := range acts {
+		s.Totals.add(act)
+
+		t, ok := sports[act.SportType]
+		if !ok {
+			t = &Totals{Sport: act.SportType}
+			sports[act.SportType] = t
+		}
+		t.add(act)
+
+		if act.Distance > s.LongestRide.Distance {
+			s.LongestRide = act
+		}
+
+		date := localDay(act.StartDateLocal)
+		d, ok := days[date]
+		if !ok {
+			d = &Day{Date: date}
+			days[date] = d
+		}
+		d.Elevation += act.Elevation
+		d.Activities++
+	}
+
+	for _, t := range sports {
+		s.Sports = append(s.Sports, *t)
+	}
+	sort.Slice(s.Sports, func(i, j int) bool {
+		if s.Sports[i].Activities != s.Sports[j].Activities {
+			return s.Sports[i].Activities > s.Sports[j].Activities
+		}
+		return s.Sports[i].Sport < s.Sports[j].Sport
+	})
+
+	for _, d := range days {
+		if d.Elevation > s.BiggestClimbDay.Elevation ||
+			(d.Elevation == s.BiggestClimbDay.Elevation && d.Elevation > 0 && d.Date.Before(s.BiggestClimbDay.Date)) {
+			s.BiggestClimbDay = *d
+		}
+	}
+	return s
+}
+
+// Eddington is how the lifetime number in miles moved over the year.
+type Eddington struct {
+	// Start is the number going into the year, End coming out of it.
+	Start int32
+	End   int32
+	// Year is the number of the year's rides alone.
+	Year int32
+	// Milestones are the numbers reached during the year.
+	Milestones []eddington.Milestone
+}
+
+// EddingtonProgress replays every ride of the athlete, rides after the year
+// are ignored.
+func EddingtonProgress(acts []eddington.Activity, year int) Eddington {
+	var e Eddington
+	days := eddington.DailyTotals(acts, eddington.SportAll)
+
+	var lifetime, yearDays []eddington.Day
+	for _, day := range days {
+		if day.Date.Year() > year {
+			break
+		}
+		lifetime = append(lifetime, day)
+		if day.Date.Year() == year {
+			yearDays = append(yearDays, day)
+		}
+	}
+
+	for _, ms := range eddington.History(lifetime, eddington.Miles) {
+		if ms.Date.Year() < year {
+			e.Start = ms.Eddington
+			continue
+		}
+		e.Milestones = append(e.Milestones, ms)
+	}
+	e.End = eddington.FromDays(lifetime, eddington.Miles).Current()
+	e.Year = eddington.FromDays(yearDays, eddington.Miles).Current()
+	return e
+}
+
+func localDay(local time.Time) time.Time {
+	local = local.UTC()
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,75 @@
+package yearreview_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/internal/eddington"
+	"github.com/Emyrk/strava/internal/yearreview"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	day := func(m time.Month, d, hour int) time.Time {
+		return time.Date(2024, m, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	acts := []yearreview.Activity{
+		{ID: 1, StartDateLocal: day(3, 1, 8), SportType: "Ride", Distance: 50_000, Elevation: 600, MovingTime: 7200},
+		// Two climbs on one day beat the biggest single climb.
+		{ID: 2, StartDateLocal: day(3, 2, 8), SportType: "Ride", Distance: 20_000, Elevation: 500, MovingTime: 3600},
+		{ID: 3, StartDateLocal: day(3, 2, 17), SportType: "GravelRide", Distance: 80_000, Elevation: 400, MovingTime: 14400},
+		{ID: 4, StartDateLocal: day(6, 9, 6), SportType: "Run", Distance: 10_000, Elevation: 50, MovingTime: 3000},
+		{ID: 5, StartDateLocal: day(6, 10, 6), SportType: "Ride", Distance: 30_000, Elevation: 200, MovingTime: 4000},
+	}
+
+	s := yearreview.Summarize(acts)
+	require.Equal(t, yearreview.Totals{Activities: 5, Distance: 190_000, Elevation: 1750, MovingTime: 32200}, s.Totals)
+	require.Equal(t, []yearreview.Totals{
+		{Sport: "Ride", Activities: 3, Distance: 100_000, Elevation: 1300, MovingTime: 14800},
+		{Sport: "GravelRide", Activities: 1, Distance: 80_000, Elevation: 400, MovingTime: 14400},
+		{Sport: "Run", Activities: 1, Distance: 10_000, Elevation: 50, MovingTime: 3000},
+	}, s.Sports)
+	require.Equal(t, int64(3), s.LongestRide.ID)
+	require.Equal(t, yearreview.Day{Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Elevation: 900, Activities: 2}, s.BiggestClimbDay)
+
+	require.Equal(t, yearreview.Summary{}, yearreview.Summarize(nil))
+}
+
+func TestEddingtonProgress(t *testing.T) {
+	t.Parallel()
+
+	const mile = float64(eddington.Miles)
+	ride := func(id int64, y int, m time.Month, d int, miles float64) eddington.Activity {
+		return eddington.Activity{ID: id, StartDateLocal: time.Date(y, m, d, 9, 0, 0, 0, time.UTC), Distance: miles * mile, Sport: eddington.SportRide}
+	}
+
+	acts := []eddington.Activity{
+		ride(1, 2023, 5, 1, 10),
+		ride(2, 2023, 5, 2, 10),
+		// 2024 takes the number from 2 to 4.
+		ride(3, 2024, 1, 5, 3),
+		ride(4, 2024, 2, 5, 4),
+		ride(5, 2024, 3, 5, 5),
+		// The next year does not count.
+		ride(6, 2025, 1, 1, 40),
+	}
+
+	e := yearreview.EddingtonProgress(acts, 2024)
+	require.Equal(t, int32(2), e.Start)
+	require.Equal(t, int32(4), e.End)
+	require.Equal(t, int32(3), e.Year)
+	require.Len(t, e.Milestones, 2)
+	require.Equal(t, int32(3), e.Milestones[0].Eddington)
+	require.Equal(t, int64(3), e.Milestones[0].ActivityID)
+	require.Equal(t, int32(4), e.Milestones[1].Eddington)
+	require.Equal(t, int64(5), e.Milestones[1].ActivityID)
+
+	empty := yearreview.EddingtonProgress(acts, 2022)
+	require.Zero(t, empty.Start)
+	require.Zero(t, empty.End)
+	require.Empty(t, empty.Milestones)
+}
@@ -0,0 +1,163 @@
+// Package diskcache keeps rendered files on disk, evicting the least recently
+// used once the cache grows past its size. Entries survive restarts, the
+// index is rebuilt from the directory on open.
+package diskcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]entry
+}
+
+type entry struct {
+	size int64
+	used time.Time
+}
+
+// Open uses dir for the cache, creating it if needed.
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read cache dir: %w", err)
+	}
+
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  make(map[string]entry, len(files)),
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) == ".tmp" {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		c.entries[f.Name()] = entry{size: info.Size(), used: info.ModTime()}
+		c.size += info.Size()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+	return c, nil
+}
+
+// Get returns the cached data for the key.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	name := fileName(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[name]
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		// Removed from under us, forget it.
+		c.size -= e.size
+		delete(c.entries, name)
+		return nil, false
+	}
+
+	// The mod time is the last use, so the order survives a restart.
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(c.dir, name), now, now)
+	e.used = now
+	c.entries[name] = e
+	return data, true
+}
+
+// Set stores the data, evicting the least recently used entries to make room.
+func (c *Cache) Set(key string, data []byte) error {
+	name := fileName(key)
+	path := filepath.Join(c.dir, name)
+
+	// Write then rename, a reader never sees half a file.
+	tmp, err := os.CreateTemp(c.dir, name+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+
+	if old, ok := c.entries[name]; ok {
+		c.size -= old.size
+	}
+	c.entries[name] = entry{size: int64(len(data)), used: time.Now()}
+	c.size += int64(len(data))
+	c.evict()
+	return nil
+}
+
+// Size is the total size of the cached files in bytes.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// evict removes the least recently used entries until the cache fits. It
+// must be called with the lock held.
+func (c *Cache) evict() {
+	if c.size <= c.maxBytes {
+		return
+	}
+
+	names := make([]string, 0, len(c.entries))
+	for name := range c.entries {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return c.entries[names[i]].used.Before(c.entries[names[j]].used)
+	})
+
+	for _, name := range names {
+		if c.size <= c.maxBytes {
+			return
+		}
+		_ = os.Remove(filepath.Join(c.dir, name))
+		c.size -= c.entries[name].size
+		delete(c.entries, name)
+	}
+}
+
+// fileName keeps keys of any shape out of the path.
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
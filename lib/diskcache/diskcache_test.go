@@ -0,0 +1,48 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/lib/diskcache"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c, err := diskcache.Open(dir, 250)
+	require.NoError(t, err)
+
+	_, ok := c.Get("a")
+	require.False(t, ok)
+
+	require.NoError(t, c.Set("a", bytes.Repeat([]byte("a"), 100)))
+	require.NoError(t, c.Set("b/../b", bytes.Repeat([]byte("b"), 100)))
+
+	// Using a makes b the least recently used.
+	data, ok := c.Get("a")
+	require.True(t, ok)
+	require.Len(t, data, 100)
+
+	require.NoError(t, c.Set("c", bytes.Repeat([]byte("c"), 100)))
+	require.EqualValues(t, 200, c.Size())
+	_, ok = c.Get("b/../b")
+	require.False(t, ok, "least recently used is evicted")
+	_, ok = c.Get("a")
+	require.True(t, ok)
+
+	// Replacing an entry does not count it twice.
+	require.NoError(t, c.Set("c", []byte("c")))
+	require.EqualValues(t, 101, c.Size())
+
+	// A reopened cache still has the entries.
+	reopened, err := diskcache.Open(dir, 250)
+	require.NoError(t, err)
+	require.EqualValues(t, 101, reopened.Size())
+	data, ok = reopened.Get("c")
+	require.True(t, ok)
+	require.Equal(t, []byte("c"), data)
+}
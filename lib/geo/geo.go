@@ -0,0 +1,208 @@
+// Package geo is the geometry of rides: decoding Strava's encoded polylines,
+// measuring them, and comparing them with each other. Distances are in meters.
+//
+// Everything here works on ride sized areas. Distances along the earth use
+// the haversine formula, while distances to lines use a flat projection
+// around the point, which is accurate to well under a meter for lines a few
+// kilometers long.
+package geo
+
+import (
+	"math"
+)
+
+// EarthRadius is the mean radius of the earth in meters.
+const EarthRadius = 6371008.8
+
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Distance is the great circle distance between two points.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Length is the distance along a polyline.
+func Length(line []Point) float64 {
+	var total float64
+	for i := 1; i < len(line); i++ {
+		total += Distance(line[i-1], line[i])
+	}
+	return total
+}
+
+// BBox is the smallest lat/lng rectangle around a set of points. It does not
+// handle lines crossing the antimeridian, there are no hills there.
+type BBox struct {
+	Min Point `json:"min"`
+	Max Point `json:"max"`
+}
+
+// Bounds returns the bounding box of the points, the zero box for none.
+func Bounds(points []Point) BBox {
+	if len(points) == 0 {
+		return BBox{}
+	}
+	b := BBox{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		b.Min.Lat = math.Min(b.Min.Lat, p.Lat)
+		b.Min.Lng = math.Min(b.Min.Lng, p.Lng)
+		b.Max.Lat = math.Max(b.Max.Lat, p.Lat)
+		b.Max.Lng = math.Max(b.Max.Lng, p.Lng)
+	}
+	return b
+}
+
+func (b BBox) Contains(p Point) bool {
+	return p.Lat >= b.Min.Lat && p.Lat <= b.Max.Lat &&
+		p.Lng >= b.Min.Lng && p.Lng <= b.Max.Lng
+}
+
+func (b BBox) Intersects(o BBox) bool {
+	return b.Min.Lat <= o.Max.Lat && o.Min.Lat <= b.Max.Lat &&
+		b.Min.Lng <= o.Max.Lng && o.Min.Lng <= b.Max.Lng
+}
+
+// Pad grows the box by at least the given meters on every side.
+func (b BBox) Pad(meters float64) BBox {
+	dLat := degrees(meters / EarthRadius)
+	// A degree of longitude is shortest at the latitude furthest from the
+	// equator, so pad enough for that edge.
+	lat := math.Min(90, math.Max(math.Abs(b.Min.Lat), math.Abs(b.Max.Lat))+dLat)
+	dLng := dLat / math.Max(math.Cos(radians(lat)), 1e-9)
+	return BBox{
+		Min: Point{Lat: b.Min.Lat - dLat, Lng: b.Min.Lng - dLng},
+		Max: Point{Lat: b.Max.Lat + dLat, Lng: b.Max.Lng + dLng},
+	}
+}
+
+// Center is the middle of the box.
+func (b BBox) Center() Point {
+	return Point{Lat: (b.Min.Lat + b.Max.Lat) / 2, Lng: (b.Min.Lng + b.Max.Lng) / 2}
+}
+
+// DistanceToSegment is the shortest distance from p to the line segment
+// between a and b.
+func DistanceToSegment(p, a, b Point) float64 {
+	// Project around p, so p is the origin.
+	scale := math.Cos(radians(p.Lat))
+	ax, ay := radians(a.Lng-p.Lng)*scale*EarthRadius, radians(a.Lat-p.Lat)*EarthRadius
+	bx, by := radians(b.Lng-p.Lng)*scale*EarthRadius, radians(b.Lat-p.Lat)*EarthRadius
+
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// DistanceToPolyline is the shortest distance from p to any part of the line,
+// +Inf for an empty line.
+func DistanceToPolyline(p Point, line []Point) float64 {
+	switch len(line) {
+	case 0:
+		return math.Inf(1)
+	case 1:
+		return Distance(p, line[0])
+	}
+
+	closest := math.Inf(1)
+	for i := 1; i < len(line); i++ {
+		closest = math.Min(closest, DistanceToSegment(p, line[i-1], line[i]))
+	}
+	return closest
+}
+
+// Simplify reduces the points of a line with the Douglas-Peucker algorithm.
+// No removed point is further than tolerance meters from the simplified line,
+// and the first and last points are always kept.
+func Simplify(line []Point, tolerance float64) []Point {
+	if len(line) < 3 {
+		return append([]Point(nil), line...)
+	}
+
+	keep := make([]bool, len(line))
+	keep[0], keep[len(line)-1] = true, true
+
+	// An explicit stack, long rides would recurse deeply.
+	stack := [][2]int{{0, len(line) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		furthest, index := 0.0, -1
+		for i := first + 1; i < last; i++ {
+			d := DistanceToSegment(line[i], line[first], line[last])
+			if d > furthest {
+				furthest, index = d, i
+			}
+		}
+		if index >= 0 && furthest > tolerance {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	simplified := make([]Point, 0, len(line)/4)
+	for i, p := range line {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+// Overlap is the fraction of line a, by length, that lies within tolerance
+// meters of line b. 1 means all of a was ridden along b, e.g. a segment that
+// is part of an activity. It is not symmetric, a short segment covers little
+// of a long activity.
+func Overlap(a, b []Point, tolerance float64) float64 {
+	total := Length(a)
+	if total == 0 || len(b) == 0 {
+		return 0
+	}
+
+	bounds := Bounds(b).Pad(tolerance)
+	// Sample a at least every half tolerance, so a gap in the overlap is
+	// never missed by more than that.
+	step := math.Max(tolerance/2, 1)
+
+	var covered float64
+	for i := 1; i < len(a); i++ {
+		from, to := a[i-1], a[i]
+		length := Distance(from, to)
+		if length == 0 {
+			continue
+		}
+		samples := int(math.Ceil(length / step))
+		for s := 0; s < samples; s++ {
+			// The middle of each piece stands in for the whole piece.
+			p := interpolate(from, to, (float64(s)+0.5)/float64(samples))
+			if bounds.Contains(p) && DistanceToPolyline(p, b) <= tolerance {
+				covered += length / float64(samples)
+			}
+		}
+	}
+	return math.Min(1, covered/total)
+}
+
+func interpolate(a, b Point, t float64) Point {
+	return Point{Lat: a.Lat + (b.Lat-a.Lat)*t, Lng: a.Lng + (b.Lng-a.Lng)*t}
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,110 @@
+package geo_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+func TestDistance(t *testing.T) {
+	t.Parallel()
+
+	// A degree of latitude is about 111.2km everywhere.
+	require.InDelta(t, 111195, geo.Distance(geo.Point{Lat: 30, Lng: -97}, geo.Point{Lat: 31, Lng: -97}), 1)
+	// A degree of longitude shrinks with latitude.
+	require.InDelta(t, 111195*math.Cos(60*math.Pi/180), geo.Distance(geo.Point{Lat: 60, Lng: 0}, geo.Point{Lat: 60, Lng: 1}), 10)
+	require.Zero(t, geo.Distance(geo.Point{Lat: 30, Lng: -97}, geo.Point{Lat: 30, Lng: -97}))
+
+	// Austin to Dallas is about 292km
+	austin := geo.Point{Lat: 30.2672, Lng: -97.7431}
+	dallas := geo.Point{Lat: 32.7767, Lng: -96.7970}
+	require.InDelta(t, 292000, geo.Distance(austin, dallas), 2000)
+	require.Equal(t, geo.Distance(austin, dallas), geo.Distance(dallas, austin))
+}
+
+func TestBounds(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, geo.BBox{}, geo.Bounds(nil))
+
+	b := geo.Bounds([]geo.Point{{Lat: 30.1, Lng: -97.9}, {Lat: 30.4, Lng: -97.7}, {Lat: 30.2, Lng: -97.8}})
+	require.Equal(t, geo.BBox{Min: geo.Point{Lat: 30.1, Lng: -97.9}, Max: geo.Point{Lat: 30.4, Lng: -97.7}}, b)
+	require.True(t, b.Contains(geo.Point{Lat: 30.2, Lng: -97.8}))
+	require.False(t, b.Contains(geo.Point{Lat: 30.5, Lng: -97.8}))
+	require.InDelta(t, 30.25, b.Center().Lat, 1e-9)
+
+	require.True(t, b.Intersects(geo.BBox{Min: geo.Point{Lat: 30.3, Lng: -97.75}, Max: geo.Point{Lat: 31, Lng: -97}}))
+	require.False(t, b.Intersects(geo.BBox{Min: geo.Point{Lat: 31, Lng: -97.75}, Max: geo.Point{Lat: 32, Lng: -97}}))
+
+	// A point 100m outside is inside the box padded by 100m.
+	outside := geo.Point{Lat: 30.4 + 90.0/111195, Lng: -97.7 + 90.0/(111195*math.Cos(30.4*math.Pi/180))}
+	require.False(t, b.Contains(outside))
+	require.True(t, b.Pad(100).Contains(outside))
+}
+
+func TestDistanceToPolyline(t *testing.T) {
+	t.Parallel()
+
+	line := []geo.Point{{Lat: 30, Lng: -97}, {Lat: 30, Lng: -96.99}}
+	// 0.0001 degrees of latitude is about 11m, beside the middle of the line.
+	require.InDelta(t, 11.12, geo.DistanceToPolyline(geo.Point{Lat: 30.0001, Lng: -96.995}, line), 0.05)
+	// Past the end, the distance is to the end point.
+	past := geo.Point{Lat: 30, Lng: -96.98}
+	require.InDelta(t, geo.Distance(past, line[1]), geo.DistanceToPolyline(past, line), 0.05)
+
+	require.True(t, math.IsInf(geo.DistanceToPolyline(past, nil), 1))
+	require.Equal(t, geo.Distance(past, line[0]), geo.DistanceToPolyline(past, line[:1]))
+
+	f := loadFixture(t, "segment.json")
+	points := decode(t, f.Map.Polyline)
+	for _, p := range points {
+		require.Zero(t, geo.DistanceToPolyline(p, points))
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	t.Parallel()
+
+	// Points on a straight line are all removed.
+	straight := []geo.Point{{Lat: 30, Lng: -97}, {Lat: 30, Lng: -96.999}, {Lat: 30, Lng: -96.998}}
+	require.Equal(t, []geo.Point{straight[0], straight[2]}, geo.Simplify(straight, 1))
+	require.Len(t, geo.Simplify(straight[:2], 1), 2)
+
+	f := loadFixture(t, "route.json")
+	points := decode(t, f.Map.Polyline)
+	for _, tolerance := range []float64{5, 25, 100} {
+		simplified := geo.Simplify(points, tolerance)
+		require.Less(t, len(simplified), len(points))
+		require.Equal(t, points[0], simplified[0])
+		require.Equal(t, points[len(points)-1], simplified[len(simplified)-1])
+		// No point is lost further than the tolerance.
+		for _, p := range points {
+			require.LessOrEqual(t, geo.DistanceToPolyline(p, simplified), tolerance+0.01)
+		}
+	}
+	require.Less(t, len(geo.Simplify(points, 100)), len(geo.Simplify(points, 5)))
+}
+
+func TestOverlap(t *testing.T) {
+	t.Parallel()
+
+	segment := decode(t, loadFixture(t, "segment.json").Map.Polyline)
+	route := loadFixture(t, "route.json")
+	activity := decode(t, loadFixture(t, "activity.json").Map.Polyline)
+
+	// The Terrace Mountain segment is part of the route.
+	require.InDelta(t, 1, geo.Overlap(segment, decode(t, route.Map.Polyline), 25), 0.01)
+	// Summary polylines are coarser, a bigger tolerance makes up for it.
+	require.Greater(t, geo.Overlap(segment, decode(t, route.Map.SummaryPolyline), 50), 0.95)
+	// The activity never goes near it.
+	require.Zero(t, geo.Overlap(segment, activity, 25))
+	// The segment is a tiny part of the whole route.
+	require.Less(t, geo.Overlap(decode(t, route.Map.Polyline), segment, 25), 0.05)
+
+	require.Equal(t, 1.0, geo.Overlap(segment, segment, 1))
+	require.Zero(t, geo.Overlap(segment, nil, 25))
+	require.Zero(t, geo.Overlap(nil, segment, 25))
+}
@@ -0,0 +1,106 @@
+package geo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GeoJSON types, as far as lines need them.
+// https://datatracker.ietf.org/doc/html/rfc7946
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	BBox     []float64 `json:"bbox,omitempty"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string    `json:"type"`
+	ID         string    `json:"id,omitempty"`
+	BBox       []float64 `json:"bbox,omitempty"`
+	Geometry   Geometry  `json:"geometry"`
+	Properties any       `json:"properties"`
+}
+
+type Geometry struct {
+	Type string `json:"type"`
+	// Coordinates are [lng, lat] pairs, GeoJSON puts longitude first.
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+// NewFeatureCollection collects features, bounded by all of them.
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	fc := FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]Feature, 0, len(features)),
+	}
+
+	var points []Point
+	for _, f := range features {
+		fc.Features = append(fc.Features, f)
+		for _, c := range f.Geometry.Coordinates {
+			points = append(points, Point{Lat: c[1], Lng: c[0]})
+		}
+	}
+	if len(points) > 0 {
+		fc.BBox = Bounds(points).GeoJSON()
+	}
+	return fc
+}
+
+// LineStringFeature is a feature of a single line.
+func LineStringFeature(id string, line []Point, properties any) Feature {
+	coords := make([][2]float64, 0, len(line))
+	for _, p := range line {
+		coords = append(coords, [2]float64{p.Lng, p.Lat})
+	}
+
+	f := Feature{
+		Type: "Feature",
+		ID:   id,
+		Geometry: Geometry{
+			Type:        "LineString",
+			Coordinates: coords,
+		},
+		Properties: properties,
+	}
+	if len(line) > 0 {
+		f.BBox = Bounds(line).GeoJSON()
+	}
+	return f
+}
+
+// GeoJSON returns the box in GeoJSON order, [west, south, east, north].
+func (b BBox) GeoJSON() []float64 {
+	return []float64{b.Min.Lng, b.Min.Lat, b.Max.Lng, b.Max.Lat}
+}
+
+// ParseBBox parses a box in GeoJSON order, "west,south,east,north".
+func ParseBBox(s string) (BBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BBox{}, fmt.Errorf("bbox must be 4 comma separated numbers, west,south,east,north")
+	}
+
+	var values [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BBox{}, fmt.Errorf("bbox value %d: %w", i+1, err)
+		}
+		values[i] = v
+	}
+
+	b := BBox{
+		Min: Point{Lng: values[0], Lat: values[1]},
+		Max: Point{Lng: values[2], Lat: values[3]},
+	}
+	if b.Min.Lat > b.Max.Lat || b.Min.Lng > b.Max.Lng {
+		return BBox{}, fmt.Errorf("bbox west,south must be less than east,north")
+	}
+	if b.Min.Lat < -90 || b.Max.Lat > 90 || b.Min.Lng < -180 || b.Max.Lng > 180 {
+		return BBox{}, fmt.Errorf("bbox is outside of the world")
+	}
+	return b, nil
+}
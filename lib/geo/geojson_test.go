@@ -0,0 +1,62 @@
+package geo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+func TestLineStringFeature(t *testing.T) {
+	t.Parallel()
+
+	line := []geo.Point{{Lat: 30.1, Lng: -97.9}, {Lat: 30.4, Lng: -97.7}}
+	fc := geo.NewFeatureCollection(
+		geo.LineStringFeature("1", line, map[string]string{"name": "Jester"}),
+		geo.LineStringFeature("2", []geo.Point{{Lat: 30.5, Lng: -97.6}}, nil),
+	)
+
+	data, err := json.Marshal(fc)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "FeatureCollection",
+		"bbox": [-97.9, 30.1, -97.6, 30.5],
+		"features": [
+			{
+				"type": "Feature",
+				"id": "1",
+				"bbox": [-97.9, 30.1, -97.7, 30.4],
+				"geometry": {"type": "LineString", "coordinates": [[-97.9, 30.1], [-97.7, 30.4]]},
+				"properties": {"name": "Jester"}
+			},
+			{
+				"type": "Feature",
+				"id": "2",
+				"bbox": [-97.6, 30.5, -97.6, 30.5],
+				"geometry": {"type": "LineString", "coordinates": [[-97.6, 30.5]]},
+				"properties": null
+			}
+		]
+	}`, string(data))
+
+	// An empty collection still has a features list.
+	data, err = json.Marshal(geo.NewFeatureCollection())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, string(data))
+}
+
+func TestParseBBox(t *testing.T) {
+	t.Parallel()
+
+	b, err := geo.ParseBBox("-97.9, 30.1,-97.7,30.4")
+	require.NoError(t, err)
+	require.Equal(t, geo.BBox{Min: geo.Point{Lat: 30.1, Lng: -97.9}, Max: geo.Point{Lat: 30.4, Lng: -97.7}}, b)
+	require.Equal(t, []float64{-97.9, 30.1, -97.7, 30.4}, b.GeoJSON())
+
+	for _, bad := range []string{"", "1,2,3", "a,1,2,3", "-97.7,30.1,-97.9,30.4", "0,-91,1,1"} {
+		_, err := geo.ParseBBox(bad)
+		require.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,84 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DecodePolyline decodes a Google encoded polyline, the format of Strava's
+// `polyline` and `summary_polyline` fields. Malformed input returns the points
+// decoded so far along with an error.
+// https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+func DecodePolyline(encoded string) ([]Point, error) {
+	var (
+		points   []Point
+		lat, lng int64
+		i        int
+	)
+	next := func() (int64, error) {
+		var result int64
+		var shift uint
+		for i < len(encoded) {
+			b := int64(encoded[i]) - 63
+			if b < 0 || b > 0x3f {
+				return 0, fmt.Errorf("invalid character %q at %d", encoded[i], i)
+			}
+			i++
+			result |= (b & 0x1f) << shift
+			shift += 5
+			if b < 0x20 {
+				if result&1 != 0 {
+					return ^(result >> 1), nil
+				}
+				return result >> 1, nil
+			}
+			if shift > 60 {
+				return 0, fmt.Errorf("value too long at %d", i)
+			}
+		}
+		return 0, fmt.Errorf("unexpected end of polyline")
+	}
+
+	for i < len(encoded) {
+		dLat, err := next()
+		if err != nil {
+			return points, err
+		}
+		dLng, err := next()
+		if err != nil {
+			return points, err
+		}
+		lat += dLat
+		lng += dLng
+		points = append(points, Point{Lat: float64(lat) / 1e5, Lng: float64(lng) / 1e5})
+	}
+	return points, nil
+}
+
+// EncodePolyline encodes points as a Google polyline. Coordinates are rounded
+// to 5 decimal places, about a meter.
+func EncodePolyline(points []Point) string {
+	var b strings.Builder
+	var prevLat, prevLng int64
+	for _, p := range points {
+		lat := int64(math.Round(p.Lat * 1e5))
+		lng := int64(math.Round(p.Lng * 1e5))
+		encodeValue(&b, lat-prevLat)
+		encodeValue(&b, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return b.String()
+}
+
+func encodeValue(b *strings.Builder, v int64) {
+	u := v << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		b.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	b.WriteByte(byte(u + 63))
+}
@@ -0,0 +1,123 @@
+package geo_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Emyrk/strava/lib/geo"
+)
+
+// fixture is the part of a Strava api response with a map.
+type fixture struct {
+	Distance    float64   `json:"distance"`
+	StartLatLng []float64 `json:"start_latlng"`
+	EndLatLng   []float64 `json:"end_latlng"`
+	Map         struct {
+		Polyline        string `json:"polyline"`
+		SummaryPolyline string `json:"summary_polyline"`
+	} `json:"map"`
+}
+
+func loadFixture(t *testing.T, name string) fixture {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "strava", "testdata", name))
+	require.NoError(t, err)
+	var f fixture
+	require.NoError(t, json.Unmarshal(data, &f))
+	return f
+}
+
+func decode(t *testing.T, encoded string) []geo.Point {
+	t.Helper()
+
+	points, err := geo.DecodePolyline(encoded)
+	require.NoError(t, err)
+	return points
+}
+
+func TestDecodePolyline(t *testing.T) {
+	t.Parallel()
+
+	// The example from Google's polyline documentation.
+	points := decode(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@")
+	require.Equal(t, []geo.Point{
+		{Lat: 38.5, Lng: -120.2},
+		{Lat: 40.7, Lng: -120.95},
+		{Lat: 43.252, Lng: -126.453},
+	}, points)
+
+	points, err := geo.DecodePolyline("")
+	require.NoError(t, err)
+	require.Empty(t, points)
+}
+
+func TestDecodePolylineMalformed(t *testing.T) {
+	t.Parallel()
+
+	// Cut off in the middle of the third point.
+	points, err := geo.DecodePolyline("_p~iF~ps|U_ulLnnqC_mqN")
+	require.Error(t, err)
+	require.Len(t, points, 2)
+
+	_, err = geo.DecodePolyline("_p~iF~ps|U\n")
+	require.Error(t, err)
+}
+
+func TestEncodePolyline(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", geo.EncodePolyline([]geo.Point{
+		{Lat: 38.5, Lng: -120.2},
+		{Lat: 40.7, Lng: -120.95},
+		{Lat: 43.252, Lng: -126.453},
+	}))
+	require.Equal(t, "", geo.EncodePolyline(nil))
+	// Rounded to 5 decimal places
+	require.Equal(t, geo.EncodePolyline([]geo.Point{{Lat: 1.000004, Lng: 2}}), geo.EncodePolyline([]geo.Point{{Lat: 1, Lng: 2}}))
+}
+
+func TestPolylineFixtures(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"activity.json", "route.json", "segment.json"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f := loadFixture(t, name)
+			for _, encoded := range []string{f.Map.Polyline, f.Map.SummaryPolyline} {
+				points := decode(t, encoded)
+				// Strava's own polylines survive a round trip exactly.
+				require.Equal(t, encoded, geo.EncodePolyline(points))
+			}
+
+			points := decode(t, f.Map.Polyline)
+			require.NotEmpty(t, points)
+			// The full polyline is within a couple percent of Strava's distance.
+			require.InEpsilon(t, f.Distance, geo.Length(points), 0.02)
+			if len(f.StartLatLng) == 2 {
+				start := geo.Point{Lat: f.StartLatLng[0], Lng: f.StartLatLng[1]}
+				end := geo.Point{Lat: f.EndLatLng[0], Lng: f.EndLatLng[1]}
+				// Activity start points are rounded to 2 decimal places.
+				require.Less(t, geo.Distance(start, points[0]), 1000.0)
+				require.Less(t, geo.Distance(end, points[len(points)-1]), 1000.0)
+			}
+		})
+	}
+}
+
+func TestSegmentFixtureEnds(t *testing.T) {
+	t.Parallel()
+
+	f := loadFixture(t, "segment.json")
+	points := decode(t, f.Map.Polyline)
+	start := geo.Point{Lat: f.StartLatLng[0], Lng: f.StartLatLng[1]}
+	end := geo.Point{Lat: f.EndLatLng[0], Lng: f.EndLatLng[1]}
+	require.Less(t, geo.Distance(start, points[0]), 5.0)
+	require.Less(t, geo.Distance(end, points[len(points)-1]), 5.0)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"html"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Meta is the link preview of a page. Crawlers do not run javascript, so
+// these are rendered into the html before it is served.
+type Meta struct {
+	Title       string
+	Description string
+	// Image is an absolute url, empty for no image.
+	Image string
+	// URL is the canonical absolute url of the page, empty to omit.
+	URL string
+}
+
+// DefaultMeta is used for any page without a more specific preview.
+var DefaultMeta = Meta{
+	Title:       "Das Hugel",
+	Description: "Tour Das Hugel",
+}
+
+// MetaLookup finds the link preview of a page from its path, e.g.
+// "/athlete/123". False means the page has no specific preview.
+type MetaLookup interface {
+	PageMeta(ctx context.Context, path string) (Meta, bool)
+}
+
+// Tags renders the meta tags of the page. All values are html escaped, they
+// come from user controlled data like athlete names.
+func (m Meta) Tags() string {
+	var b strings.Builder
+	tag := func(attr, key, value string) {
+		if value == "" {
+			return
+		}
+		b.WriteString(`<meta ` + attr + `="` + key + `" content="` + html.EscapeString(value) + `" />` + "\n")
+	}
+
+	tag("name", "description", m.Description)
+	tag("property", "og:type", "website")
+	tag("property", "og:site_name", DefaultMeta.Title)
+	tag("property", "og:title", m.Title)
+	tag("property", "og:description", m.Description)
+	tag("property", "og:url", m.URL)
+	tag("property", "og:image", m.Image)
+	card := "summary"
+	if m.Image != "" {
+		card = "summary_large_image"
+	}
+	tag("name", "twitter:card", card)
+	tag("name", "twitter:title", m.Title)
+	tag("name", "twitter:description", m.Description)
+	tag("name", "twitter:image", m.Image)
+	return b.String()
+}
+
+// metaCache remembers lookups, so a burst of crawlers does not mean a burst
+// of database queries.
+type metaCache struct {
+	lookup MetaLookup
+	ttl    time.Duration
+	max    int
+
+	mu      sync.Mutex
+	entries map[string]metaEntry
+}
+
+type metaEntry struct {
+	meta    Meta
+	ok      bool
+	fetched time.Time
+}
+
+func newMetaCache(lookup MetaLookup, ttl time.Duration, max int) *metaCache {
+	return &metaCache{
+		lookup:  lookup,
+		ttl:     ttl,
+		max:     max,
+		entries: make(map[string]metaEntry),
+	}
+}
+
+func (c *metaCache) PageMeta(ctx context.Context, path string) Meta {
+	if c == nil || c.lookup == nil {
+		return DefaultMeta
+	}
+	path = "/" + strings.Trim(path, "/")
+
+	c.mu.Lock()
+	e, hit := c.entries[path]
+	c.mu.Unlock()
+	if !hit || time.Since(e.fetched) > c.ttl {
+		meta, ok := c.lookup.PageMeta(ctx, path)
+		e = metaEntry{meta: meta, ok: ok, fetched: time.Now()}
+
+		c.mu.Lock()
+		// Paths come from the request, so bound the cache. Starting over
+		// is simpler than tracking usage, and lookups are cheap enough.
+		if len(c.entries) >= c.max {
+			c.entries = make(map[string]metaEntry)
+		}
+		c.entries[path] = e
+		c.mu.Unlock()
+	}
+
+	if !e.ok {
+		return DefaultMeta
+	}
+	if e.meta.Title == "" {
+		e.meta.Title = DefaultMeta.Title
+	}
+	if e.meta.Description == "" {
+		e.meta.Description = DefaultMeta.Description
+	}
+	return e.meta
+}
@@ -0,0 +1,57 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	server "github.com/Emyrk/strava/site"
+)
+
+type fakeLookup map[string]server.Meta
+
+func (f fakeLookup) PageMeta(_ context.Context, path string) (server.Meta, bool) {
+	m, ok := f[path]
+	return m, ok
+}
+
+func TestHandlerMeta(t *testing.T) {
+	t.Parallel()
+
+	siteFS := fstest.MapFS{
+		"index.html":    {Data: []byte(`<head><title>{{ .Title }}</title>{{ .MetaTags }}</head>`)},
+		"static/app.js": {Data: []byte(`console.log("hi")`)},
+	}
+	h := server.Handler(siteFS, fakeLookup{
+		"/athlete/1": {
+			Title:       `<script>alert("x")</script>`,
+			Description: `Steven "Hugel" & co`,
+			Image:       "https://example.com/a.png?x=1&y=2",
+		},
+	})
+
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	body := get("/athlete/1")
+	require.NotContains(t, body, "<script>")
+	require.Contains(t, body, `<title>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</title>`)
+	require.Contains(t, body, `<meta property="og:description" content="Steven &#34;Hugel&#34; &amp; co" />`)
+	require.Contains(t, body, `<meta property="og:image" content="https://example.com/a.png?x=1&amp;y=2" />`)
+	require.Contains(t, body, `<meta name="twitter:card" content="summary_large_image" />`)
+
+	body = get("/hugelboard/2024")
+	require.Contains(t, body, "<title>"+server.DefaultMeta.Title+"</title>")
+	require.NotContains(t, body, "og:image")
+
+	// Static files are untouched
+	require.Equal(t, `console.log("hi")`, get("/static/app.js"))
+}
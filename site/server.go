@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"io"
 	"io/fs"
 	"net/http"
@@ -21,9 +22,12 @@ type handler struct {
 	fs            fs.FS
 	mux           *http.ServeMux
 	htmlTemplates *template.Template
+	meta          *metaCache
 }
 
-func Handler(siteFS fs.FS) http.Handler {
+// Handler serves the site. If meta is not nil, it is used to render link
+// previews of pages.
+func Handler(siteFS fs.FS, meta MetaLookup) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.FS(siteFS)))
 
@@ -36,6 +40,7 @@ func Handler(siteFS fs.FS) http.Handler {
 		fs:            siteFS,
 		mux:           mux,
 		htmlTemplates: tmpls,
+		meta:          newMetaCache(meta, time.Minute*10, 1000),
 	}
 }
 
@@ -43,13 +48,16 @@ func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	// reqFile is the static file requested
 	reqFile := filePath(req.URL.Path)
 
+	// pagePath is the page being viewed, the request path is rewritten
+	// below when falling back to the index.
+	pagePath := req.URL.Path
 	state := htmlState{
 		GitCommit: version.GitCommit,
 		GitTag:    version.GitTag,
 		BuildTime: version.BuildTime,
 	}
 
-	if h.serveHTML(resp, req, reqFile, state) {
+	if h.serveHTML(resp, req, reqFile, pagePath, state) {
 		return
 	}
 
@@ -64,7 +72,7 @@ func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	req.URL.Path = strings.TrimSuffix(req.URL.Path, "/")
 	req.URL.Path += ".html"
 	reqFile = filePath(req.URL.Path)
-	if h.serveHTML(resp, req, reqFile, state) {
+	if h.serveHTML(resp, req, reqFile, pagePath, state) {
 		return
 	}
 
@@ -74,7 +82,7 @@ func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	}
 
 	req.URL.Path = "/"
-	if h.serveHTML(resp, req, "", state) {
+	if h.serveHTML(resp, req, "", pagePath, state) {
 		return
 	}
 
@@ -94,9 +102,23 @@ type htmlState struct {
 	GitCommit string
 	GitTag    string
 	BuildTime string
+	// Title and MetaTags are already html escaped, text/template does not
+	// escape anything.
+	Title    string
+	MetaTags string
 }
 
-func (h *handler) serveHTML(resp http.ResponseWriter, request *http.Request, reqPath string, state htmlState) bool {
+func (h *handler) serveHTML(resp http.ResponseWriter, request *http.Request, reqPath string, pagePath string, state htmlState) bool {
+	if h.htmlTemplates.Lookup(templateName(reqPath)) == nil {
+		return false
+	}
+
+	// Only look up the page once it is known to be html, static assets do
+	// not need link previews.
+	meta := h.meta.PageMeta(request.Context(), pagePath)
+	state.Title = html.EscapeString(meta.Title)
+	state.MetaTags = meta.Tags()
+
 	if data, err := h.renderHTMLWithState(reqPath, state); err == nil {
 		if reqPath == "" {
 			// Pass "index.html" to the ServeContent so the ServeContent sets the right content headers.
@@ -112,9 +134,7 @@ func (h *handler) serveHTML(resp http.ResponseWriter, request *http.Request, req
 // as a template. If it does not, it will return an error.
 func (h *handler) renderHTMLWithState(filePath string, state htmlState) ([]byte, error) {
 	var buf bytes.Buffer
-	if filePath == "" {
-		filePath = "index.html"
-	}
+	filePath = templateName(filePath)
 	tmpl := h.htmlTemplates.Lookup(filePath)
 	if tmpl == nil {
 		return nil, xerrors.Errorf("template %q not found", filePath)
@@ -172,6 +192,14 @@ func findAndParseHTMLFiles(files fs.FS) (*template.Template, error) {
 	return root, nil
 }
 
+// templateName is the name of the template of a file, the index if empty.
+func templateName(filePath string) string {
+	if filePath == "" {
+		return "index.html"
+	}
+	return filePath
+}
+
 // filePath returns the filepath of the requested file.
 func filePath(p string) string {
 	if !strings.HasPrefix(p, "/") {
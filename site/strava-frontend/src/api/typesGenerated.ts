@@ -1,5 +1,28 @@
 // Code generated by 'guts'. DO NOT EDIT.
 
+// From modelsdk/map.go
+export interface ActivityFeatureEffort {
+    segment_id: string;
+    name: string;
+    start_date: string;
+    elapsed_time: number;
+    moving_time: number;
+}
+
+// From modelsdk/map.go
+export interface ActivityFeatureProperties {
+    id: string;
+    athlete_id: string;
+    name: string;
+    sport_type: string;
+    start_date: string;
+    distance: number;
+    moving_time: number;
+    elapsed_time: number;
+    total_elevation_gain: number;
+    efforts: ActivityFeatureEffort[];
+}
+
 // From modelsdk/athlete.go
 export interface ActivitySummary {
     activity_id: string;
@@ -69,6 +92,48 @@ export interface AthleteSyncSummary {
     total_detail: number;
 }
 
+// From modelsdk/calendar.go
+export interface Calendar {
+    athlete_id: string;
+    ftp: number;
+    from: string;
+    to: string;
+    days: CalendarDay[];
+}
+
+// From modelsdk/calendar.go
+export interface CalendarDay {
+    date: string;
+    activities: number;
+    distance: number;
+    elevation: number;
+    moving_time: number;
+    kilojoules: number;
+    suffer_score: number;
+    average_heartrate: number;
+    stress: number;
+    atl: number;
+    ctl: number;
+    tsb: number;
+}
+
+// From modelsdk/route.go
+export interface ClimbComparison {
+    segment: SegmentSummary;
+    a: SegmentEffort;
+    b: SegmentEffort;
+    delta: number;
+    cumulative_delta: number;
+    watts_delta?: number;
+}
+
+// From modelsdk/athlete.go
+export interface ClimbPower {
+    vam?: number;
+    watts_per_kg?: number;
+    power_source?: string;
+}
+
 export type Comparable = string | number | boolean;
 
 // From modelsdk/route.go
@@ -119,8 +184,83 @@ export interface Eddington {
     athlete_id: number;
     miles_histogram: number[];
     current_eddington: number;
+    kilometers_histogram: number[];
+    current_eddington_km: number;
     last_calculated: string;
     total_activities: number;
+    total_days: number;
+    breakdowns: EddingtonBreakdown[];
+    metrics: EddingtonMetric[];
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonBreakdown {
+    sport: string;
+    year: number;
+    days: number;
+    miles_histogram: number[];
+    miles: number;
+    kilometers_histogram: number[];
+    kilometers: number;
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonGoal {
+    eddington: number;
+    have: number;
+    need: number;
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonHistory {
+    athlete_id: number;
+    unit: string;
+    current: number;
+    milestones: EddingtonMilestone[];
+    plan: EddingtonGoal[];
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonLeaderboard {
+    units: string;
+    year: number;
+    total: number;
+    page: number;
+    limit: number;
+    entries: EddingtonLeaderboardEntry[];
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonLeaderboardEntry {
+    rank: number;
+    athlete: MinAthlete;
+    city: string;
+    eddington: number;
+    days: number;
+    total_activities: number;
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonMetric {
+    metric: string;
+    days: number;
+    histogram: number[];
+    eddington: number;
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonMetricRank {
+    rank: number;
+    athlete_id: string;
+    eddington: number;
+    days: number;
+}
+
+// From modelsdk/athlete.go
+export interface EddingtonMilestone {
+    eddington: number;
+    date: string;
+    activity_id: string;
 }
 
 // From modelsdk/athlete.go
@@ -129,15 +269,84 @@ export interface EddingtonShort {
     total_activities: number;
 }
 
+// From modelsdk/grouprides.go
+export interface EventGroupRides {
+    route_name: string;
+    edition: string;
+    computed_at: string;
+    groups: GroupRide[];
+}
+
+// From modelsdk/route.go
+export interface GPSSegmentEffort {
+    segment_id: string;
+    source: string;
+    start_date: string;
+    elapsed_time: number;
+    distance: number;
+    coverage: number;
+}
+
+// From modelsdk/grouprides.go
+export interface GroupRide {
+    riders: number;
+    start: string;
+    best_rank: number;
+    fastest_time: number;
+    slowest_time: number;
+    average_time: number;
+    members: GroupRideMember[];
+}
+
+// From modelsdk/grouprides.go
+export interface GroupRideMember {
+    activity_id: string;
+    rank: number;
+    elapsed: number;
+    athlete: MinAthlete;
+}
+
+// From modelsdk/map.go
+export interface Heatmap {
+    route_name: string;
+    bbox: number[];
+    width: number;
+    height: number;
+    cells: number[];
+    max: number;
+    activities: number;
+    computed_at: string;
+}
+
+// From modelsdk/map.go
+export interface HeatmapOptOut {
+    opt_out: boolean;
+}
+
+// From modelsdk/route.go
+export interface HistoricalSegmentEffort {
+    effort_id: string;
+    activity_id: string;
+    start_date: string;
+    start_date_local: string;
+    elapsed_time: number;
+    moving_time: number;
+    device_watts: boolean;
+    average_watts: number;
+    pr_rank?: number;
+    kom_rank?: number;
+}
+
 // From modelsdk/athlete.go
 export interface HugelLeaderBoard {
     personal_best?: HugelLeaderBoardActivity;
     superlatives: SuperlativeList;
     activities: HugelLeaderBoardActivity[];
+    ranked_by: string;
 }
 
 // From modelsdk/athlete.go
-export interface HugelLeaderBoardActivity {
+export interface HugelLeaderBoardActivity extends ClimbPower {
     rank_one_elapsed: number;
     activity_id: string;
     athlete_id: string;
@@ -153,6 +362,13 @@ export interface HugelLeaderBoardActivity {
     activity_total_elevation_gain: number;
     activity_suffer_score: number;
     activity_achievement_count: number;
+    pacing?: Pacing;
+    rode_with?: string[];
+}
+
+// From modelsdk/athlete.go
+export interface LeaderboardOptOut {
+    opt_out: boolean;
 }
 
 // From modelsdk/map.go
@@ -174,6 +390,65 @@ export interface MinAthlete {
     hugel_count: number;
 }
 
+// From modelsdk/route.go
+export interface MissingSegment {
+    segment: SegmentSummary;
+    gps_match?: GPSSegmentEffort;
+    explanation: string;
+}
+
+// From modelsdk/route.go
+export interface MissingSegmentsResponse {
+    activity_id: string;
+    segments: MissingSegment[];
+}
+
+// From modelsdk/route.go
+export interface NearbySegment {
+    id: string;
+    name: string;
+    activity_type: string;
+    distance: number;
+    average_grade: number;
+    maximum_grade: number;
+    elevation_high: number;
+    elevation_low: number;
+    start_latlng: number[];
+    end_latlng: number[];
+    climb_category: number;
+    city: string;
+    state: string;
+    country: string;
+    hazardous: boolean;
+    distance_away: number;
+}
+
+// From modelsdk/pacing.go
+export interface Pacing {
+    climbing_time: number;
+    transition_time: number;
+    fatigue_index: number;
+    fastest?: PacingClimb;
+    slowest?: PacingClimb;
+    climbs: PacingClimb[];
+    transitions: PacingTransition[];
+}
+
+// From modelsdk/pacing.go
+export interface PacingClimb {
+    segment_id: string;
+    segment_name?: string;
+    elapsed: number;
+    relative: number;
+}
+
+// From modelsdk/pacing.go
+export interface PacingTransition {
+    from_segment_id: string;
+    to_segment_id: string;
+    seconds: number;
+}
+
 // From modelsdk/route.go
 export interface PersonalBestSegmentEffort {
     best_effort_id: string;
@@ -191,6 +466,7 @@ export interface PersonalSegment {
     detailed_segment: DetailedSegment;
     starred?: boolean;
     personal_best?: PersonalBestSegmentEffort;
+    prediction?: SegmentPrediction;
 }
 
 // From modelsdk/response.go
@@ -199,8 +475,54 @@ export interface Response {
     detail?: string;
 }
 
+// From modelsdk/route.go
+export interface RouteComparison {
+    a: RouteComparisonResult;
+    b: RouteComparisonResult;
+    climbs: ClimbComparison[];
+    transitions: TransitionComparison[];
+    only_a: SegmentSummary[];
+    only_b: SegmentSummary[];
+    total_delta: number;
+    heartrate_delta?: number;
+    watts_delta?: number;
+}
+
+// From modelsdk/route.go
+export interface RouteComparisonResult extends ClimbPower {
+    route_name: string;
+    activity_id: string;
+    activity_name: string;
+    start_date: string;
+    elapsed: number;
+    average_heartrate: number;
+    device_watts: boolean;
+    average_watts: number;
+    athlete: MinAthlete;
+}
+
+// From modelsdk/pacing.go
+export interface RoutePacing extends Pacing {
+    route_name: string;
+    edition: string;
+    activity_id: string;
+    athlete_id: string;
+    finishers: number;
+}
+
+// From modelsdk/route.go
+export interface RoutePrediction {
+    route_name: string;
+    segments: SegmentPrediction[];
+    missing: SegmentSummary[];
+    complete: boolean;
+    predicted: number;
+    low: number;
+    high: number;
+}
+
 // From modelsdk/athlete.go
-export interface SegmentEffort {
+export interface SegmentEffort extends ClimbPower {
     activity_id: string;
     effort_id: string;
     start_date: string;
@@ -211,12 +533,82 @@ export interface SegmentEffort {
     average_watts: number;
 }
 
+// From modelsdk/route.go
+export interface SegmentEffortHistory {
+    athlete_id: string;
+    segment_id: string;
+    routes: string[];
+    efforts: HistoricalSegmentEffort[];
+    elapsed_trend?: SegmentTrendLine;
+    watts_trend?: SegmentTrendLine;
+    season_bests: SegmentSeasonBest[];
+    form: SegmentForm;
+}
+
+// From modelsdk/map.go
+export interface SegmentFeatureProperties {
+    id: string;
+    name: string;
+    friendly_name: string;
+    distance: number;
+    average_grade: number;
+    maximum_grade: number;
+    elevation_high: number;
+    elevation_low: number;
+    total_elevation_gain: number;
+    climb_category: number;
+    total_effort_count: number;
+    personal_best_elapsed_time?: number;
+}
+
+// From modelsdk/route.go
+export interface SegmentForm {
+    level: string;
+    pr: number;
+    recent_best: number;
+    pace: number;
+    recent: number;
+}
+
+// From modelsdk/route.go
+export interface SegmentPrediction {
+    segment_id: string;
+    pr: number;
+    recent: number;
+    efforts: number;
+    predicted: number;
+    low: number;
+    high: number;
+}
+
+// From modelsdk/route.go
+export interface SegmentSeasonBest {
+    year: number;
+    effort_id: string;
+    elapsed_time: number;
+    date: string;
+}
+
 // From modelsdk/route.go
 export interface SegmentSummary {
     id: string;
     name: string;
 }
 
+// From modelsdk/route.go
+export interface SegmentTrendLine {
+    start: string;
+    start_value: number;
+    end: string;
+    end_value: number;
+    per_day: number;
+}
+
+// From modelsdk/route.go
+export interface SetRouteReferenceRequest {
+    activity_id: string;
+}
+
 // From sdktype/int.go
 export type StringInt = number;
 
@@ -259,6 +651,8 @@ export interface SuperlativeList {
     most_achievements: SuperlativeEntry<number>;
     longest_ride: SuperlativeEntry<number>;
     shortest_ride: SuperlativeEntry<number>;
+    least_transition: SuperlativeEntry<number>;
+    strongest_finish: SuperlativeEntry<number>;
 }
 
 // From modelsdk/athlete.go
@@ -268,9 +662,104 @@ export interface SyncActivitySummary {
     synced_at: string;
 }
 
+// From modelsdk/route.go
+export interface TransitionComparison {
+    from: SegmentSummary;
+    to: SegmentSummary;
+    a?: number;
+    b?: number;
+    delta?: number;
+}
+
+// From modelsdk/route.go
+export interface UnofficialRouteResult {
+    route_name: string;
+    activity_id: string;
+    activity_name: string;
+    start_date: string;
+    elapsed: number;
+    gps_segments: string[];
+    athlete: MinAthlete;
+    unofficial: boolean;
+}
+
 // From modelsdk/route.go
 export interface VerifyRouteResponse {
     missing_segments: SegmentSummary[];
 }
 
+// From modelsdk/yearreview.go
+export interface YearActivity {
+    activity_id: string;
+    name: string;
+    start_date_local: string;
+    sport_type: string;
+    distance: number;
+    elevation: number;
+}
+
+// From modelsdk/yearreview.go
+export interface YearDay {
+    date: string;
+    elevation: number;
+    activities: number;
+}
+
+// From modelsdk/yearreview.go
+export interface YearEddington {
+    start: number;
+    end: number;
+    year: number;
+    milestones: EddingtonMilestone[];
+}
+
+// From modelsdk/yearreview.go
+export interface YearHugel {
+    route_name: string;
+    title: string;
+    lite: boolean;
+    activity_id: string;
+    rank: number;
+    finishers: number;
+    total_time_seconds: number;
+    superlatives: string[];
+}
+
+// From modelsdk/yearreview.go
+export interface YearReview {
+    athlete_id: string;
+    year: number;
+    computed_at: string;
+    totals: YearTotals;
+    sports: YearTotals[];
+    longest_ride: YearActivity | null;
+    biggest_climb_day: YearDay | null;
+    segments: YearSegment[];
+    eddington: YearEddington;
+    hugels: YearHugel[];
+    shared: boolean;
+}
+
+// From modelsdk/yearreview.go
+export interface YearReviewShare {
+    shared: boolean;
+}
+
+// From modelsdk/yearreview.go
+export interface YearSegment {
+    segment_id: string;
+    name: string;
+    efforts: number;
+    best_time: number;
+}
+
+// From modelsdk/yearreview.go
+export interface YearTotals {
+    sport: string;
+    activities: number;
+    distance: number;
+    elevation: number;
+    moving_time: number;
+}
+
 
@@ -75,6 +75,21 @@ func (c *Client) GetActivity(ctx context.Context, activityID int64, includeEffor
 	return activity, c.DecodeResponse(resp, &activity, http.StatusOK)
 }
 
+// GetActivityStreams returns the recorded streams of an activity, e.g. "time"
+// and "latlng". Streams the device did not record are left nil.
+func (c *Client) GetActivityStreams(ctx context.Context, activityID int64, keys ...string) (StreamSet, error) {
+	resp, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/activities/%d/streams", activityID), nil, url.Values{
+		"keys":        []string{strings.Join(keys, ",")},
+		"key_by_type": []string{"true"},
+	})
+	if err != nil {
+		return StreamSet{}, fmt.Errorf("request: %w", err)
+	}
+
+	var streams StreamSet
+	return streams, c.DecodeResponse(resp, &streams, http.StatusOK)
+}
+
 func (c *Client) GetAuthenticatedAthelete(ctx context.Context) (Athlete, error) {
 	resp, err := c.Request(ctx, http.MethodGet, "/athlete", nil, nil)
 	if err != nil {
@@ -431,3 +431,26 @@ type Club struct {
 	Admin              bool     `json:"admin"`
 	Owner              bool     `json:"owner"`
 }
+
+// StreamSet is the raw recorded data of an activity, keyed by type. Only the
+// requested streams are set, and only if the device recorded them. Every set
+// stream has one value per recorded sample.
+type StreamSet struct {
+	Time           *Stream[int]        `json:"time,omitempty"`
+	LatLng         *Stream[[2]float64] `json:"latlng,omitempty"`
+	Distance       *Stream[float64]    `json:"distance,omitempty"`
+	Altitude       *Stream[float64]    `json:"altitude,omitempty"`
+	VelocitySmooth *Stream[float64]    `json:"velocity_smooth,omitempty"`
+	Heartrate      *Stream[int]        `json:"heartrate,omitempty"`
+	Cadence        *Stream[int]        `json:"cadence,omitempty"`
+	Watts          *Stream[int]        `json:"watts,omitempty"`
+	Moving         *Stream[bool]       `json:"moving,omitempty"`
+	GradeSmooth    *Stream[float64]    `json:"grade_smooth,omitempty"`
+}
+
+type Stream[T any] struct {
+	OriginalSize int    `json:"original_size"`
+	Resolution   string `json:"resolution"`
+	SeriesType   string `json:"series_type"`
+	Data         []T    `json:"data"`
+}